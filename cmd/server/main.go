@@ -9,14 +9,22 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/Kora1128/FinSight/internal/allocation"
 	"github.com/Kora1128/FinSight/internal/api/handlers"
 	"github.com/Kora1128/FinSight/internal/api/routes"
 	"github.com/Kora1128/FinSight/internal/broker"
 	"github.com/Kora1128/FinSight/internal/cache"
 	"github.com/Kora1128/FinSight/internal/config"
 	"github.com/Kora1128/FinSight/internal/database"
+	"github.com/Kora1128/FinSight/internal/instruments"
+	"github.com/Kora1128/FinSight/internal/marketdata"
+	"github.com/Kora1128/FinSight/internal/mutualfund"
 	"github.com/Kora1128/FinSight/internal/news"
 	"github.com/Kora1128/FinSight/internal/portfolio"
+	"github.com/Kora1128/FinSight/internal/rebalance"
+	"github.com/Kora1128/FinSight/internal/returns"
+	"github.com/Kora1128/FinSight/internal/streaming"
+	"github.com/Kora1128/FinSight/internal/tax"
 	"github.com/joho/godotenv" // Import the package
 )
 
@@ -55,52 +63,249 @@ func main() {
 		TTL:             24 * time.Hour,
 		CleanupInterval: 1 * time.Hour,
 	})
-	processor := news.NewProcessor(newsCache, cfg.OpenAIAPIKey)
-	fetcher := news.NewNewsFetcher()
-
 	// Initialize repositories
 	sessionRepo := database.NewSessionRepo(db)
 	brokerCredentialsRepo := database.NewBrokerCredentialsRepo(db)
 	portfolioRepo := database.NewPortfolioRepo(db)
+	tradeRepo := database.NewTradeRepo(db)
+	corporateActionRepo := database.NewCorporateActionRepo(db)
+	grandfatheringRepo := database.NewGrandfatheringRepo(db)
+	snapshotRepo := database.NewSnapshotRepo(db)
+	dividendRepo := database.NewDividendRepo(db)
+	holdingChangeRepo := database.NewHoldingChangeRepo(db)
+	targetRepo := database.NewTargetRepo(db)
+	navRepo := database.NewNAVRepo(db)
+	priceRepo := database.NewPriceRepo(db)
+	newsRepo := database.NewNewsRepo(db)
+	newsSourceRepo := database.NewNewsSourceRepo(db)
+
+	// Initialize news sources, seeding the global sources on first start
+	fetcher := news.NewNewsFetcherWithConfig(news.FetcherConfig{
+		Repository: newsSourceRepo,
+		Workers:    cfg.NewsFetchWorkers,
+		Timeout:    cfg.NewsFetchTimeout,
+	})
+	sourceService := news.NewSourceService(news.SourceServiceConfig{
+		Repository: newsSourceRepo,
+		Fetcher:    fetcher,
+	})
+	if seeded, err := sourceService.Seed(news.GetDefaultSources(), cfg.TrustedSources); err != nil {
+		log.Printf("Warning: failed to seed news sources: %v", err)
+	} else if seeded > 0 {
+		log.Printf("Seeded %d news sources", seeded)
+	}
+
+	// Initialize instrument store
+	instrumentSources := instruments.Sources{
+		NSEEquityPath:       cfg.NSEEquityListPath,
+		BSEEquityPath:       cfg.BSEEquityListPath,
+		KiteInstrumentsPath: cfg.KiteInstrumentsPath,
+	}
+	instrumentStore := instruments.NewStore()
+	if err := instrumentStore.Load(instrumentSources); err != nil {
+		log.Printf("Warning: failed to load instruments, symbols will not be canonicalized: %v", err)
+	} else {
+		log.Printf("Loaded %d instruments", instrumentStore.Len())
+	}
+
+	// Initialize the LLM provider and its prompt templates
+	llmProvider, err := news.NewLLMProvider(news.LLMConfig{
+		Provider:          cfg.LLMProvider,
+		BaseURL:           cfg.LLMBaseURL,
+		APIKey:            cfg.LLMAPIKey,
+		Model:             cfg.LLMModel,
+		Timeout:           cfg.LLMTimeout,
+		MaxTokens:         cfg.LLMMaxTokens,
+		RequestsPerMinute: cfg.LLMRequestsPerMinute,
+		TokensPerMinute:   cfg.LLMTokensPerMinute,
+	})
+	if err != nil {
+		log.Printf("Warning: failed to initialize LLM provider: %v", err)
+	}
+	prompts, err := news.LoadPromptTemplatesFile(cfg.LLMPromptsPath)
+	if err != nil {
+		log.Printf("Warning: failed to load prompt templates, using the defaults: %v", err)
+	}
+
+	// Symbols suggested by the LLM are checked against the instrument master when it is loaded, else the dictionary
+	var symbolValidator news.SymbolValidator
+	if instrumentStore.Len() > 0 {
+		symbolValidator = news.NewInstrumentSymbolValidator(instrumentStore)
+	}
+
+	// Initialize the stock resolver, falling back to OpenAI alone when the configured one is unavailable
+	stockResolver, err := news.NewStockResolver(news.StockResolverConfig{
+		Resolvers:      cfg.StockResolvers,
+		DictionaryPath: cfg.StockDictionaryPath,
+		LLM:            llmProvider,
+		Prompts:        prompts,
+		Symbols:        symbolValidator,
+	})
+	if err != nil {
+		log.Printf("Warning: failed to initialize stock resolver, using OpenAI: %v", err)
+	}
+
+	// Initialize the sentiment analyzer, falling back to keywords when the configured one is unavailable
+	sentimentAnalyzer, err := news.NewSentimentAnalyzer(news.SentimentAnalyzerConfig{
+		Analyzer:   cfg.SentimentAnalyzer,
+		LexiconDir: cfg.SentimentLexiconDir,
+		LLM:        llmProvider,
+		Prompts:    prompts,
+	})
+	if err != nil {
+		log.Printf("Warning: failed to initialize sentiment analyzer, using keywords: %v", err)
+	}
+
+	// Initialize news processor, restoring recent recommendations from the archive
+	processor := news.NewProcessorWithConfig(news.ProcessorConfig{
+		Cache:             newsCache,
+		Repository:        newsRepo,
+		StockResolver:     stockResolver,
+		SentimentAnalyzer: sentimentAnalyzer,
+		OpenAIAPIKey:      cfg.OpenAIAPIKey,
+	})
+	if loaded, err := processor.WarmCache(1000); err != nil {
+		log.Printf("Warning: failed to load archived recommendations: %v", err)
+	} else {
+		log.Printf("Loaded %d archived recommendations", loaded)
+	}
 
 	// Initialize broker manager
 	brokerManager := broker.NewBrokerManager(brokerCredentialsRepo, appCache, 24*time.Hour, 1*time.Hour)
 
+	// Initialize mutual fund NAV service
+	navService := mutualfund.NewService(mutualfund.ServiceConfig{
+		Repository: navRepo,
+		Source:     cfg.NAVAllSource,
+	})
+
+	// Initialize market data service
+	marketDataService := marketdata.NewService(marketdata.ServiceConfig{
+		Repository:   priceRepo,
+		Directory:    cfg.BhavcopyDir,
+		URLTemplates: cfg.BhavcopyURLTemplates,
+	})
+
 	// Initialize user portfolio service
 	userPortfolioService := portfolio.NewUserService(portfolio.UserServiceConfig{
-		BrokerManager:       brokerManager,
-		PortfolioRepository: portfolioRepo,
+		BrokerManager:             brokerManager,
+		PortfolioRepository:       portfolioRepo,
+		TradeRepository:           tradeRepo,
+		DividendRepository:        dividendRepo,
+		CorporateActionRepository: corporateActionRepo,
+		SnapshotRepository:        snapshotRepo,
+		HoldingChangeRepository:   holdingChangeRepo,
+		Instruments:               instrumentStore,
+		NAVs:                      navService,
+		Prices:                    marketDataService,
+	})
+
+	// Initialize live portfolio streaming on a fixed ticker session, or the Zerodha session of the ticker account
+	var tickerSessions streaming.SessionProvider
+	switch {
+	case cfg.KiteTickerAPIKey != "" && cfg.KiteTickerAccessToken != "":
+		tickerSessions = streaming.StaticSession{APIKey: cfg.KiteTickerAPIKey, AccessToken: cfg.KiteTickerAccessToken}
+	case cfg.KiteTickerUserID != "":
+		tickerSessions = streaming.AccountSession{Sessions: brokerManager, UserID: cfg.KiteTickerUserID}
+	}
+	var streamService *streaming.Service
+	if tickerSessions != nil {
+		streamService = streaming.NewService(streaming.ServiceConfig{
+			Source:      streaming.NewKiteSource(tickerSessions),
+			Portfolios:  userPortfolioService,
+			Instruments: instrumentStore,
+		})
+	} else {
+		log.Println("Warning: neither KITE_TICKER_API_KEY and KITE_TICKER_ACCESS_TOKEN nor KITE_TICKER_USER_ID is set, live portfolio streaming is disabled")
+	}
+
+	// Initialize returns service
+	returnsService := returns.NewService(returns.ServiceConfig{
+		TradeRepository:           tradeRepo,
+		CorporateActionRepository: corporateActionRepo,
+		SnapshotRepository:        snapshotRepo,
+		DividendRepository:        dividendRepo,
+	})
+
+	// Initialize allocation service
+	sectorMaster, err := allocation.LoadSectorMasterFile(cfg.SectorMasterPath)
+	if err != nil {
+		log.Printf("Warning: failed to load sector master from %s, holdings will be unclassified: %v", cfg.SectorMasterPath, err)
+		sectorMaster = allocation.NewSectorMaster()
+	}
+	sectorMaster.SetSchemeCategories(navService)
+	allocationService := allocation.NewService(allocation.ServiceConfig{
+		SnapshotRepository: snapshotRepo,
+		SectorMaster:       sectorMaster,
+	})
+
+	// Initialize rebalancing service
+	rebalanceService := rebalance.NewService(rebalance.ServiceConfig{
+		TargetRepository:   targetRepo,
+		HoldingsRepository: portfolioRepo,
+		Classifier:         sectorMaster,
 	})
 
-	// Set up background context for periodic news fetching
+	// Initialize tax service
+	taxService := tax.NewService(tax.ServiceConfig{
+		TradeRepository:           tradeRepo,
+		CorporateActionRepository: corporateActionRepo,
+		FairMarketValueRepository: grandfatheringRepo,
+		HoldingRepository:         snapshotRepo,
+		Instruments:               instrumentStore,
+	})
+
+	// Set up background context for periodic jobs
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Start periodic news fetching
+	// Start periodic instrument reloads
+	go instrumentStore.RunReload(ctx, instrumentSources, cfg.InstrumentsReloadInterval)
+
+	// Start daily mutual fund NAV ingestion
+	go navService.RunDailyIngest(ctx, cfg.NAVAllIngestInterval)
+
+	// Start daily bhavcopy ingestion
+	go marketDataService.RunDailyIngest(ctx)
+
+	// Start end-of-day portfolio snapshots
+	go userPortfolioService.RunEndOfDaySnapshots(ctx)
+
+	// Start the live price feed
+	if streamService != nil {
+		go streamService.Run(ctx)
+	}
+
+	// Start periodic news fetching; each source is fetched on its own refresh interval
 	go func() {
-		ticker := time.NewTicker(3 * time.Hour)
+		ticker := time.NewTicker(time.Minute)
 		defer ticker.Stop()
 
 		for {
+			newsItems, err := fetcher.FetchNews(ctx)
+			if err != nil {
+				log.Printf("Error fetching news: %v", err)
+			} else if len(newsItems) > 0 {
+				recommendations := processor.ProcessNews(ctx, newsItems)
+				log.Printf("Processed %d news items, generated %d recommendations", len(newsItems), len(recommendations))
+			}
+
 			select {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				newsItems, err := fetcher.FetchNews(ctx)
-				if err != nil {
-					log.Printf("Error fetching news: %v", err)
-					continue
-				}
-
-				recommendations := processor.ProcessNews(ctx, newsItems)
-				log.Printf("Processed %d news items, generated %d recommendations", len(newsItems), len(recommendations))
 			}
 		}
 	}()
 
 	// Create handlers
-	newsHandler := handlers.NewNewsHandler(processor, fetcher)
-	userPortfolioHandler := handlers.NewUserPortfolioHandler(userPortfolioService)
+	newsHandler := handlers.NewNewsHandler(processor, sourceService)
+	userPortfolioHandler := handlers.NewUserPortfolioHandler(userPortfolioService, returnsService, allocationService)
+	taxHandler := handlers.NewTaxHandler(taxService)
+	rebalanceHandler := handlers.NewRebalanceHandler(rebalanceService)
+	instrumentHandler := handlers.NewInstrumentHandler(instrumentStore)
+	streamHandler := handlers.NewStreamHandler(streamService)
+	adminHandler := handlers.NewAdminHandler(userPortfolioService, taxService)
 	userRepo := database.NewUserRepo(db)
 	sessionHandler := handlers.NewSessionHandler(
 		appCache,
@@ -114,10 +319,16 @@ func main() {
 	router := routes.SetupRouter(
 		newsHandler,
 		userPortfolioHandler,
+		taxHandler,
+		rebalanceHandler,
+		instrumentHandler,
+		streamHandler,
 		sessionHandler,
+		adminHandler,
 		appCache, // Still keeping this for now in case other handlers need it
 		sessionRepo,
 		userRepo,
+		cfg.AdminAPIToken,
 	)
 
 	// Create HTTP server
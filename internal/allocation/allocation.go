@@ -0,0 +1,112 @@
+package allocation
+
+import (
+	"sort"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// drillDown is the dimension each top-level dimension's buckets are broken down by
+var drillDown = map[models.AllocationDimension]models.AllocationDimension{
+	models.AllocationBySector:     models.AllocationByIndustry,
+	models.AllocationByAssetClass: models.AllocationBySector,
+	models.AllocationByMarketCap:  models.AllocationBySector,
+	models.AllocationByPlatform:   models.AllocationByAssetClass,
+}
+
+// Build groups holdings by the given dimension and weights each bucket by current value,
+// breaking every bucket down one level further: sectors by industry, platforms by asset class
+// and asset classes and market caps by sector
+func Build(holdings []models.Holding, master *SectorMaster, by models.AllocationDimension) *models.Allocation {
+	items := make([]models.AllocationHolding, 0, len(holdings))
+	total := 0.0
+	for _, holding := range holdings {
+		if holding.CurrentValue <= 0 {
+			continue
+		}
+		items = append(items, models.AllocationHolding{
+			ItemName:                 holding.ItemName,
+			ISIN:                     holding.ISIN,
+			Platform:                 holding.Platform,
+			Value:                    holding.CurrentValue,
+			InstrumentClassification: master.Classify(holding),
+		})
+		total += holding.CurrentValue
+	}
+	for i := range items {
+		items[i].Weight = weight(items[i].Value, total)
+	}
+
+	return &models.Allocation{
+		By:         by,
+		TotalValue: total,
+		Buckets:    group(items, by, total, true),
+	}
+}
+
+// group buckets items by dimension, largest bucket first, optionally breaking each bucket down a level
+func group(items []models.AllocationHolding, by models.AllocationDimension, total float64, withDrillDown bool) []models.AllocationBucket {
+	index := make(map[string]int)
+	buckets := []models.AllocationBucket{}
+	for _, item := range items {
+		key := dimensionKey(item, by)
+		i, ok := index[key]
+		if !ok {
+			i = len(buckets)
+			index[key] = i
+			buckets = append(buckets, models.AllocationBucket{Key: key, Holdings: []models.AllocationHolding{}})
+		}
+		buckets[i].Value += item.Value
+		buckets[i].Holdings = append(buckets[i].Holdings, item)
+	}
+
+	for i := range buckets {
+		buckets[i].Weight = weight(buckets[i].Value, total)
+		sortHoldings(buckets[i].Holdings)
+		if next, ok := drillDown[by]; ok && withDrillDown {
+			buckets[i].DrillDownBy = next
+			buckets[i].Breakdown = group(buckets[i].Holdings, next, total, false)
+		}
+	}
+	sort.SliceStable(buckets, func(i, j int) bool {
+		if buckets[i].Value != buckets[j].Value {
+			return buckets[i].Value > buckets[j].Value
+		}
+		return buckets[i].Key < buckets[j].Key
+	})
+	return buckets
+}
+
+// dimensionKey returns the bucket an item falls in along a dimension
+func dimensionKey(item models.AllocationHolding, by models.AllocationDimension) string {
+	switch by {
+	case models.AllocationByAssetClass:
+		return string(item.AssetClass)
+	case models.AllocationByMarketCap:
+		return string(item.MarketCap)
+	case models.AllocationByPlatform:
+		return item.Platform
+	case models.AllocationByIndustry:
+		return item.Industry
+	default:
+		return item.Sector
+	}
+}
+
+// sortHoldings orders holdings largest first
+func sortHoldings(holdings []models.AllocationHolding) {
+	sort.SliceStable(holdings, func(i, j int) bool {
+		if holdings[i].Value != holdings[j].Value {
+			return holdings[i].Value > holdings[j].Value
+		}
+		return holdings[i].ItemName < holdings[j].ItemName
+	})
+}
+
+// weight returns value as a percentage of total
+func weight(value, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return value / total * 100
+}
@@ -0,0 +1,195 @@
+package allocation
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadTestMaster(t *testing.T) *SectorMaster {
+	t.Helper()
+	master, err := LoadSectorMasterFile("testdata/sector_master.csv")
+	assert.NoError(t, err)
+	return master
+}
+
+func TestLoadSectorMaster(t *testing.T) {
+	master := loadTestMaster(t)
+	assert.Equal(t, 7, master.Len())
+
+	tests := []struct {
+		name    string
+		holding models.Holding
+		want    models.InstrumentClassification
+	}{
+		{
+			name:    "by ISIN",
+			holding: models.Holding{ItemName: "Infosys", ISIN: "INE009A01021", Type: models.HoldingTypeStock},
+			want:    models.InstrumentClassification{AssetClass: models.AllocationClassEquity, MarketCap: models.MarketCapLarge, Sector: "Information Technology", Industry: "IT Services & Consulting"},
+		},
+		{
+			name:    "by symbol when ISIN is missing, case-insensitive values",
+			holding: models.Holding{ItemName: "tcs", Type: models.HoldingTypeStock},
+			want:    models.InstrumentClassification{AssetClass: models.AllocationClassEquity, MarketCap: models.MarketCapLarge, Sector: "Information Technology", Industry: "IT Services & Consulting"},
+		},
+		{
+			name:    "empty columns are unclassified",
+			holding: models.Holding{ItemName: "Example Liquid Fund", ISIN: "INF000LIQUID", Type: models.HoldingTypeMutualFund},
+			want:    models.InstrumentClassification{AssetClass: models.AllocationClassCash, MarketCap: models.MarketCapUnclassified, Sector: models.Unclassified, Industry: models.Unclassified},
+		},
+		{
+			name:    "unknown stock counts as equity",
+			holding: models.Holding{ItemName: "UNLISTEDCO", ISIN: "INE999Z01011", Type: models.HoldingTypeStock},
+			want:    models.InstrumentClassification{AssetClass: models.AllocationClassEquity, MarketCap: models.MarketCapUnclassified, Sector: models.Unclassified, Industry: models.Unclassified},
+		},
+		{
+			name:    "unknown fund is unclassified",
+			holding: models.Holding{ItemName: "Some Fund", ISIN: "INF999Z01011", Type: models.HoldingTypeMutualFund},
+			want:    models.InstrumentClassification{AssetClass: models.AllocationClassUnclassified, MarketCap: models.MarketCapUnclassified, Sector: models.Unclassified, Industry: models.Unclassified},
+		},
+		{
+			name:    "unknown bond counts as debt",
+			holding: models.Holding{ItemName: "7.26% GS 2033", ISIN: "IN0020220151", Type: models.HoldingTypeBond},
+			want:    models.InstrumentClassification{AssetClass: models.AllocationClassDebt, MarketCap: models.MarketCapUnclassified, Sector: models.Unclassified, Industry: models.Unclassified},
+		},
+		{
+			name:    "unknown sovereign gold bond counts as gold",
+			holding: models.Holding{ItemName: "SGBAUG28V", ISIN: "IN0020200112", Type: models.HoldingTypeSGB},
+			want:    models.InstrumentClassification{AssetClass: models.AllocationClassGold, MarketCap: models.MarketCapUnclassified, Sector: models.Unclassified, Industry: models.Unclassified},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, master.Classify(tt.holding))
+		})
+	}
+}
+
+// fakeCategories maps scheme ISINs to AMFI categories
+type fakeCategories map[string]string
+
+func (c fakeCategories) SchemeCategory(isin string) (string, bool) {
+	category, ok := c[isin]
+	return category, ok
+}
+
+func TestClassifyFundsByCategory(t *testing.T) {
+	master := loadTestMaster(t)
+	master.SetSchemeCategories(fakeCategories{
+		"INF179K01YV8": "Open Ended Schemes(Equity Scheme - Large Cap Fund)",
+		"INF209K01YY7": "Open Ended Schemes(Debt Scheme - Banking and PSU Fund)",
+		"INF200KA1YT4": "Close Ended Schemes(Income)",
+		"INF846K01ZL0": "Open Ended Schemes(Hybrid Scheme - Balanced Advantage)",
+		"INF204K01XI3": "Open Ended Schemes(Debt Scheme - Overnight Fund)",
+		"INF769K01AX2": "Open Ended Schemes(Other Scheme - Index Funds)",
+		"INF204KC1402": "Open Ended Schemes(Other Scheme - Index Funds)",
+	})
+
+	tests := []struct {
+		name    string
+		holding models.Holding
+		want    models.AllocationClass
+	}{
+		{"equity scheme", models.Holding{ItemName: "HDFC Large Cap Fund", ISIN: "INF179K01YV8", Type: models.HoldingTypeMutualFund}, models.AllocationClassEquity},
+		{"debt scheme", models.Holding{ItemName: "ABSL Banking & PSU Debt Fund", ISIN: "INF209K01YY7", Type: models.HoldingTypeMutualFund}, models.AllocationClassDebt},
+		{"close ended income", models.Holding{ItemName: "SBI FMP 42", ISIN: "INF200KA1YT4", Type: models.HoldingTypeMutualFund}, models.AllocationClassDebt},
+		{"hybrid scheme", models.Holding{ItemName: "Edelweiss Balanced Advantage", ISIN: "INF846K01ZL0", Type: models.HoldingTypeMutualFund}, models.AllocationClassHybrid},
+		{"overnight fund is cash", models.Holding{ItemName: "Nippon Overnight Fund", ISIN: "INF204K01XI3", Type: models.HoldingTypeMutualFund}, models.AllocationClassCash},
+		{"equity index fund", models.Holding{ItemName: "Mirae Nifty 50 Index Fund", ISIN: "INF769K01AX2", Type: models.HoldingTypeMutualFund}, models.AllocationClassEquity},
+		{"debt index fund by name", models.Holding{ItemName: "Nippon Nifty SDL Plus G-Sec Index Fund", ISIN: "INF204KC1402", Type: models.HoldingTypeMutualFund}, models.AllocationClassDebt},
+		{"gold ETF by name", models.Holding{ItemName: "SETFGOLD", ISIN: "INF200KA16D8", Type: models.HoldingTypeETF}, models.AllocationClassGold},
+		{"unknown ETF counts as equity", models.Holding{ItemName: "NIFTYBEES", ISIN: "INF204KB14I2", Type: models.HoldingTypeETF}, models.AllocationClassEquity},
+		{"master takes precedence", models.Holding{ItemName: "Example Liquid Fund", ISIN: "INF000LIQUID", Type: models.HoldingTypeMutualFund}, models.AllocationClassCash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, master.Classify(tt.holding).AssetClass)
+		})
+	}
+}
+
+func TestLoadSectorMasterErrors(t *testing.T) {
+	_, err := LoadSectorMaster(strings.NewReader("isin,symbol,name\nINE009A01021,INFY,Infosys\n"))
+	assert.ErrorIs(t, err, ErrInvalidMaster)
+
+	_, err = LoadSectorMaster(strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrInvalidMaster)
+
+	_, err = LoadSectorMasterFile("testdata/missing.csv")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestBuild(t *testing.T) {
+	master := loadTestMaster(t)
+	holdings := []models.Holding{
+		{ItemName: "INFY", ISIN: "INE009A01021", Platform: models.PlatformZerodha, Type: models.HoldingTypeStock, CurrentValue: 3000},
+		{ItemName: "TCS", ISIN: "INE467B01029", Platform: models.PlatformICICIDirect, Type: models.HoldingTypeStock, CurrentValue: 1000},
+		{ItemName: "HDFCBANK", ISIN: "INE040A01034", Platform: models.PlatformZerodha, Type: models.HoldingTypeStock, CurrentValue: 2000},
+		{ItemName: "SBIN", ISIN: "INE062A01020", Platform: models.PlatformZerodha, Type: models.HoldingTypeStock, CurrentValue: 1000},
+		{ItemName: "MIDCAPCO", ISIN: "INE00EXAMPLE", Platform: models.PlatformZerodha, Type: models.HoldingTypeStock, CurrentValue: 1000},
+		{ItemName: "GOLDBEES", ISIN: "INF204KB17I5", Platform: models.PlatformZerodha, Type: models.HoldingTypeStock, CurrentValue: 2000},
+		// Exited holdings carry no weight
+		{ItemName: "ITC", ISIN: "INE154A01025", Platform: models.PlatformZerodha, Type: models.HoldingTypeStock, CurrentValue: 0},
+	}
+
+	t.Run("by sector with industry drill-down", func(t *testing.T) {
+		allocation := Build(holdings, master, models.AllocationBySector)
+		assert.Equal(t, 10000.0, allocation.TotalValue)
+		assert.Len(t, allocation.Buckets, 3)
+
+		financials := allocation.Buckets[0]
+		assert.Equal(t, "Financial Services", financials.Key)
+		assert.Equal(t, 4000.0, financials.Value)
+		assert.InDelta(t, 40.0, financials.Weight, 1e-9)
+		assert.Equal(t, models.AllocationByIndustry, financials.DrillDownBy)
+		assert.Len(t, financials.Breakdown, 2)
+		assert.Equal(t, "Private Sector Bank", financials.Breakdown[0].Key)
+		assert.InDelta(t, 30.0, financials.Breakdown[0].Weight, 1e-9)
+		assert.Equal(t, "Public Sector Bank", financials.Breakdown[1].Key)
+		assert.Empty(t, financials.Breakdown[0].Breakdown)
+
+		it := allocation.Buckets[1]
+		assert.Equal(t, "Information Technology", it.Key)
+		assert.Equal(t, "INFY", it.Holdings[0].ItemName)
+		assert.InDelta(t, 30.0, it.Holdings[0].Weight, 1e-9)
+
+		assert.Equal(t, "Gold", allocation.Buckets[2].Key)
+	})
+
+	t.Run("by asset class", func(t *testing.T) {
+		allocation := Build(holdings, master, models.AllocationByAssetClass)
+		assert.Len(t, allocation.Buckets, 2)
+		assert.Equal(t, "equity", allocation.Buckets[0].Key)
+		assert.InDelta(t, 80.0, allocation.Buckets[0].Weight, 1e-9)
+		assert.Equal(t, models.AllocationBySector, allocation.Buckets[0].DrillDownBy)
+		assert.Equal(t, "gold", allocation.Buckets[1].Key)
+	})
+
+	t.Run("by market cap", func(t *testing.T) {
+		allocation := Build(holdings, master, models.AllocationByMarketCap)
+		keys := []string{}
+		for _, bucket := range allocation.Buckets {
+			keys = append(keys, bucket.Key)
+		}
+		assert.Equal(t, []string{"large", "unclassified", "mid"}, keys)
+	})
+
+	t.Run("by platform", func(t *testing.T) {
+		allocation := Build(holdings, master, models.AllocationByPlatform)
+		assert.Len(t, allocation.Buckets, 2)
+		assert.Equal(t, models.PlatformZerodha, allocation.Buckets[0].Key)
+		assert.InDelta(t, 90.0, allocation.Buckets[0].Weight, 1e-9)
+		assert.Equal(t, models.AllocationByAssetClass, allocation.Buckets[0].DrillDownBy)
+	})
+
+	t.Run("empty portfolio", func(t *testing.T) {
+		allocation := Build(nil, master, models.AllocationBySector)
+		assert.Equal(t, 0.0, allocation.TotalValue)
+		assert.Empty(t, allocation.Buckets)
+	})
+}
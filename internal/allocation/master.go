@@ -0,0 +1,222 @@
+package allocation
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// Common sector master errors
+var (
+	ErrInvalidMaster = errors.New("invalid sector master")
+)
+
+// masterColumns are the header columns a sector master CSV must contain, in any order
+var masterColumns = []string{"isin", "symbol", "name", "asset_class", "market_cap", "sector", "industry"}
+
+// SchemeCategories looks up the AMFI category of mutual fund and ETF scheme ISINs
+type SchemeCategories interface {
+	// SchemeCategory returns the AMFI category of a scheme ISIN, e.g. "Open Ended Schemes(Equity Scheme - Large Cap Fund)"
+	SchemeCategory(isin string) (string, bool)
+}
+
+// SectorMaster classifies instruments by asset class, market cap, sector and industry
+// from a locally maintained CSV keyed by ISIN and exchange symbol
+type SectorMaster struct {
+	byISIN     map[string]models.InstrumentClassification
+	bySymbol   map[string]models.InstrumentClassification
+	categories SchemeCategories
+}
+
+// NewSectorMaster creates an empty sector master, which leaves every holding unclassified
+func NewSectorMaster() *SectorMaster {
+	return &SectorMaster{
+		byISIN:   make(map[string]models.InstrumentClassification),
+		bySymbol: make(map[string]models.InstrumentClassification),
+	}
+}
+
+// LoadSectorMasterFile reads a sector master CSV from disk
+func LoadSectorMasterFile(path string) (*SectorMaster, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return LoadSectorMaster(file)
+}
+
+// LoadSectorMaster reads a sector master CSV with the columns
+// isin, symbol, name, asset_class, market_cap, sector and industry
+func LoadSectorMaster(r io.Reader) (*SectorMaster, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMaster, err)
+	}
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		index[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range masterColumns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidMaster, column)
+		}
+	}
+
+	master := NewSectorMaster()
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidMaster, line, err)
+		}
+		field := func(column string) string {
+			return strings.TrimSpace(record[index[column]])
+		}
+
+		classification := models.InstrumentClassification{
+			AssetClass: parseAllocationClass(field("asset_class")),
+			MarketCap:  parseMarketCap(field("market_cap")),
+			Sector:     orUnclassified(field("sector")),
+			Industry:   orUnclassified(field("industry")),
+		}
+		if isin := strings.ToUpper(field("isin")); isin != "" {
+			master.byISIN[isin] = classification
+		}
+		if symbol := strings.ToUpper(field("symbol")); symbol != "" {
+			master.bySymbol[symbol] = classification
+		}
+	}
+
+	return master, nil
+}
+
+// Len returns the number of instruments in the master
+func (m *SectorMaster) Len() int {
+	return len(m.byISIN)
+}
+
+// SetSchemeCategories sets where the asset class of funds missing from the master is looked up
+func (m *SectorMaster) SetSchemeCategories(categories SchemeCategories) {
+	m.categories = categories
+}
+
+// Classify returns the classification of a holding, looked up by ISIN and then by symbol.
+// Holdings missing from the master are classified by type: stocks count as equity, bonds as debt
+// and sovereign gold bonds as gold. Mutual funds and ETFs take the asset class of their AMFI scheme
+// category, or failing that of their name; ETFs with neither count as equity.
+func (m *SectorMaster) Classify(holding models.Holding) models.InstrumentClassification {
+	if classification, ok := m.byISIN[strings.ToUpper(holding.ISIN)]; ok && holding.ISIN != "" {
+		return classification
+	}
+	if classification, ok := m.bySymbol[strings.ToUpper(holding.ItemName)]; ok {
+		return classification
+	}
+
+	classification := models.InstrumentClassification{
+		AssetClass: models.AllocationClassUnclassified,
+		MarketCap:  models.MarketCapUnclassified,
+		Sector:     models.Unclassified,
+		Industry:   models.Unclassified,
+	}
+	switch holding.Type {
+	case models.HoldingTypeStock:
+		classification.AssetClass = models.AllocationClassEquity
+	case models.HoldingTypeBond:
+		classification.AssetClass = models.AllocationClassDebt
+	case models.HoldingTypeSGB:
+		classification.AssetClass = models.AllocationClassGold
+	case models.HoldingTypeMutualFund, models.HoldingTypeETF:
+		var category string
+		if m.categories != nil && holding.ISIN != "" {
+			category, _ = m.categories.SchemeCategory(holding.ISIN)
+		}
+		classification.AssetClass = schemeAssetClass(category, holding.ItemName)
+		if classification.AssetClass == models.AllocationClassUnclassified && holding.Type == models.HoldingTypeETF {
+			classification.AssetClass = models.AllocationClassEquity
+		}
+	}
+	return classification
+}
+
+// schemeAssetClass returns the asset class of a fund from its AMFI scheme category, such as
+// "Open Ended Schemes(Debt Scheme - Liquid Fund)", falling back to its name for categories
+// like index funds and fund of funds that span asset classes
+func schemeAssetClass(category, name string) models.AllocationClass {
+	category = strings.ToLower(category)
+	switch {
+	case containsAny(category, "liquid fund", "overnight fund", "money market"):
+		return models.AllocationClassCash
+	case containsAny(category, "equity scheme", "elss", "(growth)"):
+		return models.AllocationClassEquity
+	case containsAny(category, "debt scheme", "(income)", "gilt"):
+		return models.AllocationClassDebt
+	case containsAny(category, "hybrid scheme", "solution oriented"):
+		return models.AllocationClassHybrid
+	case strings.Contains(category, "gold"):
+		return models.AllocationClassGold
+	}
+
+	name = strings.ToLower(name)
+	switch {
+	case strings.Contains(name, "gold"):
+		return models.AllocationClassGold
+	case containsAny(name, "liquid", "overnight", "money market"):
+		return models.AllocationClassCash
+	case containsAny(name, "gilt", "bond", "g-sec", "gsec", "sdl", "debt"):
+		return models.AllocationClassDebt
+	case containsAny(category, "index fund", "other etf", "fof overseas"):
+		return models.AllocationClassEquity
+	}
+	return models.AllocationClassUnclassified
+}
+
+// containsAny reports whether s contains any of the substrings
+func containsAny(s string, substrings ...string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(s, substring) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAllocationClass parses an asset class column value
+func parseAllocationClass(value string) models.AllocationClass {
+	switch class := models.AllocationClass(strings.ToLower(value)); class {
+	case models.AllocationClassEquity, models.AllocationClassDebt, models.AllocationClassGold,
+		models.AllocationClassCash, models.AllocationClassHybrid:
+		return class
+	default:
+		return models.AllocationClassUnclassified
+	}
+}
+
+// parseMarketCap parses a market cap column value
+func parseMarketCap(value string) models.MarketCapBucket {
+	switch bucket := models.MarketCapBucket(strings.ToLower(value)); bucket {
+	case models.MarketCapLarge, models.MarketCapMid, models.MarketCapSmall:
+		return bucket
+	default:
+		return models.MarketCapUnclassified
+	}
+}
+
+// orUnclassified returns value, or Unclassified when it is empty
+func orUnclassified(value string) string {
+	if value == "" {
+		return models.Unclassified
+	}
+	return value
+}
@@ -0,0 +1,52 @@
+package allocation
+
+import (
+	"context"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// SnapshotRepository defines the per-platform holdings the allocation service reads
+type SnapshotRepository interface {
+	// GetLatestSnapshotHoldings retrieves the holdings of the most recent snapshot of a user
+	GetLatestSnapshotHoldings(userID string) ([]models.Holding, bool, error)
+}
+
+// ServiceConfig holds configuration for the allocation service
+type ServiceConfig struct {
+	SnapshotRepository SnapshotRepository
+	SectorMaster       *SectorMaster
+}
+
+// Service computes allocation breakdowns of users' portfolios
+type Service struct {
+	snapshotRepository SnapshotRepository
+	sectorMaster       *SectorMaster
+}
+
+// NewService creates a new allocation service
+func NewService(config ServiceConfig) *Service {
+	if config.SectorMaster == nil {
+		config.SectorMaster = NewSectorMaster()
+	}
+
+	return &Service{
+		snapshotRepository: config.SnapshotRepository,
+		sectorMaster:       config.SectorMaster,
+	}
+}
+
+// Allocation breaks down the holdings of a user's last refresh along a dimension (default: asset class).
+// Holdings are taken per platform, before holdings with the same ISIN are merged.
+func (s *Service) Allocation(ctx context.Context, userID string, by models.AllocationDimension) (*models.Allocation, error) {
+	if by == "" {
+		by = models.AllocationByAssetClass
+	}
+
+	holdings, _, err := s.snapshotRepository.GetLatestSnapshotHoldings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return Build(holdings, s.sectorMaster, by), nil
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Kora1128/FinSight/internal/importer/corporateaction"
+	"github.com/Kora1128/FinSight/internal/marketdata"
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/Kora1128/FinSight/internal/portfolio"
+	"github.com/Kora1128/FinSight/internal/tax"
+	"github.com/gin-gonic/gin"
+)
+
+// maxReferenceFileSize bounds the reference data files admins upload
+const maxReferenceFileSize = 50 << 20
+
+// AdminHandler handles uploads of the market reference data shared by all users
+type AdminHandler struct {
+	userPortfolioService *portfolio.UserService
+	taxService           *tax.Service
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(userPortfolioService *portfolio.UserService, taxService *tax.Service) *AdminHandler {
+	return &AdminHandler{
+		userPortfolioService: userPortfolioService,
+		taxService:           taxService,
+	}
+}
+
+// ImportCorporateActions stores the splits and bonus issues of an uploaded corporate actions CSV
+func (h *AdminHandler) ImportCorporateActions(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.CorporateActionImportResponse{
+			Success: false,
+			Error:   "Corporate actions file is required",
+		})
+		return
+	}
+	if fileHeader.Size > maxReferenceFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, models.CorporateActionImportResponse{
+			Success: false,
+			Error:   "Corporate actions file is too large",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.CorporateActionImportResponse{
+			Success: false,
+			Error:   "Failed to read corporate actions file: " + err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.userPortfolioService.ImportCorporateActions(file)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, corporateaction.ErrInvalidFile) || errors.Is(err, corporateaction.ErrUnknownFormat) ||
+			errors.Is(err, corporateaction.ErrInvalidRow) || errors.Is(err, corporateaction.ErrNoActions) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, models.CorporateActionImportResponse{
+			Success: false,
+			Error:   "Failed to import corporate actions: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.CorporateActionImportResponse{
+		Success: true,
+		Data:    *result,
+	})
+}
+
+// ImportFairMarketValues stores the 31-Jan-2018 fair market values of uploaded bhavcopies and value lists
+func (h *AdminHandler) ImportFairMarketValues(c *gin.Context) {
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["file"]) == 0 {
+		c.JSON(http.StatusBadRequest, models.FairMarketValueImportResponse{
+			Success: false,
+			Error:   "At least one bhavcopy or fair market value file is required",
+		})
+		return
+	}
+
+	var files [][]byte
+	for _, fileHeader := range form.File["file"] {
+		if fileHeader.Size > maxReferenceFileSize {
+			c.JSON(http.StatusRequestEntityTooLarge, models.FairMarketValueImportResponse{
+				Success: false,
+				Error:   "File " + fileHeader.Filename + " is too large",
+			})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.FairMarketValueImportResponse{
+				Success: false,
+				Error:   "Failed to read " + fileHeader.Filename + ": " + err.Error(),
+			})
+			return
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.FairMarketValueImportResponse{
+				Success: false,
+				Error:   "Failed to read " + fileHeader.Filename + ": " + err.Error(),
+			})
+			return
+		}
+		files = append(files, data)
+	}
+
+	result, err := h.taxService.ImportFairMarketValues(files)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, tax.ErrInvalidFairMarketValues) || errors.Is(err, marketdata.ErrInvalidBhavcopy) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, models.FairMarketValueImportResponse{
+			Success: false,
+			Error:   "Failed to import fair market values: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.FairMarketValueImportResponse{
+		Success: true,
+		Data:    *result,
+	})
+}
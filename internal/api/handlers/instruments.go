@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Kora1128/FinSight/internal/instruments"
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// InstrumentHandler handles instrument lookup HTTP requests
+type InstrumentHandler struct {
+	store *instruments.Store
+}
+
+// NewInstrumentHandler creates a new instrument handler
+func NewInstrumentHandler(store *instruments.Store) *InstrumentHandler {
+	return &InstrumentHandler{
+		store: store,
+	}
+}
+
+// SearchInstruments finds instruments by ISIN, exchange symbol, BSE code or company name
+func (h *InstrumentHandler) SearchInstruments(c *gin.Context) {
+	var req models.InstrumentSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.InstrumentSearchResponse{
+			Success: false,
+			Error:   "Query parameter q is required",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.InstrumentSearchResponse{
+		Success: true,
+		Data:    h.store.Search(req.Query, req.Limit),
+	})
+}
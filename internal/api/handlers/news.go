@@ -11,39 +11,50 @@ import (
 
 // Common errors
 var (
-	ErrInvalidLimit   = errors.New("invalid limit parameter")
-	ErrMissingSymbol  = errors.New("stock symbol is required")
-	ErrInvalidSource  = errors.New("invalid source configuration")
-	ErrSourceExists   = errors.New("source already exists")
-	ErrSourceNotFound = errors.New("source not found")
-	ErrInvalidRequest = errors.New("invalid request")
+	ErrInvalidLimit    = errors.New("invalid limit parameter")
+	ErrMissingSymbol   = errors.New("stock symbol is required")
+	ErrInvalidSource   = errors.New("invalid source configuration")
+	ErrSourceExists    = errors.New("source already exists")
+	ErrSourceNotFound  = errors.New("source not found")
+	ErrInvalidRequest  = errors.New("invalid request")
+	ErrInvalidSourceID = errors.New("source ID must be numeric")
 )
 
 // NewsHandler handles news-related HTTP requests
 type NewsHandler struct {
-	processor *news.Processor
-	fetcher   *news.NewsFetcher
+	processor     *news.Processor
+	sourceService *news.SourceService
 }
 
 // NewNewsHandler creates a new news handler
-func NewNewsHandler(processor *news.Processor, fetcher *news.NewsFetcher) *NewsHandler {
+func NewNewsHandler(processor *news.Processor, sourceService *news.SourceService) *NewsHandler {
 	return &NewsHandler{
-		processor: processor,
-		fetcher:   fetcher,
+		processor:     processor,
+		sourceService: sourceService,
 	}
 }
 
-// GetRecommendations returns all recommendations
+// GetRecommendations returns all recommendations from global news
 func (h *NewsHandler) GetRecommendations(c *gin.Context) {
-	recommendations := h.processor.GetLatestRecommendations(100) // Limit to 100 recommendations
+	recommendations := h.processor.GetLatestRecommendations("", 100) // Limit to 100 recommendations
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
 		"data":   recommendations,
 	})
 }
 
-// GetLatestRecommendations returns the most recent recommendations
+// GetLatestRecommendations returns the most recent recommendations from global news
 func (h *NewsHandler) GetLatestRecommendations(c *gin.Context) {
+	h.latestRecommendations(c, "")
+}
+
+// GetUserLatestRecommendations returns the most recent recommendations from global news and the user's own sources
+func (h *NewsHandler) GetUserLatestRecommendations(c *gin.Context) {
+	h.latestRecommendations(c, c.Param("userId"))
+}
+
+// latestRecommendations responds with the most recent recommendations a user sees
+func (h *NewsHandler) latestRecommendations(c *gin.Context, userID string) {
 	limitStr := c.DefaultQuery("limit", "20")
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil {
@@ -58,15 +69,25 @@ func (h *NewsHandler) GetLatestRecommendations(c *gin.Context) {
 		limit = 20 // Default to 10 if limit is invalid
 	}
 
-	recommendations := h.processor.GetLatestRecommendations(limit)
+	recommendations := h.processor.GetLatestRecommendations(userID, limit)
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
 		"data":   recommendations,
 	})
 }
 
-// GetRecommendationsByStock returns recommendations for a specific stock
+// GetRecommendationsByStock returns recommendations for a specific stock from global news
 func (h *NewsHandler) GetRecommendationsByStock(c *gin.Context) {
+	h.recommendationsByStock(c, "")
+}
+
+// GetUserRecommendationsByStock returns recommendations for a specific stock from global news and the user's own sources
+func (h *NewsHandler) GetUserRecommendationsByStock(c *gin.Context) {
+	h.recommendationsByStock(c, c.Param("userId"))
+}
+
+// recommendationsByStock responds with the recommendations for a stock a user sees
+func (h *NewsHandler) recommendationsByStock(c *gin.Context, userID string) {
 	symbol := c.Param("symbol")
 	if symbol == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -76,33 +97,109 @@ func (h *NewsHandler) GetRecommendationsByStock(c *gin.Context) {
 		return
 	}
 
-	recommendations := h.processor.GetRecommendationsByStock(symbol)
+	recommendations := h.processor.GetRecommendationsByStock(userID, symbol)
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
 		"data":   recommendations,
 	})
 }
 
-// GetSources returns all configured news sources
+// GetSources returns the global news sources with the health of their fetches
 func (h *NewsHandler) GetSources(c *gin.Context) {
-	sources := h.fetcher.GetSources()
-	c.JSON(http.StatusOK, gin.H{
-		"status": "success",
-		"data":   sources,
-	})
+	h.listSources(c, "")
+}
+
+// GetUserSources returns the global news sources and the user's own sources with the health of their fetches
+func (h *NewsHandler) GetUserSources(c *gin.Context) {
+	h.listSources(c, c.Param("userId"))
 }
 
-// AddSourceRequest represents the request body for adding a new source
-type AddSourceRequest struct {
+// SourceRequest represents the request body for adding or replacing a source
+type SourceRequest struct {
 	Name        string `json:"name" binding:"required"`
 	URL         string `json:"url" binding:"required,url"`
 	Description string `json:"description" binding:"required"`
 	Category    string `json:"category" binding:"required"`
+
+	// Enabled controls whether the feed is fetched (default: true)
+	Enabled *bool `json:"enabled"`
+
+	// Reliability weighs the source's news between 0 and 1 (default: news.DefaultSourceReliability)
+	Reliability *float64 `json:"reliability" binding:"omitempty,min=0,max=1"`
+
+	// RefreshMinutes is how often the feed is fetched (optional)
+	RefreshMinutes int `json:"refreshMinutes" binding:"omitempty,min=1"`
 }
 
-// AddSource adds a new news source
+// source converts the request into a news source
+func (r SourceRequest) source() news.Source {
+	source := news.Source{
+		Name:           r.Name,
+		URL:            r.URL,
+		Description:    r.Description,
+		Category:       r.Category,
+		Enabled:        true,
+		RefreshMinutes: r.RefreshMinutes,
+	}
+	if r.Enabled != nil {
+		source.Enabled = *r.Enabled
+	}
+	if r.Reliability != nil {
+		source.Reliability = *r.Reliability
+	}
+	return source
+}
+
+// AddSource adds a new global news source
 func (h *NewsHandler) AddSource(c *gin.Context) {
-	var req AddSourceRequest
+	h.addSource(c, "")
+}
+
+// AddUserSource adds a news source owned by the user
+func (h *NewsHandler) AddUserSource(c *gin.Context) {
+	h.addSource(c, c.Param("userId"))
+}
+
+// UpdateSource replaces a global news source
+func (h *NewsHandler) UpdateSource(c *gin.Context) {
+	h.updateSource(c, "")
+}
+
+// UpdateUserSource replaces a news source owned by the user
+func (h *NewsHandler) UpdateUserSource(c *gin.Context) {
+	h.updateSource(c, c.Param("userId"))
+}
+
+// RemoveSource removes a global news source
+func (h *NewsHandler) RemoveSource(c *gin.Context) {
+	h.removeSource(c, "")
+}
+
+// RemoveUserSource removes a news source owned by the user
+func (h *NewsHandler) RemoveUserSource(c *gin.Context) {
+	h.removeSource(c, c.Param("userId"))
+}
+
+// listSources responds with the global sources and, when ownerID is set, the owner's sources
+func (h *NewsHandler) listSources(c *gin.Context, ownerID string) {
+	sources, err := h.sourceService.ListSources(ownerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status": "error",
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   sources,
+	})
+}
+
+// addSource creates a source of an owner, or a global source when ownerID is empty
+func (h *NewsHandler) addSource(c *gin.Context, ownerID string) {
+	var req SourceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"status": "error",
@@ -111,19 +208,9 @@ func (h *NewsHandler) AddSource(c *gin.Context) {
 		return
 	}
 
-	source := news.Source{
-		Name:        req.Name,
-		URL:         req.URL,
-		Description: req.Description,
-		Category:    req.Category,
-	}
-
-	if err := h.fetcher.AddSource(source); err != nil {
-		status := http.StatusInternalServerError
-		if errors.Is(err, news.ErrSourceExists) {
-			status = http.StatusConflict
-		}
-		c.JSON(status, gin.H{
+	source, err := h.sourceService.AddSource(ownerID, req.source())
+	if err != nil {
+		c.JSON(sourceErrorStatus(err), gin.H{
 			"status": "error",
 			"error":  err.Error(),
 		})
@@ -136,23 +223,54 @@ func (h *NewsHandler) AddSource(c *gin.Context) {
 	})
 }
 
-// RemoveSource removes a news source
-func (h *NewsHandler) RemoveSource(c *gin.Context) {
-	name := c.Param("name")
-	if name == "" {
+// updateSource replaces a source of an owner, or a global source when ownerID is empty
+func (h *NewsHandler) updateSource(c *gin.Context, ownerID string) {
+	sourceID, err := strconv.ParseInt(c.Param("sourceId"), 10, 64)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"status": "error",
-			"error":  ErrMissingSymbol.Error(),
+			"error":  ErrInvalidSourceID.Error(),
+		})
+		return
+	}
+
+	var req SourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status": "error",
+			"error":  ErrInvalidRequest.Error(),
+		})
+		return
+	}
+
+	source, err := h.sourceService.UpdateSource(ownerID, sourceID, req.source())
+	if err != nil {
+		c.JSON(sourceErrorStatus(err), gin.H{
+			"status": "error",
+			"error":  err.Error(),
 		})
 		return
 	}
 
-	if err := h.fetcher.RemoveSource(name); err != nil {
-		status := http.StatusInternalServerError
-		if errors.Is(err, news.ErrSourceNotFound) {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   source,
+	})
+}
+
+// removeSource deletes a source of an owner, or a global source when ownerID is empty
+func (h *NewsHandler) removeSource(c *gin.Context, ownerID string) {
+	sourceID, err := strconv.ParseInt(c.Param("sourceId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status": "error",
+			"error":  ErrInvalidSourceID.Error(),
+		})
+		return
+	}
+
+	if err := h.sourceService.RemoveSource(ownerID, sourceID); err != nil {
+		c.JSON(sourceErrorStatus(err), gin.H{
 			"status": "error",
 			"error":  err.Error(),
 		})
@@ -164,3 +282,17 @@ func (h *NewsHandler) RemoveSource(c *gin.Context) {
 		"message": "Source removed successfully",
 	})
 }
+
+// sourceErrorStatus maps news source errors to HTTP status codes
+func sourceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, news.ErrSourceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, news.ErrSourceExists):
+		return http.StatusConflict
+	case errors.Is(err, news.ErrInvalidSource):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/Kora1128/FinSight/internal/rebalance"
+	"github.com/gin-gonic/gin"
+)
+
+// RebalanceHandler handles allocation target and rebalancing HTTP requests
+type RebalanceHandler struct {
+	rebalanceService *rebalance.Service
+}
+
+// NewRebalanceHandler creates a new rebalance handler
+func NewRebalanceHandler(rebalanceService *rebalance.Service) *RebalanceHandler {
+	return &RebalanceHandler{
+		rebalanceService: rebalanceService,
+	}
+}
+
+// ListTargets returns the allocation targets of a user
+func (h *RebalanceHandler) ListTargets(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, models.AllocationTargetsResponse{
+			Success: false,
+			Error:   "User ID is required",
+		})
+		return
+	}
+
+	targets, err := h.rebalanceService.ListTargets(context.Background(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.AllocationTargetsResponse{
+			Success: false,
+			Error:   "Failed to retrieve allocation targets: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AllocationTargetsResponse{
+		Success: true,
+		Data:    targets,
+	})
+}
+
+// SetTarget creates an allocation target, or replaces the weight of the user's existing target for the same kind and key
+func (h *RebalanceHandler) SetTarget(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, models.AllocationTargetResponse{
+			Success: false,
+			Error:   "User ID is required",
+		})
+		return
+	}
+
+	var req models.AllocationTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.AllocationTargetResponse{
+			Success: false,
+			Error:   "Invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	target, err := h.rebalanceService.SetTarget(context.Background(), userID, req)
+	if err != nil {
+		c.JSON(targetErrorStatus(err), models.AllocationTargetResponse{
+			Success: false,
+			Error:   "Failed to save allocation target: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AllocationTargetResponse{
+		Success: true,
+		Data:    *target,
+	})
+}
+
+// UpdateTarget replaces an allocation target of a user by ID
+func (h *RebalanceHandler) UpdateTarget(c *gin.Context) {
+	userID := c.Param("userId")
+	targetID, err := strconv.ParseInt(c.Param("targetId"), 10, 64)
+	if userID == "" || err != nil {
+		c.JSON(http.StatusBadRequest, models.AllocationTargetResponse{
+			Success: false,
+			Error:   "User ID and a numeric target ID are required",
+		})
+		return
+	}
+
+	var req models.AllocationTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.AllocationTargetResponse{
+			Success: false,
+			Error:   "Invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	target, err := h.rebalanceService.UpdateTarget(context.Background(), userID, targetID, req)
+	if err != nil {
+		c.JSON(targetErrorStatus(err), models.AllocationTargetResponse{
+			Success: false,
+			Error:   "Failed to update allocation target: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AllocationTargetResponse{
+		Success: true,
+		Data:    *target,
+	})
+}
+
+// DeleteTarget removes an allocation target of a user by ID
+func (h *RebalanceHandler) DeleteTarget(c *gin.Context) {
+	userID := c.Param("userId")
+	targetID, err := strconv.ParseInt(c.Param("targetId"), 10, 64)
+	if userID == "" || err != nil {
+		c.JSON(http.StatusBadRequest, models.AllocationTargetsResponse{
+			Success: false,
+			Error:   "User ID and a numeric target ID are required",
+		})
+		return
+	}
+
+	if err := h.rebalanceService.DeleteTarget(context.Background(), userID, targetID); err != nil {
+		c.JSON(targetErrorStatus(err), models.AllocationTargetsResponse{
+			Success: false,
+			Error:   "Failed to delete allocation target: " + err.Error(),
+		})
+		return
+	}
+
+	targets, err := h.rebalanceService.ListTargets(context.Background(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.AllocationTargetsResponse{
+			Success: false,
+			Error:   "Failed to retrieve allocation targets: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AllocationTargetsResponse{
+		Success: true,
+		Data:    targets,
+	})
+}
+
+// CreateRebalancePlan returns the drift of a user's portfolio from their targets and the trades that correct it
+func (h *RebalanceHandler) CreateRebalancePlan(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, models.RebalancePlanResponse{
+			Success: false,
+			Error:   "User ID is required",
+		})
+		return
+	}
+
+	var req models.RebalancePlanRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.RebalancePlanResponse{
+				Success: false,
+				Error:   "Invalid request: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	plan, err := h.rebalanceService.RebalancePlan(context.Background(), userID, req)
+	if err != nil {
+		c.JSON(targetErrorStatus(err), models.RebalancePlanResponse{
+			Success: false,
+			Error:   "Failed to create rebalance plan: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RebalancePlanResponse{
+		Success: true,
+		Data:    *plan,
+	})
+}
+
+// targetErrorStatus maps rebalancing errors to HTTP status codes
+func targetErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, rebalance.ErrTargetNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, rebalance.ErrInvalidTarget),
+		errors.Is(err, rebalance.ErrTargetWeightsExceed),
+		errors.Is(err, rebalance.ErrNoTargets),
+		errors.Is(err, rebalance.ErrTargetWeights),
+		errors.Is(err, rebalance.ErrNothingToRebalance):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/Kora1128/FinSight/internal/streaming"
+	"github.com/gin-gonic/gin"
+)
+
+// streamHeartbeatInterval is how often an idle stream sends a heartbeat so proxies keep it open
+const streamHeartbeatInterval = 15 * time.Second
+
+// StreamHandler handles live portfolio streaming HTTP requests
+type StreamHandler struct {
+	streamService *streaming.Service
+}
+
+// NewStreamHandler creates a new stream handler; a nil service means live prices are not configured
+func NewStreamHandler(streamService *streaming.Service) *StreamHandler {
+	return &StreamHandler{
+		streamService: streamService,
+	}
+}
+
+// StreamUserPortfolio streams a user's portfolio revalued at live prices as Server-Sent Events.
+// Each "portfolio" event carries the whole portfolio; "heartbeat" events keep idle connections open.
+func (h *StreamHandler) StreamUserPortfolio(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, models.PortfolioResponse{
+			Success: false,
+			Error:   "User ID is required",
+		})
+		return
+	}
+
+	var req models.PortfolioRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.PortfolioResponse{
+			Success: false,
+			Error:   "Invalid request parameters",
+		})
+		return
+	}
+
+	if h.streamService == nil {
+		c.JSON(http.StatusServiceUnavailable, models.PortfolioResponse{
+			Success: false,
+			Error:   "Live prices are not configured",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	updates, err := h.streamService.StreamPortfolio(ctx, userID, req.Type)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.PortfolioResponse{
+			Success: false,
+			Error:   "Failed to stream portfolio: " + err.Error(),
+		})
+		return
+	}
+
+	// The server write timeout would otherwise cut the stream off
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case portfolio, ok := <-updates:
+			if !ok {
+				return false
+			}
+			c.SSEvent("portfolio", portfolio)
+			return true
+		case now := <-heartbeat.C:
+			c.SSEvent("heartbeat", now.Unix())
+			return true
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/Kora1128/FinSight/internal/tax"
+	"github.com/gin-gonic/gin"
+)
+
+// TaxHandler handles tax report HTTP requests
+type TaxHandler struct {
+	taxService *tax.Service
+}
+
+// NewTaxHandler creates a new tax handler
+func NewTaxHandler(taxService *tax.Service) *TaxHandler {
+	return &TaxHandler{
+		taxService: taxService,
+	}
+}
+
+// GetCapitalGains returns the capital gains a user realized in a financial year,
+// as JSON or, with format=csv, as a CSV in the Schedule 112A layout
+func (h *TaxHandler) GetCapitalGains(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, models.CapitalGainsResponse{
+			Success: false,
+			Error:   "User ID is required",
+		})
+		return
+	}
+
+	var req models.CapitalGainsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.CapitalGainsResponse{
+			Success: false,
+			Error:   "Invalid request parameters",
+		})
+		return
+	}
+
+	fy := tax.CurrentFinancialYear(time.Now())
+	if req.FinancialYear != "" {
+		var err error
+		fy, err = tax.ParseFinancialYear(req.FinancialYear)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.CapitalGainsResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+	}
+
+	report, err := h.taxService.CapitalGains(context.Background(), userID, fy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.CapitalGainsResponse{
+			Success: false,
+			Error:   "Failed to compute capital gains: " + err.Error(),
+		})
+		return
+	}
+
+	if req.Format == "csv" {
+		c.Header("Content-Disposition", "attachment; filename=schedule-112a-"+fy.Label+".csv")
+		c.Header("Content-Type", "text/csv")
+		c.Status(http.StatusOK)
+		if err := tax.WriteSchedule112A(c.Writer, report); err != nil {
+			c.Error(err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.CapitalGainsResponse{
+		Success: true,
+		Data:    *report,
+	})
+}
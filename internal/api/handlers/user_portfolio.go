@@ -2,22 +2,33 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"time"
 
+	"github.com/Kora1128/FinSight/internal/allocation"
+	"github.com/Kora1128/FinSight/internal/importer/cas"
+	"github.com/Kora1128/FinSight/internal/importer/dividend"
+	"github.com/Kora1128/FinSight/internal/importer/tradebook"
 	"github.com/Kora1128/FinSight/internal/models"
 	"github.com/Kora1128/FinSight/internal/portfolio"
+	"github.com/Kora1128/FinSight/internal/returns"
 	"github.com/gin-gonic/gin"
 )
 
 // UserPortfolioHandler handles user-specific portfolio-related HTTP requests
 type UserPortfolioHandler struct {
 	userPortfolioService *portfolio.UserService
+	returnsService       *returns.Service
+	allocationService    *allocation.Service
 }
 
 // NewUserPortfolioHandler creates a new user portfolio handler
-func NewUserPortfolioHandler(userPortfolioService *portfolio.UserService) *UserPortfolioHandler {
+func NewUserPortfolioHandler(userPortfolioService *portfolio.UserService, returnsService *returns.Service, allocationService *allocation.Service) *UserPortfolioHandler {
 	return &UserPortfolioHandler{
 		userPortfolioService: userPortfolioService,
+		returnsService:       returnsService,
+		allocationService:    allocationService,
 	}
 }
 
@@ -61,7 +72,7 @@ func (h *UserPortfolioHandler) GetUserPortfolio(c *gin.Context) {
 func (h *UserPortfolioHandler) RefreshUserPortfolio(c *gin.Context) {
 	userID := c.Param("userId")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, models.PortfolioResponse{
+		c.JSON(http.StatusBadRequest, models.PortfolioRefreshResponse{
 			Success: false,
 			Error:   "User ID is required",
 		})
@@ -69,9 +80,9 @@ func (h *UserPortfolioHandler) RefreshUserPortfolio(c *gin.Context) {
 	}
 
 	// Refresh the portfolio
-	err := h.userPortfolioService.RefreshPortfolio(context.Background(), userID)
+	changes, err := h.userPortfolioService.RefreshPortfolio(context.Background(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.PortfolioResponse{
+		c.JSON(http.StatusInternalServerError, models.PortfolioRefreshResponse{
 			Success: false,
 			Error:   "Failed to refresh portfolio: " + err.Error(),
 		})
@@ -81,15 +92,411 @@ func (h *UserPortfolioHandler) RefreshUserPortfolio(c *gin.Context) {
 	// Get the updated portfolio
 	portfolio, err := h.userPortfolioService.GetPortfolio(context.Background(), userID, "")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.PortfolioResponse{
+		c.JSON(http.StatusInternalServerError, models.PortfolioRefreshResponse{
 			Success: false,
 			Error:   "Failed to retrieve refreshed portfolio: " + err.Error(),
 		})
 		return
 	}
 
+	c.JSON(http.StatusOK, models.PortfolioRefreshResponse{
+		Success: true,
+		Data:    *portfolio,
+		Changes: changes,
+	})
+}
+
+// GetPortfolioChanges returns the holding changes detected on past refreshes of a user's portfolio
+func (h *UserPortfolioHandler) GetPortfolioChanges(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, models.PortfolioChangesResponse{
+			Success: false,
+			Error:   "User ID is required",
+		})
+		return
+	}
+
+	var req models.PortfolioChangesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.PortfolioChangesResponse{
+			Success: false,
+			Error:   "Invalid request parameters",
+		})
+		return
+	}
+
+	from, err := parseHistoryDate(req.From)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.PortfolioChangesResponse{
+			Success: false,
+			Error:   "Invalid from date, expected YYYY-MM-DD",
+		})
+		return
+	}
+	to, err := parseHistoryDate(req.To)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.PortfolioChangesResponse{
+			Success: false,
+			Error:   "Invalid to date, expected YYYY-MM-DD",
+		})
+		return
+	}
+
+	changes, err := h.userPortfolioService.GetHoldingChanges(context.Background(), userID, from, to, req.Type)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, portfolio.ErrInvalidHistoryRange) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, models.PortfolioChangesResponse{
+			Success: false,
+			Error:   "Failed to retrieve portfolio changes: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PortfolioChangesResponse{
+		Success: true,
+		Data:    changes,
+	})
+}
+
+// Upload size limits
+const (
+	maxCASFileSize       = 10 << 20
+	maxTradebookFileSize = 10 << 20
+	maxDividendFileSize  = 10 << 20
+)
+
+// ImportCAS imports holdings from an uploaded CDSL/NSDL Consolidated Account Statement PDF
+func (h *UserPortfolioHandler) ImportCAS(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, models.PortfolioResponse{
+			Success: false,
+			Error:   "User ID is required",
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.PortfolioResponse{
+			Success: false,
+			Error:   "CAS file is required",
+		})
+		return
+	}
+	if fileHeader.Size > maxCASFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, models.PortfolioResponse{
+			Success: false,
+			Error:   "CAS file is too large",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.PortfolioResponse{
+			Success: false,
+			Error:   "Failed to read CAS file: " + err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	// Import the statement
+	_, err = h.userPortfolioService.ImportCAS(context.Background(), userID, file, fileHeader.Size, c.PostForm("password"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, cas.ErrInvalidPassword) || errors.Is(err, cas.ErrInvalidFile) || errors.Is(err, cas.ErrNoHoldings) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, models.PortfolioResponse{
+			Success: false,
+			Error:   "Failed to import CAS: " + err.Error(),
+		})
+		return
+	}
+
+	// Get the updated portfolio
+	portfolio, err := h.userPortfolioService.GetPortfolio(context.Background(), userID, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.PortfolioResponse{
+			Success: false,
+			Error:   "Failed to retrieve updated portfolio: " + err.Error(),
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, models.PortfolioResponse{
 		Success: true,
 		Data:    *portfolio,
 	})
 }
+
+// ImportTradebook imports trades from an uploaded broker tradebook CSV
+func (h *UserPortfolioHandler) ImportTradebook(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, models.TradeImportResponse{
+			Success: false,
+			Error:   "User ID is required",
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.TradeImportResponse{
+			Success: false,
+			Error:   "Tradebook file is required",
+		})
+		return
+	}
+	if fileHeader.Size > maxTradebookFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, models.TradeImportResponse{
+			Success: false,
+			Error:   "Tradebook file is too large",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.TradeImportResponse{
+			Success: false,
+			Error:   "Failed to read tradebook file: " + err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.userPortfolioService.ImportTradebook(context.Background(), userID, file)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, tradebook.ErrInvalidFile) || errors.Is(err, tradebook.ErrUnknownFormat) ||
+			errors.Is(err, tradebook.ErrInvalidRow) || errors.Is(err, tradebook.ErrNoTrades) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, models.TradeImportResponse{
+			Success: false,
+			Error:   "Failed to import tradebook: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TradeImportResponse{
+		Success: true,
+		Data:    *result,
+	})
+}
+
+// ImportDividends imports dividends from an uploaded dividend statement CSV
+func (h *UserPortfolioHandler) ImportDividends(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, models.DividendImportResponse{
+			Success: false,
+			Error:   "User ID is required",
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.DividendImportResponse{
+			Success: false,
+			Error:   "Dividend statement file is required",
+		})
+		return
+	}
+	if fileHeader.Size > maxDividendFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, models.DividendImportResponse{
+			Success: false,
+			Error:   "Dividend statement file is too large",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.DividendImportResponse{
+			Success: false,
+			Error:   "Failed to read dividend statement file: " + err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.userPortfolioService.ImportDividends(context.Background(), userID, file)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, dividend.ErrInvalidFile) || errors.Is(err, dividend.ErrUnknownFormat) ||
+			errors.Is(err, dividend.ErrInvalidRow) || errors.Is(err, dividend.ErrNoDividends) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, models.DividendImportResponse{
+			Success: false,
+			Error:   "Failed to import dividends: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DividendImportResponse{
+		Success: true,
+		Data:    *result,
+	})
+}
+
+// GetPortfolioHistory returns the value time series of a user's portfolio from the snapshot history
+func (h *UserPortfolioHandler) GetPortfolioHistory(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, models.PortfolioHistoryResponse{
+			Success: false,
+			Error:   "User ID is required",
+		})
+		return
+	}
+
+	var req models.PortfolioHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.PortfolioHistoryResponse{
+			Success: false,
+			Error:   "Invalid request parameters",
+		})
+		return
+	}
+
+	from, err := parseHistoryDate(req.From)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.PortfolioHistoryResponse{
+			Success: false,
+			Error:   "Invalid from date, expected YYYY-MM-DD",
+		})
+		return
+	}
+	to, err := parseHistoryDate(req.To)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.PortfolioHistoryResponse{
+			Success: false,
+			Error:   "Invalid to date, expected YYYY-MM-DD",
+		})
+		return
+	}
+
+	history, err := h.userPortfolioService.GetPortfolioHistory(context.Background(), userID, from, to, req.Granularity)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, portfolio.ErrInvalidHistoryRange) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, models.PortfolioHistoryResponse{
+			Success: false,
+			Error:   "Failed to retrieve portfolio history: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PortfolioHistoryResponse{
+		Success: true,
+		Data:    *history,
+	})
+}
+
+// parseHistoryDate parses an optional YYYY-MM-DD query date, returning the zero time when empty
+func parseHistoryDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// GetPortfolioReturns returns the XIRR and time-weighted returns of a user's portfolio, platforms and holdings
+func (h *UserPortfolioHandler) GetPortfolioReturns(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, models.PortfolioReturnsResponse{
+			Success: false,
+			Error:   "User ID is required",
+		})
+		return
+	}
+
+	portfolioReturns, err := h.returnsService.PortfolioReturns(context.Background(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.PortfolioReturnsResponse{
+			Success: false,
+			Error:   "Failed to compute portfolio returns: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PortfolioReturnsResponse{
+		Success: true,
+		Data:    *portfolioReturns,
+	})
+}
+
+// GetUserFunds returns the cash and margin available at each of a user's connected brokers that report funds
+func (h *UserPortfolioHandler) GetUserFunds(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, models.FundsResponse{
+			Success: false,
+			Error:   "User ID is required",
+		})
+		return
+	}
+
+	funds, err := h.userPortfolioService.GetFunds(context.Background(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.FundsResponse{
+			Success: false,
+			Error:   "Failed to fetch funds: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.FundsResponse{
+		Success: true,
+		Data:    funds,
+	})
+}
+
+// GetPortfolioAllocation returns the weighted breakdown of a user's portfolio by sector, asset class, market cap or platform
+func (h *UserPortfolioHandler) GetPortfolioAllocation(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, models.AllocationResponse{
+			Success: false,
+			Error:   "User ID is required",
+		})
+		return
+	}
+
+	var req models.AllocationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.AllocationResponse{
+			Success: false,
+			Error:   "Invalid request parameters",
+		})
+		return
+	}
+
+	portfolioAllocation, err := h.allocationService.Allocation(context.Background(), userID, req.By)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.AllocationResponse{
+			Success: false,
+			Error:   "Failed to compute portfolio allocation: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AllocationResponse{
+		Success: true,
+		Data:    *portfolioAllocation,
+	})
+}
@@ -167,6 +167,14 @@ func (h *SessionHandler) ConnectBroker(c *gin.Context) {
 		return
 	}
 
+	if !h.brokerManager.IsSupported(req.BrokerType) {
+		c.JSON(http.StatusBadRequest, models.SessionResponse{
+			Success: false,
+			Error:   "Unsupported broker type: " + req.BrokerType,
+		})
+		return
+	}
+
 	// Check if session exists
 	session, err := h.sessionRepo.GetUserSession(req.UserID)
 	if err != nil {
@@ -203,6 +211,8 @@ func (h *SessionHandler) ConnectBroker(c *gin.Context) {
 		APISecret:    req.APISecret,
 		RequestToken: req.RequestToken,
 		Password:     req.Password,
+		RedirectURI:  req.RedirectURI,
+		ClientCode:   req.ClientCode,
 	}
 
 	_, err = h.brokerManager.CreateClient(req.BrokerType, creds)
@@ -242,6 +252,14 @@ func (h *SessionHandler) DisconnectBroker(c *gin.Context) {
 		return
 	}
 
+	if !h.brokerManager.IsSupported(brokerType) {
+		c.JSON(http.StatusBadRequest, models.SessionResponse{
+			Success: false,
+			Error:   "Unsupported broker type: " + brokerType,
+		})
+		return
+	}
+
 	// Check if session exists
 	session, err := h.sessionRepo.GetUserSession(userID)
 	if err != nil {
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminTokenHeader is the request header carrying the admin API token
+const AdminTokenHeader = "X-Admin-Token"
+
+// AdminAuth returns middleware to check that a request carries the admin API token.
+// When no token is configured every request is refused.
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"status": "error",
+				"error":  "Admin API is not enabled",
+			})
+			c.Abort()
+			return
+		}
+
+		provided := c.GetHeader(AdminTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"status": "error",
+				"error":  "Invalid admin token",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -12,10 +12,16 @@ import (
 func SetupRouter(
 	newsHandler *handlers.NewsHandler,
 	userPortfolioHandler *handlers.UserPortfolioHandler,
+	taxHandler *handlers.TaxHandler,
+	rebalanceHandler *handlers.RebalanceHandler,
+	instrumentHandler *handlers.InstrumentHandler,
+	streamHandler *handlers.StreamHandler,
 	sessionHandler *handlers.SessionHandler,
+	adminHandler *handlers.AdminHandler,
 	cache *cache.Cache,
 	sessionRepo *database.SessionRepo,
 	userRepo *database.UserRepo,
+	adminToken string,
 ) *gin.Engine {
 	r := gin.New()
 
@@ -43,15 +49,62 @@ func SetupRouter(
 			sessions.POST("/disconnect/:userId/:brokerType", sessionHandler.DisconnectBroker)
 		}
 
-		// User-specific portfolio routes - protected by session authentication
-		userPortfolio := api.Group("/users/:userId/portfolio")
-		userPortfolio.Use(middleware.SessionAuth(middleware.SessionAuthConfig{
+		sessionAuth := middleware.SessionAuth(middleware.SessionAuthConfig{
 			SessionRepo: sessionRepo,
 			UserRepo:    userRepo,
-		}))
+		})
+
+		// User-specific portfolio routes - protected by session authentication
+		userPortfolio := api.Group("/users/:userId/portfolio")
+		userPortfolio.Use(sessionAuth)
 		{
 			userPortfolio.GET("", userPortfolioHandler.GetUserPortfolio)
 			userPortfolio.POST("/refresh", userPortfolioHandler.RefreshUserPortfolio)
+			userPortfolio.POST("/import/cas", userPortfolioHandler.ImportCAS)
+			userPortfolio.POST("/import/tradebook", userPortfolioHandler.ImportTradebook)
+			userPortfolio.POST("/import/dividends", userPortfolioHandler.ImportDividends)
+			userPortfolio.GET("/returns", userPortfolioHandler.GetPortfolioReturns)
+			userPortfolio.GET("/history", userPortfolioHandler.GetPortfolioHistory)
+			userPortfolio.GET("/changes", userPortfolioHandler.GetPortfolioChanges)
+			userPortfolio.GET("/funds", userPortfolioHandler.GetUserFunds)
+			userPortfolio.GET("/allocation", userPortfolioHandler.GetPortfolioAllocation)
+			userPortfolio.GET("/stream", streamHandler.StreamUserPortfolio)
+			userPortfolio.GET("/targets", rebalanceHandler.ListTargets)
+			userPortfolio.POST("/targets", rebalanceHandler.SetTarget)
+			userPortfolio.PUT("/targets/:targetId", rebalanceHandler.UpdateTarget)
+			userPortfolio.DELETE("/targets/:targetId", rebalanceHandler.DeleteTarget)
+			userPortfolio.POST("/rebalance-plan", rebalanceHandler.CreateRebalancePlan)
+		}
+
+		// User-specific news source routes - protected by session authentication
+		userSources := api.Group("/users/:userId/news/sources")
+		userSources.Use(sessionAuth)
+		{
+			userSources.GET("", newsHandler.GetUserSources)
+			userSources.POST("", newsHandler.AddUserSource)
+			userSources.PUT("/:sourceId", newsHandler.UpdateUserSource)
+			userSources.DELETE("/:sourceId", newsHandler.RemoveUserSource)
+		}
+
+		// User-specific recommendation routes, which include news from the user's own sources - protected by session authentication
+		userRecommendations := api.Group("/users/:userId/recommendations")
+		userRecommendations.Use(sessionAuth)
+		{
+			userRecommendations.GET("/latest", newsHandler.GetUserLatestRecommendations)
+			userRecommendations.GET("/stock/:symbol", newsHandler.GetUserRecommendationsByStock)
+		}
+
+		// User-specific tax routes - protected by session authentication
+		userTax := api.Group("/users/:userId/tax")
+		userTax.Use(sessionAuth)
+		{
+			userTax.GET("/capital-gains", taxHandler.GetCapitalGains)
+		}
+
+		// Instrument routes
+		instrumentRoutes := api.Group("/instruments")
+		{
+			instrumentRoutes.GET("/search", instrumentHandler.SearchInstruments)
 		}
 
 		// News/Recommendation routes
@@ -62,12 +115,23 @@ func SetupRouter(
 			news.GET("/stock/:symbol", newsHandler.GetRecommendationsByStock)
 		}
 
-		// News sources routes
+		// Global news sources routes - changes are protected by the admin token
 		sources := api.Group("/news/sources")
 		{
+			adminAuth := middleware.AdminAuth(adminToken)
+
 			sources.GET("", newsHandler.GetSources)
-			sources.POST("", newsHandler.AddSource)
-			sources.DELETE("/:name", newsHandler.RemoveSource)
+			sources.POST("", adminAuth, newsHandler.AddSource)
+			sources.PUT("/:sourceId", adminAuth, newsHandler.UpdateSource)
+			sources.DELETE("/:sourceId", adminAuth, newsHandler.RemoveSource)
+		}
+
+		// Reference data routes - protected by the admin token
+		admin := api.Group("/admin")
+		admin.Use(middleware.AdminAuth(adminToken))
+		{
+			admin.POST("/corporate-actions", adminHandler.ImportCorporateActions)
+			admin.POST("/grandfathered-prices", adminHandler.ImportFairMarketValues)
 		}
 	}
 
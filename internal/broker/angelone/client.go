@@ -0,0 +1,372 @@
+package angelone
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/broker/types"
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// Ensure Client implements types.Client and types.FundsProvider interfaces
+var (
+	_ types.Client        = (*Client)(nil)
+	_ types.FundsProvider = (*Client)(nil)
+)
+
+// DefaultBaseURL is the base URL of the Angel One SmartAPI
+const DefaultBaseURL = "https://apiconnect.angelone.in"
+
+// API endpoints relative to the base URL
+const (
+	loginPath     = "/rest/auth/angelbroking/user/v1/loginByPassword"
+	tokenPath     = "/rest/auth/angelbroking/jwt/v1/generateTokens"
+	holdingsPath  = "/rest/secure/angelbroking/portfolio/v1/getAllHolding"
+	positionsPath = "/rest/secure/angelbroking/order/v1/getPosition"
+	fundsPath     = "/rest/secure/angelbroking/user/v1/getRMS"
+)
+
+// Client represents the Angel One broker integration client
+type Client struct {
+	httpClient   *http.Client
+	baseURL      string
+	apiKey       string
+	apiSecret    string
+	clientCode   string
+	password     string
+	totp         string
+	accessToken  string
+	refreshToken string
+	expiresAt    time.Time
+}
+
+// NewClient creates a new Angel One client.
+// The password is the account MPIN and totp the current code from the authenticator app linked to the SmartAPI account.
+func NewClient(apiKey, apiSecret, clientCode, password, totp string) *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    DefaultBaseURL,
+		apiKey:     apiKey,
+		apiSecret:  apiSecret,
+		clientCode: clientCode,
+		password:   password,
+		totp:       totp,
+	}
+}
+
+// SetBaseURL overrides the API base URL, e.g. to point the client at a sandbox
+func (c *Client) SetBaseURL(baseURL string) {
+	c.baseURL = strings.TrimRight(baseURL, "/")
+}
+
+// apiResponse represents the common envelope of SmartAPI responses
+type apiResponse struct {
+	Status    bool            `json:"status"`
+	Message   string          `json:"message"`
+	ErrorCode string          `json:"errorcode"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// sessionData represents the tokens returned on login and token generation
+type sessionData struct {
+	JWTToken     string `json:"jwtToken"`
+	RefreshToken string `json:"refreshToken"`
+	FeedToken    string `json:"feedToken"`
+}
+
+// holdingsData represents the data of the getAllHolding response
+type holdingsData struct {
+	Holdings []holding `json:"holdings"`
+}
+
+// holding represents a long-term holding as returned by SmartAPI
+type holding struct {
+	TradingSymbol string    `json:"tradingsymbol"`
+	Exchange      string    `json:"exchange"`
+	ISIN          string    `json:"isin"`
+	Quantity      flexFloat `json:"quantity"`
+	T1Quantity    flexFloat `json:"t1quantity"`
+	AveragePrice  flexFloat `json:"averageprice"`
+	LTP           flexFloat `json:"ltp"`
+	Close         flexFloat `json:"close"`
+	ProfitAndLoss flexFloat `json:"profitandloss"`
+}
+
+// position represents a position as returned by SmartAPI
+type position struct {
+	TradingSymbol string    `json:"tradingsymbol"`
+	SymbolName    string    `json:"symbolname"`
+	Exchange      string    `json:"exchange"`
+	ProductType   string    `json:"producttype"`
+	NetQty        flexFloat `json:"netqty"`
+	AvgNetPrice   flexFloat `json:"avgnetprice"`
+	LTP           flexFloat `json:"ltp"`
+	Close         flexFloat `json:"close"`
+	PnL           flexFloat `json:"pnl"`
+	Unrealised    flexFloat `json:"unrealised"`
+}
+
+// rmsData represents the funds and margin data returned by SmartAPI
+type rmsData struct {
+	Net            flexFloat `json:"net"`
+	AvailableCash  flexFloat `json:"availablecash"`
+	Collateral     flexFloat `json:"collateral"`
+	UtilisedDebits flexFloat `json:"utiliseddebits"`
+}
+
+// flexFloat decodes SmartAPI numbers, which are sent either as JSON numbers or as strings
+type flexFloat float64
+
+// UnmarshalJSON implements json.Unmarshaler
+func (f *flexFloat) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	s = strings.ReplaceAll(s, " ", "")
+	if s == "" || s == "null" {
+		*f = 0
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q: %w", string(data), err)
+	}
+	*f = flexFloat(v)
+	return nil
+}
+
+// Login authenticates the user with Angel One using the client code, MPIN and TOTP
+func (c *Client) Login() error {
+	if c.clientCode == "" || c.password == "" || c.totp == "" {
+		return errors.New("client code, password and totp are required")
+	}
+
+	body := map[string]string{
+		"clientcode": c.clientCode,
+		"password":   c.password,
+		"totp":       c.totp,
+	}
+	var session sessionData
+	if err := c.do(context.Background(), http.MethodPost, loginPath, body, false, &session); err != nil {
+		return err
+	}
+	if session.JWTToken == "" {
+		return errors.New("no access token in response")
+	}
+
+	c.setSession(session)
+	return nil
+}
+
+// CanAutoRefresh checks if the client can refresh the token automatically
+func (c *Client) CanAutoRefresh() bool {
+	return c.refreshToken != "" && c.accessToken != ""
+}
+
+// RefreshToken renews the access token using the refresh token issued on login
+func (c *Client) RefreshToken() error {
+	// Check if token is about to expire (within 1 hour)
+	if time.Until(c.expiresAt) > 1*time.Hour {
+		return nil // No need to refresh yet
+	}
+	if c.refreshToken == "" {
+		return errors.New("no refresh token available")
+	}
+
+	var session sessionData
+	body := map[string]string{"refreshToken": c.refreshToken}
+	if err := c.do(context.Background(), http.MethodPost, tokenPath, body, true, &session); err != nil {
+		return err
+	}
+	c.setSession(session)
+	return nil
+}
+
+// setSession stores the tokens of a new session
+func (c *Client) setSession(session sessionData) {
+	c.accessToken = session.JWTToken
+	if session.RefreshToken != "" {
+		c.refreshToken = session.RefreshToken
+	}
+	c.expiresAt = time.Now().Add(24 * time.Hour) // SmartAPI sessions are valid until midnight
+}
+
+// SetAccessToken sets the access token for the client
+func (c *Client) SetAccessToken(token string) {
+	c.accessToken = token
+}
+
+// GetAccessToken returns the current access token
+func (c *Client) GetAccessToken() string {
+	return c.accessToken
+}
+
+// GetAPIKey returns the API key
+func (c *Client) GetAPIKey() string {
+	return c.apiKey
+}
+
+// GetHoldings fetches the current portfolio holdings from Angel One and normalizes them into the common Holding struct
+func (c *Client) GetHoldings(ctx context.Context) ([]models.Holding, error) {
+	var data holdingsData
+	if err := c.do(ctx, http.MethodGet, holdingsPath, nil, true, &data); err != nil {
+		return nil, err
+	}
+
+	var normalizedHoldings []models.Holding
+	for _, h := range data.Holdings {
+		quantity := float64(h.Quantity + h.T1Quantity)
+		ltp := float64(h.LTP)
+		dayChange, dayChangePercent := dayChangeFor(quantity, ltp, float64(h.Close))
+		normalizedHolding := models.Holding{
+			ItemName:         stripSeries(h.TradingSymbol),
+			ISIN:             h.ISIN,
+			Quantity:         quantity,
+			AveragePrice:     float64(h.AveragePrice),
+			LastTradedPrice:  ltp,
+			CurrentValue:     quantity * ltp,
+			DayChange:        dayChange,
+			DayChangePercent: dayChangePercent,
+			TotalPnL:         float64(h.ProfitAndLoss),
+			Platform:         models.PlatformAngelOne,
+			Type:             models.HoldingTypeStock,
+			LastUpdated:      time.Now(),
+		}
+		normalizedHoldings = append(normalizedHoldings, normalizedHolding)
+	}
+	return normalizedHoldings, nil
+}
+
+// GetPositions fetches the current positions from Angel One and normalizes them into the common Holding struct
+func (c *Client) GetPositions(ctx context.Context) ([]models.Holding, error) {
+	var positions []position
+	if err := c.do(ctx, http.MethodGet, positionsPath, nil, true, &positions); err != nil {
+		return nil, err
+	}
+
+	var normalizedHoldings []models.Holding
+	for _, p := range positions {
+		quantity := float64(p.NetQty)
+		ltp := float64(p.LTP)
+		dayChange, dayChangePercent := dayChangeFor(quantity, ltp, float64(p.Close))
+		pnl := float64(p.PnL)
+		if pnl == 0 {
+			pnl = float64(p.Unrealised)
+		}
+		normalizedHolding := models.Holding{
+			ItemName:         stripSeries(p.TradingSymbol),
+			ISIN:             "", // ISIN not available in positions
+			Quantity:         quantity,
+			AveragePrice:     float64(p.AvgNetPrice),
+			LastTradedPrice:  ltp,
+			CurrentValue:     quantity * ltp,
+			DayChange:        dayChange,
+			DayChangePercent: dayChangePercent,
+			TotalPnL:         pnl,
+			Platform:         models.PlatformAngelOne,
+			Type:             models.HoldingTypeStock,
+			LastUpdated:      time.Now(),
+		}
+		normalizedHoldings = append(normalizedHoldings, normalizedHolding)
+	}
+	return normalizedHoldings, nil
+}
+
+// GetFunds fetches the available cash and margin from Angel One
+func (c *Client) GetFunds(ctx context.Context) (*models.Funds, error) {
+	var data rmsData
+	if err := c.do(ctx, http.MethodGet, fundsPath, nil, true, &data); err != nil {
+		return nil, err
+	}
+
+	return &models.Funds{
+		Platform:      models.PlatformAngelOne,
+		AvailableCash: float64(data.AvailableCash),
+		UsedMargin:    float64(data.UtilisedDebits),
+		Collateral:    float64(data.Collateral),
+		Net:           float64(data.Net),
+		LastUpdated:   time.Now(),
+	}, nil
+}
+
+// do performs a SmartAPI request and decodes the data field of the response into out
+func (c *Client) do(ctx context.Context, method, path string, payload interface{}, authenticated bool, out interface{}) error {
+	if authenticated && c.accessToken == "" {
+		return errors.New("not logged in to angel one")
+	}
+
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-UserType", "USER")
+	req.Header.Set("X-SourceID", "WEB")
+	req.Header.Set("X-ClientLocalIP", "127.0.0.1")
+	req.Header.Set("X-ClientPublicIP", "127.0.0.1")
+	req.Header.Set("X-MACAddress", "00:00:00:00:00:00")
+	req.Header.Set("X-PrivateKey", c.apiKey)
+	if authenticated {
+		req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var envelope apiResponse
+	if err := json.Unmarshal(respBody, &envelope); err != nil {
+		return fmt.Errorf("angel one api error (status %d): failed to decode response: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || !envelope.Status {
+		return fmt.Errorf("angel one api error (status %d): %s: %s", resp.StatusCode, envelope.ErrorCode, envelope.Message)
+	}
+	if len(envelope.Data) == 0 || string(envelope.Data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("failed to decode angel one data: %w", err)
+	}
+	return nil
+}
+
+// dayChangeFor calculates the absolute and percentage change since the previous close
+func dayChangeFor(quantity, ltp, close float64) (float64, float64) {
+	if close <= 0 {
+		return 0, 0
+	}
+	return (ltp - close) * quantity, (ltp - close) / close * 100
+}
+
+// stripSeries removes the exchange series suffix (e.g. "-EQ") from a trading symbol
+func stripSeries(symbol string) string {
+	for _, series := range []string{"-EQ", "-BE"} {
+		if strings.HasSuffix(symbol, series) {
+			return strings.TrimSuffix(symbol, series)
+		}
+	}
+	return symbol
+}
@@ -0,0 +1,320 @@
+package angelone
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testAPIKey       = "test-api-key"
+	testClientCode   = "A123456"
+	testPassword     = "1234"
+	testTOTP         = "654321"
+	testAccessToken  = "test-jwt-token"
+	testRefreshToken = "test-refresh-token"
+
+	holdingsJSON = `{
+		"status": true,
+		"message": "SUCCESS",
+		"errorcode": "",
+		"data": {
+			"holdings": [
+				{
+					"tradingsymbol": "TATASTEEL-EQ",
+					"exchange": "NSE",
+					"isin": "INE081A01020",
+					"t1quantity": 0,
+					"realisedquantity": 2,
+					"quantity": 2,
+					"product": "DELIVERY",
+					"averageprice": 111.87,
+					"ltp": 130.15,
+					"symboltoken": "3499",
+					"close": 129.6,
+					"profitandloss": 37,
+					"pnlpercentage": 16.34
+				},
+				{
+					"tradingsymbol": "BAJAJ-AUTO-EQ",
+					"exchange": "NSE",
+					"isin": "INE917I01010",
+					"t1quantity": 1,
+					"realisedquantity": 2,
+					"quantity": 2,
+					"product": "DELIVERY",
+					"averageprice": 8000,
+					"ltp": 9000,
+					"symboltoken": "16669",
+					"close": 9100,
+					"profitandloss": 3000,
+					"pnlpercentage": 12.5
+				}
+			],
+			"totalholding": {
+				"totalholdingvalue": 27260.3,
+				"totalinvvalue": 24223.74,
+				"totalprofitandloss": 3037,
+				"totalpnlpercentage": 12.53
+			}
+		}
+	}`
+	positionsJSON = `{
+		"status": true,
+		"message": "SUCCESS",
+		"errorcode": "",
+		"data": [
+			{
+				"exchange": "NSE",
+				"symboltoken": "2885",
+				"producttype": "INTRADAY",
+				"tradingsymbol": "RELIANCE-EQ",
+				"symbolname": "RELIANCE",
+				"netqty": "10",
+				"avgnetprice": "2235.80",
+				"netvalue": "- 22358.00",
+				"ltp": "2250.00",
+				"close": "2240.00",
+				"pnl": "142.00",
+				"unrealised": "142.00"
+			}
+		]
+	}`
+	fundsJSON = `{
+		"status": true,
+		"message": "SUCCESS",
+		"errorcode": "",
+		"data": {
+			"net": "15000.50",
+			"availablecash": "20000.00",
+			"availableintradaypayin": "0",
+			"availablelimitmargin": "0",
+			"collateral": "1000.00",
+			"m2munrealized": "0",
+			"m2mrealized": "0",
+			"utiliseddebits": "4999.50"
+		}
+	}`
+	invalidTokenJSON = `{"status": false, "message": "Invalid Token", "errorcode": "AG8001", "data": null}`
+)
+
+// newTestServer starts an httptest fake of the SmartAPI endpoints
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get("X-PrivateKey") != testAPIKey || r.Header.Get("Authorization") != "Bearer "+testAccessToken {
+			_, _ = w.Write([]byte(invalidTokenJSON))
+			return false
+		}
+		return true
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(loginPath, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("X-PrivateKey") != testAPIKey {
+			_, _ = w.Write([]byte(`{"status": false, "message": "Invalid API Key", "errorcode": "AG8004", "data": null}`))
+			return
+		}
+		if body["clientcode"] != testClientCode || body["password"] != testPassword || body["totp"] != testTOTP {
+			_, _ = w.Write([]byte(`{"status": false, "message": "Invalid totp", "errorcode": "AB1050", "data": null}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status": true, "message": "SUCCESS", "errorcode": "", "data": {"jwtToken": "` + testAccessToken + `", "refreshToken": "` + testRefreshToken + `", "feedToken": "feed"}}`))
+	})
+	mux.HandleFunc(tokenPath, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["refreshToken"] != testRefreshToken {
+			_, _ = w.Write([]byte(invalidTokenJSON))
+			return
+		}
+		if authorized(w, r) {
+			_, _ = w.Write([]byte(`{"status": true, "message": "SUCCESS", "errorcode": "", "data": {"jwtToken": "` + testAccessToken + `", "refreshToken": "` + testRefreshToken + `", "feedToken": "feed"}}`))
+		}
+	})
+	mux.HandleFunc(holdingsPath, func(w http.ResponseWriter, r *http.Request) {
+		if authorized(w, r) {
+			_, _ = w.Write([]byte(holdingsJSON))
+		}
+	})
+	mux.HandleFunc(positionsPath, func(w http.ResponseWriter, r *http.Request) {
+		if authorized(w, r) {
+			_, _ = w.Write([]byte(positionsJSON))
+		}
+	})
+	mux.HandleFunc(fundsPath, func(w http.ResponseWriter, r *http.Request) {
+		if authorized(w, r) {
+			_, _ = w.Write([]byte(fundsJSON))
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(t *testing.T, totp string) *Client {
+	t.Helper()
+	client := NewClient(testAPIKey, "test-api-secret", testClientCode, testPassword, totp)
+	client.SetBaseURL(newTestServer(t).URL)
+	return client
+}
+
+func newLoggedInClient(t *testing.T) *Client {
+	t.Helper()
+	client := newTestClient(t, testTOTP)
+	assert.NoError(t, client.Login())
+	return client
+}
+
+func TestNewClient(t *testing.T) {
+	client := NewClient(testAPIKey, "test-api-secret", testClientCode, testPassword, testTOTP)
+	assert.NotNil(t, client)
+	assert.Equal(t, DefaultBaseURL, client.baseURL)
+	assert.Equal(t, testAPIKey, client.apiKey)
+	assert.Equal(t, testClientCode, client.clientCode)
+	assert.Equal(t, testTOTP, client.totp)
+	assert.False(t, client.CanAutoRefresh())
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		name    string
+		totp    string
+		wantErr bool
+	}{
+		{
+			name:    "successful login",
+			totp:    testTOTP,
+			wantErr: false,
+		},
+		{
+			name:    "invalid totp",
+			totp:    "000000",
+			wantErr: true,
+		},
+		{
+			name:    "missing totp",
+			totp:    "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, tt.totp)
+			err := client.Login()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Empty(t, client.GetAccessToken())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testAccessToken, client.GetAccessToken())
+				assert.True(t, client.CanAutoRefresh())
+			}
+		})
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	client := newLoggedInClient(t)
+
+	// Force the token to look close to expiry
+	client.expiresAt = time.Now().Add(10 * time.Minute)
+	assert.NoError(t, client.RefreshToken())
+	assert.Equal(t, testAccessToken, client.GetAccessToken())
+	assert.True(t, time.Until(client.expiresAt) > time.Hour)
+}
+
+func TestGetHoldings(t *testing.T) {
+	client := newLoggedInClient(t)
+
+	holdings, err := client.GetHoldings(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, holdings, 2)
+
+	tataSteel := holdings[0]
+	assert.Equal(t, "TATASTEEL", tataSteel.ItemName)
+	assert.Equal(t, "INE081A01020", tataSteel.ISIN)
+	assert.Equal(t, 2.0, tataSteel.Quantity)
+	assert.Equal(t, 111.87, tataSteel.AveragePrice)
+	assert.InDelta(t, 260.30, tataSteel.CurrentValue, 0.001)
+	assert.InDelta(t, 1.10, tataSteel.DayChange, 0.001)
+	assert.Equal(t, 37.0, tataSteel.TotalPnL)
+
+	// T1 shares count towards the holding and hyphenated symbols keep their name
+	bajaj := holdings[1]
+	assert.Equal(t, "BAJAJ-AUTO", bajaj.ItemName)
+	assert.Equal(t, 3.0, bajaj.Quantity)
+	assert.Equal(t, -300.0, bajaj.DayChange)
+
+	for _, holding := range holdings {
+		assert.Equal(t, models.PlatformAngelOne, holding.Platform)
+		assert.Equal(t, models.HoldingTypeStock, holding.Type)
+	}
+}
+
+func TestGetPositions(t *testing.T) {
+	client := newLoggedInClient(t)
+
+	positions, err := client.GetPositions(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, positions, 1)
+
+	reliance := positions[0]
+	assert.Equal(t, "RELIANCE", reliance.ItemName)
+	assert.Equal(t, 10.0, reliance.Quantity)
+	assert.Equal(t, 2235.80, reliance.AveragePrice)
+	assert.Equal(t, 22500.0, reliance.CurrentValue)
+	assert.Equal(t, 100.0, reliance.DayChange)
+	assert.Equal(t, 142.0, reliance.TotalPnL)
+	assert.Equal(t, models.PlatformAngelOne, reliance.Platform)
+}
+
+func TestGetFunds(t *testing.T) {
+	client := newLoggedInClient(t)
+
+	funds, err := client.GetFunds(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, models.PlatformAngelOne, funds.Platform)
+	assert.Equal(t, 20000.0, funds.AvailableCash)
+	assert.Equal(t, 4999.50, funds.UsedMargin)
+	assert.Equal(t, 1000.0, funds.Collateral)
+	assert.Equal(t, 15000.50, funds.Net)
+}
+
+func TestInvalidToken(t *testing.T) {
+	client := newTestClient(t, testTOTP)
+	client.SetAccessToken("stale-token")
+
+	_, err := client.GetHoldings(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "AG8001")
+
+	client.SetAccessToken("")
+	_, err = client.GetFunds(context.Background())
+	assert.Error(t, err)
+}
+
+func TestMockClient(t *testing.T) {
+	mock := NewMockClient().WithMockHoldings(GetDefaultMockHoldings())
+	holdings, err := mock.GetHoldings(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, holdings, 2)
+
+	mock = NewMockClient().WithFundsError(errors.New("api error"))
+	_, err = mock.GetFunds(context.Background())
+	assert.EqualError(t, err, "api error")
+}
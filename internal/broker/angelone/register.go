@@ -0,0 +1,27 @@
+package angelone
+
+import (
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/broker/types"
+	"github.com/Kora1128/FinSight/internal/cache"
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+func init() {
+	types.Register(types.BrokerSpec{
+		Name:        models.PlatformAngelOne,
+		DisplayName: "Angel One",
+		New: func(creds types.ClientCredentials) types.Client {
+			// The request token carries the current TOTP for Angel One
+			return NewClient(creds.APIKey, creds.APISecret, creds.ClientCode, creds.Password, creds.RequestToken)
+		},
+		TokenCacheKey: cache.KeyAngelOneToken,
+		TokenTTL:      24 * time.Hour, // SmartAPI sessions are valid until midnight
+		Capabilities: types.Capabilities{
+			Positions:   true,
+			AutoRefresh: false, // Re-login needs a fresh TOTP from the user
+			Funds:       true,
+		},
+	})
+}
@@ -0,0 +1,10 @@
+package broker
+
+// Broker integrations register themselves with the types registry from their init functions.
+// Importing them here makes every supported broker available wherever the BrokerManager is used.
+import (
+	_ "github.com/Kora1128/FinSight/internal/broker/angelone"
+	_ "github.com/Kora1128/FinSight/internal/broker/icici_direct"
+	_ "github.com/Kora1128/FinSight/internal/broker/upstox"
+	_ "github.com/Kora1128/FinSight/internal/broker/zerodha"
+)
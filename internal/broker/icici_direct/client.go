@@ -6,13 +6,17 @@ import (
 	"time"
 
 	"github.com/Kora1128/FinSight/internal/broker/types"
+	"github.com/Kora1128/FinSight/internal/instruments"
 	"github.com/Kora1128/FinSight/internal/models"
 	"github.com/Kora1128/icici-breezeconnect-go/breezeconnect"
 	"github.com/Kora1128/icici-breezeconnect-go/breezeconnect/services"
 )
 
-// Ensure Client implements types.Client interface
-var _ types.Client = (*Client)(nil)
+// Ensure Client implements types.Client and types.MutualFundsProvider interfaces
+var (
+	_ types.Client              = (*Client)(nil)
+	_ types.MutualFundsProvider = (*Client)(nil)
+)
 
 // Client represents the ICICI Direct broker integration client
 type Client struct {
@@ -103,8 +107,8 @@ func (c *Client) GetHoldings(ctx context.Context) ([]models.Holding, error) {
 
 	var normalizedHoldings []models.Holding
 	for _, h := range holdings {
-		holdingType := models.HoldingTypeStock
-		// TODO: Add logic to differentiate mutual funds if needed
+		// Portfolio holdings are exchange traded, so fund units among them are ETFs
+		holdingType := instruments.ClassifyHolding(h.ISIN, h.Symbol, true)
 		normalizedHolding := models.Holding{
 			ItemName:         h.Symbol,
 			ISIN:             h.ISIN,
@@ -124,6 +128,39 @@ func (c *Client) GetHoldings(ctx context.Context) ([]models.Holding, error) {
 	return normalizedHoldings, nil
 }
 
+// GetMFHoldings fetches the mutual fund units held in the ICICI Direct demat account and normalizes them into the common Holding struct.
+// Breeze has no separate mutual fund API, so these are the demat holdings whose ISINs are those of unlisted fund units.
+func (c *Client) GetMFHoldings(ctx context.Context) ([]models.Holding, error) {
+	dematService := services.NewDematService(c.client)
+	holdings, err := dematService.GetDematHoldings()
+	if err != nil {
+		return nil, err
+	}
+
+	var normalizedHoldings []models.Holding
+	for _, h := range holdings {
+		if instruments.ClassifyHolding(h.ISIN, h.Symbol, false) != models.HoldingTypeMutualFund {
+			continue
+		}
+		normalizedHolding := models.Holding{
+			ItemName:         h.Symbol,
+			ISIN:             h.ISIN,
+			Quantity:         float64(h.Quantity),
+			AveragePrice:     h.AveragePrice,
+			LastTradedPrice:  h.LastTradedPrice,
+			CurrentValue:     h.TotalValue,
+			DayChange:        0, // Not available
+			DayChangePercent: 0, // Not available
+			TotalPnL:         h.PnL,
+			Platform:         models.PlatformICICIDirect,
+			Type:             models.HoldingTypeMutualFund,
+			LastUpdated:      time.Now(),
+		}
+		normalizedHoldings = append(normalizedHoldings, normalizedHolding)
+	}
+	return normalizedHoldings, nil
+}
+
 // GetPositions fetches the current positions from ICICI Direct and normalizes them into the common Holding struct
 func (c *Client) GetPositions(ctx context.Context) ([]models.Holding, error) {
 	portfolioService := services.NewPortfolioService(c.client)
@@ -134,7 +171,7 @@ func (c *Client) GetPositions(ctx context.Context) ([]models.Holding, error) {
 
 	var normalizedHoldings []models.Holding
 	for _, p := range positions {
-		holdingType := models.HoldingTypeStock
+		holdingType := instruments.ClassifyHolding("", p.Symbol, true)
 		normalizedHolding := models.Holding{
 			ItemName:         p.Symbol,
 			ISIN:             "", // ISIN not available in Position struct
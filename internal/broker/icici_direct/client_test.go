@@ -96,6 +96,48 @@ func TestGetHoldings(t *testing.T) {
 	}
 }
 
+func TestGetMFHoldings(t *testing.T) {
+	tests := []struct {
+		name        string
+		mockClient  *MockClient
+		wantErr     bool
+		expectedErr error
+		expectedLen int
+	}{
+		{
+			name:        "successful mutual fund holdings fetch",
+			mockClient:  NewMockClient().WithMockMFHoldings(GetDefaultMockMFHoldings()),
+			wantErr:     false,
+			expectedErr: nil,
+			expectedLen: 1,
+		},
+		{
+			name:        "failed mutual fund holdings fetch",
+			mockClient:  NewMockClient().WithMFError(errors.New("api error")),
+			wantErr:     true,
+			expectedErr: errors.New("api error"),
+			expectedLen: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			holdings, err := tt.mockClient.GetMFHoldings(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, holdings, tt.expectedLen)
+				for _, holding := range holdings {
+					assert.Equal(t, models.PlatformICICIDirect, holding.Platform)
+					assert.Equal(t, models.HoldingTypeMutualFund, holding.Type)
+				}
+			}
+		})
+	}
+}
+
 func TestGetPositions(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -0,0 +1,26 @@
+package icici_direct
+
+import (
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/broker/types"
+	"github.com/Kora1128/FinSight/internal/cache"
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+func init() {
+	types.Register(types.BrokerSpec{
+		Name:        models.PlatformICICIDirect,
+		DisplayName: "ICICI Direct",
+		New: func(creds types.ClientCredentials) types.Client {
+			return NewClient(creds.APIKey, creds.APISecret, creds.RequestToken)
+		},
+		TokenCacheKey: cache.KeyICICIToken,
+		TokenTTL:      12 * time.Hour, // ICICI session tokens typically expire in 12 hours
+		Capabilities: types.Capabilities{
+			Positions:   true,
+			AutoRefresh: true,
+			MutualFunds: true,
+		},
+	})
+}
@@ -2,6 +2,7 @@ package broker
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -13,19 +14,13 @@ import (
 
 const (
 	// ClientTypeZerodha represents a Zerodha client
-	ClientTypeZerodha string = "zerodha"
+	ClientTypeZerodha string = models.PlatformZerodha
 	// ClientTypeICICIDirect represents an ICICI Direct client
-	ClientTypeICICIDirect string = "icici_direct"
+	ClientTypeICICIDirect string = models.PlatformICICIDirect
 )
 
-// ClientCredentials represents the credentials needed to create a broker client
-type ClientCredentials struct {
-	APIKey       string
-	APISecret    string
-	RequestToken string
-	Password     string // For ICICI Direct
-	UserID       string // Unique user identifier
-}
+// ClientCredentials is an alias for types.ClientCredentials
+type ClientCredentials = types.ClientCredentials
 
 // BrokerManager manages the creation and refreshing of broker clients
 type BrokerManager struct {
@@ -34,7 +29,7 @@ type BrokerManager struct {
 	mu              sync.RWMutex
 	maxAge          time.Duration // Maximum age before a client is considered stale
 	refreshInterval time.Duration
-	factory         ClientFactory
+	registry        *types.Registry
 }
 
 // NewBrokerManager creates a new BrokerManager
@@ -44,7 +39,7 @@ func NewBrokerManager(credentialsRepo repository.BrokerCredentialsRepository, ca
 		cache:           cache,
 		maxAge:          maxAge,
 		refreshInterval: refreshInterval,
-		factory:         &DefaultClientFactory{},
+		registry:        types.DefaultRegistry(),
 	}
 
 	// Start a background goroutine to periodically refresh tokens
@@ -53,145 +48,129 @@ func NewBrokerManager(credentialsRepo repository.BrokerCredentialsRepository, ca
 	return manager
 }
 
-// CreateClient gets an existing client or creates a new one based on the provided credentials
-func (m *BrokerManager) CreateClient(clientType string, creds ClientCredentials) (types.Client, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	switch clientType {
-	case ClientTypeZerodha:
-
-		// Create a new Zerodha client
-		client := m.factory.CreateZerodhaClient(creds.APIKey, creds.APISecret, creds.RequestToken)
-
-		// Authenticate if request token is provided
-		if creds.RequestToken != "" {
-			if err := client.Login(); err != nil {
-				return nil, fmt.Errorf("failed to login to Zerodha: %w", err)
-			}
+// SupportedBrokers returns the specs of all brokers the manager can create clients for
+func (m *BrokerManager) SupportedBrokers() []types.BrokerSpec {
+	return m.registry.List()
+}
 
-			// Store the token in database
-			err := m.credentialsRepo.SaveCredentials(creds.UserID, string(ClientTypeZerodha), creds.APIKey, creds.APISecret, creds.RequestToken, time.Now().Add(m.maxAge))
-			if err != nil {
-				return nil, fmt.Errorf("failed to update access token in database: %w", err)
-			}
+// IsSupported checks if the manager can create clients for the given broker type
+func (m *BrokerManager) IsSupported(clientType string) bool {
+	_, ok := m.registry.Lookup(clientType)
+	return ok
+}
 
-			// Also keep in cache for quick access
+// lookup returns the registered spec for the given client type
+func (m *BrokerManager) lookup(clientType string) (types.BrokerSpec, error) {
+	spec, ok := m.registry.Lookup(clientType)
+	if !ok {
+		return types.BrokerSpec{}, fmt.Errorf("%w: unknown client type: %s", types.ErrBrokerNotSupported, clientType)
+	}
+	return spec, nil
+}
 
-			tokenKey := fmt.Sprintf("%s:%s", cache.KeyZerodhaToken, creds.UserID)
-			m.cache.Set(tokenKey, client.GetAccessToken(), m.maxAge)
-		}
+// tokenTTL returns how long a token for the given broker should be kept
+func (m *BrokerManager) tokenTTL(spec types.BrokerSpec) time.Duration {
+	if spec.TokenTTL > 0 && spec.TokenTTL < m.maxAge {
+		return spec.TokenTTL
+	}
+	return m.maxAge
+}
 
-		return client, nil
+// tokenKey returns the cache key of the access token for a user and broker
+func tokenKey(spec types.BrokerSpec, userID string) string {
+	return fmt.Sprintf("%s:%s", spec.TokenCacheKey, userID)
+}
 
-	case ClientTypeICICIDirect:
+// CreateClient gets an existing client or creates a new one based on the provided credentials
+func (m *BrokerManager) CreateClient(clientType string, creds ClientCredentials) (types.Client, error) {
+	spec, err := m.lookup(clientType)
+	if err != nil {
+		return nil, err
+	}
 
-		// Create a new ICICI Direct client
-		client := m.factory.CreateICICIDirectClient(creds.APIKey, creds.APISecret, creds.RequestToken)
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-		// Authenticate if request token is provided
-		if creds.RequestToken != "" {
-			if err := client.Login(); err != nil {
-				return nil, fmt.Errorf("failed to login to ICICI Direct: %w", err)
-			}
+	client := spec.New(creds)
 
-			// Store the token in database
-			err := m.credentialsRepo.SaveCredentials(creds.UserID, string(ClientTypeICICIDirect), creds.APIKey, creds.APISecret, creds.RequestToken, time.Now().Add(m.maxAge))
-			if err != nil {
-				return nil, fmt.Errorf("failed to update access token in database: %w", err)
-			}
+	// Authenticate if request token is provided
+	if creds.RequestToken != "" {
+		if err := client.Login(); err != nil {
+			return nil, fmt.Errorf("failed to login to %s: %w", spec.DisplayName, err)
+		}
 
-			// Also keep in cache for quick access
-			tokenKey := fmt.Sprintf("%s:%s", cache.KeyICICIToken, creds.UserID)
-			m.cache.Set(tokenKey, client.GetAccessToken(), m.maxAge)
+		// Store the token in database
+		ttl := m.tokenTTL(spec)
+		err := m.credentialsRepo.SaveCredentials(creds.UserID, spec.Name, creds.APIKey, creds.APISecret, creds.RequestToken, time.Now().Add(ttl))
+		if err != nil {
+			return nil, fmt.Errorf("failed to update access token in database: %w", err)
 		}
-		return client, nil
 
-	default:
-		return nil, fmt.Errorf("unknown client type: %s", clientType)
+		// Also keep in cache for quick access
+		m.cache.Set(tokenKey(spec, creds.UserID), client.GetAccessToken(), ttl)
 	}
+
+	return client, nil
 }
 
 // GetClient returns a client for the given user and client type, if it exists
 func (m *BrokerManager) GetClient(userID string, clientType string) (types.Client, bool) {
+	spec, err := m.lookup(clientType)
+	if err != nil {
+		return nil, false
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	creds, err := m.credentialsRepo.GetCredentials(userID, string(clientType))
-	if err != nil {
+	creds, err := m.credentialsRepo.GetCredentials(userID, spec.Name)
+	if err != nil || creds == nil {
 		return nil, false
 	}
 
-	switch clientType {
-	case ClientTypeZerodha:
-		client := m.factory.CreateZerodhaClient(creds.APIKey, creds.APISecret, creds.AccessToken)
-		tokenKey := fmt.Sprintf("%s:%s", cache.KeyZerodhaToken, creds.UserID)
-		if token, found := m.cache.Get(tokenKey); found {
-			client.SetAccessToken(token.(string))
-		} else {
-			return nil, false
-		}
-		return client, true
-	case ClientTypeICICIDirect:
-		client := m.factory.CreateICICIDirectClient(creds.APIKey, creds.APISecret, creds.AccessToken)
-		tokenKey := fmt.Sprintf("%s:%s", cache.KeyICICIToken, creds.UserID)
-		if token, found := m.cache.Get(tokenKey); found {
-			client.SetAccessToken(token.(string))
-		} else {
-			return nil, false
-		}
-		return client, true
-	default:
+	token, found := m.cache.Get(tokenKey(spec, creds.UserID))
+	if !found {
 		return nil, false
 	}
 
+	client := spec.New(ClientCredentials{
+		APIKey:       creds.APIKey,
+		APISecret:    creds.APISecret,
+		RequestToken: creds.AccessToken,
+		UserID:       creds.UserID,
+	})
+	client.SetAccessToken(token.(string))
+	return client, true
 }
 
 // RefreshTokens attempts to refresh the tokens for all clients of a specific user
 func (m *BrokerManager) RefreshTokens(userID string, cred *models.Credentials) error {
+	spec, err := m.lookup(cred.BrokerType)
+	if err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	switch cred.BrokerType {
-	case ClientTypeZerodha:
-
-		// Create a new Zerodha client
-		client := m.factory.CreateZerodhaClient(cred.APIKey, cred.APISecret, cred.AccessToken)
-
-		// Authenticate if request token is provided
-		if cred.AccessToken != "" {
-			if err := client.Login(); err != nil {
-				return fmt.Errorf("failed to login to Zerodha: %w", err)
-			}
-
-			// Also keep in cache for quick access
-
-			tokenKey := fmt.Sprintf("%s:%s", cache.KeyZerodhaToken, cred.UserID)
-			m.cache.Set(tokenKey, client.GetAccessToken(), m.maxAge)
-		}
-
-		return nil
-
-	case ClientTypeICICIDirect:
-
-		// Create a new ICICI Direct client
-		client := m.factory.CreateICICIDirectClient(cred.APIKey, cred.APISecret, cred.AccessToken)
-
-		// Authenticate if request token is provided
-		if cred.AccessToken != "" {
-			if err := client.Login(); err != nil {
-				return fmt.Errorf("failed to login to ICICI Direct: %w", err)
-			}
-
-			// Also keep in cache for quick access
-			tokenKey := fmt.Sprintf("%s:%s", cache.KeyICICIToken, cred.UserID)
-			m.cache.Set(tokenKey, client.GetAccessToken(), m.maxAge)
+	client := spec.New(ClientCredentials{
+		APIKey:       cred.APIKey,
+		APISecret:    cred.APISecret,
+		RequestToken: cred.AccessToken,
+		UserID:       userID,
+	})
+
+	// Authenticate if request token is provided
+	if cred.AccessToken != "" {
+		if err := client.Login(); err != nil {
+			return fmt.Errorf("failed to login to %s: %w", spec.DisplayName, err)
 		}
-		return nil
 
-	default:
-		return fmt.Errorf("unknown client type: %s", cred.BrokerType)
+		// Also keep in cache for quick access
+		m.cache.Set(tokenKey(spec, userID), client.GetAccessToken(), m.tokenTTL(spec))
 	}
+
+	return nil
 }
 
 // startRefreshWorker starts a goroutine that periodically refreshes tokens
@@ -205,20 +184,59 @@ func (m *BrokerManager) startRefreshWorker() {
 	}
 }
 
-// refreshAllTokens attempts to refresh tokens for all clients
+// refreshAllTokens attempts to refresh tokens for all clients whose token is no longer cached
 func (m *BrokerManager) refreshAllTokens() {
-	m.mu.RLock()
 	creds, err := m.credentialsRepo.GetCredentialsForAllUsers()
 	if err != nil {
 		return
 	}
 	for _, cred := range creds {
-		userID := cred.UserID
-		if _, found := m.cache.Get(fmt.Sprintf("%s:%s", cred.BrokerType+"_token", userID)); !found {
-			_ = m.RefreshTokens(userID, cred)
+		spec, ok := m.registry.Lookup(cred.BrokerType)
+		if !ok || !spec.Capabilities.AutoRefresh {
+			continue
+		}
+		if _, found := m.cache.Get(tokenKey(spec, cred.UserID)); !found {
+			_ = m.RefreshTokens(cred.UserID, cred)
 		}
 	}
-	m.mu.RUnlock()
+}
+
+// ConnectedUsers returns the IDs of all users with stored broker credentials, sorted
+func (m *BrokerManager) ConnectedUsers() ([]string, error) {
+	creds, err := m.credentialsRepo.GetCredentialsForAllUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	userIDs := make([]string, 0, len(creds))
+	for _, cred := range creds {
+		if !seen[cred.UserID] {
+			seen[cred.UserID] = true
+			userIDs = append(userIDs, cred.UserID)
+		}
+	}
+	sort.Strings(userIDs)
+	return userIDs, nil
+}
+
+// UserSession returns the API key and access token of a user's connected session of a broker,
+// reporting false when the user has no credentials for it or their token is no longer cached
+func (m *BrokerManager) UserSession(userID string, clientType string) (apiKey, accessToken string, ok bool) {
+	spec, err := m.lookup(clientType)
+	if err != nil {
+		return "", "", false
+	}
+
+	creds, err := m.credentialsRepo.GetCredentials(userID, spec.Name)
+	if err != nil || creds == nil {
+		return "", "", false
+	}
+	token, found := m.cache.Get(tokenKey(spec, userID))
+	if !found {
+		return "", "", false
+	}
+	return creds.APIKey, token.(string), true
 }
 
 // cleanupStaleClients removes clients that haven't been accessed for a long time
@@ -230,24 +248,17 @@ func (m *BrokerManager) cleanupStaleClients() {
 
 // RemoveClient removes a client for a specific user and client type
 func (m *BrokerManager) RemoveClient(userID string, clientType string) {
+	spec, err := m.lookup(clientType)
+	if err != nil {
+		return
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	switch clientType {
-	case ClientTypeZerodha:
-		// Remove from cache
-		tokenKey := fmt.Sprintf("%s:%s", cache.KeyZerodhaToken, userID)
-		m.cache.Delete(tokenKey)
-
-		// Remove from database
-		_ = m.credentialsRepo.DeleteCredentials(userID, string(ClientTypeZerodha))
+	// Remove from cache
+	m.cache.Delete(tokenKey(spec, userID))
 
-	case ClientTypeICICIDirect:
-		// Remove from cache
-		tokenKey := fmt.Sprintf("%s:%s", cache.KeyICICIToken, userID)
-		m.cache.Delete(tokenKey)
-
-		// Remove from database
-		_ = m.credentialsRepo.DeleteCredentials(userID, string(ClientTypeICICIDirect))
-	}
+	// Remove from database
+	_ = m.credentialsRepo.DeleteCredentials(userID, spec.Name)
 }
@@ -29,3 +29,21 @@ type Client interface {
 	// SetAccessToken sets the access token
 	SetAccessToken(accessToken string)
 }
+
+// FundsProvider is implemented by clients that can report available cash and margin
+type FundsProvider interface {
+	// GetFunds fetches the cash and margin available at the broker
+	GetFunds(ctx context.Context) (*models.Funds, error)
+}
+
+// TradesProvider is implemented by clients that can report executed trades
+type TradesProvider interface {
+	// GetTrades fetches the delivery trades the broker reports, typically those of the current trading day
+	GetTrades(ctx context.Context) ([]models.Trade, error)
+}
+
+// MutualFundsProvider is implemented by clients that can report mutual fund holdings kept outside the equity holdings
+type MutualFundsProvider interface {
+	// GetMFHoldings fetches the mutual fund units held through the broker
+	GetMFHoldings(ctx context.Context) ([]models.Holding, error)
+}
@@ -0,0 +1,169 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Common registry errors
+var (
+	ErrInvalidSpec        = errors.New("invalid broker spec")
+	ErrBrokerRegistered   = errors.New("broker already registered")
+	ErrBrokerNotSupported = errors.New("broker not supported")
+)
+
+// ClientCredentials represents the credentials needed to create a broker client
+type ClientCredentials struct {
+	APIKey       string
+	APISecret    string
+	RequestToken string
+	Password     string // For ICICI Direct and Angel One (MPIN)
+	RedirectURI  string // For OAuth brokers that require the redirect URI on code exchange (Upstox)
+	ClientCode   string // Broker account ID, for brokers that log in with it (Angel One)
+	UserID       string // Unique user identifier
+}
+
+// Constructor creates a broker client from the given credentials
+type Constructor func(creds ClientCredentials) Client
+
+// Capabilities describes the optional features a broker integration supports
+type Capabilities struct {
+	// Positions indicates that the broker exposes open positions in addition to holdings
+	Positions bool
+
+	// AutoRefresh indicates that access tokens can be renewed without user interaction
+	AutoRefresh bool
+
+	// Funds indicates that the client implements FundsProvider
+	Funds bool
+
+	// Trades indicates that the client implements TradesProvider
+	Trades bool
+
+	// MutualFunds indicates that the client implements MutualFundsProvider
+	MutualFunds bool
+}
+
+// BrokerSpec describes a broker integration and how to construct its client
+type BrokerSpec struct {
+	// Name is the broker type identifier, also used as the holding platform
+	Name string
+
+	// DisplayName is the human readable broker name used in messages
+	DisplayName string
+
+	// New constructs a client for the broker
+	New Constructor
+
+	// TokenCacheKey is the cache key prefix under which access tokens are kept
+	TokenCacheKey string
+
+	// TokenTTL is how long an access token stays valid after login
+	TokenTTL time.Duration
+
+	// Capabilities lists the optional features of the broker
+	Capabilities Capabilities
+}
+
+// Registry holds the set of available broker integrations
+type Registry struct {
+	mu    sync.RWMutex
+	specs map[string]BrokerSpec
+}
+
+// NewRegistry creates an empty broker registry
+func NewRegistry() *Registry {
+	return &Registry{
+		specs: make(map[string]BrokerSpec),
+	}
+}
+
+// Register adds a broker spec to the registry
+func (r *Registry) Register(spec BrokerSpec) error {
+	if spec.Name == "" || spec.New == nil {
+		return fmt.Errorf("%w: name and constructor are required", ErrInvalidSpec)
+	}
+	if spec.DisplayName == "" {
+		spec.DisplayName = spec.Name
+	}
+	if spec.TokenCacheKey == "" {
+		spec.TokenCacheKey = spec.Name + "_token"
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.specs[spec.Name]; exists {
+		return fmt.Errorf("%w: %s", ErrBrokerRegistered, spec.Name)
+	}
+	r.specs[spec.Name] = spec
+	return nil
+}
+
+// Lookup returns the spec registered under the given name
+func (r *Registry) Lookup(name string) (BrokerSpec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	spec, ok := r.specs[name]
+	return spec, ok
+}
+
+// List returns all registered specs sorted by name
+func (r *Registry) List() []BrokerSpec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	specs := make([]BrokerSpec, 0, len(r.specs))
+	for _, spec := range r.specs {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].Name < specs[j].Name
+	})
+	return specs
+}
+
+// Names returns the names of all registered brokers sorted alphabetically
+func (r *Registry) Names() []string {
+	specs := r.List()
+	names := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		names = append(names, spec.Name)
+	}
+	return names
+}
+
+// defaultRegistry is the registry broker packages register themselves with
+var defaultRegistry = NewRegistry()
+
+// DefaultRegistry returns the process-wide broker registry
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// Register adds a broker spec to the default registry.
+// It is meant to be called from a broker package's init function and panics on invalid or duplicate specs.
+func Register(spec BrokerSpec) {
+	if err := defaultRegistry.Register(spec); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the spec registered under the given name in the default registry
+func Lookup(name string) (BrokerSpec, bool) {
+	return defaultRegistry.Lookup(name)
+}
+
+// IsRegistered reports whether a broker with the given name is registered in the default registry
+func IsRegistered(name string) bool {
+	_, ok := defaultRegistry.Lookup(name)
+	return ok
+}
+
+// Registered returns all specs in the default registry sorted by name
+func Registered() []BrokerSpec {
+	return defaultRegistry.List()
+}
@@ -0,0 +1,105 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubClient struct {
+	accessToken string
+}
+
+func (c *stubClient) GetHoldings(ctx context.Context) ([]models.Holding, error)  { return nil, nil }
+func (c *stubClient) GetPositions(ctx context.Context) ([]models.Holding, error) { return nil, nil }
+func (c *stubClient) Login() error                                               { return nil }
+func (c *stubClient) CanAutoRefresh() bool                                       { return false }
+func (c *stubClient) RefreshToken() error                                        { return nil }
+func (c *stubClient) GetAccessToken() string                                     { return c.accessToken }
+func (c *stubClient) SetAccessToken(accessToken string)                          { c.accessToken = accessToken }
+
+func newStubSpec(name string) BrokerSpec {
+	return BrokerSpec{
+		Name: name,
+		New: func(creds ClientCredentials) Client {
+			return &stubClient{accessToken: creds.RequestToken}
+		},
+		TokenTTL: time.Hour,
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	tests := []struct {
+		name        string
+		specs       []BrokerSpec
+		expectedErr error
+	}{
+		{
+			name:        "valid spec",
+			specs:       []BrokerSpec{newStubSpec("alpha")},
+			expectedErr: nil,
+		},
+		{
+			name:        "missing name",
+			specs:       []BrokerSpec{newStubSpec("")},
+			expectedErr: ErrInvalidSpec,
+		},
+		{
+			name:        "missing constructor",
+			specs:       []BrokerSpec{{Name: "alpha"}},
+			expectedErr: ErrInvalidSpec,
+		},
+		{
+			name:        "duplicate name",
+			specs:       []BrokerSpec{newStubSpec("alpha"), newStubSpec("alpha")},
+			expectedErr: ErrBrokerRegistered,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			var err error
+			for _, spec := range tt.specs {
+				if err = registry.Register(spec); err != nil {
+					break
+				}
+			}
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRegistryLookup(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Register(newStubSpec("alpha")))
+
+	spec, ok := registry.Lookup("alpha")
+	assert.True(t, ok)
+	assert.Equal(t, "alpha", spec.DisplayName)
+	assert.Equal(t, "alpha_token", spec.TokenCacheKey)
+
+	client := spec.New(ClientCredentials{RequestToken: "token"})
+	assert.Equal(t, "token", client.GetAccessToken())
+
+	_, ok = registry.Lookup("beta")
+	assert.False(t, ok)
+}
+
+func TestRegistryList(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Register(newStubSpec("gamma")))
+	assert.NoError(t, registry.Register(newStubSpec("alpha")))
+	assert.NoError(t, registry.Register(newStubSpec("beta")))
+
+	assert.Equal(t, []string{"alpha", "beta", "gamma"}, registry.Names())
+	assert.Len(t, registry.List(), 3)
+}
@@ -0,0 +1,319 @@
+package upstox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/broker/types"
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// Ensure Client implements types.Client interface
+var _ types.Client = (*Client)(nil)
+
+// DefaultBaseURL is the base URL of the Upstox v2 API
+const DefaultBaseURL = "https://api.upstox.com/v2"
+
+// API endpoints relative to the base URL
+const (
+	tokenPath     = "/login/authorization/token"
+	holdingsPath  = "/portfolio/long-term-holdings"
+	positionsPath = "/portfolio/short-term-positions"
+)
+
+// Client represents the Upstox broker integration client
+type Client struct {
+	httpClient   *http.Client
+	baseURL      string
+	apiKey       string
+	apiSecret    string
+	requestToken string
+	redirectURI  string
+	accessToken  string
+	expiresAt    time.Time
+}
+
+// NewClient creates a new Upstox client with the provided API key, secret and authorization code.
+// The redirect URI must match the one registered for the app and used to obtain the authorization code.
+func NewClient(apiKey, apiSecret, requestToken, redirectURI string) *Client {
+	return &Client{
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+		baseURL:      DefaultBaseURL,
+		apiKey:       apiKey,
+		apiSecret:    apiSecret,
+		requestToken: requestToken,
+		redirectURI:  redirectURI,
+	}
+}
+
+// SetBaseURL overrides the API base URL, e.g. to point the client at a sandbox
+func (c *Client) SetBaseURL(baseURL string) {
+	c.baseURL = strings.TrimRight(baseURL, "/")
+}
+
+// tokenResponse represents the response of the authorization code exchange
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	UserID      string `json:"user_id"`
+	Email       string `json:"email"`
+}
+
+// apiError represents a single error entry returned by the Upstox API
+type apiError struct {
+	ErrorCode string `json:"errorCode"`
+	Message   string `json:"message"`
+}
+
+// apiResponse represents the common envelope of Upstox API responses
+type apiResponse struct {
+	Status string          `json:"status"`
+	Data   json.RawMessage `json:"data"`
+	Errors []apiError      `json:"errors"`
+}
+
+// holding represents a long-term holding as returned by Upstox
+type holding struct {
+	ISIN                string  `json:"isin"`
+	CompanyName         string  `json:"company_name"`
+	TradingSymbol       string  `json:"trading_symbol"`
+	Exchange            string  `json:"exchange"`
+	InstrumentToken     string  `json:"instrument_token"`
+	Quantity            float64 `json:"quantity"`
+	AveragePrice        float64 `json:"average_price"`
+	LastPrice           float64 `json:"last_price"`
+	ClosePrice          float64 `json:"close_price"`
+	PnL                 float64 `json:"pnl"`
+	DayChange           float64 `json:"day_change"`
+	DayChangePercentage float64 `json:"day_change_percentage"`
+}
+
+// position represents a short-term position as returned by Upstox
+type position struct {
+	TradingSymbol   string  `json:"trading_symbol"`
+	Exchange        string  `json:"exchange"`
+	InstrumentToken string  `json:"instrument_token"`
+	Product         string  `json:"product"`
+	Quantity        float64 `json:"quantity"`
+	AveragePrice    float64 `json:"average_price"`
+	LastPrice       float64 `json:"last_price"`
+	ClosePrice      float64 `json:"close_price"`
+	PnL             float64 `json:"pnl"`
+	Unrealised      float64 `json:"unrealised"`
+	Realised        float64 `json:"realised"`
+}
+
+// Login exchanges the authorization code for an access token
+func (c *Client) Login() error {
+	if c.requestToken == "" || c.apiSecret == "" {
+		return errors.New("invalid authorization code or api secret")
+	}
+
+	form := url.Values{}
+	form.Set("code", c.requestToken)
+	form.Set("client_id", c.apiKey)
+	form.Set("client_secret", c.apiSecret)
+	form.Set("redirect_uri", c.redirectURI)
+	form.Set("grant_type", "authorization_code")
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+tokenPath, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return decodeError(resp.StatusCode, body)
+	}
+
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return errors.New("no access token in response")
+	}
+
+	c.accessToken = token.AccessToken
+	c.expiresAt = nextExpiry(time.Now())
+	return nil
+}
+
+// CanAutoRefresh checks if the client can refresh the token automatically.
+// Upstox has no refresh token, a new authorization code is needed every day.
+func (c *Client) CanAutoRefresh() bool {
+	return false
+}
+
+// RefreshToken attempts to refresh the authentication token
+func (c *Client) RefreshToken() error {
+	if c.accessToken != "" && time.Until(c.expiresAt) > 1*time.Hour {
+		return nil // No need to refresh yet
+	}
+	return errors.New("upstox tokens cannot be refreshed, re-authorization required")
+}
+
+// SetAccessToken sets the access token for the client
+func (c *Client) SetAccessToken(token string) {
+	c.accessToken = token
+}
+
+// GetAccessToken returns the current access token
+func (c *Client) GetAccessToken() string {
+	return c.accessToken
+}
+
+// GetAPIKey returns the API key
+func (c *Client) GetAPIKey() string {
+	return c.apiKey
+}
+
+// GetHoldings fetches the current portfolio holdings from Upstox and normalizes them into the common Holding struct
+func (c *Client) GetHoldings(ctx context.Context) ([]models.Holding, error) {
+	var holdings []holding
+	if err := c.get(ctx, holdingsPath, &holdings); err != nil {
+		return nil, err
+	}
+
+	var normalizedHoldings []models.Holding
+	for _, h := range holdings {
+		normalizedHolding := models.Holding{
+			ItemName:         h.TradingSymbol,
+			ISIN:             h.ISIN,
+			Quantity:         h.Quantity,
+			AveragePrice:     h.AveragePrice,
+			LastTradedPrice:  h.LastPrice,
+			CurrentValue:     h.Quantity * h.LastPrice,
+			DayChange:        h.DayChange * h.Quantity, // Upstox reports the change per share
+			DayChangePercent: h.DayChangePercentage,
+			TotalPnL:         h.PnL,
+			Platform:         models.PlatformUpstox,
+			Type:             models.HoldingTypeStock,
+			LastUpdated:      time.Now(),
+		}
+		normalizedHoldings = append(normalizedHoldings, normalizedHolding)
+	}
+	return normalizedHoldings, nil
+}
+
+// GetPositions fetches the current positions from Upstox and normalizes them into the common Holding struct
+func (c *Client) GetPositions(ctx context.Context) ([]models.Holding, error) {
+	var positions []position
+	if err := c.get(ctx, positionsPath, &positions); err != nil {
+		return nil, err
+	}
+
+	var normalizedHoldings []models.Holding
+	for _, p := range positions {
+		dayChange := 0.0
+		dayChangePercent := 0.0
+		if p.ClosePrice > 0 {
+			dayChange = (p.LastPrice - p.ClosePrice) * p.Quantity
+			dayChangePercent = (p.LastPrice - p.ClosePrice) / p.ClosePrice * 100
+		}
+		normalizedHolding := models.Holding{
+			ItemName:         p.TradingSymbol,
+			ISIN:             isinFromInstrumentToken(p.InstrumentToken),
+			Quantity:         p.Quantity,
+			AveragePrice:     p.AveragePrice,
+			LastTradedPrice:  p.LastPrice,
+			CurrentValue:     p.Quantity * p.LastPrice,
+			DayChange:        dayChange,
+			DayChangePercent: dayChangePercent,
+			TotalPnL:         p.PnL,
+			Platform:         models.PlatformUpstox,
+			Type:             models.HoldingTypeStock,
+			LastUpdated:      time.Now(),
+		}
+		normalizedHoldings = append(normalizedHoldings, normalizedHolding)
+	}
+	return normalizedHoldings, nil
+}
+
+// get performs an authenticated GET request and decodes the data field of the response into out
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	if c.accessToken == "" {
+		return errors.New("not logged in to upstox")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return decodeError(resp.StatusCode, body)
+	}
+
+	var envelope apiResponse
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("failed to decode upstox response: %w", err)
+	}
+	if envelope.Status != "success" {
+		return decodeError(resp.StatusCode, body)
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("failed to decode upstox data: %w", err)
+	}
+	return nil
+}
+
+// decodeError builds an error from an Upstox error response
+func decodeError(statusCode int, body []byte) error {
+	var envelope apiResponse
+	if err := json.Unmarshal(body, &envelope); err == nil && len(envelope.Errors) > 0 {
+		e := envelope.Errors[0]
+		return fmt.Errorf("upstox api error (status %d): %s: %s", statusCode, e.ErrorCode, e.Message)
+	}
+	return fmt.Errorf("upstox api error (status %d)", statusCode)
+}
+
+// isinFromInstrumentToken extracts the ISIN from an equity instrument token such as "NSE_EQ|INE002A01018"
+func isinFromInstrumentToken(token string) string {
+	segment, isin, found := strings.Cut(token, "|")
+	if !found || !strings.HasSuffix(segment, "_EQ") || len(isin) != 12 {
+		return ""
+	}
+	return isin
+}
+
+// nextExpiry returns when a token issued at the given time expires.
+// Upstox tokens are valid until 3:30 AM IST on the following day.
+func nextExpiry(issuedAt time.Time) time.Time {
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	local := issuedAt.In(ist)
+	expiry := time.Date(local.Year(), local.Month(), local.Day(), 3, 30, 0, 0, ist)
+	if !expiry.After(local) {
+		expiry = expiry.AddDate(0, 0, 1)
+	}
+	return expiry
+}
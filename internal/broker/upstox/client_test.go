@@ -0,0 +1,279 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testAccessToken = "test-access-token"
+	holdingsJSON    = `{
+		"status": "success",
+		"data": [
+			{
+				"isin": "INE002A01018",
+				"company_name": "RELIANCE INDUSTRIES LTD",
+				"trading_symbol": "RELIANCE",
+				"exchange": "NSE",
+				"instrument_token": "NSE_EQ|INE002A01018",
+				"product": "D",
+				"quantity": 10,
+				"average_price": 2500.0,
+				"last_price": 2600.0,
+				"close_price": 2580.0,
+				"pnl": 1000.0,
+				"day_change": 20.0,
+				"day_change_percentage": 0.78
+			},
+			{
+				"isin": "INE467B01029",
+				"company_name": "TATA CONSULTANCY SERVICES LTD",
+				"trading_symbol": "TCS",
+				"exchange": "NSE",
+				"instrument_token": "NSE_EQ|INE467B01029",
+				"product": "D",
+				"quantity": 5,
+				"average_price": 3500.0,
+				"last_price": 3600.0,
+				"close_price": 3650.0,
+				"pnl": 500.0,
+				"day_change": -50.0,
+				"day_change_percentage": -1.37
+			}
+		]
+	}`
+	positionsJSON = `{
+		"status": "success",
+		"data": [
+			{
+				"trading_symbol": "INFY",
+				"exchange": "NSE",
+				"instrument_token": "NSE_EQ|INE009A01021",
+				"product": "I",
+				"quantity": 20,
+				"average_price": 1500.0,
+				"last_price": 1550.0,
+				"close_price": 1500.0,
+				"pnl": 1000.0,
+				"unrealised": 1000.0,
+				"realised": 0
+			},
+			{
+				"trading_symbol": "NIFTY25JANFUT",
+				"exchange": "NFO",
+				"instrument_token": "NSE_FO|35001",
+				"product": "D",
+				"quantity": 50,
+				"average_price": 23000.0,
+				"last_price": 23100.0,
+				"close_price": 23050.0,
+				"pnl": 5000.0,
+				"unrealised": 5000.0,
+				"realised": 0
+			}
+		]
+	}`
+	invalidTokenJSON = `{
+		"status": "error",
+		"errors": [
+			{
+				"errorCode": "UDAPI100050",
+				"message": "Invalid token used to access API"
+			}
+		]
+	}`
+)
+
+// newTestServer starts a local stand-in for the Upstox API
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get("Authorization") != "Bearer "+testAccessToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(invalidTokenJSON))
+			return false
+		}
+		return true
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(tokenPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.PostForm.Get("code") != "valid-code" ||
+			r.PostForm.Get("client_id") != "test-api-key" ||
+			r.PostForm.Get("client_secret") != "test-api-secret" ||
+			r.PostForm.Get("redirect_uri") != "https://example.com/callback" ||
+			r.PostForm.Get("grant_type") != "authorization_code" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","errors":[{"errorCode":"UDAPI100057","message":"Invalid Auth code"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"email":"user@example.com","user_id":"AB1234","access_token":"` + testAccessToken + `"}`))
+	})
+	mux.HandleFunc(holdingsPath, func(w http.ResponseWriter, r *http.Request) {
+		if authorized(w, r) {
+			_, _ = w.Write([]byte(holdingsJSON))
+		}
+	})
+	mux.HandleFunc(positionsPath, func(w http.ResponseWriter, r *http.Request) {
+		if authorized(w, r) {
+			_, _ = w.Write([]byte(positionsJSON))
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(t *testing.T, code string) *Client {
+	t.Helper()
+	client := NewClient("test-api-key", "test-api-secret", code, "https://example.com/callback")
+	client.SetBaseURL(newTestServer(t).URL)
+	return client
+}
+
+func TestNewClient(t *testing.T) {
+	client := NewClient("test-api-key", "test-api-secret", "test-code", "https://example.com/callback")
+	assert.NotNil(t, client)
+	assert.Equal(t, DefaultBaseURL, client.baseURL)
+	assert.Equal(t, "test-api-key", client.apiKey)
+	assert.Equal(t, "test-api-secret", client.apiSecret)
+	assert.Equal(t, "test-code", client.requestToken)
+	assert.Equal(t, "https://example.com/callback", client.redirectURI)
+	assert.False(t, client.CanAutoRefresh())
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		wantErr bool
+	}{
+		{
+			name:    "successful code exchange",
+			code:    "valid-code",
+			wantErr: false,
+		},
+		{
+			name:    "invalid authorization code",
+			code:    "expired-code",
+			wantErr: true,
+		},
+		{
+			name:    "missing authorization code",
+			code:    "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, tt.code)
+			err := client.Login()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Empty(t, client.GetAccessToken())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testAccessToken, client.GetAccessToken())
+				assert.True(t, client.expiresAt.After(time.Now()))
+			}
+		})
+	}
+}
+
+func TestGetHoldings(t *testing.T) {
+	client := newTestClient(t, "valid-code")
+	assert.NoError(t, client.Login())
+
+	holdings, err := client.GetHoldings(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, holdings, 2)
+
+	reliance := holdings[0]
+	assert.Equal(t, "RELIANCE", reliance.ItemName)
+	assert.Equal(t, "INE002A01018", reliance.ISIN)
+	assert.Equal(t, 10.0, reliance.Quantity)
+	assert.Equal(t, 2500.0, reliance.AveragePrice)
+	assert.Equal(t, 2600.0, reliance.LastTradedPrice)
+	assert.Equal(t, 26000.0, reliance.CurrentValue)
+	assert.Equal(t, 200.0, reliance.DayChange)
+	assert.Equal(t, 1000.0, reliance.TotalPnL)
+
+	for _, holding := range holdings {
+		assert.Equal(t, models.PlatformUpstox, holding.Platform)
+		assert.Equal(t, models.HoldingTypeStock, holding.Type)
+	}
+}
+
+func TestGetPositions(t *testing.T) {
+	client := newTestClient(t, "valid-code")
+	assert.NoError(t, client.Login())
+
+	positions, err := client.GetPositions(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, positions, 2)
+
+	assert.Equal(t, "INFY", positions[0].ItemName)
+	assert.Equal(t, "INE009A01021", positions[0].ISIN)
+	assert.Equal(t, 31000.0, positions[0].CurrentValue)
+	assert.Equal(t, 1000.0, positions[0].DayChange)
+
+	// Derivatives carry no ISIN in their instrument token
+	assert.Equal(t, "NIFTY25JANFUT", positions[1].ItemName)
+	assert.Empty(t, positions[1].ISIN)
+
+	for _, position := range positions {
+		assert.Equal(t, models.PlatformUpstox, position.Platform)
+	}
+}
+
+func TestInvalidToken(t *testing.T) {
+	client := newTestClient(t, "valid-code")
+	client.SetAccessToken("stale-token")
+
+	_, err := client.GetHoldings(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "UDAPI100050")
+
+	client.SetAccessToken("")
+	_, err = client.GetPositions(context.Background())
+	assert.Error(t, err)
+}
+
+func TestMockClient(t *testing.T) {
+	mock := NewMockClient().WithMockHoldings(GetDefaultMockHoldings())
+	holdings, err := mock.GetHoldings(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, holdings, 2)
+
+	mock = NewMockClient().WithPositionsError(errors.New("api error"))
+	_, err = mock.GetPositions(context.Background())
+	assert.EqualError(t, err, "api error")
+}
+
+func TestNextExpiry(t *testing.T) {
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+
+	evening := time.Date(2025, 1, 10, 18, 0, 0, 0, ist)
+	assert.Equal(t, time.Date(2025, 1, 11, 3, 30, 0, 0, ist), nextExpiry(evening))
+
+	earlyMorning := time.Date(2025, 1, 10, 2, 0, 0, 0, ist)
+	assert.Equal(t, time.Date(2025, 1, 10, 3, 30, 0, 0, ist), nextExpiry(earlyMorning))
+}
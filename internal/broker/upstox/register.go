@@ -0,0 +1,25 @@
+package upstox
+
+import (
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/broker/types"
+	"github.com/Kora1128/FinSight/internal/cache"
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+func init() {
+	types.Register(types.BrokerSpec{
+		Name:        models.PlatformUpstox,
+		DisplayName: "Upstox",
+		New: func(creds types.ClientCredentials) types.Client {
+			return NewClient(creds.APIKey, creds.APISecret, creds.RequestToken, creds.RedirectURI)
+		},
+		TokenCacheKey: cache.KeyUpstoxToken,
+		TokenTTL:      24 * time.Hour, // Upstox tokens are valid until 3:30 AM the next day
+		Capabilities: types.Capabilities{
+			Positions:   true,
+			AutoRefresh: false,
+		},
+	})
+}
@@ -6,12 +6,17 @@ import (
 	"time"
 
 	"github.com/Kora1128/FinSight/internal/broker/types"
+	"github.com/Kora1128/FinSight/internal/instruments"
 	"github.com/Kora1128/FinSight/internal/models"
 	kiteconnect "github.com/zerodha/gokiteconnect/v4"
 )
 
-// Ensure Client implements types.Client interface
-var _ types.Client = (*Client)(nil)
+// Ensure Client implements types.Client, types.TradesProvider and types.MutualFundsProvider interfaces
+var (
+	_ types.Client              = (*Client)(nil)
+	_ types.TradesProvider      = (*Client)(nil)
+	_ types.MutualFundsProvider = (*Client)(nil)
+)
 
 // Client represents the Zerodha broker integration client
 type Client struct {
@@ -98,8 +103,8 @@ func (c *Client) GetHoldings(ctx context.Context) ([]models.Holding, error) {
 
 	var normalizedHoldings []models.Holding
 	for _, h := range holdings {
-		holdingType := models.HoldingTypeStock
-		// TODO: Add logic to differentiate mutual funds if needed
+		// Equity holdings are exchange traded, so fund units among them are ETFs
+		holdingType := instruments.ClassifyHolding(h.ISIN, h.Tradingsymbol, true)
 		normalizedHolding := models.Holding{
 			ItemName:         h.Tradingsymbol,
 			ISIN:             h.ISIN,
@@ -119,6 +124,36 @@ func (c *Client) GetHoldings(ctx context.Context) ([]models.Holding, error) {
 	return normalizedHoldings, nil
 }
 
+// GetMFHoldings fetches the mutual fund holdings from Zerodha Coin and normalizes them into the common Holding struct
+func (c *Client) GetMFHoldings(ctx context.Context) ([]models.Holding, error) {
+	holdings, err := c.kc.GetMFHoldings()
+	if err != nil {
+		return nil, err
+	}
+
+	var normalizedHoldings []models.Holding
+	for _, h := range holdings {
+		normalizedHoldings = append(normalizedHoldings, normalizeMFHolding(h))
+	}
+	return normalizedHoldings, nil
+}
+
+// normalizeMFHolding converts a Coin holding; Kite reports the scheme ISIN as its trading symbol
+func normalizeMFHolding(h kiteconnect.MFHolding) models.Holding {
+	return models.Holding{
+		ItemName:        h.Fund,
+		ISIN:            h.Tradingsymbol,
+		Quantity:        h.Quantity,
+		AveragePrice:    h.AveragePrice,
+		LastTradedPrice: h.LastPrice,
+		CurrentValue:    h.Quantity * h.LastPrice,
+		TotalPnL:        h.Pnl,
+		Platform:        models.PlatformZerodha,
+		Type:            models.HoldingTypeMutualFund,
+		LastUpdated:     time.Now(),
+	}
+}
+
 // GetPositions fetches the current positions from Zerodha and normalizes them into the common Holding struct
 func (c *Client) GetPositions(ctx context.Context) ([]models.Holding, error) {
 	positions, err := c.kc.GetPositions()
@@ -128,7 +163,7 @@ func (c *Client) GetPositions(ctx context.Context) ([]models.Holding, error) {
 
 	var normalizedHoldings []models.Holding
 	for _, p := range positions.Net {
-		holdingType := models.HoldingTypeStock
+		holdingType := instruments.ClassifyHolding("", p.Tradingsymbol, true)
 		normalizedHolding := models.Holding{
 			ItemName:         p.Tradingsymbol,
 			ISIN:             "", // ISIN not available in Position struct
@@ -147,3 +182,42 @@ func (c *Client) GetPositions(ctx context.Context) ([]models.Holding, error) {
 	}
 	return normalizedHoldings, nil
 }
+
+// GetTrades fetches the day's delivery (CNC) equity trades from Zerodha.
+// Kite only reports trades of the current trading day and without ISINs; older history comes from Console tradebooks.
+func (c *Client) GetTrades(ctx context.Context) ([]models.Trade, error) {
+	trades, err := c.kc.GetTrades()
+	if err != nil {
+		return nil, err
+	}
+
+	var normalizedTrades []models.Trade
+	for _, t := range trades {
+		if t.Product != kiteconnect.ProductCNC || (t.Exchange != kiteconnect.ExchangeNSE && t.Exchange != kiteconnect.ExchangeBSE) {
+			continue
+		}
+
+		side := models.TradeSideBuy
+		if t.TransactionType == kiteconnect.TransactionTypeSell {
+			side = models.TradeSideSell
+		}
+
+		tradeDate := t.ExchangeTimestamp.Time
+		if tradeDate.IsZero() {
+			tradeDate = t.FillTimestamp.Time
+		}
+
+		normalizedTrades = append(normalizedTrades, models.Trade{
+			TradeID:   t.TradeID,
+			OrderID:   t.OrderID,
+			Symbol:    t.TradingSymbol,
+			Exchange:  t.Exchange,
+			Side:      side,
+			Quantity:  t.Quantity,
+			Price:     t.AveragePrice,
+			TradeDate: tradeDate,
+			Platform:  models.PlatformZerodha,
+		})
+	}
+	return normalizedTrades, nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/Kora1128/FinSight/internal/models"
 	"github.com/stretchr/testify/assert"
+	kiteconnect "github.com/zerodha/gokiteconnect/v4"
 )
 
 func TestNewClient(t *testing.T) {
@@ -97,6 +98,68 @@ func TestGetHoldings(t *testing.T) {
 	}
 }
 
+func TestGetMFHoldings(t *testing.T) {
+	tests := []struct {
+		name        string
+		mockClient  *MockClient
+		wantErr     bool
+		expectedErr error
+		expectedLen int
+	}{
+		{
+			name:        "successful mutual fund holdings fetch",
+			mockClient:  NewMockClient().WithMockMFHoldings(GetDefaultMockMFHoldings()),
+			wantErr:     false,
+			expectedErr: nil,
+			expectedLen: 1,
+		},
+		{
+			name:        "failed mutual fund holdings fetch",
+			mockClient:  NewMockClient().WithMFError(errors.New("api error")),
+			wantErr:     true,
+			expectedErr: errors.New("api error"),
+			expectedLen: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			holdings, err := tt.mockClient.GetMFHoldings(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, holdings, tt.expectedLen)
+				for _, holding := range holdings {
+					assert.Equal(t, models.PlatformZerodha, holding.Platform)
+					assert.Equal(t, models.HoldingTypeMutualFund, holding.Type)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeMFHolding(t *testing.T) {
+	holding := normalizeMFHolding(kiteconnect.MFHolding{
+		Folio:         "12345678/90",
+		Fund:          "HDFC Large Cap Fund - Direct Plan",
+		Tradingsymbol: "INF179K01YV8",
+		AveragePrice:  1000,
+		LastPrice:     1200,
+		Pnl:           2000,
+		Quantity:      10,
+	})
+
+	assert.Equal(t, "HDFC Large Cap Fund - Direct Plan", holding.ItemName)
+	assert.Equal(t, "INF179K01YV8", holding.ISIN)
+	assert.Equal(t, 10.0, holding.Quantity)
+	assert.Equal(t, 12000.0, holding.CurrentValue)
+	assert.Equal(t, 2000.0, holding.TotalPnL)
+	assert.Equal(t, models.PlatformZerodha, holding.Platform)
+	assert.Equal(t, models.HoldingTypeMutualFund, holding.Type)
+}
+
 func TestGetPositions(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -15,6 +15,10 @@ type MockClient struct {
 	HoldingsError  error
 	Positions      []models.Holding
 	PositionsError error
+	MFHoldings     []models.Holding
+	MFError        error
+	Trades         []models.Trade
+	TradesError    error
 }
 
 // NewMockClient creates a new mock Zerodha client
@@ -43,6 +47,22 @@ func (m *MockClient) GetPositions(ctx context.Context) ([]models.Holding, error)
 	return m.Positions, nil
 }
 
+// GetTrades returns mock trades data
+func (m *MockClient) GetTrades(ctx context.Context) ([]models.Trade, error) {
+	if m.TradesError != nil {
+		return nil, m.TradesError
+	}
+	return m.Trades, nil
+}
+
+// GetMFHoldings returns mock mutual fund holdings data
+func (m *MockClient) GetMFHoldings(ctx context.Context) ([]models.Holding, error) {
+	if m.MFError != nil {
+		return nil, m.MFError
+	}
+	return m.MFHoldings, nil
+}
+
 // CanAutoRefresh implements the CanAutoRefresh method
 func (m *MockClient) CanAutoRefresh() bool {
 	return true
@@ -75,6 +95,18 @@ func (m *MockClient) WithMockPositions(positions []models.Holding) *MockClient {
 	return m
 }
 
+// WithMockTrades sets mock trades data
+func (m *MockClient) WithMockTrades(trades []models.Trade) *MockClient {
+	m.Trades = trades
+	return m
+}
+
+// WithMockMFHoldings sets mock mutual fund holdings data
+func (m *MockClient) WithMockMFHoldings(holdings []models.Holding) *MockClient {
+	m.MFHoldings = holdings
+	return m
+}
+
 // WithLoginError sets a mock login error
 func (m *MockClient) WithLoginError(err error) *MockClient {
 	m.LoginError = err
@@ -93,6 +125,18 @@ func (m *MockClient) WithPositionsError(err error) *MockClient {
 	return m
 }
 
+// WithMFError sets a mock mutual fund holdings error
+func (m *MockClient) WithMFError(err error) *MockClient {
+	m.MFError = err
+	return m
+}
+
+// WithTradesError sets a mock trades error
+func (m *MockClient) WithTradesError(err error) *MockClient {
+	m.TradesError = err
+	return m
+}
+
 // GetDefaultMockHoldings returns a set of default mock holdings for testing
 func GetDefaultMockHoldings() []models.Holding {
 	return []models.Holding{
@@ -127,6 +171,24 @@ func GetDefaultMockHoldings() []models.Holding {
 	}
 }
 
+// GetDefaultMockMFHoldings returns a set of default mock mutual fund holdings for testing
+func GetDefaultMockMFHoldings() []models.Holding {
+	return []models.Holding{
+		{
+			ItemName:        "HDFC Large Cap Fund - Direct Plan",
+			ISIN:            "INF179K01YV8",
+			Quantity:        12.345,
+			AveragePrice:    1000.0,
+			LastTradedPrice: 1198.56,
+			CurrentValue:    14796.22,
+			TotalPnL:        2451.22,
+			Platform:        models.PlatformZerodha,
+			Type:            models.HoldingTypeMutualFund,
+			LastUpdated:     time.Now(),
+		},
+	}
+}
+
 // GetDefaultMockPositions returns a set of default mock positions for testing
 func GetDefaultMockPositions() []models.Holding {
 	return []models.Holding{
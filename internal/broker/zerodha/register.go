@@ -0,0 +1,27 @@
+package zerodha
+
+import (
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/broker/types"
+	"github.com/Kora1128/FinSight/internal/cache"
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+func init() {
+	types.Register(types.BrokerSpec{
+		Name:        models.PlatformZerodha,
+		DisplayName: "Zerodha",
+		New: func(creds types.ClientCredentials) types.Client {
+			return NewClient(creds.APIKey, creds.APISecret, creds.RequestToken)
+		},
+		TokenCacheKey: cache.KeyZerodhaToken,
+		TokenTTL:      24 * time.Hour, // Kite access tokens expire at the end of the trading day
+		Capabilities: types.Capabilities{
+			Positions:   true,
+			AutoRefresh: true,
+			Trades:      true,
+			MutualFunds: true,
+		},
+	})
+}
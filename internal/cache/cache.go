@@ -26,6 +26,8 @@ const (
 	KeyRecommendations = "recommendations"
 	KeyZerodhaToken    = "zerodha_token"
 	KeyICICIToken      = "icici_direct_token"
+	KeyUpstoxToken     = "upstox_token"
+	KeyAngelOneToken   = "angelone_token"
 )
 
 // ClearAll clears all cached data
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 	
@@ -13,14 +14,26 @@ import (
 // Config holds all configuration for the application
 type Config struct {
 	// Server configuration
-	Port         string
-	Environment  string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
+	Port          string
+	Environment   string
+	ReadTimeout   time.Duration
+	WriteTimeout  time.Duration
+	AdminAPIToken string // Token required by the admin endpoints; they are disabled when empty
 
 	// OpenAI configuration
 	OpenAIAPIKey string
 
+	// LLM configuration
+	LLMProvider          string        // LLM provider: openai, openai-compatible or fake
+	LLMBaseURL           string        // Endpoint of an OpenAI-compatible server such as Ollama
+	LLMAPIKey            string        // API key of the LLM provider (default: OPENAI_API_KEY)
+	LLMModel             string        // Model name (default: the provider's default)
+	LLMTimeout           time.Duration // Timeout of a single completion
+	LLMMaxTokens         int           // Tokens generated by a single completion at most
+	LLMRequestsPerMinute int           // Requests per minute at most (0 for unlimited)
+	LLMTokensPerMinute   int           // Tokens per minute at most (0 for unlimited)
+	LLMPromptsPath       string        // JSON file overriding the prompt templates
+
 	// Cache configuration
 	CacheTTL time.Duration
 
@@ -32,21 +45,61 @@ type Config struct {
 	SupabaseClient     *supabase.Client // Supabase client for easy API access
 
 	// News configuration
-	TrustedSources []string
+	TrustedSources      []string
+	NewsFetchWorkers    int           // Number of feeds fetched at the same time
+	NewsFetchTimeout    time.Duration // Timeout of a single feed request
+	StockResolvers      []string      // Stock resolvers tried in order: dictionary, llm (default: dictionary)
+	StockDictionaryPath string        // CSV of company names, aliases and tickers for the dictionary resolver
+	SentimentAnalyzer   string        // Sentiment analyzer: keyword, lexicon or llm (default: keyword)
+	SentimentLexiconDir string        // Directory of the lexicon analyzer's word lists
+
+	// Allocation configuration
+	SectorMasterPath string // CSV classifying instruments by asset class, market cap, sector and industry
+
+	// Instrument master configuration
+	NSEEquityListPath         string        // NSE list of securities (EQUITY_L.csv)
+	BSEEquityListPath         string        // BSE list of scrips
+	KiteInstrumentsPath       string        // Kite Connect instruments dump
+	InstrumentsReloadInterval time.Duration // How often the instrument files are re-read
+
+	// Mutual fund configuration
+	NAVAllSource         string        // AMFI NAVAll.txt file path or URL
+	NAVAllIngestInterval time.Duration // How often NAVs are ingested
+
+	// Market data configuration
+	BhavcopyDir          string   // Directory of NSE/BSE bhavcopy CSV or zip files
+	BhavcopyURLTemplates []string // Bhavcopy download URLs with the trading day as a Go time layout in braces
+
+	// Live price configuration
+	KiteTickerAPIKey      string // Kite Connect API key of a fixed ticker session
+	KiteTickerAccessToken string // Kite Connect access token of a fixed ticker session, which expires daily
+	KiteTickerUserID      string // User whose Zerodha session the ticker connects with when no fixed session is set
 }
 
 // New creates a new Config instance with values from environment variables
 func New() *Config {
 	cfg := &Config{
 		// Server configuration
-		Port:         getEnv("APP_PORT", "8080"),
-		Environment:  getEnv("APP_ENV", "development"),
-		ReadTimeout:  getDurationEnv("APP_READ_TIMEOUT", 10*time.Second),
-		WriteTimeout: getDurationEnv("APP_WRITE_TIMEOUT", 10*time.Second),
+		Port:          getEnv("APP_PORT", "8080"),
+		Environment:   getEnv("APP_ENV", "development"),
+		ReadTimeout:   getDurationEnv("APP_READ_TIMEOUT", 10*time.Second),
+		WriteTimeout:  getDurationEnv("APP_WRITE_TIMEOUT", 10*time.Second),
+		AdminAPIToken: getEnv("ADMIN_API_TOKEN", ""),
 
 		// OpenAI configuration
 		OpenAIAPIKey: getEnv("OPENAI_API_KEY", ""),
 
+		// LLM configuration
+		LLMProvider:          getEnv("LLM_PROVIDER", "openai"),
+		LLMBaseURL:           getEnv("LLM_BASE_URL", ""),
+		LLMAPIKey:            getEnv("LLM_API_KEY", getEnv("OPENAI_API_KEY", "")),
+		LLMModel:             getEnv("LLM_MODEL", ""),
+		LLMTimeout:           getDurationEnv("LLM_TIMEOUT", 30*time.Second),
+		LLMMaxTokens:         getIntEnv("LLM_MAX_TOKENS", 512),
+		LLMRequestsPerMinute: getIntEnv("LLM_REQUESTS_PER_MINUTE", 0),
+		LLMTokensPerMinute:   getIntEnv("LLM_TOKENS_PER_MINUTE", 0),
+		LLMPromptsPath:       getEnv("LLM_PROMPTS_PATH", "data/llm_prompts.json"),
+
 		// Cache configuration
 		CacheTTL: getDurationEnv("CACHE_TTL", 15*time.Minute),
 
@@ -56,7 +109,35 @@ func New() *Config {
 		SupabasePassword: getEnv("SUPABASE_PASSWORD", ""),
 
 		// News configuration
-		TrustedSources: getTrustedSources(),
+		TrustedSources:      getTrustedSources(),
+		NewsFetchWorkers:    getIntEnv("NEWS_FETCH_WORKERS", 4),
+		NewsFetchTimeout:    getDurationEnv("NEWS_FETCH_TIMEOUT", 20*time.Second),
+		StockResolvers:      getListEnv("STOCK_RESOLVERS"),
+		StockDictionaryPath: getEnv("STOCK_DICTIONARY_PATH", "data/stock_dictionary.csv"),
+		SentimentAnalyzer:   getEnv("SENTIMENT_ANALYZER", "keyword"),
+		SentimentLexiconDir: getEnv("SENTIMENT_LEXICON_DIR", "data/lexicon"),
+
+		// Allocation configuration
+		SectorMasterPath: getEnv("SECTOR_MASTER_PATH", "data/sector_master.csv"),
+
+		// Instrument master configuration
+		NSEEquityListPath:         getEnv("NSE_EQUITY_LIST_PATH", "data/instruments/EQUITY_L.csv"),
+		BSEEquityListPath:         getEnv("BSE_EQUITY_LIST_PATH", "data/instruments/bse_equity.csv"),
+		KiteInstrumentsPath:       getEnv("KITE_INSTRUMENTS_PATH", "data/instruments/kite_instruments.csv"),
+		InstrumentsReloadInterval: getDurationEnv("INSTRUMENTS_RELOAD_INTERVAL", 24*time.Hour),
+
+		// Mutual fund configuration
+		NAVAllSource:         getEnv("AMFI_NAVALL_SOURCE", "https://portal.amfiindia.com/spages/NAVAll.txt"),
+		NAVAllIngestInterval: getDurationEnv("AMFI_NAVALL_INTERVAL", 24*time.Hour),
+
+		// Market data configuration
+		BhavcopyDir:          getEnv("BHAVCOPY_DIR", "data/bhavcopy"),
+		BhavcopyURLTemplates: getListEnv("BHAVCOPY_URL_TEMPLATES"),
+
+		// Live price configuration
+		KiteTickerAPIKey:      getEnv("KITE_TICKER_API_KEY", ""),
+		KiteTickerAccessToken: getEnv("KITE_TICKER_ACCESS_TOKEN", ""),
+		KiteTickerUserID:      getEnv("KITE_TICKER_USER_ID", ""),
 	}
 
 	// Initialize Supabase client if URL and API key are provided
@@ -116,6 +197,27 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+// getIntEnv gets an integer from an environment variable or returns a default value
+func getIntEnv(key string, defaultValue int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+// getListEnv gets a comma-separated list from an environment variable, skipping empty entries
+func getListEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // getTrustedSources returns the list of trusted sources from environment variables
 func getTrustedSources() []string {
 	// Default trusted sources
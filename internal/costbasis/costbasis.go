@@ -0,0 +1,320 @@
+// Package costbasis reconstructs open lots and realized disposals from trade history
+// using first-in, first-out matching, adjusting lots for splits and bonus issues.
+package costbasis
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// epsilon absorbs floating point residue when lots are consumed
+const epsilon = 1e-9
+
+// Lot represents shares bought in a single trade that are still held
+type Lot struct {
+	TradeID    string    `json:"tradeId"`
+	AcquiredAt time.Time `json:"acquiredAt"`
+	Quantity   float64   `json:"quantity"`
+
+	// UnitCost is the cost per share including buy charges
+	UnitCost float64 `json:"unitCost"`
+}
+
+// Cost returns the total cost of the lot
+func (l Lot) Cost() float64 {
+	return l.Quantity * l.UnitCost
+}
+
+// UnrealizedPnL returns the gain or loss of the lot at the given market price
+func (l Lot) UnrealizedPnL(price float64) float64 {
+	return l.Quantity*price - l.Cost()
+}
+
+// Disposal represents the part of a sale matched against a single lot
+type Disposal struct {
+	TradeID    string    `json:"tradeId"`
+	LotTradeID string    `json:"lotTradeId"`
+	AcquiredAt time.Time `json:"acquiredAt"`
+	SoldAt     time.Time `json:"soldAt"`
+	Quantity   float64   `json:"quantity"`
+
+	// UnitCost is the cost per share of the matched lot including buy charges
+	UnitCost float64 `json:"unitCost"`
+
+	// UnitSalePrice is the sale price per share before charges
+	UnitSalePrice float64 `json:"unitSalePrice"`
+
+	// UnitProceeds is the sale price per share net of sell charges
+	UnitProceeds float64 `json:"unitProceeds"`
+
+	// SaleCharges is the share of the sell trade's charges attributed to this disposal
+	SaleCharges float64 `json:"saleCharges"`
+}
+
+// SaleValue returns the sale value of the shares sold before charges
+func (d Disposal) SaleValue() float64 {
+	return d.Quantity * d.UnitSalePrice
+}
+
+// Cost returns the cost of the shares sold
+func (d Disposal) Cost() float64 {
+	return d.Quantity * d.UnitCost
+}
+
+// Proceeds returns the net sale value of the shares sold
+func (d Disposal) Proceeds() float64 {
+	return d.Quantity * d.UnitProceeds
+}
+
+// Gain returns the realized gain or loss
+func (d Disposal) Gain() float64 {
+	return d.Proceeds() - d.Cost()
+}
+
+// Position holds the open lots and realized disposals of one security at one platform
+type Position struct {
+	Platform  string     `json:"platform"`
+	ISIN      string     `json:"isin"`
+	Symbol    string     `json:"symbol"`
+	Lots      []Lot      `json:"lots"`
+	Disposals []Disposal `json:"disposals"`
+
+	// Unmatched is the quantity sold without a matching buy, which means the trade history is incomplete
+	Unmatched float64 `json:"unmatched"`
+}
+
+// Quantity returns the number of shares held across open lots
+func (p *Position) Quantity() float64 {
+	total := 0.0
+	for _, lot := range p.Lots {
+		total += lot.Quantity
+	}
+	return total
+}
+
+// CostBasis returns the total cost of the open lots
+func (p *Position) CostBasis() float64 {
+	total := 0.0
+	for _, lot := range p.Lots {
+		total += lot.Cost()
+	}
+	return total
+}
+
+// AverageCost returns the weighted average cost per share of the open lots
+func (p *Position) AverageCost() float64 {
+	quantity := p.Quantity()
+	if quantity <= epsilon {
+		return 0
+	}
+	return p.CostBasis() / quantity
+}
+
+// UnrealizedPnL returns the gain or loss of the open lots at the given market price
+func (p *Position) UnrealizedPnL(price float64) float64 {
+	return p.Quantity()*price - p.CostBasis()
+}
+
+// RealizedPnL returns the total gain or loss of all disposals
+func (p *Position) RealizedPnL() float64 {
+	total := 0.0
+	for _, disposal := range p.Disposals {
+		total += disposal.Gain()
+	}
+	return total
+}
+
+// Complete reports whether every sale was matched against a known buy
+func (p *Position) Complete() bool {
+	return p.Unmatched <= epsilon
+}
+
+// buy opens a new lot
+func (p *Position) buy(trade models.Trade) {
+	p.Lots = append(p.Lots, Lot{
+		TradeID:    trade.TradeID,
+		AcquiredAt: trade.TradeDate,
+		Quantity:   trade.Quantity,
+		UnitCost:   (trade.Quantity*trade.Price + trade.Charges) / trade.Quantity,
+	})
+}
+
+// sell consumes open lots oldest first
+func (p *Position) sell(trade models.Trade) {
+	unitCharges := trade.Charges / trade.Quantity
+	unitProceeds := trade.Price - unitCharges
+	remaining := trade.Quantity
+
+	for remaining > epsilon && len(p.Lots) > 0 {
+		lot := &p.Lots[0]
+		matched := lot.Quantity
+		if remaining < matched {
+			matched = remaining
+		}
+
+		p.Disposals = append(p.Disposals, Disposal{
+			TradeID:       trade.TradeID,
+			LotTradeID:    lot.TradeID,
+			AcquiredAt:    lot.AcquiredAt,
+			SoldAt:        trade.TradeDate,
+			Quantity:      matched,
+			UnitCost:      lot.UnitCost,
+			UnitSalePrice: trade.Price,
+			UnitProceeds:  unitProceeds,
+			SaleCharges:   matched * unitCharges,
+		})
+
+		lot.Quantity -= matched
+		remaining -= matched
+		if lot.Quantity <= epsilon {
+			p.Lots = p.Lots[1:]
+		}
+	}
+
+	if remaining > epsilon {
+		p.Unmatched += remaining
+	}
+}
+
+// apply adjusts open lots for a corporate action
+func (p *Position) apply(action models.CorporateAction) {
+	ratio := action.Ratio()
+	if ratio <= 0 || len(p.Lots) == 0 {
+		return
+	}
+
+	switch action.Type {
+	case models.CorporateActionSplit:
+		// Each lot keeps its acquisition date and total cost over more shares
+		for i := range p.Lots {
+			p.Lots[i].Quantity *= ratio
+			p.Lots[i].UnitCost /= ratio
+		}
+	case models.CorporateActionBonus:
+		// Bonus shares are acquired on the ex-date at zero cost
+		bonus := p.Quantity() * ratio
+		p.Lots = append(p.Lots, Lot{
+			TradeID:    "bonus-" + action.ExDate.Format("2006-01-02"),
+			AcquiredAt: action.ExDate,
+			Quantity:   bonus,
+		})
+	}
+}
+
+// Book holds the positions reconstructed from a trade history
+type Book struct {
+	positions map[string]*Position
+}
+
+// Build replays trades in date order, applying each corporate action before the trades on or after its ex-date.
+// Callers should only pass actions that have already taken effect.
+func Build(trades []models.Trade, actions []models.CorporateAction) *Book {
+	book := &Book{positions: make(map[string]*Position)}
+
+	trades = append([]models.Trade(nil), trades...)
+	sort.SliceStable(trades, func(i, j int) bool {
+		if !trades[i].TradeDate.Equal(trades[j].TradeDate) {
+			return trades[i].TradeDate.Before(trades[j].TradeDate)
+		}
+		// Trades recorded without a time are ordered buys first
+		return trades[i].Side == models.TradeSideBuy && trades[j].Side != models.TradeSideBuy
+	})
+
+	actions = append([]models.CorporateAction(nil), actions...)
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].ExDate.Before(actions[j].ExDate)
+	})
+
+	next := 0
+	for _, trade := range trades {
+		for next < len(actions) && !actions[next].ExDate.After(trade.TradeDate) {
+			book.apply(actions[next])
+			next++
+		}
+
+		if trade.Quantity <= 0 {
+			continue
+		}
+
+		position := book.position(trade)
+		switch trade.Side {
+		case models.TradeSideBuy:
+			position.buy(trade)
+		case models.TradeSideSell:
+			position.sell(trade)
+		}
+	}
+
+	for ; next < len(actions); next++ {
+		book.apply(actions[next])
+	}
+
+	return book
+}
+
+// Position returns the position for a security at a platform, looked up by ISIN or, failing that, by symbol
+func (b *Book) Position(platform, isin, symbol string) (*Position, bool) {
+	if isin != "" {
+		if position, ok := b.positions[positionKey(platform, isin, "")]; ok {
+			return position, true
+		}
+	}
+	if symbol != "" {
+		if position, ok := b.positions[positionKey(platform, "", symbol)]; ok {
+			return position, true
+		}
+	}
+	return nil, false
+}
+
+// Positions returns all positions sorted by platform and security
+func (b *Book) Positions() []*Position {
+	keys := make([]string, 0, len(b.positions))
+	for key := range b.positions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	positions := make([]*Position, 0, len(keys))
+	for _, key := range keys {
+		positions = append(positions, b.positions[key])
+	}
+	return positions
+}
+
+// position returns the position a trade belongs to, creating it if needed
+func (b *Book) position(trade models.Trade) *Position {
+	key := positionKey(trade.Platform, trade.ISIN, trade.Symbol)
+	position, ok := b.positions[key]
+	if !ok {
+		position = &Position{
+			Platform: trade.Platform,
+			ISIN:     trade.ISIN,
+		}
+		b.positions[key] = position
+	}
+	if trade.Symbol != "" {
+		position.Symbol = trade.Symbol
+	}
+	return position
+}
+
+// apply adjusts every position in the action's security
+func (b *Book) apply(action models.CorporateAction) {
+	for _, position := range b.positions {
+		if position.ISIN != "" && position.ISIN == action.ISIN {
+			position.apply(action)
+		}
+	}
+}
+
+// positionKey identifies a security at a platform by ISIN, or by symbol when the ISIN is unknown
+func positionKey(platform, isin, symbol string) string {
+	if isin != "" {
+		return platform + "|" + isin
+	}
+	return platform + "|symbol:" + strings.ToUpper(symbol)
+}
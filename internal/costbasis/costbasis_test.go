@@ -0,0 +1,231 @@
+package costbasis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const testISIN = "INE002A01018"
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func trade(id string, side models.TradeSide, date time.Time, quantity, price, charges float64) models.Trade {
+	return models.Trade{
+		TradeID:   id,
+		ISIN:      testISIN,
+		Symbol:    "RELIANCE",
+		Side:      side,
+		Quantity:  quantity,
+		Price:     price,
+		Charges:   charges,
+		TradeDate: date,
+		Platform:  models.PlatformZerodha,
+	}
+}
+
+func TestBuild(t *testing.T) {
+	buy, sell := models.TradeSideBuy, models.TradeSideSell
+
+	tests := []struct {
+		name          string
+		trades        []models.Trade
+		actions       []models.CorporateAction
+		price         float64
+		wantLots      []Lot
+		wantAvgCost   float64
+		wantRealized  float64
+		wantUnreal    float64
+		wantDisposals int
+		wantComplete  bool
+	}{
+		{
+			name: "fifo across two lots with charges",
+			trades: []models.Trade{
+				// Unit cost (1000 + 10) / 10 = 101
+				trade("b1", buy, day(2024, 1, 10), 10, 100, 10),
+				trade("b2", buy, day(2024, 2, 10), 10, 120, 0),
+				// Unit proceeds (1950 - 15) / 15 = 129; gains 10*(129-101) + 5*(129-120) = 280 + 45
+				trade("s1", sell, day(2024, 3, 10), 15, 130, 15),
+			},
+			price: 150,
+			wantLots: []Lot{
+				{TradeID: "b2", AcquiredAt: day(2024, 2, 10), Quantity: 5, UnitCost: 120},
+			},
+			wantAvgCost:   120,
+			wantRealized:  325,
+			wantUnreal:    150,
+			wantDisposals: 2,
+			wantComplete:  true,
+		},
+		{
+			name: "split adjusts earlier lots only",
+			trades: []models.Trade{
+				trade("b1", buy, day(2024, 1, 10), 10, 1000, 0),
+				// Bought on the ex-date at the post-split price
+				trade("b2", buy, day(2024, 6, 1), 10, 210, 0),
+				// 50 from b1 at 200 and 5 from b2 at 210: 50*50 + 5*40
+				trade("s1", sell, day(2024, 7, 1), 55, 250, 0),
+			},
+			actions: []models.CorporateAction{
+				{ISIN: testISIN, Type: models.CorporateActionSplit, ExDate: day(2024, 6, 1), Numerator: 5, Denominator: 1},
+			},
+			price: 260,
+			wantLots: []Lot{
+				{TradeID: "b2", AcquiredAt: day(2024, 6, 1), Quantity: 5, UnitCost: 210},
+			},
+			wantAvgCost:   210,
+			wantRealized:  2700,
+			wantUnreal:    250,
+			wantDisposals: 2,
+			wantComplete:  true,
+		},
+		{
+			name: "bonus issues zero cost lot on ex-date",
+			trades: []models.Trade{
+				trade("b1", buy, day(2023, 5, 1), 10, 500, 0),
+				// 10 from b1 at 500 and 5 bonus shares at zero: 10*(-200) + 5*300
+				trade("s1", sell, day(2024, 5, 1), 15, 300, 0),
+			},
+			actions: []models.CorporateAction{
+				{ISIN: testISIN, Type: models.CorporateActionBonus, ExDate: day(2023, 9, 1), Numerator: 1, Denominator: 1},
+			},
+			price: 320,
+			wantLots: []Lot{
+				{TradeID: "bonus-2023-09-01", AcquiredAt: day(2023, 9, 1), Quantity: 5, UnitCost: 0},
+			},
+			wantAvgCost:   0,
+			wantRealized:  -500,
+			wantUnreal:    1600,
+			wantDisposals: 2,
+			wantComplete:  true,
+		},
+		{
+			name: "bonus after last trade still applies",
+			trades: []models.Trade{
+				trade("b1", buy, day(2023, 5, 1), 10, 300, 0),
+			},
+			actions: []models.CorporateAction{
+				// 1:2 bonus issues 5 shares on 10 held; average cost falls to 3000 / 15
+				{ISIN: testISIN, Type: models.CorporateActionBonus, ExDate: day(2023, 9, 1), Numerator: 1, Denominator: 2},
+				{ISIN: "INE009A01021", Type: models.CorporateActionSplit, ExDate: day(2023, 9, 1), Numerator: 2, Denominator: 1},
+			},
+			price: 250,
+			wantLots: []Lot{
+				{TradeID: "b1", AcquiredAt: day(2023, 5, 1), Quantity: 10, UnitCost: 300},
+				{TradeID: "bonus-2023-09-01", AcquiredAt: day(2023, 9, 1), Quantity: 5, UnitCost: 0},
+			},
+			wantAvgCost:  200,
+			wantUnreal:   750,
+			wantComplete: true,
+		},
+		{
+			name: "same day sell listed before buy is matched",
+			trades: []models.Trade{
+				trade("s1", sell, day(2024, 4, 2), 4, 110, 0),
+				trade("b1", buy, day(2024, 4, 2), 10, 100, 0),
+			},
+			price: 100,
+			wantLots: []Lot{
+				{TradeID: "b1", AcquiredAt: day(2024, 4, 2), Quantity: 6, UnitCost: 100},
+			},
+			wantAvgCost:   100,
+			wantRealized:  40,
+			wantUnreal:    0,
+			wantDisposals: 1,
+			wantComplete:  true,
+		},
+		{
+			name: "sale without enough history is incomplete",
+			trades: []models.Trade{
+				trade("b1", buy, day(2024, 1, 1), 5, 100, 0),
+				trade("s1", sell, day(2024, 2, 1), 8, 120, 0),
+			},
+			price:         120,
+			wantLots:      nil,
+			wantRealized:  100,
+			wantDisposals: 1,
+			wantComplete:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := Build(tt.trades, tt.actions)
+			position, ok := book.Position(models.PlatformZerodha, testISIN, "")
+			assert.True(t, ok)
+
+			assert.Equal(t, len(tt.wantLots), len(position.Lots))
+			for i, want := range tt.wantLots {
+				if i >= len(position.Lots) {
+					break
+				}
+				got := position.Lots[i]
+				assert.Equal(t, want.TradeID, got.TradeID)
+				assert.Equal(t, want.AcquiredAt, got.AcquiredAt)
+				assert.InDelta(t, want.Quantity, got.Quantity, 1e-9)
+				assert.InDelta(t, want.UnitCost, got.UnitCost, 1e-9)
+			}
+
+			assert.InDelta(t, tt.wantAvgCost, position.AverageCost(), 1e-9)
+			assert.InDelta(t, tt.wantRealized, position.RealizedPnL(), 1e-9)
+			assert.InDelta(t, tt.wantUnreal, position.UnrealizedPnL(tt.price), 1e-9)
+			assert.Len(t, position.Disposals, tt.wantDisposals)
+			assert.Equal(t, tt.wantComplete, position.Complete())
+		})
+	}
+}
+
+func TestDisposalDetails(t *testing.T) {
+	book := Build([]models.Trade{
+		trade("b1", models.TradeSideBuy, day(2022, 1, 3), 10, 100, 0),
+		trade("s1", models.TradeSideSell, day(2024, 1, 3), 4, 150, 6),
+	}, nil)
+
+	position, ok := book.Position(models.PlatformZerodha, testISIN, "")
+	assert.True(t, ok)
+	assert.Len(t, position.Disposals, 1)
+
+	disposal := position.Disposals[0]
+	assert.Equal(t, "s1", disposal.TradeID)
+	assert.Equal(t, "b1", disposal.LotTradeID)
+	assert.Equal(t, day(2022, 1, 3), disposal.AcquiredAt)
+	assert.Equal(t, day(2024, 1, 3), disposal.SoldAt)
+	assert.Equal(t, 600.0, disposal.SaleValue())
+	assert.Equal(t, 6.0, disposal.SaleCharges)
+	assert.Equal(t, 400.0, disposal.Cost())
+	assert.Equal(t, 594.0, disposal.Proceeds())
+	assert.Equal(t, 194.0, disposal.Gain())
+
+	// The remaining lot's per-lot P&L
+	assert.Equal(t, 6.0, position.Lots[0].Quantity)
+	assert.Equal(t, 300.0, position.Lots[0].UnrealizedPnL(150))
+}
+
+func TestPositionsArePerPlatform(t *testing.T) {
+	zerodhaBuy := trade("z1", models.TradeSideBuy, day(2024, 1, 1), 10, 100, 0)
+	iciciBuy := trade("i1", models.TradeSideBuy, day(2024, 1, 1), 5, 200, 0)
+	iciciBuy.Platform = models.PlatformICICIDirect
+	iciciBuy.ISIN = ""
+	iciciBuy.Symbol = "relind"
+
+	book := Build([]models.Trade{zerodhaBuy, iciciBuy}, nil)
+	assert.Len(t, book.Positions(), 2)
+
+	zerodha, ok := book.Position(models.PlatformZerodha, testISIN, "RELIANCE")
+	assert.True(t, ok)
+	assert.Equal(t, 10.0, zerodha.Quantity())
+
+	// Trades without an ISIN are found by symbol
+	icici, ok := book.Position(models.PlatformICICIDirect, testISIN, "RELIND")
+	assert.True(t, ok)
+	assert.Equal(t, 5.0, icici.Quantity())
+	assert.Equal(t, 200.0, icici.AverageCost())
+
+	_, ok = book.Position(models.PlatformUpstox, testISIN, "RELIANCE")
+	assert.False(t, ok)
+}
@@ -113,6 +113,33 @@ func (r *BrokerCredentialsRepo) HasCredentials(userID string, brokerType string)
 	return count > 0, nil
 }
 
+// ListBrokerTypes retrieves the broker types the user has stored credentials for
+func (r *BrokerCredentialsRepo) ListBrokerTypes(userID string) ([]string, error) {
+	rows, err := r.db.Query(
+		"SELECT broker_type FROM broker_credentials WHERE user_id = $1 ORDER BY broker_type",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	brokerTypes := []string{}
+	for rows.Next() {
+		var brokerType string
+		if err := rows.Scan(&brokerType); err != nil {
+			return nil, err
+		}
+		brokerTypes = append(brokerTypes, brokerType)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return brokerTypes, nil
+}
+
 // GetAccessToken retrieves the access token for a user's broker credentials
 func (r *BrokerCredentialsRepo) GetAccessToken(userID string, brokerType string) (string, error) {
 	var accessToken string
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/lib/pq"
+)
+
+// CorporateActionRepo handles split and bonus records in the database
+type CorporateActionRepo struct {
+	db *DB
+}
+
+// NewCorporateActionRepo creates a new corporate action repository
+func NewCorporateActionRepo(db *DB) *CorporateActionRepo {
+	return &CorporateActionRepo{db: db}
+}
+
+// SaveCorporateAction stores a corporate action, replacing the ratio of an existing record for the same security, type and ex-date
+func (r *CorporateActionRepo) SaveCorporateAction(action models.CorporateAction) error {
+	_, err := r.db.Exec(
+		`INSERT INTO corporate_actions (isin, action_type, ex_date, numerator, denominator) 
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (isin, action_type, ex_date) 
+		DO UPDATE SET numerator = $4, denominator = $5`,
+		action.ISIN,
+		action.Type,
+		action.ExDate,
+		action.Numerator,
+		action.Denominator,
+	)
+	return err
+}
+
+// GetCorporateActions retrieves the actions for the given securities that took effect on or before the given date
+func (r *CorporateActionRepo) GetCorporateActions(isins []string, asOf time.Time) ([]models.CorporateAction, error) {
+	rows, err := r.db.Query(
+		`SELECT isin, action_type, ex_date, numerator, denominator 
+		FROM corporate_actions WHERE isin = ANY($1) AND ex_date <= $2 ORDER BY ex_date`,
+		pq.Array(isins), asOf,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []models.CorporateAction
+	for rows.Next() {
+		var action models.CorporateAction
+		err := rows.Scan(
+			&action.ISIN,
+			&action.Type,
+			&action.ExDate,
+			&action.Numerator,
+			&action.Denominator,
+		)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return actions, nil
+}
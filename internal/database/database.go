@@ -183,6 +183,349 @@ func initDB(db *sql.DB) error {
 		return fmt.Errorf("failed to create portfolio_holdings table: %w", err)
 	}
 
+	// Create imported_holdings table for holdings uploaded from statements such as the CAS
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS imported_holdings (
+			id SERIAL PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			source TEXT NOT NULL,
+			item_name TEXT NOT NULL,
+			isin TEXT,
+			quantity REAL NOT NULL,
+			average_price REAL NOT NULL,
+			last_traded_price REAL NOT NULL,
+			current_value REAL NOT NULL,
+			day_change REAL NOT NULL,
+			day_change_percent REAL NOT NULL,
+			total_pnl REAL NOT NULL,
+			platform TEXT NOT NULL,
+			holding_type TEXT NOT NULL,
+			last_updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users (user_id)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create imported_holdings table: %w", err)
+	}
+
+	// Create trades table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS trades (
+			id SERIAL PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			platform TEXT NOT NULL,
+			trade_id TEXT NOT NULL,
+			order_id TEXT,
+			isin TEXT,
+			symbol TEXT NOT NULL,
+			exchange TEXT,
+			side TEXT NOT NULL,
+			quantity REAL NOT NULL,
+			price REAL NOT NULL,
+			charges REAL NOT NULL DEFAULT 0,
+			trade_date TIMESTAMP NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users (user_id),
+			UNIQUE (user_id, platform, trade_id)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create trades table: %w", err)
+	}
+
+	// Create corporate_actions table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS corporate_actions (
+			id SERIAL PRIMARY KEY,
+			isin TEXT NOT NULL,
+			action_type TEXT NOT NULL,
+			ex_date DATE NOT NULL,
+			numerator REAL NOT NULL,
+			denominator REAL NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (isin, action_type, ex_date)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create corporate_actions table: %w", err)
+	}
+
+	// Create grandfathered_prices table holding 31-Jan-2018 fair market values
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS grandfathered_prices (
+			isin TEXT PRIMARY KEY,
+			fair_market_value REAL NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create grandfathered_prices table: %w", err)
+	}
+
+	// Create portfolio_snapshots table, an append-only log of portfolio totals taken at each refresh and end of day
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS portfolio_snapshots (
+			id SERIAL PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			snapshot_date DATE NOT NULL,
+			source TEXT NOT NULL,
+			total_value REAL NOT NULL,
+			total_investment REAL NOT NULL,
+			total_pnl REAL NOT NULL,
+			total_day_change REAL NOT NULL,
+			holdings_count INTEGER NOT NULL,
+			taken_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users (user_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_portfolio_snapshots_user_date
+		ON portfolio_snapshots(user_id, snapshot_date);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create portfolio_snapshots table: %w", err)
+	}
+
+	// Create holding_snapshots table with the holdings of each portfolio snapshot
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS holding_snapshots (
+			id SERIAL PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			snapshot_date DATE NOT NULL,
+			item_name TEXT NOT NULL,
+			isin TEXT,
+			quantity REAL NOT NULL,
+			average_price REAL NOT NULL,
+			last_traded_price REAL NOT NULL,
+			current_value REAL NOT NULL,
+			day_change REAL NOT NULL,
+			day_change_percent REAL NOT NULL,
+			total_pnl REAL NOT NULL,
+			platform TEXT NOT NULL,
+			holding_type TEXT NOT NULL,
+			snapshot_id INTEGER NOT NULL REFERENCES portfolio_snapshots (id),
+			taken_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users (user_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_holding_snapshots_user_date
+		ON holding_snapshots(user_id, snapshot_date);
+
+		CREATE INDEX IF NOT EXISTS idx_holding_snapshots_snapshot
+		ON holding_snapshots(snapshot_id);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create holding_snapshots table: %w", err)
+	}
+
+	// Create holding_changes table with the differences detected between consecutive snapshots
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS holding_changes (
+			id SERIAL PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			snapshot_id INTEGER NOT NULL,
+			change_type TEXT NOT NULL,
+			item_name TEXT NOT NULL,
+			isin TEXT,
+			platform TEXT NOT NULL,
+			previous_quantity REAL NOT NULL,
+			quantity REAL NOT NULL,
+			previous_price REAL NOT NULL,
+			price REAL NOT NULL,
+			price_change_percent REAL NOT NULL,
+			detected_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users (user_id),
+			FOREIGN KEY (snapshot_id) REFERENCES portfolio_snapshots (id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_holding_changes_user_detected
+		ON holding_changes(user_id, detected_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create holding_changes table: %w", err)
+	}
+
+	// Create allocation_targets table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS allocation_targets (
+			id SERIAL PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			target_key TEXT NOT NULL,
+			weight REAL NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users (user_id),
+			UNIQUE (user_id, kind, target_key)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create allocation_targets table: %w", err)
+	}
+
+	// Create dividends table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS dividends (
+			id SERIAL PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			platform TEXT NOT NULL,
+			isin TEXT,
+			symbol TEXT NOT NULL,
+			paid_on DATE NOT NULL,
+			amount REAL NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users (user_id),
+			UNIQUE (user_id, platform, symbol, paid_on)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create dividends table: %w", err)
+	}
+
+	// Create mf_navs table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS mf_navs (
+			scheme_code TEXT NOT NULL,
+			isin_growth TEXT,
+			isin_reinvestment TEXT,
+			scheme_name TEXT NOT NULL,
+			fund_house TEXT,
+			category TEXT,
+			nav REAL NOT NULL,
+			nav_date DATE NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (scheme_code, nav_date)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_mf_navs_isin_growth
+		ON mf_navs(isin_growth, nav_date);
+
+		CREATE INDEX IF NOT EXISTS idx_mf_navs_isin_reinvestment
+		ON mf_navs(isin_reinvestment, nav_date);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create mf_navs table: %w", err)
+	}
+
+	// Create market_prices table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS market_prices (
+			isin TEXT NOT NULL,
+			trade_date DATE NOT NULL,
+			exchange TEXT NOT NULL,
+			symbol TEXT NOT NULL,
+			open REAL NOT NULL,
+			high REAL NOT NULL,
+			low REAL NOT NULL,
+			close REAL NOT NULL,
+			prev_close REAL NOT NULL,
+			volume REAL NOT NULL,
+			turnover REAL NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (isin, trade_date)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create market_prices table: %w", err)
+	}
+
+	// Create news_items table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS news_items (
+			link TEXT PRIMARY KEY,
+			title TEXT NOT NULL,
+			description TEXT,
+			source TEXT NOT NULL,
+			category TEXT,
+			published_at TIMESTAMP NOT NULL,
+			sentiment REAL NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_news_items_published_at
+		ON news_items(published_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create news_items table: %w", err)
+	}
+
+	// Create recommendations table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS recommendations (
+			news_link TEXT NOT NULL REFERENCES news_items(link) ON DELETE CASCADE,
+			stock_symbol TEXT NOT NULL,
+			action TEXT NOT NULL,
+			confidence REAL NOT NULL,
+			reason TEXT,
+			created_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (news_link, stock_symbol)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_recommendations_created_at
+		ON recommendations(created_at);
+
+		CREATE INDEX IF NOT EXISTS idx_recommendations_stock_symbol
+		ON recommendations(stock_symbol, created_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create recommendations table: %w", err)
+	}
+
+	// Add the stock's role in the news and the sentiment attributed to it to recommendations
+	_, err = db.Exec(`
+		ALTER TABLE recommendations
+		ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS sentiment REAL NOT NULL DEFAULT 0;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add role and sentiment to recommendations table: %w", err)
+	}
+
+	// Add the event type and impact horizon the sentiment analyzer reports to recommendations
+	_, err = db.Exec(`
+		ALTER TABLE recommendations
+		ADD COLUMN IF NOT EXISTS event_type TEXT NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS horizon TEXT NOT NULL DEFAULT '';
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add event type and horizon to recommendations table: %w", err)
+	}
+
+	// Create news_item_owners table with the owners of the sources each news item came from, '' for global sources
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS news_item_owners (
+			link TEXT NOT NULL REFERENCES news_items(link) ON DELETE CASCADE,
+			owner_id TEXT NOT NULL,
+			PRIMARY KEY (link, owner_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_news_item_owners_owner
+		ON news_item_owners(owner_id);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create news_item_owners table: %w", err)
+	}
+
+	// Create news_sources table; global sources have an empty owner
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS news_sources (
+			id SERIAL PRIMARY KEY,
+			owner_id TEXT NOT NULL DEFAULT '',
+			name TEXT NOT NULL,
+			url TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			category TEXT NOT NULL DEFAULT '',
+			enabled BOOLEAN NOT NULL DEFAULT TRUE,
+			reliability REAL NOT NULL,
+			refresh_minutes INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (owner_id, name)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create news_sources table: %w", err)
+	}
+
 	log.Println("Database initialized successfully")
 
 	// Perform any necessary migrations
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// DividendRepo handles dividend records in the database
+type DividendRepo struct {
+	db *DB
+}
+
+// NewDividendRepo creates a new dividend repository
+func NewDividendRepo(db *DB) *DividendRepo {
+	return &DividendRepo{db: db}
+}
+
+// SaveDividend stores a dividend received by a user, replacing the amount of an existing record for the same payout
+func (r *DividendRepo) SaveDividend(userID string, dividend models.Dividend) error {
+	_, err := r.db.Exec(
+		`INSERT INTO dividends (user_id, platform, isin, symbol, paid_on, amount) 
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, platform, symbol, paid_on) 
+		DO UPDATE SET isin = $3, amount = $6`,
+		userID,
+		dividend.Platform,
+		dividend.ISIN,
+		dividend.Symbol,
+		dividend.PaidOn,
+		dividend.Amount,
+	)
+	return err
+}
+
+// GetDividends retrieves all dividends received by a user ordered by payment date
+func (r *DividendRepo) GetDividends(userID string) ([]models.Dividend, error) {
+	rows, err := r.db.Query(
+		"SELECT platform, isin, symbol, paid_on, amount FROM dividends WHERE user_id = $1 ORDER BY paid_on",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dividends []models.Dividend
+	for rows.Next() {
+		var dividend models.Dividend
+		err := rows.Scan(
+			&dividend.Platform,
+			&dividend.ISIN,
+			&dividend.Symbol,
+			&dividend.PaidOn,
+			&dividend.Amount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		dividends = append(dividends, dividend)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return dividends, nil
+}
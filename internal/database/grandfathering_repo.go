@@ -0,0 +1,55 @@
+package database
+
+import (
+	"github.com/lib/pq"
+)
+
+// GrandfatheringRepo handles the 31-Jan-2018 fair market values used for capital gains grandfathering
+type GrandfatheringRepo struct {
+	db *DB
+}
+
+// NewGrandfatheringRepo creates a new grandfathering repository
+func NewGrandfatheringRepo(db *DB) *GrandfatheringRepo {
+	return &GrandfatheringRepo{db: db}
+}
+
+// SaveFairMarketValue stores the 31-Jan-2018 value per unit of a security
+func (r *GrandfatheringRepo) SaveFairMarketValue(isin string, value float64) error {
+	_, err := r.db.Exec(
+		`INSERT INTO grandfathered_prices (isin, fair_market_value) 
+		VALUES ($1, $2)
+		ON CONFLICT (isin) 
+		DO UPDATE SET fair_market_value = $2, updated_at = CURRENT_TIMESTAMP`,
+		isin, value,
+	)
+	return err
+}
+
+// GetFairMarketValues retrieves the 31-Jan-2018 value per unit of the given securities, keyed by ISIN
+func (r *GrandfatheringRepo) GetFairMarketValues(isins []string) (map[string]float64, error) {
+	rows, err := r.db.Query(
+		"SELECT isin, fair_market_value FROM grandfathered_prices WHERE isin = ANY($1)",
+		pq.Array(isins),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make(map[string]float64)
+	for rows.Next() {
+		var isin string
+		var value float64
+		if err := rows.Scan(&isin, &value); err != nil {
+			return nil, err
+		}
+		values[isin] = value
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
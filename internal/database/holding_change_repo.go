@@ -0,0 +1,102 @@
+package database
+
+import (
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// HoldingChangeRepo handles the holding changes detected on portfolio refreshes in the database
+type HoldingChangeRepo struct {
+	db *DB
+}
+
+// NewHoldingChangeRepo creates a new holding change repository
+func NewHoldingChangeRepo(db *DB) *HoldingChangeRepo {
+	return &HoldingChangeRepo{db: db}
+}
+
+// SaveHoldingChanges stores the changes detected when the given snapshot was taken
+func (r *HoldingChangeRepo) SaveHoldingChanges(userID string, snapshotID int64, changes []models.HoldingChange) error {
+	// Begin a transaction
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, change := range changes {
+		_, err = tx.Exec(
+			`INSERT INTO holding_changes 
+			(user_id, snapshot_id, change_type, item_name, isin, platform, previous_quantity, quantity, 
+			previous_price, price, price_change_percent, detected_at) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+			userID,
+			snapshotID,
+			change.Type,
+			change.ItemName,
+			change.ISIN,
+			change.Platform,
+			change.PreviousQuantity,
+			change.Quantity,
+			change.PreviousPrice,
+			change.Price,
+			change.PriceChangePct,
+			change.DetectedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Commit the transaction
+	return tx.Commit()
+}
+
+// GetHoldingChanges retrieves the changes detected for a user in a time range, newest first,
+// optionally filtered by change type
+func (r *HoldingChangeRepo) GetHoldingChanges(userID string, from, to time.Time, changeType models.HoldingChangeType) ([]models.HoldingChange, error) {
+	rows, err := r.db.Query(
+		`SELECT change_type, item_name, isin, platform, previous_quantity, quantity, 
+		previous_price, price, price_change_percent, detected_at 
+		FROM holding_changes 
+		WHERE user_id = $1 AND detected_at >= $2 AND detected_at < $3 AND ($4 = '' OR change_type = $4) 
+		ORDER BY detected_at DESC, platform, item_name`,
+		userID, from, to, changeType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []models.HoldingChange{}
+	for rows.Next() {
+		var change models.HoldingChange
+		err := rows.Scan(
+			&change.Type,
+			&change.ItemName,
+			&change.ISIN,
+			&change.Platform,
+			&change.PreviousQuantity,
+			&change.Quantity,
+			&change.PreviousPrice,
+			&change.Price,
+			&change.PriceChangePct,
+			&change.DetectedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,177 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/lib/pq"
+)
+
+// NAVRepo handles the daily mutual fund NAV history in the database
+type NAVRepo struct {
+	db *DB
+}
+
+// NewNAVRepo creates a new NAV repository
+func NewNAVRepo(db *DB) *NAVRepo {
+	return &NAVRepo{db: db}
+}
+
+// SaveNAVs stores scheme NAVs, replacing any already stored for the same scheme and day, and returns the number stored
+func (r *NAVRepo) SaveNAVs(navs []models.SchemeNAV) (int, error) {
+	// Begin a transaction
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	stmt, err := tx.Prepare(
+		`INSERT INTO mf_navs
+		(scheme_code, isin_growth, isin_reinvestment, scheme_name, fund_house, category, nav, nav_date)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (scheme_code, nav_date)
+		DO UPDATE SET isin_growth = $2, isin_reinvestment = $3, scheme_name = $4, fund_house = $5, category = $6, nav = $7`,
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for _, nav := range navs {
+		_, err = stmt.Exec(
+			nav.SchemeCode,
+			nav.ISINGrowth,
+			nav.ISINReinvestment,
+			nav.SchemeName,
+			nav.FundHouse,
+			nav.Category,
+			nav.NAV,
+			nav.Date,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// Commit the transaction
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(navs), nil
+}
+
+// GetLatestNAVs retrieves the latest NAV of each of the given scheme ISINs, with the NAV published before it, keyed by ISIN
+func (r *NAVRepo) GetLatestNAVs(isins []string) (map[string]models.NAVQuote, error) {
+	rows, err := r.db.Query(
+		`SELECT isin, nav, previous_nav, nav_date FROM (
+			SELECT isin, nav, nav_date,
+				LAG(nav) OVER (PARTITION BY isin ORDER BY nav_date) AS previous_nav,
+				ROW_NUMBER() OVER (PARTITION BY isin ORDER BY nav_date DESC) AS position
+			FROM (
+				SELECT isin_growth AS isin, nav, nav_date FROM mf_navs WHERE isin_growth = ANY($1)
+				UNION ALL
+				SELECT isin_reinvestment AS isin, nav, nav_date FROM mf_navs WHERE isin_reinvestment = ANY($1)
+			) scheme_navs
+		) ranked WHERE position = 1`,
+		pq.Array(isins),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	quotes := make(map[string]models.NAVQuote)
+	for rows.Next() {
+		var quote models.NAVQuote
+		var previous sql.NullFloat64
+		if err := rows.Scan(&quote.ISIN, &quote.NAV, &previous, &quote.Date); err != nil {
+			return nil, err
+		}
+		quote.PreviousNAV = previous.Float64
+		quotes[quote.ISIN] = quote
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return quotes, nil
+}
+
+// GetSchemeCategories retrieves the category each scheme ISIN was last published under, keyed by ISIN
+func (r *NAVRepo) GetSchemeCategories() (map[string]string, error) {
+	rows, err := r.db.Query(
+		`SELECT DISTINCT ON (isin) isin, category FROM (
+			SELECT isin_growth AS isin, category, nav_date FROM mf_navs WHERE isin_growth <> ''
+			UNION ALL
+			SELECT isin_reinvestment AS isin, category, nav_date FROM mf_navs WHERE isin_reinvestment <> ''
+		) scheme_categories
+		WHERE category <> ''
+		ORDER BY isin, nav_date DESC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := make(map[string]string)
+	for rows.Next() {
+		var isin, category string
+		if err := rows.Scan(&isin, &category); err != nil {
+			return nil, err
+		}
+		categories[isin] = category
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// GetNAVHistory retrieves the NAVs of a scheme ISIN between two days inclusive, ordered by date
+func (r *NAVRepo) GetNAVHistory(isin string, from, to time.Time) ([]models.SchemeNAV, error) {
+	rows, err := r.db.Query(
+		`SELECT scheme_code, isin_growth, isin_reinvestment, scheme_name, fund_house, category, nav, nav_date
+		FROM mf_navs
+		WHERE (isin_growth = $1 OR isin_reinvestment = $1) AND nav_date BETWEEN $2 AND $3
+		ORDER BY nav_date`,
+		isin, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var navs []models.SchemeNAV
+	for rows.Next() {
+		var nav models.SchemeNAV
+		err := rows.Scan(
+			&nav.SchemeCode,
+			&nav.ISINGrowth,
+			&nav.ISINReinvestment,
+			&nav.SchemeName,
+			&nav.FundHouse,
+			&nav.Category,
+			&nav.NAV,
+			&nav.Date,
+		)
+		if err != nil {
+			return nil, err
+		}
+		navs = append(navs, nav)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return navs, nil
+}
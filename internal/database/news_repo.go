@@ -0,0 +1,245 @@
+package database
+
+import (
+	"github.com/Kora1128/FinSight/internal/news"
+	"github.com/lib/pq"
+)
+
+// Ensure NewsRepo implements news.NewsRepository
+var _ news.NewsRepository = (*NewsRepo)(nil)
+
+// NewsRepo handles the archive of processed news items and recommendations in the database
+type NewsRepo struct {
+	db *DB
+}
+
+// NewNewsRepo creates a new news repository
+func NewNewsRepo(db *DB) *NewsRepo {
+	return &NewsRepo{db: db}
+}
+
+// SaveNewsItems archives news items, ignoring links already stored, and records their owners,
+// adding to those of links already stored; items without owners are global
+func (r *NewsRepo) SaveNewsItems(items []news.NewsItem) error {
+	// Begin a transaction
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	stmt, err := tx.Prepare(
+		`INSERT INTO news_items
+		(link, title, description, source, category, published_at, sentiment)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (link) DO NOTHING`,
+	)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	ownerStmt, err := tx.Prepare(
+		`INSERT INTO news_item_owners (link, owner_id)
+		VALUES ($1, $2)
+		ON CONFLICT (link, owner_id) DO NOTHING`,
+	)
+	if err != nil {
+		return err
+	}
+	defer ownerStmt.Close()
+
+	for _, item := range items {
+		_, err = stmt.Exec(
+			item.Link,
+			item.Title,
+			item.Description,
+			item.Source,
+			item.Category,
+			item.PublishedAt,
+			item.Sentiment,
+		)
+		if err != nil {
+			return err
+		}
+
+		owners := item.Owners
+		if len(owners) == 0 {
+			owners = []string{""}
+		}
+		for _, owner := range owners {
+			if _, err = ownerStmt.Exec(item.Link, owner); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	return err
+}
+
+// SaveRecommendations stores recommendations, replacing any already stored for the same link and stock
+func (r *NewsRepo) SaveRecommendations(recommendations []news.Recommendation) error {
+	// Begin a transaction
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	stmt, err := tx.Prepare(
+		`INSERT INTO recommendations
+		(news_link, stock_symbol, role, action, confidence, sentiment, event_type, horizon, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (news_link, stock_symbol)
+		DO UPDATE SET role = $3, action = $4, confidence = $5, sentiment = $6, event_type = $7, horizon = $8,
+			reason = $9, created_at = $10`,
+	)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, recommendation := range recommendations {
+		_, err = stmt.Exec(
+			recommendation.NewsItem.Link,
+			recommendation.StockSymbol,
+			recommendation.Role,
+			recommendation.Action,
+			recommendation.Confidence,
+			recommendation.Sentiment,
+			recommendation.EventType,
+			recommendation.Horizon,
+			recommendation.Reason,
+			recommendation.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	return err
+}
+
+// GetStoredLinks returns which of the given links have already been archived
+func (r *NewsRepo) GetStoredLinks(links []string) (map[string]bool, error) {
+	rows, err := r.db.Query(`SELECT link FROM news_items WHERE link = ANY($1)`, pq.Array(links))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stored := make(map[string]bool)
+	for rows.Next() {
+		var link string
+		if err := rows.Scan(&link); err != nil {
+			return nil, err
+		}
+		stored[link] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+// GetRecentRecommendations retrieves the most recent recommendations with more than news.MinRecommendationConfidence
+// of every owner, with the owners of their news, newest first
+func (r *NewsRepo) GetRecentRecommendations(limit int) ([]news.Recommendation, error) {
+	return r.queryRecommendations(
+		recommendationColumns+`
+		WHERE r.confidence > $2
+		ORDER BY r.created_at DESC LIMIT $1`,
+		limit, news.MinRecommendationConfidence,
+	)
+}
+
+// GetLatestRecommendations retrieves the most recent recommendations with more than news.MinRecommendationConfidence
+// that a user sees, newest first; an empty userID retrieves those from global news only
+func (r *NewsRepo) GetLatestRecommendations(userID string, limit int) ([]news.Recommendation, error) {
+	return r.queryRecommendations(
+		recommendationColumns+`
+		WHERE r.confidence > $2 AND `+visibleToUser+`
+		ORDER BY r.created_at DESC LIMIT $1`,
+		limit, news.MinRecommendationConfidence, userID,
+	)
+}
+
+// GetRecommendationsByStock retrieves all recommendations for a stock that a user sees, whatever its role in the
+// news, newest first; an empty userID retrieves those from global news only
+func (r *NewsRepo) GetRecommendationsByStock(userID, stockSymbol string, limit int) ([]news.Recommendation, error) {
+	return r.queryRecommendations(
+		recommendationColumns+`
+		WHERE r.stock_symbol = $1 AND `+visibleToUser+`
+		ORDER BY r.created_at DESC LIMIT $2`,
+		stockSymbol, limit, userID,
+	)
+}
+
+// recommendationColumns selects recommendations with their news item and its owners, none for global news
+const recommendationColumns = `SELECT r.stock_symbol, r.role, r.action, r.confidence, r.sentiment, r.event_type, r.horizon,
+		r.reason, r.created_at, n.link, n.title, n.description, n.source, n.category, n.published_at, n.sentiment,
+		CASE WHEN EXISTS (SELECT 1 FROM news_item_owners o WHERE o.link = n.link AND o.owner_id = '')
+			THEN '{}'::TEXT[]
+			ELSE ARRAY(SELECT o.owner_id FROM news_item_owners o WHERE o.link = n.link ORDER BY o.owner_id)
+		END
+	FROM recommendations r JOIN news_items n ON n.link = r.news_link`
+
+// visibleToUser restricts recommendations to global news and news from the sources of the user given as $3
+const visibleToUser = `EXISTS (SELECT 1 FROM news_item_owners o WHERE o.link = n.link AND o.owner_id IN ('', $3))`
+
+// queryRecommendations runs a recommendation query and scans the result
+func (r *NewsRepo) queryRecommendations(query string, args ...interface{}) ([]news.Recommendation, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recommendations []news.Recommendation
+	for rows.Next() {
+		var recommendation news.Recommendation
+		err := rows.Scan(
+			&recommendation.StockSymbol,
+			&recommendation.Role,
+			&recommendation.Action,
+			&recommendation.Confidence,
+			&recommendation.Sentiment,
+			&recommendation.EventType,
+			&recommendation.Horizon,
+			&recommendation.Reason,
+			&recommendation.CreatedAt,
+			&recommendation.NewsItem.Link,
+			&recommendation.NewsItem.Title,
+			&recommendation.NewsItem.Description,
+			&recommendation.NewsItem.Source,
+			&recommendation.NewsItem.Category,
+			&recommendation.NewsItem.PublishedAt,
+			&recommendation.NewsItem.Sentiment,
+			pq.Array(&recommendation.NewsItem.Owners),
+		)
+		if err != nil {
+			return nil, err
+		}
+		recommendations = append(recommendations, recommendation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return recommendations, nil
+}
@@ -0,0 +1,156 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/Kora1128/FinSight/internal/news"
+	"github.com/lib/pq"
+)
+
+// Ensure NewsSourceRepo implements news.SourceRepository
+var _ news.SourceRepository = (*NewsSourceRepo)(nil)
+
+// uniqueViolation is the PostgreSQL error code of a unique constraint violation
+const uniqueViolation = "23505"
+
+// NewsSourceRepo handles global and user-owned news sources in the database
+type NewsSourceRepo struct {
+	db *DB
+}
+
+// NewNewsSourceRepo creates a new news source repository
+func NewNewsSourceRepo(db *DB) *NewsSourceRepo {
+	return &NewsSourceRepo{db: db}
+}
+
+// GetSources retrieves the global sources and, when userID is set, the sources the user owns
+func (r *NewsSourceRepo) GetSources(userID string) ([]news.Source, error) {
+	return r.querySources(
+		`SELECT id, owner_id, name, url, description, category, enabled, reliability, refresh_minutes
+		FROM news_sources WHERE owner_id = '' OR ($1 <> '' AND owner_id = $1)
+		ORDER BY owner_id, name`,
+		userID,
+	)
+}
+
+// GetEnabledSources retrieves the enabled sources of every owner, global sources first
+func (r *NewsSourceRepo) GetEnabledSources() ([]news.Source, error) {
+	return r.querySources(
+		`SELECT id, owner_id, name, url, description, category, enabled, reliability, refresh_minutes
+		FROM news_sources WHERE enabled
+		ORDER BY owner_id, id`,
+	)
+}
+
+// CountGlobalSources returns the number of global sources
+func (r *NewsSourceRepo) CountGlobalSources() (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM news_sources WHERE owner_id = ''").Scan(&count)
+	return count, err
+}
+
+// SaveSource creates a source and sets its ID, returning news.ErrSourceExists when its owner already has a source with its name
+func (r *NewsSourceRepo) SaveSource(source *news.Source) error {
+	err := r.db.QueryRow(
+		`INSERT INTO news_sources (owner_id, name, url, description, category, enabled, reliability, refresh_minutes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`,
+		source.OwnerID,
+		source.Name,
+		source.URL,
+		source.Description,
+		source.Category,
+		source.Enabled,
+		source.Reliability,
+		source.RefreshMinutes,
+	).Scan(&source.ID)
+	if isUniqueViolation(err) {
+		return news.ErrSourceExists
+	}
+	return err
+}
+
+// UpdateSource updates a source of its owner by ID, reporting whether it exists
+func (r *NewsSourceRepo) UpdateSource(source *news.Source) (bool, error) {
+	result, err := r.db.Exec(
+		`UPDATE news_sources SET name = $1, url = $2, description = $3, category = $4, enabled = $5,
+			reliability = $6, refresh_minutes = $7, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8 AND owner_id = $9`,
+		source.Name,
+		source.URL,
+		source.Description,
+		source.Category,
+		source.Enabled,
+		source.Reliability,
+		source.RefreshMinutes,
+		source.ID,
+		source.OwnerID,
+	)
+	if isUniqueViolation(err) {
+		return false, news.ErrSourceExists
+	}
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// DeleteSource deletes a source of an owner by ID, reporting whether it existed
+func (r *NewsSourceRepo) DeleteSource(ownerID string, id int64) (bool, error) {
+	result, err := r.db.Exec("DELETE FROM news_sources WHERE id = $1 AND owner_id = $2", id, ownerID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// querySources runs a news source query and scans the result
+func (r *NewsSourceRepo) querySources(query string, args ...interface{}) ([]news.Source, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sources []news.Source
+	for rows.Next() {
+		var source news.Source
+		err := rows.Scan(
+			&source.ID,
+			&source.OwnerID,
+			&source.Name,
+			&source.URL,
+			&source.Description,
+			&source.Category,
+			&source.Enabled,
+			&source.Reliability,
+			&source.RefreshMinutes,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sources, nil
+}
+
+// isUniqueViolation reports whether an error is a PostgreSQL unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
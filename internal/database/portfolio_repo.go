@@ -194,6 +194,99 @@ func (r *PortfolioRepo) GetHoldingsByType(userID string, holdingType models.Hold
 	return holdings, nil
 }
 
+// SaveImportedHoldings replaces the holdings imported from a source (e.g. a CAS upload) for a user
+func (r *PortfolioRepo) SaveImportedHoldings(userID string, source string, holdings []models.Holding) error {
+	// Begin a transaction
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Delete holdings from the previous import of this source
+	_, err = tx.Exec("DELETE FROM imported_holdings WHERE user_id = $1 AND source = $2", userID, source)
+	if err != nil {
+		return err
+	}
+
+	// Insert new holdings
+	for _, holding := range holdings {
+		_, err = tx.Exec(
+			`INSERT INTO imported_holdings 
+			(user_id, source, item_name, isin, quantity, average_price, last_traded_price, 
+			current_value, day_change, day_change_percent, total_pnl, platform, holding_type, last_updated) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+			userID,
+			source,
+			holding.ItemName,
+			holding.ISIN,
+			holding.Quantity,
+			holding.AveragePrice,
+			holding.LastTradedPrice,
+			holding.CurrentValue,
+			holding.DayChange,
+			holding.DayChangePercent,
+			holding.TotalPnL,
+			holding.Platform,
+			holding.Type,
+			holding.LastUpdated,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Commit the transaction
+	return tx.Commit()
+}
+
+// GetImportedHoldings retrieves the holdings imported from all sources for a user
+func (r *PortfolioRepo) GetImportedHoldings(userID string) ([]models.Holding, error) {
+	rows, err := r.db.Query(
+		`SELECT item_name, isin, quantity, average_price, last_traded_price, 
+		current_value, day_change, day_change_percent, total_pnl, platform, holding_type, last_updated 
+		FROM imported_holdings WHERE user_id = $1`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holdings []models.Holding
+	for rows.Next() {
+		var holding models.Holding
+		err := rows.Scan(
+			&holding.ItemName,
+			&holding.ISIN,
+			&holding.Quantity,
+			&holding.AveragePrice,
+			&holding.LastTradedPrice,
+			&holding.CurrentValue,
+			&holding.DayChange,
+			&holding.DayChangePercent,
+			&holding.TotalPnL,
+			&holding.Platform,
+			&holding.Type,
+			&holding.LastUpdated,
+		)
+		if err != nil {
+			return nil, err
+		}
+		holdings = append(holdings, holding)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return holdings, nil
+}
+
 // GetPortfolioLastUpdated gets the timestamp when the portfolio was last updated
 func (r *PortfolioRepo) GetPortfolioLastUpdated(userID string) (time.Time, bool, error) {
 	var lastUpdated time.Time
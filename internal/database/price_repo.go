@@ -0,0 +1,134 @@
+package database
+
+import (
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// PriceRepo handles end-of-day market prices in the database
+type PriceRepo struct {
+	db *DB
+}
+
+// NewPriceRepo creates a new market price repository
+func NewPriceRepo(db *DB) *PriceRepo {
+	return &PriceRepo{db: db}
+}
+
+// SavePriceBars stores price bars, one per security and day, and returns the number stored.
+// An NSE bar replaces a BSE bar for the same day but not the other way round.
+func (r *PriceRepo) SavePriceBars(bars []models.PriceBar) (int, error) {
+	// Begin a transaction
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	stmt, err := tx.Prepare(
+		`INSERT INTO market_prices
+		(isin, trade_date, exchange, symbol, open, high, low, close, prev_close, volume, turnover)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (isin, trade_date)
+		DO UPDATE SET exchange = $3, symbol = $4, open = $5, high = $6, low = $7, close = $8, prev_close = $9, volume = $10, turnover = $11
+		WHERE market_prices.exchange <> 'NSE' OR EXCLUDED.exchange = 'NSE'`,
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for _, bar := range bars {
+		_, err = stmt.Exec(
+			bar.ISIN,
+			bar.Date,
+			bar.Exchange,
+			bar.Symbol,
+			bar.Open,
+			bar.High,
+			bar.Low,
+			bar.Close,
+			bar.PrevClose,
+			bar.Volume,
+			bar.Turnover,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// Commit the transaction
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(bars), nil
+}
+
+// GetPriceBar retrieves the bar of a security on the last trading day on or before the given day
+func (r *PriceRepo) GetPriceBar(isin string, onOrBefore time.Time) (models.PriceBar, bool, error) {
+	bars, err := r.queryPriceBars(
+		`SELECT isin, symbol, exchange, trade_date, open, high, low, close, prev_close, volume, turnover
+		FROM market_prices WHERE isin = $1 AND trade_date <= $2
+		ORDER BY trade_date DESC LIMIT 1`,
+		isin, onOrBefore,
+	)
+	if err != nil {
+		return models.PriceBar{}, false, err
+	}
+	if len(bars) == 0 {
+		return models.PriceBar{}, false, nil
+	}
+	return bars[0], true, nil
+}
+
+// GetPriceBars retrieves the bars of a security between two days inclusive, ordered by date
+func (r *PriceRepo) GetPriceBars(isin string, from, to time.Time) ([]models.PriceBar, error) {
+	return r.queryPriceBars(
+		`SELECT isin, symbol, exchange, trade_date, open, high, low, close, prev_close, volume, turnover
+		FROM market_prices WHERE isin = $1 AND trade_date BETWEEN $2 AND $3
+		ORDER BY trade_date`,
+		isin, from, to,
+	)
+}
+
+// queryPriceBars runs a price bar query and scans the result
+func (r *PriceRepo) queryPriceBars(query string, args ...interface{}) ([]models.PriceBar, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bars []models.PriceBar
+	for rows.Next() {
+		var bar models.PriceBar
+		err := rows.Scan(
+			&bar.ISIN,
+			&bar.Symbol,
+			&bar.Exchange,
+			&bar.Date,
+			&bar.Open,
+			&bar.High,
+			&bar.Low,
+			&bar.Close,
+			&bar.PrevClose,
+			&bar.Volume,
+			&bar.Turnover,
+		)
+		if err != nil {
+			return nil, err
+		}
+		bars = append(bars, bar)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return bars, nil
+}
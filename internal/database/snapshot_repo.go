@@ -0,0 +1,228 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// SnapshotRepo handles the append-only portfolio snapshot history in the database
+type SnapshotRepo struct {
+	db *DB
+}
+
+// NewSnapshotRepo creates a new snapshot repository
+func NewSnapshotRepo(db *DB) *SnapshotRepo {
+	return &SnapshotRepo{db: db}
+}
+
+// SaveSnapshot appends a portfolio snapshot and the holdings it was computed from, and sets the snapshot ID
+func (r *SnapshotRepo) SaveSnapshot(userID string, snapshot *models.PortfolioSnapshot, holdings []models.Holding) error {
+	// Begin a transaction
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Insert portfolio totals
+	err = tx.QueryRow(
+		`INSERT INTO portfolio_snapshots 
+		(user_id, snapshot_date, source, total_value, total_investment, total_pnl, total_day_change, holdings_count, taken_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`,
+		userID,
+		snapshot.Date,
+		snapshot.Source,
+		snapshot.TotalValue,
+		snapshot.TotalInvestment,
+		snapshot.TotalPnL,
+		snapshot.TotalDayChange,
+		snapshot.HoldingsCount,
+		snapshot.TakenAt,
+	).Scan(&snapshot.ID)
+	if err != nil {
+		return err
+	}
+
+	// Insert holdings
+	for _, holding := range holdings {
+		_, err = tx.Exec(
+			`INSERT INTO holding_snapshots 
+			(snapshot_id, user_id, snapshot_date, item_name, isin, quantity, average_price, last_traded_price, 
+			current_value, day_change, day_change_percent, total_pnl, platform, holding_type, taken_at) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`,
+			snapshot.ID,
+			userID,
+			snapshot.Date,
+			holding.ItemName,
+			holding.ISIN,
+			holding.Quantity,
+			holding.AveragePrice,
+			holding.LastTradedPrice,
+			holding.CurrentValue,
+			holding.DayChange,
+			holding.DayChangePercent,
+			holding.TotalPnL,
+			holding.Platform,
+			holding.Type,
+			snapshot.TakenAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Commit the transaction
+	return tx.Commit()
+}
+
+// GetPortfolioSnapshots retrieves all portfolio snapshots of a user between two days inclusive, ordered by time taken
+func (r *SnapshotRepo) GetPortfolioSnapshots(userID string, from, to time.Time) ([]models.PortfolioSnapshot, error) {
+	rows, err := r.db.Query(
+		`SELECT id, snapshot_date, taken_at, source, total_value, total_investment, total_pnl, total_day_change, holdings_count 
+		FROM portfolio_snapshots WHERE user_id = $1 AND snapshot_date BETWEEN $2 AND $3 
+		ORDER BY taken_at, id`,
+		userID, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []models.PortfolioSnapshot
+	for rows.Next() {
+		var snapshot models.PortfolioSnapshot
+		err := rows.Scan(
+			&snapshot.ID,
+			&snapshot.Date,
+			&snapshot.TakenAt,
+			&snapshot.Source,
+			&snapshot.TotalValue,
+			&snapshot.TotalInvestment,
+			&snapshot.TotalPnL,
+			&snapshot.TotalDayChange,
+			&snapshot.HoldingsCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// GetHoldingSnapshots retrieves the holdings of the last snapshot of each day between two days inclusive, ordered by day
+func (r *SnapshotRepo) GetHoldingSnapshots(userID string, from, to time.Time) ([]models.HoldingSnapshot, error) {
+	rows, err := r.db.Query(
+		`SELECT h.snapshot_date, h.item_name, h.isin, h.quantity, h.average_price, h.last_traded_price, 
+		h.current_value, h.day_change, h.day_change_percent, h.total_pnl, h.platform, h.holding_type, h.taken_at 
+		FROM holding_snapshots h 
+		JOIN (
+			SELECT DISTINCT ON (snapshot_date) id FROM portfolio_snapshots 
+			WHERE user_id = $1 AND snapshot_date BETWEEN $2 AND $3 
+			ORDER BY snapshot_date, taken_at DESC, id DESC
+		) latest ON h.snapshot_id = latest.id 
+		ORDER BY h.snapshot_date, h.platform, h.item_name`,
+		userID, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []models.HoldingSnapshot
+	for rows.Next() {
+		var snapshot models.HoldingSnapshot
+		err := rows.Scan(
+			&snapshot.Date,
+			&snapshot.ItemName,
+			&snapshot.ISIN,
+			&snapshot.Quantity,
+			&snapshot.AveragePrice,
+			&snapshot.LastTradedPrice,
+			&snapshot.CurrentValue,
+			&snapshot.DayChange,
+			&snapshot.DayChangePercent,
+			&snapshot.TotalPnL,
+			&snapshot.Platform,
+			&snapshot.Type,
+			&snapshot.LastUpdated,
+		)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// GetLatestSnapshotHoldings retrieves the holdings of the most recent snapshot of a user
+func (r *SnapshotRepo) GetLatestSnapshotHoldings(userID string) ([]models.Holding, bool, error) {
+	var snapshotID int64
+	err := r.db.QueryRow(
+		"SELECT id FROM portfolio_snapshots WHERE user_id = $1 ORDER BY taken_at DESC, id DESC LIMIT 1",
+		userID,
+	).Scan(&snapshotID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	rows, err := r.db.Query(
+		`SELECT item_name, isin, quantity, average_price, last_traded_price, 
+		current_value, day_change, day_change_percent, total_pnl, platform, holding_type, taken_at 
+		FROM holding_snapshots WHERE snapshot_id = $1`,
+		snapshotID,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	holdings := []models.Holding{}
+	for rows.Next() {
+		var holding models.Holding
+		err := rows.Scan(
+			&holding.ItemName,
+			&holding.ISIN,
+			&holding.Quantity,
+			&holding.AveragePrice,
+			&holding.LastTradedPrice,
+			&holding.CurrentValue,
+			&holding.DayChange,
+			&holding.DayChangePercent,
+			&holding.TotalPnL,
+			&holding.Platform,
+			&holding.Type,
+			&holding.LastUpdated,
+		)
+		if err != nil {
+			return nil, false, err
+		}
+		holdings = append(holdings, holding)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	return holdings, true, nil
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// TargetRepo handles users' allocation targets in the database
+type TargetRepo struct {
+	db *DB
+}
+
+// NewTargetRepo creates a new allocation target repository
+func NewTargetRepo(db *DB) *TargetRepo {
+	return &TargetRepo{db: db}
+}
+
+// GetTargets retrieves all allocation targets of a user
+func (r *TargetRepo) GetTargets(userID string) ([]models.AllocationTarget, error) {
+	rows, err := r.db.Query(
+		"SELECT id, kind, target_key, weight, updated_at FROM allocation_targets WHERE user_id = $1 ORDER BY kind, weight DESC, target_key",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []models.AllocationTarget
+	for rows.Next() {
+		var target models.AllocationTarget
+		err := rows.Scan(
+			&target.ID,
+			&target.Kind,
+			&target.Key,
+			&target.Weight,
+			&target.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+// SaveTarget creates or replaces the target of a user for a kind and key, and sets the target ID
+func (r *TargetRepo) SaveTarget(userID string, target *models.AllocationTarget) error {
+	return r.db.QueryRow(
+		`INSERT INTO allocation_targets (user_id, kind, target_key, weight) 
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, kind, target_key) 
+		DO UPDATE SET weight = $4, updated_at = CURRENT_TIMESTAMP
+		RETURNING id, updated_at`,
+		userID,
+		target.Kind,
+		target.Key,
+		target.Weight,
+	).Scan(&target.ID, &target.UpdatedAt)
+}
+
+// UpdateTarget updates a target of a user by ID, reporting whether it exists
+func (r *TargetRepo) UpdateTarget(userID string, target *models.AllocationTarget) (bool, error) {
+	result, err := r.db.Exec(
+		`UPDATE allocation_targets SET kind = $1, target_key = $2, weight = $3, updated_at = CURRENT_TIMESTAMP 
+		WHERE id = $4 AND user_id = $5`,
+		target.Kind,
+		target.Key,
+		target.Weight,
+		target.ID,
+		userID,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// DeleteTarget deletes a target of a user by ID, reporting whether it existed
+func (r *TargetRepo) DeleteTarget(userID string, targetID int64) (bool, error) {
+	result, err := r.db.Exec("DELETE FROM allocation_targets WHERE id = $1 AND user_id = $2", targetID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// TradeRepo handles trade history operations in the database
+type TradeRepo struct {
+	db *DB
+}
+
+// NewTradeRepo creates a new trade repository
+func NewTradeRepo(db *DB) *TradeRepo {
+	return &TradeRepo{db: db}
+}
+
+// SaveTrades stores trades for a user, skipping trades already stored under the same platform and trade ID.
+// It returns the number of trades that were newly inserted.
+func (r *TradeRepo) SaveTrades(userID string, trades []models.Trade) (int, error) {
+	// Begin a transaction
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	inserted := 0
+	for _, trade := range trades {
+		result, execErr := tx.Exec(
+			`INSERT INTO trades 
+			(user_id, platform, trade_id, order_id, isin, symbol, exchange, side, quantity, price, charges, trade_date) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			ON CONFLICT (user_id, platform, trade_id) DO NOTHING`,
+			userID,
+			trade.Platform,
+			trade.TradeID,
+			trade.OrderID,
+			trade.ISIN,
+			trade.Symbol,
+			trade.Exchange,
+			trade.Side,
+			trade.Quantity,
+			trade.Price,
+			trade.Charges,
+			trade.TradeDate,
+		)
+		if execErr != nil {
+			err = execErr
+			return 0, err
+		}
+
+		rows, execErr := result.RowsAffected()
+		if execErr != nil {
+			err = execErr
+			return 0, err
+		}
+		inserted += int(rows)
+	}
+
+	// Commit the transaction
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return inserted, nil
+}
+
+// GetTrades retrieves all trades for a user ordered by trade date
+func (r *TradeRepo) GetTrades(userID string) ([]models.Trade, error) {
+	return r.queryTrades(
+		`SELECT platform, trade_id, order_id, isin, symbol, exchange, side, quantity, price, charges, trade_date 
+		FROM trades WHERE user_id = $1 ORDER BY trade_date, id`,
+		userID,
+	)
+}
+
+// GetTradesByISIN retrieves the trades in a single security for a user ordered by trade date
+func (r *TradeRepo) GetTradesByISIN(userID string, isin string) ([]models.Trade, error) {
+	return r.queryTrades(
+		`SELECT platform, trade_id, order_id, isin, symbol, exchange, side, quantity, price, charges, trade_date 
+		FROM trades WHERE user_id = $1 AND isin = $2 ORDER BY trade_date, id`,
+		userID, isin,
+	)
+}
+
+// queryTrades runs a trade query and scans the results
+func (r *TradeRepo) queryTrades(query string, args ...interface{}) ([]models.Trade, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trades []models.Trade
+	for rows.Next() {
+		var trade models.Trade
+		err := rows.Scan(
+			&trade.Platform,
+			&trade.TradeID,
+			&trade.OrderID,
+			&trade.ISIN,
+			&trade.Symbol,
+			&trade.Exchange,
+			&trade.Side,
+			&trade.Quantity,
+			&trade.Price,
+			&trade.Charges,
+			&trade.TradeDate,
+		)
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, trade)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return trades, nil
+}
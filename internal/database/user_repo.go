@@ -134,18 +134,12 @@ func (r *SessionRepo) GetSession(sessionID string) (*models.UserSession, error)
 
 	// Get broker connections
 	brokerRepo := NewBrokerCredentialsRepo(r.db)
-	zerodhaConnected, err := brokerRepo.HasCredentials(session.UserID, models.PlatformZerodha)
+	connectedBrokers, err := brokerRepo.ListBrokerTypes(session.UserID)
 	if err != nil {
 		return nil, err
 	}
-	
-	iciciConnected, err := brokerRepo.HasCredentials(session.UserID, models.PlatformICICIDirect)
-	if err != nil {
-		return nil, err
-	}
-	
-	session.ZerodhaConnected = zerodhaConnected
-	session.ICICIConnected = iciciConnected
+
+	session.ConnectedBrokers = connectedBrokers
 
 	return session, nil
 }
@@ -170,18 +164,12 @@ func (r *SessionRepo) GetUserSession(userID string) (*models.UserSession, error)
 
 	// Get broker connections
 	brokerRepo := NewBrokerCredentialsRepo(r.db)
-	zerodhaConnected, err := brokerRepo.HasCredentials(session.UserID, models.PlatformZerodha)
+	connectedBrokers, err := brokerRepo.ListBrokerTypes(session.UserID)
 	if err != nil {
 		return nil, err
 	}
-	
-	iciciConnected, err := brokerRepo.HasCredentials(session.UserID, models.PlatformICICIDirect)
-	if err != nil {
-		return nil, err
-	}
-	
-	session.ZerodhaConnected = zerodhaConnected
-	session.ICICIConnected = iciciConnected
+
+	session.ConnectedBrokers = connectedBrokers
 
 	return session, nil
 }
@@ -0,0 +1,249 @@
+// Package cas imports holdings from the Consolidated Account Statement (CAS)
+// PDFs published monthly by the depositories (CDSL and NSDL).
+package cas
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/instruments"
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/ledongthuc/pdf"
+)
+
+// Common errors
+var (
+	ErrInvalidPassword = errors.New("invalid CAS password")
+	ErrInvalidFile     = errors.New("file is not a readable CAS PDF")
+	ErrNoHoldings      = errors.New("no holdings found in CAS")
+)
+
+var (
+	// isinPattern matches Indian ISINs, e.g. INE002A01018 (equity) or INF179K01VY8 (mutual fund)
+	isinPattern = regexp.MustCompile(`^IN[A-Z0-9]{10}$`)
+
+	// statementDatePattern matches the statement date, e.g. "for the period from 01-Sep-2025 to 30-Sep-2025" or "as on 30-Sep-2025"
+	statementDatePattern = regexp.MustCompile(`(?i)(?:\bto|\bas on)\s+(\d{1,2}-[A-Za-z]{3}-\d{4})`)
+
+	// folioPattern matches mutual fund folio numbers, e.g. 12345678/90
+	folioPattern = regexp.MustCompile(`^(?i:folio(?:\s*no\.?)?[:\s]*)?[0-9]+(?:/[0-9A-Z]+)?$`)
+)
+
+// Statement represents the holdings parsed from a CAS
+type Statement struct {
+	// Date is the date the holdings are valued at
+	Date time.Time
+
+	// Holdings are the equity and mutual fund holdings across all accounts in the statement
+	Holdings []models.Holding
+}
+
+// Parse reads a password-protected CAS PDF and returns the holdings it contains.
+// The password is the PAN of the first holder in upper case, as set by the depositories.
+func Parse(r io.ReaderAt, size int64, password string) (*Statement, error) {
+	rows, err := extractRows(r, size, password)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRows(rows)
+}
+
+// ParseRows parses the text rows of a CAS, where each row is the list of text cells on a line
+func ParseRows(rows [][]string) (*Statement, error) {
+	statement := &Statement{}
+
+	for _, cells := range rows {
+		cells = compact(cells)
+		if len(cells) == 0 {
+			continue
+		}
+
+		if statement.Date.IsZero() {
+			if date, ok := parseStatementDate(strings.Join(cells, " ")); ok {
+				statement.Date = date
+				continue
+			}
+		}
+
+		if holding, ok := parseHoldingRow(cells); ok {
+			statement.Holdings = append(statement.Holdings, holding)
+		}
+	}
+
+	if len(statement.Holdings) == 0 {
+		return nil, ErrNoHoldings
+	}
+
+	if statement.Date.IsZero() {
+		statement.Date = time.Now()
+	}
+	for i := range statement.Holdings {
+		statement.Holdings[i].LastUpdated = statement.Date
+	}
+
+	return statement, nil
+}
+
+// extractRows decrypts the PDF and returns the text cells of every line in page order
+func extractRows(r io.ReaderAt, size int64, password string) (rows [][]string, err error) {
+	// The PDF reader panics on some malformed input
+	defer func() {
+		if rec := recover(); rec != nil {
+			rows = nil
+			err = fmt.Errorf("%w: %v", ErrInvalidFile, rec)
+		}
+	}()
+
+	attempts := 0
+	reader, err := pdf.NewReaderEncrypted(r, size, func() string {
+		// The reader keeps asking until the callback returns an empty password
+		attempts++
+		if attempts > 1 {
+			return ""
+		}
+		return password
+	})
+	if err != nil {
+		if errors.Is(err, pdf.ErrInvalidPassword) {
+			return nil, ErrInvalidPassword
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		pageRows, err := page.GetTextByRow()
+		if err != nil {
+			return nil, fmt.Errorf("%w: page %d: %v", ErrInvalidFile, i, err)
+		}
+		for _, row := range pageRows {
+			cells := make([]string, 0, len(row.Content))
+			for _, text := range row.Content {
+				cells = append(cells, text.S)
+			}
+			rows = append(rows, cells)
+		}
+	}
+
+	return rows, nil
+}
+
+// parseStatementDate extracts the statement date from a header line
+func parseStatementDate(line string) (time.Time, bool) {
+	match := statementDatePattern.FindStringSubmatch(line)
+	if match == nil {
+		return time.Time{}, false
+	}
+	date, err := time.Parse("02-Jan-2006", normalizeDate(match[1]))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// parseHoldingRow parses a holding line of the form
+// ISIN | Name | [Folio] | Quantity | Price | Value
+func parseHoldingRow(cells []string) (models.Holding, bool) {
+	// The ISIN and name may share a cell
+	first := strings.Fields(cells[0])
+	if len(first) == 0 || !isinPattern.MatchString(first[0]) {
+		return models.Holding{}, false
+	}
+	isin := first[0]
+	rest := cells[1:]
+	if len(first) > 1 {
+		rest = append([]string{strings.Join(first[1:], " ")}, rest...)
+	}
+
+	// The last three cells are quantity, price and value
+	if len(rest) < 4 {
+		return models.Holding{}, false
+	}
+	numbers := make([]float64, 3)
+	for i, cell := range rest[len(rest)-3:] {
+		n, ok := parseAmount(cell)
+		if !ok {
+			return models.Holding{}, false
+		}
+		numbers[i] = n
+	}
+	quantity, price, value := numbers[0], numbers[1], numbers[2]
+	if quantity <= 0 {
+		return models.Holding{}, false
+	}
+
+	var nameParts []string
+	for _, cell := range rest[:len(rest)-3] {
+		if folioPattern.MatchString(cell) {
+			continue
+		}
+		nameParts = append(nameParts, cell)
+	}
+	if len(nameParts) == 0 {
+		return models.Holding{}, false
+	}
+
+	if value == 0 {
+		value = quantity * price
+	}
+
+	name := strings.Join(nameParts, " ")
+	return models.Holding{
+		ItemName:        name,
+		ISIN:            isin,
+		Quantity:        quantity,
+		LastTradedPrice: price,
+		CurrentValue:    value,
+		Platform:        models.PlatformCAS,
+		Type:            instruments.ClassifyHolding(isin, name, false),
+	}, true
+}
+
+// parseAmount parses a number in Indian digit grouping, e.g. "2,27,802.60"
+func parseAmount(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "Rs.")
+	s = strings.TrimPrefix(s, "₹")
+	s = strings.ReplaceAll(s, ",", "")
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// normalizeDate upper-cases the first letter of the month so that e.g. "30-SEP-2025" parses
+func normalizeDate(date string) string {
+	parts := strings.Split(date, "-")
+	if len(parts) != 3 {
+		return date
+	}
+	month := strings.ToLower(parts[1])
+	parts[1] = strings.ToUpper(month[:1]) + month[1:]
+	return strings.Join(parts, "-")
+}
+
+// compact trims the cells of a row and drops empty ones
+func compact(cells []string) []string {
+	result := cells[:0:0]
+	for _, cell := range cells {
+		cell = strings.TrimSpace(string(bytes.ReplaceAll([]byte(cell), []byte{0xa0}, []byte{' '})))
+		if cell != "" {
+			result = append(result, cell)
+		}
+	}
+	return result
+}
@@ -0,0 +1,147 @@
+package cas
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const samplePassword = "ABCDE1234F"
+
+func openSample(t *testing.T) *bytes.Reader {
+	t.Helper()
+	data, err := os.ReadFile("testdata/sample_cas.pdf")
+	assert.NoError(t, err)
+	return bytes.NewReader(data)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{
+			name:     "correct password",
+			password: samplePassword,
+		},
+		{
+			name:     "wrong password",
+			password: "WRONG1234X",
+			wantErr:  ErrInvalidPassword,
+		},
+		{
+			name:     "missing password",
+			password: "",
+			wantErr:  ErrInvalidPassword,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := openSample(t)
+			statement, err := Parse(r, r.Size(), tt.password)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, statement)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, time.Date(2025, 9, 30, 0, 0, 0, 0, time.UTC), statement.Date)
+			assert.Len(t, statement.Holdings, 5)
+
+			reliance := statement.Holdings[0]
+			assert.Equal(t, "RELIANCE INDUSTRIES LIMITED", reliance.ItemName)
+			assert.Equal(t, "INE002A01018", reliance.ISIN)
+			assert.Equal(t, 50.0, reliance.Quantity)
+			assert.Equal(t, 1378.20, reliance.LastTradedPrice)
+			assert.Equal(t, 68910.0, reliance.CurrentValue)
+			assert.Equal(t, models.HoldingTypeStock, reliance.Type)
+
+			// Indian digit grouping is handled
+			assert.Equal(t, 144570.0, statement.Holdings[2].CurrentValue)
+
+			// Folio numbers are not part of the scheme name
+			hdfc := statement.Holdings[3]
+			assert.Equal(t, "HDFC Flexi Cap Fund - Direct Plan - Growth", hdfc.ItemName)
+			assert.Equal(t, "INF179K01VY8", hdfc.ISIN)
+			assert.Equal(t, 152.347, hdfc.Quantity)
+			assert.Equal(t, 2112.58, hdfc.LastTradedPrice)
+			assert.Equal(t, 321847.22, hdfc.CurrentValue)
+			assert.Equal(t, models.HoldingTypeMutualFund, hdfc.Type)
+
+			for _, holding := range statement.Holdings {
+				assert.Equal(t, models.PlatformCAS, holding.Platform)
+				assert.Equal(t, statement.Date, holding.LastUpdated)
+			}
+		})
+	}
+}
+
+func TestParseInvalidFile(t *testing.T) {
+	r := bytes.NewReader([]byte("not a pdf"))
+	_, err := Parse(r, r.Size(), samplePassword)
+	assert.ErrorIs(t, err, ErrInvalidFile)
+}
+
+func TestParseRows(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     [][]string
+		wantErr  error
+		wantLen  int
+		wantDate time.Time
+	}{
+		{
+			name: "CDSL layout with as on date",
+			rows: [][]string{
+				{"CONSOLIDATED ACCOUNT STATEMENT"},
+				{"Holdings as on 31-OCT-2025"},
+				{"INE040A01034 HDFC BANK LTD", "25.000", "1,002.45", "25,061.25"},
+				{"INE040A01034", "HDFC BANK LTD", "Free", "25", "1002.45", "not a number"},
+			},
+			wantLen:  1,
+			wantDate: time.Date(2025, 10, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "value derived when missing",
+			rows: [][]string{
+				{"INF846K01EW2", "Axis ELSS Tax Saver Fund", "Folio No: 9104567", "10", "95.5", "0"},
+			},
+			wantLen: 1,
+		},
+		{
+			name: "no holdings",
+			rows: [][]string{
+				{"Statement for the period from 01-Sep-2025 to 30-Sep-2025"},
+				{"ISIN", "Security", "Current Bal.", "Market Price", "Value"},
+			},
+			wantErr: ErrNoHoldings,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement, err := ParseRows(tt.rows)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, statement.Holdings, tt.wantLen)
+			if !tt.wantDate.IsZero() {
+				assert.Equal(t, tt.wantDate, statement.Date)
+			}
+			for _, holding := range statement.Holdings {
+				assert.NotEmpty(t, holding.ItemName)
+				assert.InDelta(t, holding.Quantity*holding.LastTradedPrice, holding.CurrentValue, 0.01)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+// Package corporateaction imports stock splits and bonus issues from the corporate actions
+// CSV that NSE publishes, or from a plain CSV listing them by ISIN.
+package corporateaction
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// Common errors
+var (
+	ErrInvalidFile   = errors.New("file is not a readable CSV")
+	ErrUnknownFormat = errors.New("unrecognized corporate actions format")
+	ErrInvalidRow    = errors.New("invalid corporate action row")
+	ErrNoActions     = errors.New("no splits or bonus issues found in file")
+)
+
+// Action is a split or bonus issue read from a file
+type Action struct {
+	models.CorporateAction
+
+	// Symbol is the NSE symbol the action was announced under; files that list it by symbol leave the ISIN empty
+	Symbol string
+}
+
+// Header columns of the NSE corporate actions export:
+// SYMBOL,COMPANY NAME,SERIES,PURPOSE,FACE VALUE,EX-DATE,RECORD DATE,BOOK CLOSURE START DATE,BOOK CLOSURE END DATE
+const (
+	nseSymbolColumn  = "symbol"
+	nseSeriesColumn  = "series"
+	nsePurposeColumn = "purpose"
+	nseExDateColumn  = "ex-date"
+)
+
+// Header columns of the plain format: isin,type,ex_date,numerator,denominator
+const (
+	isinColumn        = "isin"
+	typeColumn        = "type"
+	exDateColumn      = "ex_date"
+	numeratorColumn   = "numerator"
+	denominatorColumn = "denominator"
+)
+
+// Purposes NSE announces splits and bonus issues with, such as "Bonus 1:2" and
+// "Face Value Split (Sub-Division) - From Rs 10/- Per Share To Rs 2/- Per Share"
+var (
+	bonusPattern = regexp.MustCompile(`(?i)\bbonus\s*(\d+(?:\.\d+)?)\s*:\s*(\d+(?:\.\d+)?)`)
+	splitPattern = regexp.MustCompile(`(?i)\bsplit\b.*?\bfrom\s+r[se]\.?\s*(\d+(?:\.\d+)?).*?\bto\s+r[se]\.?\s*(\d+(?:\.\d+)?)`)
+)
+
+// dateLayouts are the ex-date layouts accepted
+var dateLayouts = []string{"02-Jan-2006", "2006-01-02", "02-01-2006", "02/01/2006"}
+
+// Parse reads a corporate actions CSV, detecting its format from the header row.
+// Rows of the NSE export announcing anything other than a split or bonus, such as dividends, are skipped.
+func Parse(r io.Reader) ([]Action, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	if len(records) == 0 {
+		return nil, ErrUnknownFormat
+	}
+
+	header := newColumns(records[0])
+	var parse func(record []string) ([]Action, error)
+	switch {
+	case header.has(nseSymbolColumn, nsePurposeColumn, nseExDateColumn):
+		parse = header.parseNSE
+	case header.has(isinColumn, typeColumn, exDateColumn, numeratorColumn, denominatorColumn):
+		parse = header.parsePlain
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	var actions []Action
+	for i, record := range records[1:] {
+		if isBlank(record) {
+			continue
+		}
+		parsed, err := parse(record)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidRow, i+2, err)
+		}
+		actions = append(actions, parsed...)
+	}
+
+	if len(actions) == 0 {
+		return nil, ErrNoActions
+	}
+	return actions, nil
+}
+
+// parseNSE reads the splits and bonus issues a row of the NSE export announces
+func (c columns) parseNSE(record []string) ([]Action, error) {
+	// Only equity shares are adjusted; other series of the same symbol carry the same announcement
+	if series := strings.ToUpper(c.get(record, nseSeriesColumn)); series != "" && series != "EQ" && series != "BE" {
+		return nil, nil
+	}
+
+	purpose := c.get(record, nsePurposeColumn)
+	bonus := bonusPattern.FindStringSubmatch(purpose)
+	split := splitPattern.FindStringSubmatch(purpose)
+	if bonus == nil && split == nil {
+		return nil, nil
+	}
+
+	symbol := strings.ToUpper(c.get(record, nseSymbolColumn))
+	if symbol == "" {
+		return nil, fmt.Errorf("missing symbol")
+	}
+	exDate, err := parseDate(c.get(record, nseExDateColumn))
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []Action
+	add := func(actionType models.CorporateActionType, numerator, denominator string) error {
+		action, err := newAction(actionType, exDate, numerator, denominator)
+		if err != nil {
+			return err
+		}
+		actions = append(actions, Action{CorporateAction: action, Symbol: symbol})
+		return nil
+	}
+	if split != nil {
+		// Shares issued per share held is the old face value over the new one
+		if err := add(models.CorporateActionSplit, split[1], split[2]); err != nil {
+			return nil, err
+		}
+	}
+	if bonus != nil {
+		if err := add(models.CorporateActionBonus, bonus[1], bonus[2]); err != nil {
+			return nil, err
+		}
+	}
+	return actions, nil
+}
+
+// parsePlain reads a row of the plain format
+func (c columns) parsePlain(record []string) ([]Action, error) {
+	isin := strings.ToUpper(c.get(record, isinColumn))
+	if isin == "" {
+		return nil, fmt.Errorf("missing isin")
+	}
+
+	actionType := models.CorporateActionType(strings.ToLower(c.get(record, typeColumn)))
+	if actionType != models.CorporateActionSplit && actionType != models.CorporateActionBonus {
+		return nil, fmt.Errorf("invalid type %q", c.get(record, typeColumn))
+	}
+	exDate, err := parseDate(c.get(record, exDateColumn))
+	if err != nil {
+		return nil, err
+	}
+
+	action, err := newAction(actionType, exDate, c.get(record, numeratorColumn), c.get(record, denominatorColumn))
+	if err != nil {
+		return nil, err
+	}
+	action.ISIN = isin
+	return []Action{{CorporateAction: action}}, nil
+}
+
+// newAction builds an action from the text of its ratio
+func newAction(actionType models.CorporateActionType, exDate time.Time, numerator, denominator string) (models.CorporateAction, error) {
+	n, err := strconv.ParseFloat(strings.TrimSpace(numerator), 64)
+	if err != nil || n <= 0 {
+		return models.CorporateAction{}, fmt.Errorf("invalid ratio %s:%s", numerator, denominator)
+	}
+	d, err := strconv.ParseFloat(strings.TrimSpace(denominator), 64)
+	if err != nil || d <= 0 {
+		return models.CorporateAction{}, fmt.Errorf("invalid ratio %s:%s", numerator, denominator)
+	}
+	return models.CorporateAction{Type: actionType, ExDate: exDate, Numerator: n, Denominator: d}, nil
+}
+
+// parseDate parses an ex-date in any of the accepted layouts
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid ex-date %q", value)
+}
+
+// columns maps normalized header names to their column index
+type columns map[string]int
+
+// newColumns indexes a header row
+func newColumns(record []string) columns {
+	header := make(columns, len(record))
+	for i, name := range record {
+		name = strings.TrimPrefix(name, "\ufeff")
+		header[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	return header
+}
+
+// has reports whether all the named columns are present
+func (c columns) has(names ...string) bool {
+	for _, name := range names {
+		if _, ok := c[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// get returns the value of a column in the record
+func (c columns) get(record []string, name string) string {
+	if i, ok := c[name]; ok && i < len(record) {
+		return strings.TrimSpace(record[i])
+	}
+	return ""
+}
+
+// isBlank reports whether a record has no content
+func isBlank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package corporateaction
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNSE(t *testing.T) {
+	f, err := os.Open("testdata/nse_corporate_actions.csv")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	actions, err := Parse(f)
+	assert.NoError(t, err)
+	if assert.Len(t, actions, 3, "dividends and other series are skipped") {
+		assert.Equal(t, Action{
+			Symbol: "HDFCBANK",
+			CorporateAction: models.CorporateAction{
+				Type:        models.CorporateActionSplit,
+				ExDate:      time.Date(2019, 9, 19, 0, 0, 0, 0, time.UTC),
+				Numerator:   2,
+				Denominator: 1,
+			},
+		}, actions[0])
+		assert.Equal(t, models.CorporateActionBonus, actions[1].Type)
+		assert.Equal(t, 1.0, actions[1].Ratio())
+		assert.Equal(t, "RELIANCE", actions[1].Symbol)
+		assert.Equal(t, 10.0, actions[2].Ratio())
+		assert.Empty(t, actions[2].ISIN)
+	}
+}
+
+func TestParsePlain(t *testing.T) {
+	actions, err := Parse(strings.NewReader("isin,type,ex_date,numerator,denominator\nine009a01021,Bonus,2018-09-04,1,1\n"))
+	assert.NoError(t, err)
+	if assert.Len(t, actions, 1) {
+		assert.Equal(t, "INE009A01021", actions[0].ISIN)
+		assert.Equal(t, models.CorporateActionBonus, actions[0].Type)
+	}
+
+	_, err = Parse(strings.NewReader("isin,type,ex_date,numerator,denominator\nINE009A01021,merger,2018-09-04,1,1\n"))
+	assert.ErrorIs(t, err, ErrInvalidRow)
+
+	_, err = Parse(strings.NewReader("isin,type,ex_date,numerator,denominator\nINE009A01021,split,2018-09-04,0,1\n"))
+	assert.ErrorIs(t, err, ErrInvalidRow)
+
+	_, err = Parse(strings.NewReader("symbol,price\nINFY,1500\n"))
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+
+	_, err = Parse(strings.NewReader("SYMBOL,SERIES,PURPOSE,EX-DATE\nINFY,EQ,Dividend - Rs 21 Per Share,25-Oct-2024\n"))
+	assert.ErrorIs(t, err, ErrNoActions)
+}
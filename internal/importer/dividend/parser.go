@@ -0,0 +1,223 @@
+// Package dividend imports the dividends a user received from broker dividend statements
+// (Zerodha Console) or a plain CSV listing them by platform.
+package dividend
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// Common errors
+var (
+	ErrInvalidFile   = errors.New("file is not a readable CSV")
+	ErrUnknownFormat = errors.New("unrecognized dividend statement format")
+	ErrInvalidRow    = errors.New("invalid dividend row")
+	ErrNoDividends   = errors.New("no dividends found in statement")
+)
+
+// maxHeaderScan is how many leading lines may precede the header row
+const maxHeaderScan = 20
+
+// format parses the dividend statement layout of a single source
+type format interface {
+	// detect reports whether the header row belongs to the format
+	detect(header columns) bool
+
+	// parse converts a data row into a dividend
+	parse(header columns, record []string) (models.Dividend, error)
+}
+
+// formats lists the supported layouts in detection order
+var formats = []format{
+	zerodhaFormat{},
+	plainFormat{},
+}
+
+// zerodhaFormat parses the equity dividends statement downloaded from Zerodha Console:
+// Symbol,ISIN,Ex-Date,Quantity,Dividend Per Share,Net Dividend Amount.
+// The statement gives no payment date, so dividends are dated on their ex-date.
+type zerodhaFormat struct{}
+
+func (zerodhaFormat) detect(header columns) bool {
+	return header.has("symbol", "ex-date", "net dividend amount")
+}
+
+func (zerodhaFormat) parse(header columns, record []string) (models.Dividend, error) {
+	paidOn, err := parseDate(header.get(record, "ex-date"))
+	if err != nil {
+		return models.Dividend{}, err
+	}
+	amount, err := parseAmount(header.get(record, "net dividend amount"))
+	if err != nil {
+		return models.Dividend{}, err
+	}
+
+	return models.Dividend{
+		ISIN:     strings.ToUpper(header.get(record, "isin")),
+		Symbol:   header.get(record, "symbol"),
+		Platform: models.PlatformZerodha,
+		PaidOn:   paidOn,
+		Amount:   amount,
+	}, nil
+}
+
+// plainFormat parses a CSV with platform,symbol,isin,paid_on,amount columns, the isin being optional
+type plainFormat struct{}
+
+func (plainFormat) detect(header columns) bool {
+	return header.has("platform", "symbol", "paid_on", "amount")
+}
+
+func (plainFormat) parse(header columns, record []string) (models.Dividend, error) {
+	platform := strings.ToLower(header.get(record, "platform"))
+	if !platforms[platform] {
+		return models.Dividend{}, fmt.Errorf("invalid platform %q", header.get(record, "platform"))
+	}
+	paidOn, err := parseDate(header.get(record, "paid_on"))
+	if err != nil {
+		return models.Dividend{}, err
+	}
+	amount, err := parseAmount(header.get(record, "amount"))
+	if err != nil {
+		return models.Dividend{}, err
+	}
+
+	return models.Dividend{
+		ISIN:     strings.ToUpper(header.get(record, "isin")),
+		Symbol:   header.get(record, "symbol"),
+		Platform: platform,
+		PaidOn:   paidOn,
+		Amount:   amount,
+	}, nil
+}
+
+// platforms lists the platforms dividends may be recorded against
+var platforms = map[string]bool{
+	models.PlatformICICIDirect: true,
+	models.PlatformZerodha:     true,
+	models.PlatformUpstox:      true,
+	models.PlatformAngelOne:    true,
+	models.PlatformCAS:         true,
+}
+
+// Parse reads a dividend statement CSV, detecting its layout from the header row
+func Parse(r io.Reader) ([]models.Dividend, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	// Statements may put a title or account details above the header row
+	var (
+		header  columns
+		matched format
+		start   int
+	)
+	for i := 0; i < len(records) && i < maxHeaderScan && matched == nil; i++ {
+		candidate := newColumns(records[i])
+		for _, f := range formats {
+			if f.detect(candidate) {
+				header, matched, start = candidate, f, i+1
+				break
+			}
+		}
+	}
+	if matched == nil {
+		return nil, ErrUnknownFormat
+	}
+
+	var dividends []models.Dividend
+	for i, record := range records[start:] {
+		if isBlank(record) {
+			continue
+		}
+		dividend, err := matched.parse(header, record)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidRow, start+i+1, err)
+		}
+		if dividend.Symbol == "" {
+			return nil, fmt.Errorf("%w: line %d: missing symbol", ErrInvalidRow, start+i+1)
+		}
+		dividends = append(dividends, dividend)
+	}
+
+	if len(dividends) == 0 {
+		return nil, ErrNoDividends
+	}
+	return dividends, nil
+}
+
+// columns maps normalized header names to their column index
+type columns map[string]int
+
+// newColumns indexes a header row
+func newColumns(record []string) columns {
+	header := make(columns, len(record))
+	for i, name := range record {
+		name = strings.TrimPrefix(name, "\ufeff")
+		header[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	return header
+}
+
+// has reports whether all the named columns are present
+func (c columns) has(names ...string) bool {
+	for _, name := range names {
+		if _, ok := c[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// get returns the value of a column in the record
+func (c columns) get(record []string, name string) string {
+	if i, ok := c[name]; ok && i < len(record) {
+		return strings.TrimSpace(record[i])
+	}
+	return ""
+}
+
+// parseAmount parses a positive amount that may contain digit grouping commas
+func parseAmount(value string) (float64, error) {
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+	if err != nil || amount <= 0 {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
+
+// parseDate parses a date in any of the layouts statements use, interpreting it in IST
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "02-01-2006", "02/01/2006", "02-Jan-2006"} {
+		if t, err := time.ParseInLocation(layout, value, ist); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// ist is Indian Standard Time, the time zone statement dates are recorded in
+var ist = time.FixedZone("IST", 5*60*60+30*60)
+
+// isBlank reports whether a record has no content
+func isBlank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package dividend
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseZerodha(t *testing.T) {
+	f, err := os.Open("testdata/zerodha_dividends.csv")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	dividends, err := Parse(f)
+	assert.NoError(t, err)
+	if assert.Len(t, dividends, 3) {
+		assert.Equal(t, models.Dividend{
+			ISIN:     "INE154A01025",
+			Symbol:   "ITC",
+			Platform: models.PlatformZerodha,
+			PaidOn:   time.Date(2024, 6, 4, 0, 0, 0, 0, ist),
+			Amount:   750,
+		}, dividends[0])
+		assert.Equal(t, 292.5, dividends[2].Amount)
+	}
+}
+
+func TestParsePlain(t *testing.T) {
+	dividends, err := Parse(strings.NewReader("platform,symbol,isin,paid_on,amount\nUpstox,TCS,,2024-07-19,\"1,200\"\n"))
+	assert.NoError(t, err)
+	if assert.Len(t, dividends, 1) {
+		assert.Equal(t, models.PlatformUpstox, dividends[0].Platform)
+		assert.Empty(t, dividends[0].ISIN)
+		assert.Equal(t, 1200.0, dividends[0].Amount)
+	}
+
+	_, err = Parse(strings.NewReader("platform,symbol,isin,paid_on,amount\nrobinhood,TCS,,2024-07-19,100\n"))
+	assert.ErrorIs(t, err, ErrInvalidRow)
+
+	_, err = Parse(strings.NewReader("platform,symbol,isin,paid_on,amount\nzerodha,TCS,,2024-07-19,0\n"))
+	assert.ErrorIs(t, err, ErrInvalidRow)
+
+	_, err = Parse(strings.NewReader("platform,symbol,isin,paid_on,amount\n"))
+	assert.ErrorIs(t, err, ErrNoDividends)
+
+	_, err = Parse(strings.NewReader("symbol,price\nINFY,1500\n"))
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -0,0 +1,67 @@
+package tradebook
+
+import (
+	"strings"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// iciciDirectFormat parses the equity trade book downloaded from ICICI Direct:
+// Date,Stock,Action,Qty,Price,Trade Value,Order Ref.,Settlement,Segment,DP Id - Client DP Id,Exchange,
+// STT,Transaction and SEBI Turnover charges,Stamp Duty,Brokerage + Service Tax,Brokerage incl. of taxes
+type iciciDirectFormat struct{}
+
+func (iciciDirectFormat) platform() string {
+	return models.PlatformICICIDirect
+}
+
+func (iciciDirectFormat) detect(header columns) bool {
+	return header.has("date", "stock", "action", "qty", "price")
+}
+
+func (iciciDirectFormat) parse(header columns, record []string) (models.Trade, error) {
+	side, err := parseSide(header.get(record, "action"))
+	if err != nil {
+		return models.Trade{}, err
+	}
+	quantity, err := parseNumber("quantity", header.get(record, "qty"))
+	if err != nil {
+		return models.Trade{}, err
+	}
+	price, err := parseNumber("price", header.get(record, "price"))
+	if err != nil {
+		return models.Trade{}, err
+	}
+	tradeDate, err := parseDate(header.get(record, "date"), "02-Jan-2006", "02-01-2006", "02/01/2006", "2006-01-02", "02-Jan-06")
+	if err != nil {
+		return models.Trade{}, err
+	}
+
+	// Statutory charges plus brokerage; older exports only carry brokerage before taxes
+	var charges float64
+	for _, names := range [][]string{
+		{"stt"},
+		{"transaction and sebi turnover charges"},
+		{"stamp duty"},
+		{"brokerage incl. of taxes", "brokerage + service tax"},
+	} {
+		charge, err := parseNumber(names[0], header.get(record, names...))
+		if err != nil {
+			return models.Trade{}, err
+		}
+		charges += charge
+	}
+
+	return models.Trade{
+		TradeID:   header.get(record, "trade id", "trade no.", "trade no"),
+		OrderID:   header.get(record, "order ref.", "order ref", "order reference"),
+		ISIN:      strings.ToUpper(header.get(record, "isin", "isin code")),
+		Symbol:    header.get(record, "stock"),
+		Exchange:  header.get(record, "exchange"),
+		Side:      side,
+		Quantity:  quantity,
+		Price:     price,
+		Charges:   charges,
+		TradeDate: tradeDate,
+	}, nil
+}
@@ -0,0 +1,217 @@
+// Package tradebook imports executed trades from the tradebook CSVs that
+// brokers let users download (Zerodha Console, ICICI Direct).
+package tradebook
+
+import (
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// Common errors
+var (
+	ErrInvalidFile   = errors.New("file is not a readable CSV")
+	ErrUnknownFormat = errors.New("unrecognized tradebook format")
+	ErrInvalidRow    = errors.New("invalid tradebook row")
+	ErrNoTrades      = errors.New("no trades found in tradebook")
+)
+
+// maxHeaderScan is how many leading lines may precede the header row
+const maxHeaderScan = 20
+
+// Tradebook represents the trades parsed from a tradebook file
+type Tradebook struct {
+	// Platform is the broker the tradebook was detected as
+	Platform string
+
+	// Trades are the executed trades in file order
+	Trades []models.Trade
+}
+
+// format parses the tradebook layout of a single broker
+type format interface {
+	// platform returns the broker the format belongs to
+	platform() string
+
+	// detect reports whether the header row belongs to the format
+	detect(header columns) bool
+
+	// parse converts a data row into a trade
+	parse(header columns, record []string) (models.Trade, error)
+}
+
+// formats lists the supported tradebook layouts in detection order
+var formats = []format{
+	zerodhaFormat{},
+	iciciDirectFormat{},
+}
+
+// Parse reads a tradebook CSV, detecting the broker from its header row
+func Parse(r io.Reader) (*Tradebook, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	// Some exports put a title or account details above the header row
+	var (
+		header  columns
+		matched format
+		start   int
+	)
+	for i := 0; i < len(records) && i < maxHeaderScan && matched == nil; i++ {
+		candidate := newColumns(records[i])
+		for _, f := range formats {
+			if f.detect(candidate) {
+				header, matched, start = candidate, f, i+1
+				break
+			}
+		}
+	}
+	if matched == nil {
+		return nil, ErrUnknownFormat
+	}
+
+	tradebook := &Tradebook{Platform: matched.platform()}
+	occurrences := make(map[string]int)
+	for i, record := range records[start:] {
+		if isBlank(record) {
+			continue
+		}
+		trade, err := matched.parse(header, record)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidRow, start+i+1, err)
+		}
+		trade.Platform = matched.platform()
+
+		// Tradebooks without trade numbers get a stable ID derived from the row so that re-uploads dedupe
+		if trade.TradeID == "" {
+			trade.TradeID = syntheticTradeID(matched.platform(), record, occurrences)
+		}
+
+		tradebook.Trades = append(tradebook.Trades, trade)
+	}
+
+	if len(tradebook.Trades) == 0 {
+		return nil, ErrNoTrades
+	}
+
+	return tradebook, nil
+}
+
+// syntheticTradeID hashes the row contents. Identical rows, such as two equal fills of one order,
+// are told apart by their occurrence count within the file.
+func syntheticTradeID(platform string, record []string, occurrences map[string]int) string {
+	fields := make([]string, len(record))
+	for i, field := range record {
+		fields[i] = strings.TrimSpace(field)
+	}
+	sum := sha1.Sum([]byte(strings.Join(fields, "|")))
+	id := platform + "-" + hex.EncodeToString(sum[:10])
+
+	occurrences[id]++
+	if n := occurrences[id]; n > 1 {
+		id = fmt.Sprintf("%s-%d", id, n)
+	}
+	return id
+}
+
+// columns maps normalized header names to their column index
+type columns map[string]int
+
+// newColumns indexes a header row
+func newColumns(record []string) columns {
+	header := make(columns, len(record))
+	for i, name := range record {
+		header[normalizeHeader(name)] = i
+	}
+	return header
+}
+
+// has reports whether all the named columns are present
+func (c columns) has(names ...string) bool {
+	for _, name := range names {
+		if _, ok := c[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// get returns the value of the first named column present in the record
+func (c columns) get(record []string, names ...string) string {
+	for _, name := range names {
+		if i, ok := c[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+	}
+	return ""
+}
+
+// normalizeHeader lower-cases a header and strips the byte order mark some exports start with
+func normalizeHeader(name string) string {
+	name = strings.TrimPrefix(name, "\ufeff")
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// parseSide parses a buy/sell indicator
+func parseSide(value string) (models.TradeSide, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "buy", "b":
+		return models.TradeSideBuy, nil
+	case "sell", "s":
+		return models.TradeSideSell, nil
+	default:
+		return "", fmt.Errorf("invalid trade side %q", value)
+	}
+}
+
+// parseNumber parses a number that may contain digit grouping commas
+func parseNumber(field, value string) (float64, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", "")
+	if value == "" || value == "-" {
+		return 0, nil
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", field, value)
+	}
+	return n, nil
+}
+
+// parseDate parses a date in any of the given layouts, interpreting it in IST
+func parseDate(value string, layouts ...string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, ist); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid trade date %q", value)
+}
+
+// ist is Indian Standard Time, the time zone tradebook dates are recorded in
+var ist = time.FixedZone("IST", 5*60*60+30*60)
+
+// isBlank reports whether a record has no content
+func isBlank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+package tradebook
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseFile(t *testing.T, name string) (*Tradebook, error) {
+	t.Helper()
+	f, err := os.Open(name)
+	assert.NoError(t, err)
+	defer f.Close()
+	return Parse(f)
+}
+
+func TestParseZerodha(t *testing.T) {
+	tradebook, err := parseFile(t, "testdata/zerodha_tradebook.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, models.PlatformZerodha, tradebook.Platform)
+	assert.Len(t, tradebook.Trades, 4)
+
+	first := tradebook.Trades[0]
+	assert.Equal(t, "50511374", first.TradeID)
+	assert.Equal(t, "1300000009245826", first.OrderID)
+	assert.Equal(t, "INE009A01021", first.ISIN)
+	assert.Equal(t, "INFY", first.Symbol)
+	assert.Equal(t, "NSE", first.Exchange)
+	assert.Equal(t, models.TradeSideBuy, first.Side)
+	assert.Equal(t, 10.0, first.Quantity)
+	assert.Equal(t, 1390.5, first.Price)
+	assert.Equal(t, 0.0, first.Charges)
+	assert.Equal(t, time.Date(2023, 4, 12, 9, 21, 7, 0, ist), first.TradeDate)
+	assert.Equal(t, models.PlatformZerodha, first.Platform)
+
+	sell := tradebook.Trades[3]
+	assert.Equal(t, models.TradeSideSell, sell.Side)
+	assert.Equal(t, 8.0, sell.Quantity)
+}
+
+func TestParseICICIDirect(t *testing.T) {
+	tradebook, err := parseFile(t, "testdata/icici_direct_tradebook.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, models.PlatformICICIDirect, tradebook.Platform)
+	assert.Len(t, tradebook.Trades, 3)
+
+	first := tradebook.Trades[0]
+	assert.Equal(t, "RELIND", first.Symbol)
+	assert.Equal(t, "20240103N100012345", first.OrderID)
+	assert.Equal(t, models.TradeSideBuy, first.Side)
+	assert.Equal(t, 5.0, first.Quantity)
+	assert.Equal(t, 2580.40, first.Price)
+	assert.InDelta(t, 13.00+0.42+1.94+41.30, first.Charges, 0.0001)
+	assert.Equal(t, time.Date(2024, 1, 3, 0, 0, 0, 0, ist), first.TradeDate)
+
+	// Identical fills get distinct synthetic IDs
+	assert.NotEmpty(t, first.TradeID)
+	assert.NotEqual(t, first.TradeID, tradebook.Trades[1].TradeID)
+	assert.Equal(t, models.TradeSideSell, tradebook.Trades[2].Side)
+
+	// Re-parsing the same file yields the same IDs so re-uploads dedupe
+	again, err := parseFile(t, "testdata/icici_direct_tradebook.csv")
+	assert.NoError(t, err)
+	for i := range tradebook.Trades {
+		assert.Equal(t, tradebook.Trades[i].TradeID, again.Trades[i].TradeID)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr error
+		errText string
+	}{
+		{
+			name:    "unknown format",
+			input:   "date,description,amount\n2024-01-01,coffee,120\n",
+			wantErr: ErrUnknownFormat,
+		},
+		{
+			name:    "header only",
+			input:   "symbol,isin,trade_date,exchange,trade_type,quantity,price,trade_id\n",
+			wantErr: ErrNoTrades,
+		},
+		{
+			name:    "invalid side",
+			wantErr: ErrInvalidRow,
+			input:   "symbol,isin,trade_date,exchange,trade_type,quantity,price,trade_id\nINFY,INE009A01021,2023-04-12,NSE,hold,1,10,1\n",
+			errText: "line 2: invalid trade side",
+		},
+		{
+			name:    "invalid quantity",
+			input:   "symbol,isin,trade_date,exchange,trade_type,quantity,price,trade_id\nINFY,INE009A01021,2023-04-12,NSE,buy,ten,10,1\n",
+			errText: "invalid quantity",
+		},
+		{
+			name:    "invalid date",
+			input:   "Date,Stock,Action,Qty,Price\n2024/13/45,RELIND,Buy,1,10\n",
+			errText: "invalid trade date",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input))
+			assert.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			if tt.errText != "" {
+				assert.Contains(t, err.Error(), tt.errText)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package tradebook
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// zerodhaFormat parses the tradebook downloaded from Zerodha Console:
+// symbol,isin,trade_date,exchange,segment,series,trade_type,auction,quantity,price,trade_id,order_id,order_execution_time
+type zerodhaFormat struct{}
+
+func (zerodhaFormat) platform() string {
+	return models.PlatformZerodha
+}
+
+func (zerodhaFormat) detect(header columns) bool {
+	return header.has("symbol", "trade_date", "trade_type", "quantity", "price", "trade_id")
+}
+
+func (zerodhaFormat) parse(header columns, record []string) (models.Trade, error) {
+	tradeID := header.get(record, "trade_id")
+	if tradeID == "" {
+		return models.Trade{}, fmt.Errorf("missing trade_id")
+	}
+
+	side, err := parseSide(header.get(record, "trade_type"))
+	if err != nil {
+		return models.Trade{}, err
+	}
+	quantity, err := parseNumber("quantity", header.get(record, "quantity"))
+	if err != nil {
+		return models.Trade{}, err
+	}
+	price, err := parseNumber("price", header.get(record, "price"))
+	if err != nil {
+		return models.Trade{}, err
+	}
+
+	// Prefer the execution timestamp, falling back to the trade date
+	tradeDate, err := parseDate(header.get(record, "order_execution_time"), "2006-01-02T15:04:05", "2006-01-02 15:04:05")
+	if err != nil {
+		tradeDate, err = parseDate(header.get(record, "trade_date"), "2006-01-02", "02-01-2006", "02/01/2006")
+		if err != nil {
+			return models.Trade{}, err
+		}
+	}
+
+	return models.Trade{
+		TradeID:   tradeID,
+		OrderID:   header.get(record, "order_id"),
+		ISIN:      strings.ToUpper(header.get(record, "isin")),
+		Symbol:    header.get(record, "symbol"),
+		Exchange:  header.get(record, "exchange"),
+		Side:      side,
+		Quantity:  quantity,
+		Price:     price,
+		TradeDate: tradeDate,
+	}, nil
+}
@@ -0,0 +1,69 @@
+package instruments
+
+import (
+	"strings"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// Indian ISINs are IN, an issuer type, a four character issuer code, a two digit security type,
+// a two character serial and a check digit, e.g. INE009A01021
+const (
+	isinLength        = 12
+	isinIssuerType    = 2
+	isinSecurityStart = 7
+	isinSecurityEnd   = 9
+)
+
+// debentureSecurityTypes are the security types of company debt, i.e. secured and unsecured debentures
+var debentureSecurityTypes = map[string]bool{
+	"07": true,
+	"08": true,
+}
+
+// ClassifyHolding determines the holding type of a security from its ISIN and symbol.
+// Mutual fund units and ETFs share the INF prefix, so listed reports whether the security trades on an exchange.
+// Issuer types 0 to 8 are government securities, which are bonds unless the symbol names a sovereign gold bond.
+// Securities without an Indian ISIN are stocks unless the symbol says otherwise.
+func ClassifyHolding(isin, symbol string, listed bool) models.HoldingType {
+	isin = strings.ToUpper(strings.TrimSpace(isin))
+	if isSGBSymbol(symbol) {
+		return models.HoldingTypeSGB
+	}
+	if len(isin) != isinLength || !strings.HasPrefix(isin, "IN") {
+		if isETFSymbol(symbol) {
+			return models.HoldingTypeETF
+		}
+		return models.HoldingTypeStock
+	}
+
+	switch issuer := isin[isinIssuerType]; {
+	case issuer == 'F':
+		if listed || isETFSymbol(symbol) {
+			return models.HoldingTypeETF
+		}
+		return models.HoldingTypeMutualFund
+	case issuer >= '0' && issuer <= '8':
+		return models.HoldingTypeBond
+	case issuer == 'E' && debentureSecurityTypes[isin[isinSecurityStart:isinSecurityEnd]]:
+		return models.HoldingTypeBond
+	default:
+		return models.HoldingTypeStock
+	}
+}
+
+// isSGBSymbol reports whether a symbol or name is that of a sovereign gold bond, e.g. SGBAUG28V
+func isSGBSymbol(symbol string) bool {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	return strings.HasPrefix(symbol, "SGB") || strings.Contains(symbol, "SOVEREIGN GOLD")
+}
+
+// isETFSymbol reports whether an exchange symbol is that of an exchange traded fund, e.g. NIFTYBEES or SETFNIF50.
+// Scheme names are not matched, since fund of funds investing in ETFs are named after them.
+func isETFSymbol(symbol string) bool {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if strings.ContainsAny(symbol, " \t") {
+		return false
+	}
+	return strings.HasSuffix(symbol, "BEES") || strings.Contains(symbol, "ETF")
+}
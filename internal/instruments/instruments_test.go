@@ -0,0 +1,158 @@
+package instruments
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSources = Sources{
+	NSEEquityPath:       "testdata/EQUITY_L.csv",
+	BSEEquityPath:       "testdata/bse_equity.csv",
+	KiteInstrumentsPath: "testdata/kite_instruments.csv",
+}
+
+func loadTestStore(t *testing.T) *Store {
+	t.Helper()
+	store := NewStore()
+	assert.NoError(t, store.Load(testSources))
+	return store
+}
+
+func TestLoadExchangeLists(t *testing.T) {
+	store := loadTestStore(t)
+	// Six NSE listings plus HDFC Bank from BSE; the delisted scrip is skipped
+	assert.Equal(t, 7, store.Len())
+	assert.False(t, store.LoadedAt().IsZero())
+
+	infy, ok := store.Lookup("INE009A01021")
+	assert.True(t, ok)
+	assert.Equal(t, "INFY", infy.NSESymbol)
+	assert.Equal(t, "500209", infy.BSECode)
+	assert.Equal(t, "INFY", infy.BSESymbol)
+	assert.Equal(t, "Infosys Limited", infy.Name)
+	assert.Equal(t, "IT Consulting & Software", infy.Sector)
+	// The BSE and Kite names normalize to the NSE name and add no aliases
+	assert.Empty(t, infy.Aliases)
+	assert.Equal(t, uint32(408065), infy.KiteToken)
+
+	tcs, ok := store.Lookup("TCS")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"TATA CONSULTANCY SERV LT"}, tcs.Aliases)
+
+	hdfc, ok := store.Lookup("HDFCBANK")
+	assert.True(t, ok)
+	assert.Empty(t, hdfc.NSESymbol)
+	assert.Equal(t, "500180", hdfc.BSECode)
+	assert.Equal(t, uint32(128053508), hdfc.KiteToken)
+
+	_, ok = store.Lookup("DELISTED")
+	assert.False(t, ok)
+}
+
+func TestLookup(t *testing.T) {
+	store := loadTestStore(t)
+
+	tests := []struct {
+		identifier string
+		wantISIN   string
+	}{
+		{identifier: "ine009a01021", wantISIN: "INE009A01021"},
+		{identifier: "INFY", wantISIN: "INE009A01021"},
+		{identifier: "NSE:INFY", wantISIN: "INE009A01021"},
+		{identifier: "INFY-EQ", wantISIN: "INE009A01021"},
+		{identifier: "infy.ns", wantISIN: "INE009A01021"},
+		{identifier: "500209", wantISIN: "INE009A01021"},
+		{identifier: "Infosys Ltd.", wantISIN: "INE009A01021"},
+		{identifier: "TATA CONSULTANCY SERV LT", wantISIN: "INE467B01029"},
+		{identifier: "BAJAJ-AUTO", wantISIN: "INE917I01010"},
+		{identifier: "BAJAJ-AUTO-EQ", wantISIN: "INE917I01010"},
+		{identifier: "M&M", wantISIN: "INE101A01026"},
+		{identifier: "Bank of India", wantISIN: "INE084A01016"},
+		{identifier: "State Bank of India", wantISIN: "INE062A01020"},
+		{identifier: "UNKNOWN", wantISIN: ""},
+		{identifier: "", wantISIN: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.identifier, func(t *testing.T) {
+			assert.Equal(t, tt.wantISIN, store.ISIN(tt.identifier))
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	store := loadTestStore(t)
+
+	symbols := func(results []models.Instrument) []string {
+		out := make([]string, 0, len(results))
+		for _, instrument := range results {
+			out = append(out, instrument.ISIN)
+		}
+		return out
+	}
+
+	// Exact symbol first, then the name prefix match
+	assert.Equal(t, []string{"INE467B01029"}, symbols(store.Search("tcs", 0)))
+	assert.Equal(t, []string{"INE009A01021"}, symbols(store.Search("infos", 0)))
+
+	// Symbol prefix ranks above names containing the word
+	results := store.Search("bank", 0)
+	assert.Equal(t, []string{"INE084A01016", "INE040A01034", "INE062A01020"}, symbols(results))
+
+	// Substring match on names
+	assert.Equal(t, []string{"INE467B01029"}, symbols(store.Search("consult", 0)))
+
+	assert.Len(t, store.Search("a", 2), 2)
+	assert.Empty(t, store.Search("  ", 0))
+	assert.Empty(t, store.Search("zzz", 0))
+}
+
+func TestLoadErrors(t *testing.T) {
+	store := NewStore()
+	assert.ErrorIs(t, store.Load(Sources{NSEEquityPath: "testdata/missing.csv"}), ErrNoInstrumentFiles)
+	assert.Equal(t, 0, store.Len())
+
+	_, err := LoadNSEEquityList(strings.NewReader("SYMBOL,NAME\nINFY,Infosys\n"))
+	assert.ErrorIs(t, err, ErrInvalidFile)
+
+	_, err = LoadKiteInstruments(strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrInvalidFile)
+
+	// A bad file keeps the previous contents
+	store = loadTestStore(t)
+	assert.Error(t, store.Load(Sources{NSEEquityPath: "testdata/kite_instruments.csv"}))
+	assert.Equal(t, 7, store.Len())
+}
+
+func TestClassifyHolding(t *testing.T) {
+	tests := []struct {
+		name   string
+		isin   string
+		symbol string
+		listed bool
+		want   models.HoldingType
+	}{
+		{name: "equity share", isin: "INE009A01021", symbol: "INFY", listed: true, want: models.HoldingTypeStock},
+		{name: "partly paid share", isin: "IN9002A01024", symbol: "RELIANCEPP", listed: true, want: models.HoldingTypeStock},
+		{name: "listed fund units are ETFs", isin: "INF204KB17I5", symbol: "GOLDBEES", listed: true, want: models.HoldingTypeETF},
+		{name: "ETF symbol without listing", isin: "INF200KA16D8", symbol: "SETFNIF50", listed: false, want: models.HoldingTypeETF},
+		{name: "unlisted fund units are mutual funds", isin: "INF179K01YV8", symbol: "HDFC Large Cap Fund - Direct Plan", listed: false, want: models.HoldingTypeMutualFund},
+		{name: "fund of funds named after an ETF", isin: "INF109KC1Q56", symbol: "ICICI Prudential Nifty ETF FoF", listed: false, want: models.HoldingTypeMutualFund},
+		{name: "company debenture", isin: "INE001A07QY9", symbol: "HDFC NCD", listed: true, want: models.HoldingTypeBond},
+		{name: "government security", isin: "IN0020220151", symbol: "726GS2033", listed: true, want: models.HoldingTypeBond},
+		{name: "state development loan", isin: "IN1520230101", symbol: "SDL", listed: false, want: models.HoldingTypeBond},
+		{name: "sovereign gold bond", isin: "IN0020200112", symbol: "SGBAUG28V", listed: true, want: models.HoldingTypeSGB},
+		{name: "sovereign gold bond by statement name", isin: "IN0020200112", symbol: "SOVEREIGN GOLD BOND 2.50% 2028", listed: false, want: models.HoldingTypeSGB},
+		{name: "position without ISIN", symbol: "NIFTYBEES", listed: true, want: models.HoldingTypeETF},
+		{name: "stock position without ISIN", symbol: "TCS", listed: true, want: models.HoldingTypeStock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClassifyHolding(tt.isin, tt.symbol, tt.listed))
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package instruments
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// Common loader errors
+var (
+	ErrInvalidFile = errors.New("invalid instrument file")
+)
+
+// KiteInstrument is an equity row of the Kite Connect instruments dump
+type KiteInstrument struct {
+	InstrumentToken string
+	TradingSymbol   string
+	Name            string
+	Exchange        string
+}
+
+// csvTable reads a CSV with a header row and gives access to columns by name
+type csvTable struct {
+	reader *csv.Reader
+	index  map[string]int
+	line   int
+}
+
+// newCSVTable reads the header of a CSV, requiring one of the given names for each required column
+func newCSVTable(r io.Reader, required ...[]string) (*csvTable, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		index[normalizeColumn(column)] = i
+	}
+
+	table := &csvTable{reader: reader, index: index, line: 1}
+	for _, names := range required {
+		if table.column(names...) < 0 {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidFile, names[0])
+		}
+	}
+	return table, nil
+}
+
+// column returns the index of the first of the given column names present in the header, or -1
+func (t *csvTable) column(names ...string) int {
+	for _, name := range names {
+		if i, ok := t.index[normalizeColumn(name)]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// next returns the next record, or io.EOF after the last one
+func (t *csvTable) next() ([]string, error) {
+	record, err := t.reader.Read()
+	t.line++
+	if err == io.EOF {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidFile, t.line, err)
+	}
+	return record, nil
+}
+
+// field returns the trimmed value of a column in a record, or "" when absent
+func field(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// normalizeColumn lowercases a header name and drops spaces, underscores and a byte order mark
+func normalizeColumn(name string) string {
+	name = strings.TrimPrefix(name, "\ufeff")
+	name = strings.ToLower(name)
+	return strings.NewReplacer(" ", "", "_", "", ".", "").Replace(name)
+}
+
+// LoadNSEEquityList reads the NSE list of securities (EQUITY_L.csv)
+func LoadNSEEquityList(r io.Reader) ([]models.Instrument, error) {
+	table, err := newCSVTable(r, []string{"SYMBOL"}, []string{"NAME OF COMPANY"}, []string{"ISIN NUMBER"})
+	if err != nil {
+		return nil, err
+	}
+	symbol, name, isin := table.column("SYMBOL"), table.column("NAME OF COMPANY"), table.column("ISIN NUMBER")
+
+	var list []models.Instrument
+	for {
+		record, err := table.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		instrument := models.Instrument{
+			ISIN:      strings.ToUpper(field(record, isin)),
+			NSESymbol: strings.ToUpper(field(record, symbol)),
+			Name:      field(record, name),
+		}
+		if instrument.ISIN == "" || instrument.NSESymbol == "" {
+			continue
+		}
+		list = append(list, instrument)
+	}
+	return list, nil
+}
+
+// LoadBSEEquityList reads the BSE list of scrips as exported from the BSE website
+func LoadBSEEquityList(r io.Reader) ([]models.Instrument, error) {
+	table, err := newCSVTable(r, []string{"Security Code"}, []string{"ISIN No", "ISIN"})
+	if err != nil {
+		return nil, err
+	}
+	code := table.column("Security Code")
+	symbol := table.column("Security Id")
+	name := table.column("Issuer Name", "Security Name")
+	securityName := table.column("Security Name")
+	isin := table.column("ISIN No", "ISIN")
+	sector := table.column("Sector Name", "Industry", "Industry New Name")
+	status := table.column("Status")
+
+	var list []models.Instrument
+	for {
+		record, err := table.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if s := field(record, status); s != "" && !strings.EqualFold(s, "Active") {
+			continue
+		}
+		instrument := models.Instrument{
+			ISIN:      strings.ToUpper(field(record, isin)),
+			BSECode:   field(record, code),
+			BSESymbol: strings.ToUpper(field(record, symbol)),
+			Name:      field(record, name),
+			Sector:    field(record, sector),
+		}
+		if alias := field(record, securityName); alias != "" && !strings.EqualFold(alias, instrument.Name) {
+			instrument.Aliases = append(instrument.Aliases, alias)
+		}
+		if instrument.ISIN == "" || instrument.BSECode == "" {
+			continue
+		}
+		list = append(list, instrument)
+	}
+	return list, nil
+}
+
+// LoadKiteInstruments reads the NSE and BSE equity rows of the Kite Connect instruments CSV
+func LoadKiteInstruments(r io.Reader) ([]KiteInstrument, error) {
+	table, err := newCSVTable(r, []string{"instrument_token"}, []string{"tradingsymbol"}, []string{"exchange"})
+	if err != nil {
+		return nil, err
+	}
+	token, symbol, name := table.column("instrument_token"), table.column("tradingsymbol"), table.column("name")
+	exchange, instrumentType := table.column("exchange"), table.column("instrument_type")
+
+	var list []KiteInstrument
+	for {
+		record, err := table.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		ex := strings.ToUpper(field(record, exchange))
+		if (ex != "NSE" && ex != "BSE") || !strings.EqualFold(field(record, instrumentType), "EQ") {
+			continue
+		}
+		list = append(list, KiteInstrument{
+			InstrumentToken: field(record, token),
+			TradingSymbol:   strings.ToUpper(field(record, symbol)),
+			Name:            field(record, name),
+			Exchange:        ex,
+		})
+	}
+	return list, nil
+}
@@ -0,0 +1,97 @@
+package instruments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// ErrNoInstrumentFiles is returned when none of the configured instrument files exist
+var ErrNoInstrumentFiles = errors.New("no instrument files found")
+
+// Sources are the local files instruments are loaded from; empty or missing paths are skipped
+type Sources struct {
+	// NSEEquityPath is the NSE list of securities (EQUITY_L.csv)
+	NSEEquityPath string
+
+	// BSEEquityPath is the BSE list of scrips exported as CSV
+	BSEEquityPath string
+
+	// KiteInstrumentsPath is the Kite Connect instruments dump
+	KiteInstrumentsPath string
+}
+
+// Load reads the source files into the store, replacing its contents
+func (s *Store) Load(sources Sources) error {
+	found := false
+
+	nse, ok, err := readFile(sources.NSEEquityPath, LoadNSEEquityList)
+	if err != nil {
+		return fmt.Errorf("failed to load NSE equity list: %w", err)
+	}
+	found = found || ok
+
+	bse, ok, err := readFile(sources.BSEEquityPath, LoadBSEEquityList)
+	if err != nil {
+		return fmt.Errorf("failed to load BSE equity list: %w", err)
+	}
+	found = found || ok
+
+	kite, ok, err := readFile(sources.KiteInstrumentsPath, LoadKiteInstruments)
+	if err != nil {
+		return fmt.Errorf("failed to load Kite instruments: %w", err)
+	}
+	found = found || ok
+
+	if !found {
+		return ErrNoInstrumentFiles
+	}
+
+	s.Replace(Merge(nse, bse, kite))
+	return nil
+}
+
+// RunReload reloads the store from the source files at every interval until ctx is cancelled.
+// A failed reload is logged and the previous contents are kept.
+func (s *Store) RunReload(ctx context.Context, sources Sources, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Load(sources); err != nil {
+				log.Printf("Error reloading instruments: %v", err)
+				continue
+			}
+			log.Printf("Reloaded %d instruments", s.Len())
+		}
+	}
+}
+
+// readFile parses a file with the given loader, reporting false without error when the path is empty or missing
+func readFile[T any](path string, load func(io.Reader) ([]T, error)) ([]T, bool, error) {
+	if path == "" {
+		return nil, false, nil
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+
+	rows, err := load(file)
+	if err != nil {
+		return nil, false, err
+	}
+	return rows, true, nil
+}
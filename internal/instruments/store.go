@@ -0,0 +1,329 @@
+package instruments
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// DefaultSearchLimit is the number of results Search returns when no limit is given
+const DefaultSearchLimit = 20
+
+// corporateSuffixes are dropped from the end of company names when matching them
+var corporateSuffixes = []string{"LIMITED", "LTD"}
+
+// listingSuffixes are exchange and series suffixes brokers append to symbols
+var listingSuffixes = []string{"-EQ", "-BE", "-BZ", "-SM", "-ST", ".NS", ".BO", "-NSE", "-BSE"}
+
+// Store is a concurrency-safe, searchable set of instruments keyed by ISIN.
+// Any of an instrument's ISIN, NSE symbol, BSE code, BSE symbol, name or aliases resolve to it.
+type Store struct {
+	mu           sync.RWMutex
+	byISIN       map[string]*models.Instrument
+	byIdentifier map[string]string
+	byName       map[string]string
+	list         []*models.Instrument
+	loadedAt     time.Time
+}
+
+// NewStore creates an empty instrument store
+func NewStore() *Store {
+	return &Store{
+		byISIN:       make(map[string]*models.Instrument),
+		byIdentifier: make(map[string]string),
+		byName:       make(map[string]string),
+	}
+}
+
+// Replace swaps the contents of the store for the given instruments
+func (s *Store) Replace(instruments []models.Instrument) {
+	byISIN := make(map[string]*models.Instrument, len(instruments))
+	byIdentifier := make(map[string]string, len(instruments)*3)
+	byName := make(map[string]string, len(instruments)*2)
+	list := make([]*models.Instrument, 0, len(instruments))
+
+	for i := range instruments {
+		instrument := instruments[i]
+		if instrument.ISIN == "" {
+			continue
+		}
+		byISIN[instrument.ISIN] = &instrument
+		list = append(list, &instrument)
+
+		for _, identifier := range []string{instrument.ISIN, instrument.NSESymbol, instrument.BSECode, instrument.BSESymbol} {
+			if identifier != "" {
+				byIdentifier[strings.ToUpper(identifier)] = instrument.ISIN
+			}
+		}
+		for _, name := range append([]string{instrument.Name}, instrument.Aliases...) {
+			if key := normalizeName(name); key != "" {
+				if _, taken := byName[key]; !taken {
+					byName[key] = instrument.ISIN
+				}
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byISIN = byISIN
+	s.byIdentifier = byIdentifier
+	s.byName = byName
+	s.list = list
+	s.loadedAt = time.Now()
+}
+
+// Len returns the number of instruments in the store
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.list)
+}
+
+// LoadedAt returns when the store was last replaced
+func (s *Store) LoadedAt() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.loadedAt
+}
+
+// Lookup resolves an identifier to an instrument. The identifier may be an ISIN, an NSE or BSE symbol
+// with or without an exchange prefix or series suffix (NSE:INFY, INFY-EQ, INFY.NS), a BSE scrip code or a company name.
+func (s *Store) Lookup(identifier string) (models.Instrument, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if isin, ok := s.resolve(identifier); ok {
+		return *s.byISIN[isin], true
+	}
+	return models.Instrument{}, false
+}
+
+// ISIN resolves an identifier to an ISIN, returning "" when it is unknown
+func (s *Store) ISIN(identifier string) string {
+	instrument, ok := s.Lookup(identifier)
+	if !ok {
+		return ""
+	}
+	return instrument.ISIN
+}
+
+// resolve finds the ISIN for an identifier; the caller must hold the read lock
+func (s *Store) resolve(identifier string) (string, bool) {
+	symbol := canonicalSymbol(identifier)
+	if symbol == "" {
+		return "", false
+	}
+	if isin, ok := s.byIdentifier[symbol]; ok {
+		return isin, true
+	}
+	if isin, ok := s.byName[normalizeName(identifier)]; ok {
+		return isin, true
+	}
+	return "", false
+}
+
+// Search returns instruments matching a query, best matches first: exact identifiers,
+// then symbol prefixes, then name prefixes, then names containing a word starting with the query,
+// then any substring match
+func (s *Store) Search(query string, limit int) []models.Instrument {
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	symbol := canonicalSymbol(query)
+	name := normalizeName(query)
+	if symbol == "" && name == "" {
+		return []models.Instrument{}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type match struct {
+		instrument *models.Instrument
+		rank       int
+	}
+	var matches []match
+	for _, instrument := range s.list {
+		if rank, ok := matchRank(instrument, symbol, name); ok {
+			matches = append(matches, match{instrument: instrument, rank: rank})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].rank < matches[j].rank
+	})
+
+	results := make([]models.Instrument, 0, limit)
+	for _, m := range matches {
+		if len(results) == limit {
+			break
+		}
+		results = append(results, *m.instrument)
+	}
+	return results
+}
+
+// matchRank scores how well an instrument matches a query given as a canonical symbol and a normalized name
+func matchRank(instrument *models.Instrument, symbol, name string) (int, bool) {
+	identifiers := []string{instrument.ISIN, instrument.NSESymbol, instrument.BSECode, instrument.BSESymbol}
+	for _, identifier := range identifiers {
+		if identifier != "" && identifier == symbol {
+			return 0, true
+		}
+	}
+	for _, identifier := range identifiers {
+		if identifier != "" && symbol != "" && strings.HasPrefix(identifier, symbol) {
+			return 1, true
+		}
+	}
+	if name == "" {
+		return 0, false
+	}
+
+	names := make([]string, 0, len(instrument.Aliases)+1)
+	for _, n := range append([]string{instrument.Name}, instrument.Aliases...) {
+		names = append(names, normalizeName(n))
+	}
+	for _, n := range names {
+		if strings.HasPrefix(n, name) {
+			return 2, true
+		}
+	}
+	for _, n := range names {
+		if strings.Contains(" "+n, " "+name) {
+			return 3, true
+		}
+	}
+	for _, n := range names {
+		if strings.Contains(n, name) {
+			return 4, true
+		}
+	}
+	return 0, false
+}
+
+// canonicalSymbol uppercases a symbol and strips exchange prefixes and series suffixes
+func canonicalSymbol(identifier string) string {
+	symbol := strings.ToUpper(strings.TrimSpace(identifier))
+	if i := strings.Index(symbol, ":"); i >= 0 {
+		symbol = symbol[i+1:]
+	}
+	for _, suffix := range listingSuffixes {
+		if strings.HasSuffix(symbol, suffix) && len(symbol) > len(suffix) {
+			symbol = strings.TrimSuffix(symbol, suffix)
+			break
+		}
+	}
+	return symbol
+}
+
+// normalizeName uppercases a company name, replaces punctuation with spaces and drops trailing corporate suffixes
+func normalizeName(name string) string {
+	words := strings.FieldsFunc(strings.ToUpper(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '&'
+	})
+	for len(words) > 1 && isCorporateSuffix(words[len(words)-1]) {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
+
+// isCorporateSuffix reports whether a word is a corporate suffix dropped from names
+func isCorporateSuffix(word string) bool {
+	for _, suffix := range corporateSuffixes {
+		if word == suffix {
+			return true
+		}
+	}
+	return false
+}
+
+// Merge combines exchange lists and Kite instruments into one instrument per ISIN.
+// Kite rows carry no ISIN and are matched by NSE or BSE symbol; their names become aliases
+// and their instrument tokens the instrument's Kite token, preferring the NSE listing.
+func Merge(nse, bse []models.Instrument, kite []KiteInstrument) []models.Instrument {
+	byISIN := make(map[string]*models.Instrument)
+	var order []string
+	add := func(instrument models.Instrument) *models.Instrument {
+		existing, ok := byISIN[instrument.ISIN]
+		if !ok {
+			copied := instrument
+			copied.Aliases = append([]string(nil), instrument.Aliases...)
+			byISIN[instrument.ISIN] = &copied
+			order = append(order, instrument.ISIN)
+			return &copied
+		}
+		return existing
+	}
+
+	for _, instrument := range nse {
+		add(instrument)
+	}
+	for _, instrument := range bse {
+		existing := add(instrument)
+		if existing.BSECode == "" {
+			existing.BSECode = instrument.BSECode
+			existing.BSESymbol = instrument.BSESymbol
+			addAlias(existing, instrument.Name)
+		}
+		if existing.Sector == "" {
+			existing.Sector = instrument.Sector
+		}
+		for _, alias := range instrument.Aliases {
+			addAlias(existing, alias)
+		}
+	}
+
+	nseSymbols := make(map[string]*models.Instrument)
+	bseSymbols := make(map[string]*models.Instrument)
+	for _, isin := range order {
+		instrument := byISIN[isin]
+		if instrument.NSESymbol != "" {
+			nseSymbols[instrument.NSESymbol] = instrument
+		}
+		if instrument.BSESymbol != "" {
+			bseSymbols[instrument.BSESymbol] = instrument
+		}
+	}
+	for _, row := range kite {
+		symbols := nseSymbols
+		if row.Exchange == "BSE" {
+			symbols = bseSymbols
+		}
+		if instrument, ok := symbols[row.TradingSymbol]; ok {
+			addAlias(instrument, row.Name)
+			token, err := strconv.ParseUint(row.InstrumentToken, 10, 32)
+			if err == nil && (instrument.KiteToken == 0 || row.Exchange == "NSE") {
+				instrument.KiteToken = uint32(token)
+			}
+		}
+	}
+
+	merged := make([]models.Instrument, 0, len(order))
+	for _, isin := range order {
+		merged = append(merged, *byISIN[isin])
+	}
+	return merged
+}
+
+// addAlias adds a name to an instrument's aliases unless it matches its name or an existing alias
+func addAlias(instrument *models.Instrument, alias string) {
+	key := normalizeName(alias)
+	if key == "" || key == normalizeName(instrument.Name) {
+		return
+	}
+	for _, existing := range instrument.Aliases {
+		if normalizeName(existing) == key {
+			return
+		}
+	}
+	instrument.Aliases = append(instrument.Aliases, alias)
+}
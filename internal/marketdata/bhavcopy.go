@@ -0,0 +1,236 @@
+package marketdata
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// Common parser errors
+var (
+	ErrInvalidBhavcopy = errors.New("invalid bhavcopy file")
+)
+
+// bhavcopyFormat maps the columns of one bhavcopy layout to price bar fields
+type bhavcopyFormat struct {
+	exchange    string // fixed exchange, or empty when the exchange column says
+	dateLayouts []string
+	columns     map[string]string // field -> column name
+}
+
+// Price bar fields read from bhavcopy columns
+const (
+	fieldISIN      = "isin"
+	fieldSymbol    = "symbol"
+	fieldExchange  = "exchange"
+	fieldSegment   = "segment"
+	fieldDate      = "date"
+	fieldOpen      = "open"
+	fieldHigh      = "high"
+	fieldLow       = "low"
+	fieldClose     = "close"
+	fieldPrevClose = "prevClose"
+	fieldVolume    = "volume"
+	fieldTurnover  = "turnover"
+)
+
+// bhavcopyFormats are the supported layouts, detected by their header:
+// the common UDiFF format both exchanges publish since July 2024, the older NSE CM bhavcopy
+// and the older BSE equity bhavcopy with ISINs
+var bhavcopyFormats = []bhavcopyFormat{
+	{
+		dateLayouts: []string{"2006-01-02"},
+		columns: map[string]string{
+			fieldISIN:      "ISIN",
+			fieldSymbol:    "TckrSymb",
+			fieldExchange:  "Src",
+			fieldSegment:   "Sgmt",
+			fieldDate:      "TradDt",
+			fieldOpen:      "OpnPric",
+			fieldHigh:      "HghPric",
+			fieldLow:       "LwPric",
+			fieldClose:     "ClsPric",
+			fieldPrevClose: "PrvsClsgPric",
+			fieldVolume:    "TtlTradgVol",
+			fieldTurnover:  "TtlTrfVal",
+		},
+	},
+	{
+		exchange:    models.ExchangeNSE,
+		dateLayouts: []string{"02-Jan-2006"},
+		columns: map[string]string{
+			fieldISIN:      "ISIN",
+			fieldSymbol:    "SYMBOL",
+			fieldDate:      "TIMESTAMP",
+			fieldOpen:      "OPEN",
+			fieldHigh:      "HIGH",
+			fieldLow:       "LOW",
+			fieldClose:     "CLOSE",
+			fieldPrevClose: "PREVCLOSE",
+			fieldVolume:    "TOTTRDQTY",
+			fieldTurnover:  "TOTTRDVAL",
+		},
+	},
+	{
+		exchange:    models.ExchangeBSE,
+		dateLayouts: []string{"02-Jan-06", "02-Jan-2006", "2006-01-02"},
+		columns: map[string]string{
+			fieldISIN:      "ISIN_CODE",
+			fieldSymbol:    "SC_CODE",
+			fieldDate:      "TRADING_DATE",
+			fieldOpen:      "OPEN",
+			fieldHigh:      "HIGH",
+			fieldLow:       "LOW",
+			fieldClose:     "CLOSE",
+			fieldPrevClose: "PREVCLOSE",
+			fieldVolume:    "NO_OF_SHRS",
+			fieldTurnover:  "NET_TURNOV",
+		},
+	},
+}
+
+// requiredFields must be present in a layout's header for it to match
+var requiredFields = []string{fieldISIN, fieldSymbol, fieldDate, fieldClose}
+
+// ParseBhavcopy parses an NSE or BSE bhavcopy CSV into price bars.
+// Rows without an ISIN or a positive close, and non cash market rows of UDiFF files, are skipped.
+// When a security trades in several series the most traded one is kept.
+func ParseBhavcopy(r io.Reader) ([]models.PriceBar, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBhavcopy, err)
+	}
+	format, index, ok := detectFormat(header)
+	if !ok {
+		return nil, fmt.Errorf("%w: unrecognized header", ErrInvalidBhavcopy)
+	}
+
+	var bars []models.PriceBar
+	byISIN := make(map[string]int)
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidBhavcopy, line, err)
+		}
+
+		cell := func(field string) string {
+			i, ok := index[field]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		if segment := cell(fieldSegment); segment != "" && !strings.EqualFold(segment, "CM") {
+			continue
+		}
+		isin := strings.ToUpper(cell(fieldISIN))
+		closePrice := parsePrice(cell(fieldClose))
+		if isin == "" || closePrice <= 0 {
+			continue
+		}
+		date, ok := parseDate(cell(fieldDate), format.dateLayouts)
+		if !ok {
+			return nil, fmt.Errorf("%w: line %d: invalid date %q", ErrInvalidBhavcopy, line, cell(fieldDate))
+		}
+
+		exchange := format.exchange
+		if exchange == "" {
+			exchange = strings.ToUpper(cell(fieldExchange))
+		}
+
+		bar := models.PriceBar{
+			ISIN:      isin,
+			Symbol:    cell(fieldSymbol),
+			Exchange:  exchange,
+			Date:      date,
+			Open:      parsePrice(cell(fieldOpen)),
+			High:      parsePrice(cell(fieldHigh)),
+			Low:       parsePrice(cell(fieldLow)),
+			Close:     closePrice,
+			PrevClose: parsePrice(cell(fieldPrevClose)),
+			Volume:    parsePrice(cell(fieldVolume)),
+			Turnover:  parsePrice(cell(fieldTurnover)),
+		}
+
+		if i, seen := byISIN[isin]; seen {
+			if bar.Volume > bars[i].Volume {
+				bars[i] = bar
+			}
+			continue
+		}
+		byISIN[isin] = len(bars)
+		bars = append(bars, bar)
+	}
+
+	return bars, nil
+}
+
+// detectFormat finds the layout whose required columns are all in the header, returning its field to column index map
+func detectFormat(header []string) (bhavcopyFormat, map[string]int, bool) {
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		column = strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		columns[column] = i
+	}
+
+	for _, format := range bhavcopyFormats {
+		index := make(map[string]int, len(format.columns))
+		for field, column := range format.columns {
+			if i, ok := columns[strings.ToUpper(column)]; ok {
+				index[field] = i
+			}
+		}
+
+		matched := true
+		for _, field := range requiredFields {
+			if _, ok := index[field]; !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return format, index, true
+		}
+	}
+	return bhavcopyFormat{}, nil, false
+}
+
+// parsePrice parses a numeric cell, treating blanks and dashes as zero
+func parsePrice(value string) float64 {
+	value = strings.ReplaceAll(value, ",", "")
+	if value == "" || value == "-" {
+		return 0
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// parseDate parses a trading date in any of the given layouts
+func parseDate(value string, layouts []string) (time.Time, bool) {
+	for _, layout := range layouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,260 @@
+package marketdata
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	nseUDiFF  = "testdata/bhavcopy/BhavCopy_NSE_CM_0_0_0_20251014_F_0000.csv"
+	bseUDiFF  = "testdata/bhavcopy/BhavCopy_BSE_CM_0_0_0_20251014_F_0000.CSV"
+	nseLegacy = "testdata/bhavcopy/cm13OCT2025bhav.csv"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func parseFixture(t *testing.T, path string) []models.PriceBar {
+	t.Helper()
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	bars, err := ParseBhavcopy(file)
+	assert.NoError(t, err)
+	return bars
+}
+
+func TestParseBhavcopy(t *testing.T) {
+	t.Run("NSE UDiFF", func(t *testing.T) {
+		bars := parseFixture(t, nseUDiFF)
+		// The row without an ISIN is skipped and TCS keeps its most traded series
+		assert.Len(t, bars, 4)
+
+		infy := bars[0]
+		assert.Equal(t, models.PriceBar{
+			ISIN:      "INE009A01021",
+			Symbol:    "INFY",
+			Exchange:  models.ExchangeNSE,
+			Date:      day(2025, 10, 14),
+			Open:      1480.00,
+			High:      1495.50,
+			Low:       1472.10,
+			Close:     1490.20,
+			PrevClose: 1478.60,
+			Volume:    5123456,
+			Turnover:  7634123456.50,
+		}, infy)
+
+		tcs := bars[1]
+		assert.Equal(t, "TCS", tcs.Symbol)
+		assert.Equal(t, 3035.40, tcs.Close)
+		assert.Equal(t, 1987654.0, tcs.Volume)
+	})
+
+	t.Run("BSE UDiFF", func(t *testing.T) {
+		bars := parseFixture(t, bseUDiFF)
+		// Derivatives rows are not part of the cash market
+		assert.Len(t, bars, 2)
+		for _, bar := range bars {
+			assert.Equal(t, models.ExchangeBSE, bar.Exchange)
+		}
+		assert.Equal(t, "INE040A01034", bars[1].ISIN)
+		assert.Equal(t, 1008.75, bars[1].Close)
+	})
+
+	t.Run("NSE CM", func(t *testing.T) {
+		bars := parseFixture(t, nseLegacy)
+		assert.Len(t, bars, 2)
+		assert.Equal(t, models.ExchangeNSE, bars[0].Exchange)
+		assert.Equal(t, day(2025, 10, 13), bars[0].Date)
+		assert.Equal(t, 1478.60, bars[0].Close)
+	})
+}
+
+func TestParseBhavcopyInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "empty", input: ""},
+		{name: "unrecognized header", input: "Date,Price\n2025-10-14,10\n"},
+		{name: "invalid date", input: "SYMBOL,SERIES,CLOSE,TIMESTAMP,ISIN\nINFY,EQ,1478.60,2025/10/13,INE009A01021\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBhavcopy(strings.NewReader(tt.input))
+			assert.ErrorIs(t, err, ErrInvalidBhavcopy)
+		})
+	}
+}
+
+// fakePriceRepository keeps price bars in memory, preferring NSE bars like the database does
+type fakePriceRepository struct {
+	bars map[string]map[time.Time]models.PriceBar
+}
+
+func newFakePriceRepository() *fakePriceRepository {
+	return &fakePriceRepository{bars: make(map[string]map[time.Time]models.PriceBar)}
+}
+
+func (r *fakePriceRepository) SavePriceBars(bars []models.PriceBar) (int, error) {
+	for _, bar := range bars {
+		if r.bars[bar.ISIN] == nil {
+			r.bars[bar.ISIN] = make(map[time.Time]models.PriceBar)
+		}
+		existing, ok := r.bars[bar.ISIN][bar.Date]
+		if ok && existing.Exchange == models.ExchangeNSE && bar.Exchange != models.ExchangeNSE {
+			continue
+		}
+		r.bars[bar.ISIN][bar.Date] = bar
+	}
+	return len(bars), nil
+}
+
+func (r *fakePriceRepository) GetPriceBar(isin string, onOrBefore time.Time) (models.PriceBar, bool, error) {
+	var latest models.PriceBar
+	found := false
+	for date, bar := range r.bars[isin] {
+		if !date.After(onOrBefore) && (!found || date.After(latest.Date)) {
+			latest, found = bar, true
+		}
+	}
+	return latest, found, nil
+}
+
+func (r *fakePriceRepository) GetPriceBars(isin string, from, to time.Time) ([]models.PriceBar, error) {
+	var bars []models.PriceBar
+	for date, bar := range r.bars[isin] {
+		if !date.Before(from) && !date.After(to) {
+			bars = append(bars, bar)
+		}
+	}
+	sort.Slice(bars, func(i, j int) bool { return bars[i].Date.Before(bars[j].Date) })
+	return bars, nil
+}
+
+func TestIngestDirectory(t *testing.T) {
+	repo := newFakePriceRepository()
+	service := NewService(ServiceConfig{Repository: repo, Directory: "testdata/bhavcopy"})
+
+	count, err := service.IngestDirectory(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 8, count)
+
+	// Unchanged files are not read again
+	count, err = service.IngestDirectory(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	tests := []struct {
+		name      string
+		isin      string
+		date      time.Time
+		wantClose float64
+		wantOK    bool
+	}{
+		{name: "NSE close preferred over BSE", isin: "INE009A01021", date: day(2025, 10, 14), wantClose: 1490.20, wantOK: true},
+		{name: "last trading day before a holiday", isin: "INE009A01021", date: day(2025, 10, 16), wantClose: 1490.20, wantOK: true},
+		{name: "earlier day", isin: "INE009A01021", date: day(2025, 10, 13), wantClose: 1478.60, wantOK: true},
+		{name: "IST evening counts as the same day", isin: "ine009a01021", date: time.Date(2025, 10, 13, 20, 0, 0, 0, ist), wantClose: 1478.60, wantOK: true},
+		{name: "BSE only", isin: "INE040A01034", date: day(2025, 10, 14), wantClose: 1008.75, wantOK: true},
+		{name: "before the history", isin: "INE009A01021", date: day(2025, 10, 12), wantOK: false},
+		{name: "unknown security", isin: "INE000000000", date: day(2025, 10, 14), wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			closePrice, ok, err := service.GetClose(tt.isin, tt.date)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantClose, closePrice)
+		})
+	}
+
+	bars, err := service.GetRange("INE467B01029", day(2025, 10, 1), day(2025, 10, 31))
+	assert.NoError(t, err)
+	assert.Len(t, bars, 2)
+	assert.Equal(t, day(2025, 10, 13), bars[0].Date)
+	assert.Equal(t, 3035.40, bars[1].Close)
+}
+
+// zipFixture returns a zip archive holding a fixture file, as the exchanges publish them
+func zipFixture(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	file, err := archive.Create("BhavCopy_NSE_CM_0_0_0_20251014_F_0000.csv")
+	assert.NoError(t, err)
+	_, err = file.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, archive.Close())
+	return buf.Bytes()
+}
+
+func TestIngestDay(t *testing.T) {
+	archive := zipFixture(t, nseUDiFF)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/nse/BhavCopy_NSE_CM_0_0_0_20251014_F_0000.csv.zip":
+			w.Write(archive)
+		case "/error/20251014.csv":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	repo := newFakePriceRepository()
+	service := NewService(ServiceConfig{
+		Repository: repo,
+		URLTemplates: []string{
+			server.URL + "/nse/BhavCopy_NSE_CM_0_0_0_{20060102}_F_0000.csv.zip",
+			server.URL + "/bse/BhavCopy_BSE_CM_0_0_0_{20060102}_F_0000.CSV",
+		},
+	})
+
+	// The BSE file is missing for the day and skipped
+	count, err := service.IngestDay(context.Background(), day(2025, 10, 14))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	closePrice, ok, err := service.GetClose("INF204KB17I5", day(2025, 10, 14))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 98.64, closePrice)
+
+	// Nothing is published on a holiday
+	count, err = service.IngestDay(context.Background(), day(2025, 10, 15))
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	failing := NewService(ServiceConfig{Repository: repo, URLTemplates: []string{server.URL + "/error/{20060102}.csv"}})
+	_, err = failing.IngestDay(context.Background(), day(2025, 10, 14))
+	assert.Error(t, err)
+}
+
+func TestNextIngest(t *testing.T) {
+	// Friday afternoon runs that evening
+	friday := time.Date(2025, 10, 17, 15, 0, 0, 0, ist)
+	assert.Equal(t, time.Date(2025, 10, 17, 19, 30, 0, 0, ist), nextIngest(friday))
+
+	// Friday night moves to Monday
+	assert.Equal(t, time.Date(2025, 10, 20, 19, 30, 0, 0, ist), nextIngest(friday.Add(6*time.Hour)))
+}
@@ -0,0 +1,319 @@
+package marketdata
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// ist is Indian Standard Time, in which trading days are reckoned
+var ist = time.FixedZone("IST", 5*60*60+30*60)
+
+// ingestHour and ingestMinute set when, in IST, the day's bhavcopies are fetched; both exchanges have published by then
+const (
+	ingestHour   = 19
+	ingestMinute = 30
+)
+
+// errNoBhavcopy is returned when an exchange has not published a bhavcopy for a day, e.g. a trading holiday
+var errNoBhavcopy = errors.New("no bhavcopy published")
+
+// PriceProvider defines the interface for end-of-day prices
+type PriceProvider interface {
+	// GetClose returns the closing price of a security on the last trading day on or before the given day
+	GetClose(isin string, date time.Time) (float64, bool, error)
+
+	// GetRange returns the daily price bars of a security between two days inclusive, ordered by date
+	GetRange(isin string, from, to time.Time) ([]models.PriceBar, error)
+}
+
+// PriceRepository defines the interface for storing end-of-day price bars
+type PriceRepository interface {
+	// SavePriceBars stores price bars, one per security and day, and returns the number stored.
+	// An NSE bar replaces a BSE bar for the same day but not the other way round.
+	SavePriceBars(bars []models.PriceBar) (int, error)
+
+	// GetPriceBar retrieves the bar of a security on the last trading day on or before the given day
+	GetPriceBar(isin string, onOrBefore time.Time) (models.PriceBar, bool, error)
+
+	// GetPriceBars retrieves the bars of a security between two days inclusive, ordered by date
+	GetPriceBars(isin string, from, to time.Time) ([]models.PriceBar, error)
+}
+
+// Ensure Service implements PriceProvider
+var _ PriceProvider = (*Service)(nil)
+
+// ServiceConfig holds configuration for the market data service
+type ServiceConfig struct {
+	Repository PriceRepository
+
+	// Directory holds bhavcopy CSV or zip files to ingest; new and changed files are picked up on each run (optional)
+	Directory string
+
+	// URLTemplates are bhavcopy download URLs with the trading day as a Go time layout in braces,
+	// e.g. https://example.com/BhavCopy_NSE_CM_0_0_0_{20060102}_F_0000.csv.zip (optional)
+	URLTemplates []string
+
+	// HTTPClient downloads bhavcopies (default: a client with a one minute timeout)
+	HTTPClient *http.Client
+}
+
+// Service ingests bhavcopies and serves end-of-day prices
+type Service struct {
+	repository   PriceRepository
+	directory    string
+	urlTemplates []string
+	httpClient   *http.Client
+
+	mu       sync.Mutex
+	ingested map[string]time.Time // file path -> modification time when last ingested
+}
+
+// NewService creates a new market data service
+func NewService(config ServiceConfig) *Service {
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: time.Minute}
+	}
+
+	return &Service{
+		repository:   config.Repository,
+		directory:    config.Directory,
+		urlTemplates: config.URLTemplates,
+		httpClient:   config.HTTPClient,
+		ingested:     make(map[string]time.Time),
+	}
+}
+
+// GetClose returns the closing price of a security on the last trading day on or before the given day
+func (s *Service) GetClose(isin string, date time.Time) (float64, bool, error) {
+	bar, ok, err := s.repository.GetPriceBar(strings.ToUpper(isin), tradingDay(date))
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	return bar.Close, true, nil
+}
+
+// GetRange returns the daily price bars of a security between two days inclusive, ordered by date
+func (s *Service) GetRange(isin string, from, to time.Time) ([]models.PriceBar, error) {
+	return s.repository.GetPriceBars(strings.ToUpper(isin), tradingDay(from), tradingDay(to))
+}
+
+// IngestDirectory ingests the bhavcopy files in the configured directory that are new or changed since the last run,
+// returning the number of bars stored. A missing directory has nothing to ingest.
+func (s *Service) IngestDirectory(ctx context.Context) (int, error) {
+	if s.directory == "" {
+		return 0, nil
+	}
+	entries, err := os.ReadDir(s.directory)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read bhavcopy directory: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := 0
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+		name := entry.Name()
+		if entry.IsDir() || !isBhavcopyFile(name) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return total, err
+		}
+		path := filepath.Join(s.directory, name)
+		if modified, ok := s.ingested[path]; ok && modified.Equal(info.ModTime()) {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return total, err
+		}
+		count, err := s.store(data)
+		if err != nil {
+			return total, fmt.Errorf("failed to ingest %s: %w", name, err)
+		}
+		s.ingested[path] = info.ModTime()
+		total += count
+	}
+	return total, nil
+}
+
+// IngestDay downloads and ingests the bhavcopies of a trading day from every URL template,
+// returning the number of bars stored. Templates without a file for the day are skipped.
+func (s *Service) IngestDay(ctx context.Context, day time.Time) (int, error) {
+	total := 0
+	for _, template := range s.urlTemplates {
+		url := expandTemplate(template, day.In(ist))
+		data, err := s.download(ctx, url)
+		if errors.Is(err, errNoBhavcopy) {
+			continue
+		}
+		if err != nil {
+			return total, fmt.Errorf("failed to download %s: %w", url, err)
+		}
+		count, err := s.store(data)
+		if err != nil {
+			return total, fmt.Errorf("failed to ingest %s: %w", url, err)
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// RunDailyIngest ingests the bhavcopy directory at start, and then every weekday evening ingests
+// the directory and the day's downloads until ctx is cancelled. Failures are logged and retried the next day.
+func (s *Service) RunDailyIngest(ctx context.Context) {
+	s.ingestAndLog(ctx, time.Time{})
+
+	for {
+		next := nextIngest(time.Now())
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.ingestAndLog(ctx, next)
+		}
+	}
+}
+
+// ingestAndLog ingests the directory and, when day is set, the downloads of that day
+func (s *Service) ingestAndLog(ctx context.Context, day time.Time) {
+	count, err := s.IngestDirectory(ctx)
+	if err != nil {
+		log.Printf("Error ingesting bhavcopy directory: %v", err)
+	}
+	if !day.IsZero() {
+		downloaded, err := s.IngestDay(ctx, day)
+		if err != nil {
+			log.Printf("Error downloading bhavcopies: %v", err)
+		}
+		count += downloaded
+	}
+	if count > 0 {
+		log.Printf("Ingested %d end-of-day prices", count)
+	}
+}
+
+// store parses a bhavcopy CSV, or every CSV in a zip archive, and saves its bars
+func (s *Service) store(data []byte) (int, error) {
+	bars, err := ParseBhavcopyData(data)
+	if err != nil {
+		return 0, err
+	}
+	if len(bars) == 0 {
+		return 0, nil
+	}
+	return s.repository.SavePriceBars(bars)
+}
+
+// download fetches a bhavcopy, reporting errNoBhavcopy when the exchange has none for the day
+func (s *Service) download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	// The exchange archives reject requests without a browser-like user agent
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; FinSight)")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errNoBhavcopy
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ParseBhavcopyData parses a bhavcopy CSV or every CSV in a zip archive
+func ParseBhavcopyData(data []byte) ([]models.PriceBar, error) {
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return ParseBhavcopy(bytes.NewReader(data))
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBhavcopy, err)
+	}
+	var bars []models.PriceBar
+	for _, file := range archive.File {
+		if !strings.EqualFold(filepath.Ext(file.Name), ".csv") {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBhavcopy, err)
+		}
+		fileBars, err := ParseBhavcopy(reader)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		bars = append(bars, fileBars...)
+	}
+	return bars, nil
+}
+
+// isBhavcopyFile reports whether a file name has a bhavcopy extension
+func isBhavcopyFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".csv" || ext == ".zip"
+}
+
+// templateLayout matches the date layout placeholder of a URL template
+var templateLayout = regexp.MustCompile(`\{([^}]+)\}`)
+
+// expandTemplate replaces the date layout placeholders of a URL template with the given day
+func expandTemplate(template string, day time.Time) string {
+	return templateLayout.ReplaceAllStringFunc(template, func(placeholder string) string {
+		return day.Format(placeholder[1 : len(placeholder)-1])
+	})
+}
+
+// nextIngest returns the first weekday ingest time after t
+func nextIngest(t time.Time) time.Time {
+	local := t.In(ist)
+	next := time.Date(local.Year(), local.Month(), local.Day(), ingestHour, ingestMinute, 0, 0, ist)
+	if !next.After(local) {
+		next = next.AddDate(0, 0, 1)
+	}
+	for next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// tradingDay returns the IST calendar day of t as midnight UTC, the form trading dates are stored in
+func tradingDay(t time.Time) time.Time {
+	local := t.In(ist)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
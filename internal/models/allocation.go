@@ -0,0 +1,90 @@
+package models
+
+// AllocationClass represents the broad asset class a holding contributes to in allocation breakdowns
+type AllocationClass string
+
+const (
+	AllocationClassEquity       AllocationClass = "equity"
+	AllocationClassDebt         AllocationClass = "debt"
+	AllocationClassGold         AllocationClass = "gold"
+	AllocationClassCash         AllocationClass = "cash"
+	AllocationClassHybrid       AllocationClass = "hybrid"
+	AllocationClassUnclassified AllocationClass = "unclassified"
+)
+
+// MarketCapBucket represents the SEBI market capitalisation category of a listed company
+type MarketCapBucket string
+
+const (
+	MarketCapLarge        MarketCapBucket = "large"
+	MarketCapMid          MarketCapBucket = "mid"
+	MarketCapSmall        MarketCapBucket = "small"
+	MarketCapUnclassified MarketCapBucket = "unclassified"
+)
+
+// Unclassified is the sector and industry of holdings missing from the sector master
+const Unclassified = "Unclassified"
+
+// InstrumentClassification represents how an instrument is categorised for allocation
+type InstrumentClassification struct {
+	AssetClass AllocationClass `json:"assetClass"`
+	MarketCap  MarketCapBucket `json:"marketCap"`
+	Sector     string          `json:"sector"`
+	Industry   string          `json:"industry"`
+}
+
+// AllocationDimension is the attribute holdings are grouped by in an allocation breakdown
+type AllocationDimension string
+
+const (
+	AllocationBySector     AllocationDimension = "sector"
+	AllocationByAssetClass AllocationDimension = "assetClass"
+	AllocationByMarketCap  AllocationDimension = "marketCap"
+	AllocationByPlatform   AllocationDimension = "platform"
+	AllocationByIndustry   AllocationDimension = "industry"
+)
+
+// AllocationRequest represents the request parameters for the allocation endpoint
+type AllocationRequest struct {
+	By AllocationDimension `form:"by" binding:"omitempty,oneof=sector assetClass marketCap platform"`
+}
+
+// AllocationHolding represents a holding's share of an allocation bucket
+type AllocationHolding struct {
+	ItemName string  `json:"itemName"`
+	ISIN     string  `json:"isin"`
+	Platform string  `json:"platform"`
+	Value    float64 `json:"value"`
+
+	// Weight is the holding's percentage of the whole portfolio
+	Weight float64 `json:"weight"`
+	InstrumentClassification
+}
+
+// AllocationBucket represents the holdings sharing one value of an allocation dimension
+type AllocationBucket struct {
+	Key   string  `json:"key"`
+	Value float64 `json:"value"`
+
+	// Weight is the bucket's percentage of the whole portfolio
+	Weight float64 `json:"weight"`
+
+	// DrillDownBy is the dimension Breakdown groups the bucket's holdings by
+	DrillDownBy AllocationDimension `json:"drillDownBy,omitempty"`
+	Breakdown   []AllocationBucket  `json:"breakdown,omitempty"`
+	Holdings    []AllocationHolding `json:"holdings"`
+}
+
+// Allocation represents a weighted breakdown of a portfolio along one dimension
+type Allocation struct {
+	By         AllocationDimension `json:"by"`
+	TotalValue float64             `json:"totalValue"`
+	Buckets    []AllocationBucket  `json:"buckets"`
+}
+
+// AllocationResponse represents the response for the allocation endpoint
+type AllocationResponse struct {
+	Success bool       `json:"success"`
+	Data    Allocation `json:"data"`
+	Error   string     `json:"error,omitempty"`
+}
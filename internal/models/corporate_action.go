@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// CorporateActionType represents the kind of corporate action that changes share counts
+type CorporateActionType string
+
+const (
+	CorporateActionSplit CorporateActionType = "split"
+	CorporateActionBonus CorporateActionType = "bonus"
+)
+
+// CorporateAction represents a stock split or bonus issue for a security.
+// For a split, Numerator new shares replace every Denominator old shares (a 1:5 split of a ₹10 share into ₹2 shares is 5/1).
+// For a bonus, Numerator bonus shares are issued for every Denominator shares held (a 1:2 bonus is 1/2).
+type CorporateAction struct {
+	ISIN        string              `json:"isin"`
+	Type        CorporateActionType `json:"type"`
+	ExDate      time.Time           `json:"exDate"`
+	Numerator   float64             `json:"numerator"`
+	Denominator float64             `json:"denominator"`
+}
+
+// CorporateActionImportResult summarizes a corporate actions import
+type CorporateActionImportResult struct {
+	Total    int `json:"total"`
+	Imported int `json:"imported"`
+
+	// Unresolved lists the symbols whose ISIN could not be found in the instrument master
+	Unresolved []string `json:"unresolved"`
+}
+
+// CorporateActionImportResponse represents the response for the corporate actions import endpoint
+type CorporateActionImportResponse struct {
+	Success bool                        `json:"success"`
+	Data    CorporateActionImportResult `json:"data"`
+	Error   string                      `json:"error,omitempty"`
+}
+
+// Ratio returns the number of shares issued per share held
+func (a CorporateAction) Ratio() float64 {
+	if a.Denominator == 0 {
+		return 0
+	}
+	return a.Numerator / a.Denominator
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Dividend represents a dividend or fund payout received on a holding
+type Dividend struct {
+	ISIN     string    `json:"isin"`
+	Symbol   string    `json:"symbol"`
+	Platform string    `json:"platform"`
+	PaidOn   time.Time `json:"paidOn"`
+	Amount   float64   `json:"amount"`
+}
+
+// DividendImportResult summarizes a dividend statement import
+type DividendImportResult struct {
+	Total    int     `json:"total"`
+	Amount   float64 `json:"amount"`
+	Resolved int     `json:"resolved"`
+}
+
+// DividendImportResponse represents the response for the dividend import endpoint
+type DividendImportResponse struct {
+	Success bool                 `json:"success"`
+	Data    DividendImportResult `json:"data"`
+	Error   string               `json:"error,omitempty"`
+}
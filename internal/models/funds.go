@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Funds represents the cash and margin available at a broker
+type Funds struct {
+	Platform      string    `json:"platform"`
+	AvailableCash float64   `json:"availableCash"`
+	UsedMargin    float64   `json:"usedMargin"`
+	Collateral    float64   `json:"collateral"`
+	Net           float64   `json:"net"`
+	LastUpdated   time.Time `json:"lastUpdated"`
+}
+
+// FundsResponse represents the response for the funds endpoint
+type FundsResponse struct {
+	Success bool    `json:"success"`
+	Data    []Funds `json:"data,omitempty"`
+	Error   string  `json:"error,omitempty"`
+}
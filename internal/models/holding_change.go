@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// HoldingChangeType represents the kind of difference between two holdings sets
+type HoldingChangeType string
+
+const (
+	// HoldingChangeNew is a holding that was not held before
+	HoldingChangeNew HoldingChangeType = "new"
+
+	// HoldingChangeExit is a holding that is no longer held
+	HoldingChangeExit HoldingChangeType = "exit"
+
+	// HoldingChangeQuantity is a holding whose quantity changed
+	HoldingChangeQuantity HoldingChangeType = "quantity"
+
+	// HoldingChangePrice is a holding whose price moved beyond the configured threshold
+	HoldingChangePrice HoldingChangeType = "price"
+)
+
+// HoldingChange represents one difference between the previous and refreshed holdings of a user
+type HoldingChange struct {
+	Type             HoldingChangeType `json:"type"`
+	ItemName         string            `json:"itemName"`
+	ISIN             string            `json:"isin"`
+	Platform         string            `json:"platform"`
+	PreviousQuantity float64           `json:"previousQuantity"`
+	Quantity         float64           `json:"quantity"`
+	PreviousPrice    float64           `json:"previousPrice"`
+	Price            float64           `json:"price"`
+	PriceChangePct   float64           `json:"priceChangePct"`
+	DetectedAt       time.Time         `json:"detectedAt"`
+}
+
+// PortfolioChangesRequest represents the request parameters for the portfolio changes endpoint.
+// Dates are in YYYY-MM-DD form.
+type PortfolioChangesRequest struct {
+	From string            `form:"from"`
+	To   string            `form:"to"`
+	Type HoldingChangeType `form:"type" binding:"omitempty,oneof=new exit quantity price"`
+}
+
+// PortfolioChangesResponse represents the response for the portfolio changes endpoint
+type PortfolioChangesResponse struct {
+	Success bool            `json:"success"`
+	Data    []HoldingChange `json:"data"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// PortfolioRefreshResponse represents the response for the portfolio refresh endpoint,
+// with the changes the refresh detected against the previous holdings
+type PortfolioRefreshResponse struct {
+	Success bool            `json:"success"`
+	Data    Portfolio       `json:"data"`
+	Changes []HoldingChange `json:"changes"`
+	Error   string          `json:"error,omitempty"`
+}
@@ -0,0 +1,28 @@
+package models
+
+// Instrument represents a listed security with its identifiers across exchanges and brokers
+type Instrument struct {
+	ISIN      string   `json:"isin"`
+	NSESymbol string   `json:"nseSymbol,omitempty"`
+	BSECode   string   `json:"bseCode,omitempty"`
+	BSESymbol string   `json:"bseSymbol,omitempty"`
+	Name      string   `json:"name"`
+	Sector    string   `json:"sector,omitempty"`
+	Aliases   []string `json:"aliases,omitempty"`
+
+	// KiteToken is the Kite Connect instrument token, used to subscribe to live ticks; NSE is preferred over BSE
+	KiteToken uint32 `json:"kiteToken,omitempty"`
+}
+
+// InstrumentSearchRequest represents the request parameters for the instrument search endpoint
+type InstrumentSearchRequest struct {
+	Query string `form:"q" binding:"required"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// InstrumentSearchResponse represents the response for the instrument search endpoint
+type InstrumentSearchResponse struct {
+	Success bool         `json:"success"`
+	Data    []Instrument `json:"data"`
+	Error   string       `json:"error,omitempty"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Exchange identifiers of end-of-day price data
+const (
+	ExchangeNSE = "NSE"
+	ExchangeBSE = "BSE"
+)
+
+// PriceBar represents the end-of-day open, high, low, close and volume of a security on one trading day
+type PriceBar struct {
+	ISIN      string    `json:"isin"`
+	Symbol    string    `json:"symbol"`
+	Exchange  string    `json:"exchange"`
+	Date      time.Time `json:"date"`
+	Open      float64   `json:"open"`
+	High      float64   `json:"high"`
+	Low       float64   `json:"low"`
+	Close     float64   `json:"close"`
+	PrevClose float64   `json:"prevClose"`
+	Volume    float64   `json:"volume"`
+	Turnover  float64   `json:"turnover"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// SchemeNAV represents the net asset value of a mutual fund scheme on one day, as published by AMFI
+type SchemeNAV struct {
+	SchemeCode       string    `json:"schemeCode"`
+	ISINGrowth       string    `json:"isinGrowth"`
+	ISINReinvestment string    `json:"isinReinvestment"`
+	SchemeName       string    `json:"schemeName"`
+	FundHouse        string    `json:"fundHouse"`
+	Category         string    `json:"category"`
+	NAV              float64   `json:"nav"`
+	Date             time.Time `json:"date"`
+}
+
+// NAVQuote represents the latest known NAV of a scheme ISIN and the NAV published before it
+type NAVQuote struct {
+	ISIN        string    `json:"isin"`
+	NAV         float64   `json:"nav"`
+	PreviousNAV float64   `json:"previousNav"`
+	Date        time.Time `json:"date"`
+}
@@ -4,4 +4,9 @@ package models
 const (
 	PlatformICICIDirect = "icici_direct"
 	PlatformZerodha     = "zerodha"
+	PlatformUpstox      = "upstox"
+	PlatformAngelOne    = "angelone"
+
+	// PlatformCAS marks holdings imported from a depository Consolidated Account Statement
+	PlatformCAS = "cas"
 )
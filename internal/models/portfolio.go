@@ -2,12 +2,15 @@ package models
 
 import "time"
 
-// HoldingType represents the type of holding (stock or mutual fund)
+// HoldingType represents the type of holding (stock, mutual fund, ETF, bond or sovereign gold bond)
 type HoldingType string
 
 const (
 	HoldingTypeStock      HoldingType = "stock"
 	HoldingTypeMutualFund HoldingType = "mutualfund"
+	HoldingTypeETF        HoldingType = "etf"
+	HoldingTypeBond       HoldingType = "bond"
+	HoldingTypeSGB        HoldingType = "sgb"
 )
 
 // Holding represents a normalized holding item from any broker
@@ -38,7 +41,7 @@ type Portfolio struct {
 
 // PortfolioRequest represents the request parameters for portfolio endpoints
 type PortfolioRequest struct {
-	Type HoldingType `form:"type" binding:"omitempty,oneof=stock mutualfund all"`
+	Type HoldingType `form:"type" binding:"omitempty,oneof=stock mutualfund etf bond sgb all"`
 }
 
 // PortfolioResponse represents the response for portfolio endpoints
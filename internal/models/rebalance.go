@@ -0,0 +1,125 @@
+package models
+
+import "time"
+
+// TargetKind represents what an allocation target weights
+type TargetKind string
+
+const (
+	// TargetKindAssetClass targets a share of the portfolio for an asset class
+	TargetKindAssetClass TargetKind = "assetClass"
+
+	// TargetKindHolding targets a share of the portfolio for a single security, keyed by ISIN
+	TargetKindHolding TargetKind = "holding"
+)
+
+// AllocationTarget represents a user's desired share of the portfolio for an asset class or holding
+type AllocationTarget struct {
+	ID   int64      `json:"id"`
+	Kind TargetKind `json:"kind"`
+
+	// Key is the asset class for asset class targets and the ISIN for holding targets
+	Key string `json:"key"`
+
+	// Weight is the target percentage of the portfolio
+	Weight    float64   `json:"weight"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// AllocationTargetRequest represents the request body for creating or updating an allocation target
+type AllocationTargetRequest struct {
+	Kind   TargetKind `json:"kind" binding:"required,oneof=assetClass holding"`
+	Key    string     `json:"key" binding:"required"`
+	Weight float64    `json:"weight" binding:"required,gt=0,lte=100"`
+}
+
+// AllocationTargetResponse represents the response for endpoints returning a single allocation target
+type AllocationTargetResponse struct {
+	Success bool             `json:"success"`
+	Data    AllocationTarget `json:"data"`
+	Error   string           `json:"error,omitempty"`
+}
+
+// AllocationTargetsResponse represents the response for endpoints returning a user's allocation targets
+type AllocationTargetsResponse struct {
+	Success bool               `json:"success"`
+	Data    []AllocationTarget `json:"data"`
+	Error   string             `json:"error,omitempty"`
+}
+
+// RebalanceMode represents how a rebalancing plan may move money
+type RebalanceMode string
+
+const (
+	// RebalanceModeFull buys and sells to bring every target back to weight
+	RebalanceModeFull RebalanceMode = "full"
+
+	// RebalanceModeNewMoneyOnly only buys, spending new money on the most underweight targets
+	RebalanceModeNewMoneyOnly RebalanceMode = "newMoneyOnly"
+)
+
+// RebalancePlanRequest represents the request body for the rebalance plan endpoint
+type RebalancePlanRequest struct {
+	// Kind selects which targets to rebalance against (default: holding targets when any exist, otherwise asset class)
+	Kind TargetKind `json:"kind" binding:"omitempty,oneof=assetClass holding"`
+
+	// Mode defaults to full
+	Mode RebalanceMode `json:"mode" binding:"omitempty,oneof=full newMoneyOnly"`
+
+	// NewMoney is cash to be invested in addition to the current holdings
+	NewMoney float64 `json:"newMoney" binding:"gte=0"`
+
+	// MinTradeValue is the smallest trade value worth placing (default: 500)
+	MinTradeValue float64 `json:"minTradeValue" binding:"gte=0"`
+}
+
+// TargetDrift represents how far an asset class or holding is from its target
+type TargetDrift struct {
+	Key           string  `json:"key"`
+	Name          string  `json:"name,omitempty"`
+	CurrentValue  float64 `json:"currentValue"`
+	CurrentWeight float64 `json:"currentWeight"`
+	TargetWeight  float64 `json:"targetWeight"`
+	TargetValue   float64 `json:"targetValue"`
+
+	// Drift is the current weight minus the target weight, in percentage points
+	Drift float64 `json:"drift"`
+}
+
+// RebalanceTrade represents one order in a rebalancing plan
+type RebalanceTrade struct {
+	ItemName string    `json:"itemName"`
+	ISIN     string    `json:"isin"`
+	Platform string    `json:"platform"`
+	Side     TradeSide `json:"side"`
+	Quantity float64   `json:"quantity"`
+	Price    float64   `json:"price"`
+	Value    float64   `json:"value"`
+}
+
+// RebalancePlan represents the drift of a portfolio from its targets and the trades that correct it
+type RebalancePlan struct {
+	Kind          TargetKind       `json:"kind"`
+	Mode          RebalanceMode    `json:"mode"`
+	TotalValue    float64          `json:"totalValue"`
+	NewMoney      float64          `json:"newMoney"`
+	MinTradeValue float64          `json:"minTradeValue"`
+	Drift         []TargetDrift    `json:"drift"`
+	Trades        []RebalanceTrade `json:"trades"`
+
+	// CashRemaining is the new money plus sale proceeds left after the planned buys
+	CashRemaining float64 `json:"cashRemaining"`
+
+	// Unplaced lists targets that need buying but have no current holding to buy into
+	Unplaced []TargetDrift `json:"unplaced"`
+
+	// Warnings lists holdings left out of the plan, such as those whose asset class is unknown
+	Warnings []string `json:"warnings"`
+}
+
+// RebalancePlanResponse represents the response for the rebalance plan endpoint
+type RebalancePlanResponse struct {
+	Success bool          `json:"success"`
+	Data    RebalancePlan `json:"data"`
+	Error   string        `json:"error,omitempty"`
+}
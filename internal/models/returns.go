@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// ReturnMetrics represents the annualized and time-weighted performance of a portfolio, platform or holding.
+// Metrics that cannot be computed from the available history are omitted.
+type ReturnMetrics struct {
+	// XIRR is the annualized money-weighted return in percent
+	XIRR *float64 `json:"xirr,omitempty"`
+
+	// TWR is the cumulative time-weighted return in percent over the snapshot window
+	TWR *float64 `json:"twr,omitempty"`
+
+	// AnnualizedTWR is the time-weighted return in percent per year, given for windows of at least a year
+	AnnualizedTWR *float64 `json:"annualizedTwr,omitempty"`
+
+	// TWRFrom and TWRTo bound the snapshot window the time-weighted return covers
+	TWRFrom *time.Time `json:"twrFrom,omitempty"`
+	TWRTo   *time.Time `json:"twrTo,omitempty"`
+
+	// CurrentValue is the latest value of the holdings measured
+	CurrentValue float64 `json:"currentValue"`
+
+	// CoveredValue is the part of CurrentValue whose full trade history is known and included in XIRR
+	CoveredValue float64 `json:"coveredValue"`
+}
+
+// HoldingReturns represents the returns of a single holding at a platform
+type HoldingReturns struct {
+	ItemName string `json:"itemName"`
+	ISIN     string `json:"isin"`
+	Platform string `json:"platform"`
+	ReturnMetrics
+}
+
+// PlatformReturns represents the returns of all holdings at a platform
+type PlatformReturns struct {
+	Platform string `json:"platform"`
+	ReturnMetrics
+}
+
+// PortfolioReturns represents returns at portfolio, platform and holding level
+type PortfolioReturns struct {
+	AsOf      time.Time         `json:"asOf"`
+	Portfolio ReturnMetrics     `json:"portfolio"`
+	Platforms []PlatformReturns `json:"platforms"`
+	Holdings  []HoldingReturns  `json:"holdings"`
+}
+
+// PortfolioReturnsResponse represents the response for the portfolio returns endpoint
+type PortfolioReturnsResponse struct {
+	Success bool             `json:"success"`
+	Data    PortfolioReturns `json:"data"`
+	Error   string           `json:"error,omitempty"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// SnapshotSource identifies what recorded a portfolio snapshot
+type SnapshotSource string
+
+const (
+	// SnapshotSourceRefresh is a snapshot taken when a user refreshes their portfolio
+	SnapshotSourceRefresh SnapshotSource = "refresh"
+
+	// SnapshotSourceEndOfDay is a snapshot taken by the scheduled end-of-day job
+	SnapshotSourceEndOfDay SnapshotSource = "eod"
+)
+
+// HoldingSnapshot represents the valuation of a holding at a platform on a given day
+type HoldingSnapshot struct {
+	Date time.Time `json:"date"`
+	Holding
+}
+
+// PortfolioSnapshot represents the portfolio-level totals recorded at one point in time
+type PortfolioSnapshot struct {
+	ID              int64          `json:"id"`
+	Date            time.Time      `json:"date"`
+	TakenAt         time.Time      `json:"takenAt"`
+	Source          SnapshotSource `json:"source"`
+	TotalValue      float64        `json:"totalValue"`
+	TotalInvestment float64        `json:"totalInvestment"`
+	TotalPnL        float64        `json:"totalPnL"`
+	TotalDayChange  float64        `json:"totalDayChange"`
+	HoldingsCount   int            `json:"holdingsCount"`
+}
+
+// HistoryGranularity is the spacing of points in a portfolio history series
+type HistoryGranularity string
+
+const (
+	HistoryGranularityDay   HistoryGranularity = "day"
+	HistoryGranularityWeek  HistoryGranularity = "week"
+	HistoryGranularityMonth HistoryGranularity = "month"
+)
+
+// PortfolioHistoryRequest represents the request parameters for the portfolio history endpoint.
+// Dates are in YYYY-MM-DD form.
+type PortfolioHistoryRequest struct {
+	From        string             `form:"from"`
+	To          string             `form:"to"`
+	Granularity HistoryGranularity `form:"granularity" binding:"omitempty,oneof=day week month"`
+}
+
+// PortfolioHistoryPoint represents the portfolio value at the end of one period
+type PortfolioHistoryPoint struct {
+	Date            time.Time `json:"date"`
+	TotalValue      float64   `json:"totalValue"`
+	TotalInvestment float64   `json:"totalInvestment"`
+	TotalPnL        float64   `json:"totalPnL"`
+	TotalDayChange  float64   `json:"totalDayChange"`
+}
+
+// PortfolioHistory represents a portfolio value time series
+type PortfolioHistory struct {
+	From        time.Time               `json:"from"`
+	To          time.Time               `json:"to"`
+	Granularity HistoryGranularity      `json:"granularity"`
+	Points      []PortfolioHistoryPoint `json:"points"`
+}
+
+// PortfolioHistoryResponse represents the response for the portfolio history endpoint
+type PortfolioHistoryResponse struct {
+	Success bool             `json:"success"`
+	Data    PortfolioHistory `json:"data"`
+	Error   string           `json:"error,omitempty"`
+}
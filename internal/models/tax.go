@@ -0,0 +1,111 @@
+package models
+
+import "time"
+
+// AssetClass represents how a capital asset is treated for capital gains tax
+type AssetClass string
+
+const (
+	// AssetClassEquity covers listed shares and equity oriented mutual funds on which STT is paid
+	AssetClassEquity AssetClass = "equity"
+
+	// AssetClassDebtFund covers debt, liquid and other non-equity mutual funds
+	AssetClassDebtFund AssetClass = "debt_fund"
+
+	// AssetClassOther covers every other capital asset
+	AssetClassOther AssetClass = "other"
+)
+
+// GainType represents the holding period classification of a capital gain
+type GainType string
+
+const (
+	GainTypeShortTerm GainType = "STCG"
+	GainTypeLongTerm  GainType = "LTCG"
+)
+
+// CapitalGainEntry represents the gain on shares or units sold from a single purchase lot
+type CapitalGainEntry struct {
+	ISIN       string     `json:"isin"`
+	Name       string     `json:"name"`
+	Platform   string     `json:"platform"`
+	AssetClass AssetClass `json:"assetClass"`
+	GainType   GainType   `json:"gainType"`
+
+	// Section is the Income Tax Act section the gain is taxed under, e.g. 111A or 112A, or "slab" for gains taxed at slab rates
+	Section    string    `json:"section"`
+	AcquiredAt time.Time `json:"acquiredAt"`
+	SoldAt     time.Time `json:"soldAt"`
+	Quantity   float64   `json:"quantity"`
+	SalePrice  float64   `json:"salePrice"`
+	SaleValue  float64   `json:"saleValue"`
+
+	// ActualCost is the purchase cost including buy charges
+	ActualCost float64 `json:"actualCost"`
+
+	// FairMarketValue is the per unit value on 31-Jan-2018 used for grandfathering, zero when not applicable
+	FairMarketValue float64 `json:"fairMarketValue"`
+
+	// CostOfAcquisition is the cost deducted, after grandfathering where it applies
+	CostOfAcquisition float64 `json:"costOfAcquisition"`
+
+	// TransferExpenses are the sell charges attributed to the sale
+	TransferExpenses float64 `json:"transferExpenses"`
+	Gain             float64 `json:"gain"`
+
+	// TaxRate is the special rate in percent, zero for gains taxed at slab rates
+	TaxRate float64 `json:"taxRate"`
+}
+
+// CapitalGainsSummary totals a capital gains report
+type CapitalGainsSummary struct {
+	EquitySTCG        float64 `json:"equitySTCG"`
+	EquityLTCG        float64 `json:"equityLTCG"`
+	LTCGExemption     float64 `json:"ltcgExemption"`
+	TaxableEquityLTCG float64 `json:"taxableEquityLTCG"`
+	DebtFundGains     float64 `json:"debtFundGains"`
+	OtherSTCG         float64 `json:"otherSTCG"`
+	OtherLTCG         float64 `json:"otherLTCG"`
+
+	// SlabRateGains are gains added to income and taxed at slab rates
+	SlabRateGains float64 `json:"slabRateGains"`
+
+	// EstimatedTax is the tax on gains taxed at special rates, before surcharge and cess
+	EstimatedTax float64 `json:"estimatedTax"`
+}
+
+// CapitalGainsReport represents the realized capital gains of a user for a financial year
+type CapitalGainsReport struct {
+	FinancialYear string              `json:"financialYear"`
+	From          time.Time           `json:"from"`
+	To            time.Time           `json:"to"`
+	Entries       []CapitalGainEntry  `json:"entries"`
+	Summary       CapitalGainsSummary `json:"summary"`
+	Warnings      []string            `json:"warnings,omitempty"`
+}
+
+// CapitalGainsRequest represents the query parameters for the capital gains endpoint
+type CapitalGainsRequest struct {
+	FinancialYear string `form:"fy"`
+	Format        string `form:"format" binding:"omitempty,oneof=json csv"`
+}
+
+// CapitalGainsResponse represents the response for the capital gains endpoint
+type CapitalGainsResponse struct {
+	Success bool               `json:"success"`
+	Data    CapitalGainsReport `json:"data"`
+	Error   string             `json:"error,omitempty"`
+}
+
+// FairMarketValueImportResult summarizes an import of 31-Jan-2018 fair market values
+type FairMarketValueImportResult struct {
+	Files    int `json:"files"`
+	Imported int `json:"imported"`
+}
+
+// FairMarketValueImportResponse represents the response for the fair market value import endpoint
+type FairMarketValueImportResponse struct {
+	Success bool                        `json:"success"`
+	Data    FairMarketValueImportResult `json:"data"`
+	Error   string                      `json:"error,omitempty"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// TradeSide represents the side of a trade (buy or sell)
+type TradeSide string
+
+const (
+	TradeSideBuy  TradeSide = "buy"
+	TradeSideSell TradeSide = "sell"
+)
+
+// Trade represents a single executed trade from a broker tradebook
+type Trade struct {
+	TradeID   string    `json:"tradeId"`
+	OrderID   string    `json:"orderId"`
+	ISIN      string    `json:"isin"`
+	Symbol    string    `json:"symbol"`
+	Exchange  string    `json:"exchange"`
+	Side      TradeSide `json:"side"`
+	Quantity  float64   `json:"quantity"`
+	Price     float64   `json:"price"`
+	Charges   float64   `json:"charges"`
+	TradeDate time.Time `json:"tradeDate"`
+	Platform  string    `json:"platform"`
+}
+
+// TradeImportResult summarizes a tradebook import
+type TradeImportResult struct {
+	Platform   string `json:"platform"`
+	Total      int    `json:"total"`
+	Imported   int    `json:"imported"`
+	Duplicates int    `json:"duplicates"`
+}
+
+// TradeImportResponse represents the response for the tradebook import endpoint
+type TradeImportResponse struct {
+	Success bool              `json:"success"`
+	Data    TradeImportResult `json:"data"`
+	Error   string            `json:"error,omitempty"`
+}
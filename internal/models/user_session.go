@@ -11,8 +11,7 @@ type UserSession struct {
 	UserID           string    `json:"userId"`
 	Email            string    `json:"email"`
 	SessionID        string    `json:"sessionId"`
-	ZerodhaConnected bool      `json:"zerodhaConnected"`
-	ICICIConnected   bool      `json:"iciciConnected"`
+	ConnectedBrokers []string  `json:"connectedBrokers"`
 	CreatedAt        time.Time `json:"createdAt"`
 	LastAccessedAt   time.Time `json:"lastAccessedAt"`
 	ExpiresAt        time.Time `json:"expiresAt"`
@@ -23,12 +22,13 @@ func NewUserSession(email string, sessionDuration time.Duration) *UserSession {
 	userID := uuid.New().String()
 	now := time.Now()
 	return &UserSession{
-		UserID:         userID,
-		Email:          email,
-		SessionID:      uuid.New().String(),
-		CreatedAt:      now,
-		LastAccessedAt: now,
-		ExpiresAt:      now.Add(sessionDuration),
+		UserID:           userID,
+		Email:            email,
+		SessionID:        uuid.New().String(),
+		ConnectedBrokers: []string{},
+		CreatedAt:        now,
+		LastAccessedAt:   now,
+		ExpiresAt:        now.Add(sessionDuration),
 	}
 }
 
@@ -46,18 +46,20 @@ func (s *UserSession) Touch() {
 type SessionInfo struct {
 	UserID           string    `json:"userId"`
 	Email            string    `json:"email"`
-	ZerodhaConnected bool      `json:"zerodhaConnected"`
-	ICICIConnected   bool      `json:"iciciConnected"`
+	ConnectedBrokers []string  `json:"connectedBrokers"`
 	ExpiresAt        time.Time `json:"expiresAt"`
 }
 
 // GetInfo returns the public session information
 func (s *UserSession) GetInfo() SessionInfo {
+	connected := s.ConnectedBrokers
+	if connected == nil {
+		connected = []string{}
+	}
 	return SessionInfo{
 		UserID:           s.UserID,
 		Email:            s.Email,
-		ZerodhaConnected: s.ZerodhaConnected,
-		ICICIConnected:   s.ICICIConnected,
+		ConnectedBrokers: connected,
 		ExpiresAt:        s.ExpiresAt,
 	}
 }
@@ -65,11 +67,13 @@ func (s *UserSession) GetInfo() SessionInfo {
 // UserCredentials represents user credentials for broker authentication
 type UserCredentials struct {
 	UserID       string `json:"userId"`
-	BrokerType   string `json:"brokerType" binding:"required,oneof=zerodha icici_direct"`
+	BrokerType   string `json:"brokerType" binding:"required"` // Validated against the broker registry
 	APIKey       string `json:"apiKey" binding:"required"`
 	APISecret    string `json:"apiSecret" binding:"required"`
 	RequestToken string `json:"requestToken"`
-	Password     string `json:"password,omitempty"` // For ICICI Direct
+	Password     string `json:"password,omitempty"`    // For ICICI Direct, MPIN for Angel One
+	RedirectURI  string `json:"redirectUri,omitempty"` // For Upstox
+	ClientCode   string `json:"clientCode,omitempty"`  // For Angel One
 }
 
 // SessionResponse represents the response for session-related endpoints
@@ -0,0 +1,105 @@
+package mutualfund
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// Common parser errors
+var (
+	ErrInvalidNAVFile = errors.New("invalid NAVAll file")
+)
+
+// navDateLayout is the date format of the NAVAll file, e.g. 15-Oct-2025
+const navDateLayout = "02-Jan-2006"
+
+// navColumns is the number of fields in a NAVAll scheme row
+const navColumns = 6
+
+// ParseNAVAll parses the AMFI NAVAll.txt format.
+// The file is a semicolon-separated table of schemes interleaved with lines naming the scheme category
+// and fund house of the rows that follow. Schemes whose NAV is not available are skipped.
+func ParseNAVAll(r io.Reader) ([]models.SchemeNAV, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var (
+		navs      []models.SchemeNAV
+		category  string
+		fundHouse string
+		header    bool
+		line      int
+	)
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if text == "" {
+			continue
+		}
+
+		if !header {
+			if !strings.HasPrefix(strings.ToLower(text), "scheme code;") {
+				return nil, fmt.Errorf("%w: missing header", ErrInvalidNAVFile)
+			}
+			header = true
+			continue
+		}
+
+		fields := strings.Split(text, ";")
+		if len(fields) < navColumns {
+			// Section lines name the category, e.g. "Open Ended Schemes(Equity Scheme - Large Cap Fund)",
+			// followed by the fund house
+			if strings.Contains(text, "Schemes(") || strings.HasSuffix(text, "Schemes") {
+				category = text
+				fundHouse = ""
+			} else {
+				fundHouse = text
+			}
+			continue
+		}
+
+		nav, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(fields[4]), ",", ""), 64)
+		if err != nil || nav <= 0 {
+			continue
+		}
+		date, err := time.Parse(navDateLayout, strings.TrimSpace(fields[5]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid date %q", ErrInvalidNAVFile, line, fields[5])
+		}
+
+		navs = append(navs, models.SchemeNAV{
+			SchemeCode:       strings.TrimSpace(fields[0]),
+			ISINGrowth:       navISIN(fields[1]),
+			ISINReinvestment: navISIN(fields[2]),
+			SchemeName:       strings.TrimSpace(fields[3]),
+			FundHouse:        fundHouse,
+			Category:         category,
+			NAV:              nav,
+			Date:             date,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNAVFile, err)
+	}
+	if !header {
+		return nil, fmt.Errorf("%w: empty file", ErrInvalidNAVFile)
+	}
+
+	return navs, nil
+}
+
+// navISIN normalizes an ISIN cell, which AMFI leaves blank or sets to "-" when a plan has no such option
+func navISIN(cell string) string {
+	isin := strings.ToUpper(strings.TrimSpace(cell))
+	if len(isin) != 12 {
+		return ""
+	}
+	return isin
+}
@@ -0,0 +1,135 @@
+package mutualfund
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNAVAll(t *testing.T) {
+	file, err := os.Open("testdata/NAVAll.txt")
+	assert.NoError(t, err)
+	defer file.Close()
+
+	navs, err := ParseNAVAll(file)
+	assert.NoError(t, err)
+	// The segregated portfolio without a NAV is skipped
+	assert.Len(t, navs, 6)
+
+	first := navs[0]
+	assert.Equal(t, "119551", first.SchemeCode)
+	assert.Equal(t, "INF209KA12Z1", first.ISINGrowth)
+	assert.Equal(t, "INF209KA13Z9", first.ISINReinvestment)
+	assert.Equal(t, "Aditya Birla Sun Life Banking & PSU Debt Fund  - DIRECT - IDCW", first.SchemeName)
+	assert.Equal(t, "Aditya Birla Sun Life Mutual Fund", first.FundHouse)
+	assert.Equal(t, "Open Ended Schemes(Debt Scheme - Banking and PSU Fund)", first.Category)
+	assert.Equal(t, 108.4375, first.NAV)
+	assert.Equal(t, time.Date(2025, 10, 14, 0, 0, 0, 0, time.UTC), first.Date)
+
+	// A dash means the plan has no reinvestment option
+	assert.Equal(t, "INF209K01YY7", navs[1].ISINGrowth)
+	assert.Empty(t, navs[1].ISINReinvestment)
+
+	// The fund house changes within a category
+	assert.Equal(t, "ICICI Prudential Mutual Fund", navs[4].FundHouse)
+	assert.Equal(t, "Open Ended Schemes(Equity Scheme - Large Cap Fund)", navs[4].Category)
+
+	last := navs[5]
+	assert.Equal(t, "SBI Mutual Fund", last.FundHouse)
+	assert.Equal(t, "Close Ended Schemes(Income)", last.Category)
+	assert.Empty(t, last.ISINReinvestment)
+	assert.Equal(t, time.Date(2025, 10, 13, 0, 0, 0, 0, time.UTC), last.Date)
+}
+
+func TestParseNAVAllInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "empty", input: ""},
+		{name: "missing header", input: "119551;INF209KA12Z1;-;Fund;10.5;14-Oct-2025\n"},
+		{name: "invalid date", input: "Scheme Code;ISIN Div Payout/ ISIN Growth;ISIN Div Reinvestment;Scheme Name;Net Asset Value;Date\n119551;INF209KA12Z1;-;Fund;10.5;2025-10-14\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseNAVAll(strings.NewReader(tt.input))
+			assert.ErrorIs(t, err, ErrInvalidNAVFile)
+		})
+	}
+}
+
+// fakeNAVRepository records the NAVs saved by the service
+type fakeNAVRepository struct {
+	saved []models.SchemeNAV
+}
+
+func (r *fakeNAVRepository) SaveNAVs(navs []models.SchemeNAV) (int, error) {
+	r.saved = append(r.saved, navs...)
+	return len(navs), nil
+}
+
+func (r *fakeNAVRepository) GetLatestNAVs(isins []string) (map[string]models.NAVQuote, error) {
+	return map[string]models.NAVQuote{}, nil
+}
+
+func (r *fakeNAVRepository) GetSchemeCategories() (map[string]string, error) {
+	return map[string]string{"INF000000001": "Open Ended Schemes(Debt Scheme - Liquid Fund)"}, nil
+}
+
+func TestIngestFromFile(t *testing.T) {
+	repo := &fakeNAVRepository{}
+	service := NewService(ServiceConfig{Repository: repo, Source: "testdata/NAVAll.txt"})
+
+	count, err := service.Ingest(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 6, count)
+	assert.Len(t, repo.saved, 6)
+
+	category, ok := service.SchemeCategory("INF179K01YV8")
+	assert.True(t, ok)
+	assert.Equal(t, "Open Ended Schemes(Equity Scheme - Large Cap Fund)", category)
+	category, _ = service.SchemeCategory("INF209KA13Z9")
+	assert.Equal(t, "Open Ended Schemes(Debt Scheme - Banking and PSU Fund)", category)
+
+	_, err = NewService(ServiceConfig{Repository: repo, Source: "testdata/missing.txt"}).Ingest(context.Background())
+	assert.Error(t, err)
+}
+
+func TestLoadSchemeCategories(t *testing.T) {
+	service := NewService(ServiceConfig{Repository: &fakeNAVRepository{}})
+	_, ok := service.SchemeCategory("INF000000001")
+	assert.False(t, ok)
+
+	assert.NoError(t, service.LoadSchemeCategories())
+	category, ok := service.SchemeCategory("inf000000001")
+	assert.True(t, ok)
+	assert.Equal(t, "Open Ended Schemes(Debt Scheme - Liquid Fund)", category)
+}
+
+func TestIngestFromURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/NAVAll.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, "testdata/NAVAll.txt")
+	}))
+	defer server.Close()
+
+	repo := &fakeNAVRepository{}
+	service := NewService(ServiceConfig{Repository: repo, Source: server.URL + "/NAVAll.txt"})
+	count, err := service.Ingest(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 6, count)
+
+	_, err = NewService(ServiceConfig{Repository: repo, Source: server.URL + "/missing.txt"}).Ingest(context.Background())
+	assert.Error(t, err)
+}
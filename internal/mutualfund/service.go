@@ -0,0 +1,187 @@
+package mutualfund
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// DefaultNAVAllURL is where AMFI publishes the latest NAV of every scheme
+const DefaultNAVAllURL = "https://portal.amfiindia.com/spages/NAVAll.txt"
+
+// NAVRepository defines the interface for storing the daily NAV history
+type NAVRepository interface {
+	// SaveNAVs stores scheme NAVs, replacing any already stored for the same scheme and day, and returns the number stored
+	SaveNAVs(navs []models.SchemeNAV) (int, error)
+
+	// GetLatestNAVs retrieves the latest NAV of each of the given scheme ISINs, keyed by ISIN
+	GetLatestNAVs(isins []string) (map[string]models.NAVQuote, error)
+
+	// GetSchemeCategories retrieves the AMFI category each scheme ISIN was last published under, keyed by ISIN
+	GetSchemeCategories() (map[string]string, error)
+}
+
+// ServiceConfig holds configuration for the mutual fund NAV service
+type ServiceConfig struct {
+	Repository NAVRepository
+
+	// Source is the NAVAll.txt file path or http(s) URL (default: DefaultNAVAllURL)
+	Source string
+
+	// HTTPClient downloads the source when it is a URL (default: a client with a one minute timeout)
+	HTTPClient *http.Client
+}
+
+// Service ingests AMFI NAVs and serves the latest NAV and category of mutual fund schemes
+type Service struct {
+	repository NAVRepository
+	source     string
+	httpClient *http.Client
+
+	categoriesMu sync.RWMutex
+	categories   map[string]string
+}
+
+// NewService creates a new mutual fund NAV service
+func NewService(config ServiceConfig) *Service {
+	if config.Source == "" {
+		config.Source = DefaultNAVAllURL
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: time.Minute}
+	}
+
+	return &Service{
+		repository: config.Repository,
+		source:     config.Source,
+		httpClient: config.HTTPClient,
+		categories: make(map[string]string),
+	}
+}
+
+// Ingest reads the NAVAll source and stores its NAVs, returning the number stored
+func (s *Service) Ingest(ctx context.Context) (int, error) {
+	body, err := s.open(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read NAVs from %s: %w", s.source, err)
+	}
+	defer body.Close()
+
+	navs, err := ParseNAVAll(body)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := s.repository.SaveNAVs(navs)
+	if err != nil {
+		return 0, err
+	}
+
+	categories := make(map[string]string, 2*len(navs))
+	for _, nav := range navs {
+		for _, isin := range []string{nav.ISINGrowth, nav.ISINReinvestment} {
+			if isin != "" && nav.Category != "" {
+				categories[isin] = nav.Category
+			}
+		}
+	}
+	s.setCategories(categories)
+
+	return count, nil
+}
+
+// LoadSchemeCategories loads the scheme categories stored by earlier ingests, so they are known before the next one
+func (s *Service) LoadSchemeCategories() error {
+	categories, err := s.repository.GetSchemeCategories()
+	if err != nil {
+		return err
+	}
+	s.setCategories(categories)
+	return nil
+}
+
+// SchemeCategory returns the AMFI category of a scheme ISIN, e.g. "Open Ended Schemes(Equity Scheme - Large Cap Fund)"
+func (s *Service) SchemeCategory(isin string) (string, bool) {
+	s.categoriesMu.RLock()
+	defer s.categoriesMu.RUnlock()
+
+	category, ok := s.categories[strings.ToUpper(isin)]
+	return category, ok
+}
+
+// setCategories merges scheme categories into those known
+func (s *Service) setCategories(categories map[string]string) {
+	s.categoriesMu.Lock()
+	defer s.categoriesMu.Unlock()
+
+	for isin, category := range categories {
+		s.categories[isin] = category
+	}
+}
+
+// RunDailyIngest ingests NAVs once at start and then at every interval until ctx is cancelled.
+// A failed ingest is logged and retried at the next interval.
+func (s *Service) RunDailyIngest(ctx context.Context, interval time.Duration) {
+	ingest := func() {
+		count, err := s.Ingest(ctx)
+		if err != nil {
+			log.Printf("Error ingesting mutual fund NAVs: %v", err)
+			return
+		}
+		log.Printf("Ingested %d mutual fund NAVs", count)
+	}
+
+	if err := s.LoadSchemeCategories(); err != nil {
+		log.Printf("Error loading mutual fund scheme categories: %v", err)
+	}
+	ingest()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ingest()
+		}
+	}
+}
+
+// LatestNAVs retrieves the latest NAV of each of the given scheme ISINs, keyed by ISIN
+func (s *Service) LatestNAVs(isins []string) (map[string]models.NAVQuote, error) {
+	if len(isins) == 0 {
+		return map[string]models.NAVQuote{}, nil
+	}
+	return s.repository.GetLatestNAVs(isins)
+}
+
+// open returns the contents of the source, downloading it when the source is a URL
+func (s *Service) open(ctx context.Context) (io.ReadCloser, error) {
+	if !strings.HasPrefix(s.source, "http://") && !strings.HasPrefix(s.source, "https://") {
+		return os.Open(s.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,90 @@
+package news
+
+// ahoCorasick finds every occurrence of a fixed set of patterns in a text in a single pass
+type ahoCorasick struct {
+	nodes    []acNode
+	patterns []string
+}
+
+// acNode is a state of the automaton: a prefix of one or more patterns
+type acNode struct {
+	next map[byte]int
+
+	// fail is the state of the longest proper suffix of this prefix that is also a prefix
+	fail int
+
+	// output lists the patterns ending at this state, including those reached through fail links
+	output []int
+}
+
+// newAhoCorasick builds the automaton for a set of patterns
+func newAhoCorasick(patterns []string) *ahoCorasick {
+	a := &ahoCorasick{
+		nodes:    []acNode{{next: make(map[byte]int)}},
+		patterns: patterns,
+	}
+
+	// Build the trie of patterns
+	for i, pattern := range patterns {
+		state := 0
+		for j := 0; j < len(pattern); j++ {
+			next, ok := a.nodes[state].next[pattern[j]]
+			if !ok {
+				next = len(a.nodes)
+				a.nodes = append(a.nodes, acNode{next: make(map[byte]int)})
+				a.nodes[state].next[pattern[j]] = next
+			}
+			state = next
+		}
+		a.nodes[state].output = append(a.nodes[state].output, i)
+	}
+
+	// Link failure states breadth first, so shorter prefixes are linked before longer ones
+	queue := make([]int, 0, len(a.nodes))
+	for _, child := range a.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for c, child := range a.nodes[state].next {
+			fail := a.nodes[state].fail
+			for fail != 0 {
+				if _, ok := a.nodes[fail].next[c]; ok {
+					break
+				}
+				fail = a.nodes[fail].fail
+			}
+			if target, ok := a.nodes[fail].next[c]; ok && target != child {
+				fail = target
+			} else {
+				fail = 0
+			}
+			a.nodes[child].fail = fail
+			a.nodes[child].output = append(a.nodes[child].output, a.nodes[fail].output...)
+			queue = append(queue, child)
+		}
+	}
+
+	return a
+}
+
+// match calls fn with the pattern index and the start and end offsets of every occurrence in text, overlapping ones included
+func (a *ahoCorasick) match(text string, fn func(pattern, start, end int)) {
+	state := 0
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		for state != 0 {
+			if _, ok := a.nodes[state].next[c]; ok {
+				break
+			}
+			state = a.nodes[state].fail
+		}
+		if next, ok := a.nodes[state].next[c]; ok {
+			state = next
+		}
+		for _, pattern := range a.nodes[state].output {
+			fn(pattern, i+1-len(a.patterns[pattern]), i+1)
+		}
+	}
+}
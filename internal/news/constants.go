@@ -26,7 +26,7 @@ const (
 
 	// Relevance score components
 	KeywordMatchScore      = 0.2
-	SourceReliabilityScore = 0.3
+	SourceReliabilityScore = 0.3 // scaled by the source's reliability
 	RecentNewsScore        = 0.2
 	OlderNewsScore         = 0.1
 
@@ -42,12 +42,9 @@ const (
 	MinConfidenceScore = 0.0
 
 	// Confidence score components
-	MoneyControlConfidence     = 0.4
-	EconomicTimesConfidence    = 0.35
-	BusinessStandardConfidence = 0.35
-	DefaultSourceConfidence    = 0.2
-	ContentQualityScore        = 0.2
-	SentimentStrengthWeight    = 0.2
+	SourceConfidenceWeight  = 0.4 // scaled by the source's reliability
+	ContentQualityScore     = 0.2
+	SentimentStrengthWeight = 0.2
 )
 
 // Action types
@@ -58,6 +55,48 @@ const (
 	ActionWatch = "WATCH"
 )
 
+// Entity roles, from the stocks an article is about to those it only mentions
+const (
+	RoleSubject   = "subject"
+	RolePeer      = "peer"
+	RoleMentioned = "mentioned"
+)
+
+// Event types, as reported by the LLM sentiment analyzer
+const (
+	EventEarnings        = "earnings"         // results and profit or revenue updates
+	EventGuidance        = "guidance"         // outlook and forecasts by the company
+	EventRating          = "rating"           // analyst ratings and target prices
+	EventDeal            = "deal"             // orders, contracts, partnerships, mergers and acquisitions
+	EventCorporateAction = "corporate_action" // dividends, buybacks, splits, bonuses and stake sales
+	EventManagement      = "management"       // leadership and governance changes
+	EventRegulatory      = "regulatory"       // regulators, courts, taxes and policy aimed at the company or its sector
+	EventMacro           = "macro"            // the economy and the market as a whole
+	EventOther           = "other"
+)
+
+// Impact horizons, from the soonest to the latest a stock is expected to move on news
+const (
+	HorizonIntraday   = "intraday"
+	HorizonShortTerm  = "short_term"  // days to weeks
+	HorizonMediumTerm = "medium_term" // months
+	HorizonLongTerm   = "long_term"   // a year or more
+)
+
+// Entity constants
+const (
+	// MinEntityConfidence is how sure a resolver must be of a stock to recommend on it
+	MinEntityConfidence = 0.5
+
+	// Recommendation confidence multipliers by entity role
+	SubjectConfidenceWeight   = 1.0
+	PeerConfidenceWeight      = 0.9
+	MentionedConfidenceWeight = 0.75
+
+	// MinRecommendationConfidence is the confidence a recommendation needs to be served as a latest recommendation
+	MinRecommendationConfidence = 0.5
+)
+
 // Keywords for sentiment analysis
 var (
 	// Positive keywords indicate bullish or positive sentiment
@@ -92,15 +131,3 @@ var (
 		"partnership", "agreement", "contract", "deal",
 	}
 )
-
-// Source reliability rankings
-var (
-	// ReliableSources contains names of sources considered highly reliable
-	ReliableSources = map[string]bool{
-		"MoneyControl":                   true,
-		"Economic Times":                 true,
-		"Business Standard":              true,
-		"Business Standard Markets":      true,
-		"Business Standard Stock Market": true,
-	}
-)
@@ -0,0 +1,513 @@
+package news
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Common dictionary errors
+var (
+	ErrInvalidDictionary = errors.New("invalid stock dictionary")
+)
+
+// dictionaryColumns are the header columns a stock dictionary CSV must contain, in any order
+var dictionaryColumns = []string{"symbol", "name", "aliases"}
+
+// corporateSuffixes are dropped from the end of company names, since news rarely spells them out
+var corporateSuffixes = []string{" ltd", " limited"}
+
+// clauseAbbreviations are words whose trailing full stop does not end a sentence
+var clauseAbbreviations = map[string]bool{
+	"dr": true, "mr": true, "mrs": true, "ms": true, "ltd": true, "co": true, "inc": true,
+	"no": true, "rs": true, "vs": true, "st": true, "corp": true,
+}
+
+// clauseConjunctions separate clauses that may carry opposite sentiment
+var clauseConjunctions = []string{" while ", " whereas ", " even as ", " but "}
+
+// Weights of the ways a company can be mentioned
+const (
+	nameMatchWeight   = 1.0
+	tickerMatchWeight = 0.9
+	aliasMatchWeight  = 0.8
+)
+
+// DictionaryEntry is a company with the names news may use for it
+type DictionaryEntry struct {
+	// Symbol is the NSE symbol, which also matches when written in capitals
+	Symbol  string
+	Name    string
+	Aliases []string
+}
+
+// SymbolMatch is a stock symbol mentioned in a text with its share of all mentions, between 0 and 1
+type SymbolMatch struct {
+	Symbol string  `json:"symbol"`
+	Score  float64 `json:"score"`
+}
+
+// dictionaryCandidate is a company a pattern may refer to
+type dictionaryCandidate struct {
+	symbol string
+	weight float64
+
+	// ticker candidates only match when the text writes the pattern in capitals
+	ticker bool
+}
+
+// DictionaryStockResolver implements StockResolver offline, matching company names, brand aliases and
+// tickers from a dictionary in one pass over the text.
+// Matches are whole words, a longer match wins over the shorter ones inside it ("Tata Motors" over "Tata"),
+// and a name shared by several companies is split between them.
+type DictionaryStockResolver struct {
+	matcher    *ahoCorasick
+	candidates [][]dictionaryCandidate
+	symbols    map[string]bool
+}
+
+// NewDictionaryStockResolver creates a dictionary stock resolver from its entries
+func NewDictionaryStockResolver(entries []DictionaryEntry) *DictionaryStockResolver {
+	var patterns []string
+	var candidates [][]dictionaryCandidate
+	index := make(map[string]int)
+	add := func(text string, candidate dictionaryCandidate) {
+		pattern, _ := normalizeMatchText(text)
+		if strings.TrimSpace(pattern) == "" {
+			return
+		}
+		i, ok := index[pattern]
+		if !ok {
+			i = len(patterns)
+			index[pattern] = i
+			patterns = append(patterns, pattern)
+			candidates = append(candidates, nil)
+		}
+		candidates[i] = append(candidates[i], candidate)
+	}
+
+	symbols := make(map[string]bool)
+	for _, entry := range entries {
+		symbol := strings.ToUpper(strings.TrimSpace(entry.Symbol))
+		if symbol == "" {
+			continue
+		}
+		symbols[symbol] = true
+
+		add(symbol, dictionaryCandidate{symbol: symbol, weight: tickerMatchWeight, ticker: true})
+		if name := trimCorporateSuffix(entry.Name); name != "" {
+			add(name, dictionaryCandidate{symbol: symbol, weight: nameMatchWeight})
+		}
+		for _, alias := range entry.Aliases {
+			add(alias, dictionaryCandidate{symbol: symbol, weight: aliasMatchWeight})
+		}
+	}
+
+	return &DictionaryStockResolver{
+		matcher:    newAhoCorasick(patterns),
+		candidates: candidates,
+		symbols:    symbols,
+	}
+}
+
+// LoadStockDictionaryFile reads a stock dictionary CSV from disk
+func LoadStockDictionaryFile(path string) (*DictionaryStockResolver, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return LoadStockDictionary(file)
+}
+
+// LoadStockDictionary reads a stock dictionary CSV with the columns symbol, name and aliases,
+// where aliases are separated by "|"
+func LoadStockDictionary(r io.Reader) (*DictionaryStockResolver, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDictionary, err)
+	}
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		index[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range dictionaryColumns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidDictionary, column)
+		}
+	}
+
+	var entries []DictionaryEntry
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidDictionary, line, err)
+		}
+		field := func(column string) string {
+			return strings.TrimSpace(record[index[column]])
+		}
+
+		entry := DictionaryEntry{
+			Symbol: field("symbol"),
+			Name:   field("name"),
+		}
+		for _, alias := range strings.Split(field("aliases"), "|") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				entry.Aliases = append(entry.Aliases, alias)
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	return NewDictionaryStockResolver(entries), nil
+}
+
+// Len returns the number of symbols in the dictionary
+func (r *DictionaryStockResolver) Len() int {
+	return len(r.symbols)
+}
+
+// ValidSymbol implements the SymbolValidator interface, accepting the symbols in the dictionary
+func (r *DictionaryStockResolver) ValidSymbol(symbol string) (string, bool) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	return symbol, r.symbols[symbol]
+}
+
+// Match returns the symbols mentioned in a text, highest score first.
+// Market-wide mentions count only when no company is mentioned.
+func (r *DictionaryStockResolver) Match(text string) []SymbolMatch {
+	scores := make(map[string]float64)
+	for _, clause := range splitClauses(text) {
+		for symbol, score := range r.scoreClause(clause) {
+			scores[symbol] += score
+		}
+	}
+	if len(scores) > 1 {
+		delete(scores, MarketSymbol)
+	}
+
+	var total float64
+	for _, score := range scores {
+		total += score
+	}
+	matches := make([]SymbolMatch, 0, len(scores))
+	for symbol, score := range scores {
+		matches = append(matches, SymbolMatch{Symbol: symbol, Score: score / total})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Symbol < matches[j].Symbol
+	})
+	return matches
+}
+
+// ResolveEntities implements the StockResolver interface.
+// The stocks of the first headline clause naming any are its subjects; other stocks in the headline, and stocks
+// sharing a clause with a subject, are peers; the rest are mentioned. Without stocks in the headline the first
+// body clause naming any takes its place. An entity's confidence is the weight of its mentions, up to 1.
+func (r *DictionaryStockResolver) ResolveEntities(ctx context.Context, headline, body string) ([]StockEntity, error) {
+	type mention struct {
+		score      float64
+		inHeadline bool
+		clauses    []int
+	}
+	mentions := make(map[string]*mention)
+	var clauses []string
+	var clauseSymbols [][]string
+	for _, part := range []struct {
+		text       string
+		inHeadline bool
+	}{{headline, true}, {body, false}} {
+		for _, clause := range splitClauses(part.text) {
+			scores := r.scoreClause(clause)
+			if len(scores) == 0 {
+				continue
+			}
+			var symbols []string
+			for symbol, score := range scores {
+				m, ok := mentions[symbol]
+				if !ok {
+					m = &mention{}
+					mentions[symbol] = m
+				}
+				m.score += score
+				m.inHeadline = m.inHeadline || part.inHeadline
+				m.clauses = append(m.clauses, len(clauses))
+				symbols = append(symbols, symbol)
+			}
+			clauses = append(clauses, clause)
+			clauseSymbols = append(clauseSymbols, symbols)
+		}
+	}
+	if len(mentions) > 1 {
+		delete(mentions, MarketSymbol)
+	}
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+
+	// The first clause naming a company other than the market holds the subjects
+	subjects := make(map[string]bool)
+	for _, symbols := range clauseSymbols {
+		for _, symbol := range symbols {
+			if _, ok := mentions[symbol]; ok {
+				subjects[symbol] = true
+			}
+		}
+		if len(subjects) > 0 {
+			break
+		}
+	}
+
+	entities := make([]StockEntity, 0, len(mentions))
+	for symbol, m := range mentions {
+		role := RoleMentioned
+		switch {
+		case subjects[symbol]:
+			role = RoleSubject
+		case m.inHeadline:
+			role = RolePeer
+		default:
+			for _, i := range m.clauses {
+				for _, other := range clauseSymbols[i] {
+					if subjects[other] {
+						role = RolePeer
+					}
+				}
+			}
+		}
+
+		mentionedIn := make([]string, 0, len(m.clauses))
+		for _, i := range m.clauses {
+			mentionedIn = append(mentionedIn, clauses[i])
+		}
+		entities = append(entities, StockEntity{
+			Symbol:     symbol,
+			Role:       role,
+			Confidence: math.Min(m.score, 1),
+			Context:    strings.Join(mentionedIn, ". "),
+		})
+	}
+	sortEntities(entities)
+	return entities, nil
+}
+
+// scoreClause returns the weight of the mentions of each symbol in a clause.
+// A longer match wins over the shorter ones inside it, and a name shared by several companies is split between them.
+func (r *DictionaryStockResolver) scoreClause(clause string) map[string]float64 {
+	lower, cased := normalizeMatchText(clause)
+
+	// Find every occurrence, keeping only the candidates it can refer to
+	type occurrence struct {
+		start, end int
+		candidates []dictionaryCandidate
+	}
+	var occurrences []occurrence
+	r.matcher.match(lower, func(pattern, start, end int) {
+		var matched []dictionaryCandidate
+		word := cased[start+1 : end-1]
+		for _, candidate := range r.candidates[pattern] {
+			if candidate.ticker && word != strings.ToUpper(word) {
+				continue
+			}
+			matched = append(matched, candidate)
+		}
+		if len(matched) > 0 {
+			// Patterns are padded with the spaces around them, which neighbouring matches share
+			occurrences = append(occurrences, occurrence{start: start + 1, end: end - 1, candidates: matched})
+		}
+	})
+
+	// Keep the leftmost longest occurrences that do not overlap
+	sort.Slice(occurrences, func(i, j int) bool {
+		if occurrences[i].start != occurrences[j].start {
+			return occurrences[i].start < occurrences[j].start
+		}
+		return occurrences[i].end > occurrences[j].end
+	})
+	scores := make(map[string]float64)
+	covered := 0
+	for _, o := range occurrences {
+		if o.start < covered {
+			continue
+		}
+		covered = o.end
+
+		weights := make(map[string]float64)
+		for _, candidate := range o.candidates {
+			if candidate.weight > weights[candidate.symbol] {
+				weights[candidate.symbol] = candidate.weight
+			}
+		}
+		for symbol, weight := range weights {
+			scores[symbol] += weight / float64(len(weights))
+		}
+	}
+	return scores
+}
+
+// ResolveSymbol implements the StockResolver interface, returning the best scoring symbol,
+// or an empty string when nothing matches or the best matches tie
+func (r *DictionaryStockResolver) ResolveSymbol(ctx context.Context, text string) (string, error) {
+	matches := r.Match(text)
+	if len(matches) == 0 || (len(matches) > 1 && matches[0].Score == matches[1].Score) {
+		return "", nil
+	}
+	return matches[0].Symbol, nil
+}
+
+// normalizeMatchText reduces a text to words separated and surrounded by single spaces,
+// returning it lowercased and as written. Bytes of non-ASCII characters count as letters.
+func normalizeMatchText(text string) (lower, cased string) {
+	var l, c strings.Builder
+	l.Grow(len(text) + 2)
+	c.Grow(len(text) + 2)
+	l.WriteByte(' ')
+	c.WriteByte(' ')
+
+	space := true
+	for i := 0; i < len(text); i++ {
+		b := text[i]
+		switch {
+		case b >= 'a' && b <= 'z', b >= '0' && b <= '9', b >= 0x80:
+			l.WriteByte(b)
+			c.WriteByte(b)
+			space = false
+		case b >= 'A' && b <= 'Z':
+			l.WriteByte(b + 'a' - 'A')
+			c.WriteByte(b)
+			space = false
+		case !space:
+			l.WriteByte(' ')
+			c.WriteByte(' ')
+			space = true
+		}
+	}
+	if !space {
+		l.WriteByte(' ')
+		c.WriteByte(' ')
+	}
+	return l.String(), c.String()
+}
+
+// splitClauses splits a text into sentences, and sentences into clauses at contrasting conjunctions,
+// so that "Infosys rises while Wipro falls" attributes each move to its own company
+func splitClauses(text string) []string {
+	var clauses []string
+	add := func(clause string) {
+		if clause = strings.TrimSpace(clause); clause != "" {
+			clauses = append(clauses, clause)
+		}
+	}
+
+	start := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '.', '!', '?':
+			// A full stop ends a sentence only before a space and after a word that is neither an initial nor an abbreviation
+			if i+1 < len(text) && text[i+1] != ' ' && text[i+1] != '\n' {
+				continue
+			}
+			if word := lastWord(text[start:i]); text[i] == '.' && (len(word) == 1 || clauseAbbreviations[strings.ToLower(word)]) {
+				continue
+			}
+		case ';', '\n':
+		default:
+			continue
+		}
+		add(text[start:i])
+		start = i + 1
+	}
+	add(text[start:])
+
+	var split []string
+	for _, clause := range clauses {
+		for {
+			i, conjunction := indexConjunction(clause)
+			if i < 0 {
+				break
+			}
+			if head := strings.TrimSpace(clause[:i]); head != "" {
+				split = append(split, head)
+			}
+			clause = clause[i+len(conjunction):]
+		}
+		if clause = strings.TrimSpace(clause); clause != "" {
+			split = append(split, clause)
+		}
+	}
+	return split
+}
+
+// indexConjunction returns the offset of the first clause conjunction in a text, ignoring ASCII case, and the conjunction
+func indexConjunction(text string) (int, string) {
+	lower := []byte(text)
+	for i, c := range lower {
+		if c >= 'A' && c <= 'Z' {
+			lower[i] = c + 'a' - 'A'
+		}
+	}
+
+	first, found := -1, ""
+	for _, conjunction := range clauseConjunctions {
+		if i := strings.Index(string(lower), conjunction); i >= 0 && (first < 0 || i < first) {
+			first, found = i, conjunction
+		}
+	}
+	return first, found
+}
+
+// lastWord returns the last run of letters in a text
+func lastWord(text string) string {
+	end := len(text)
+	start := end
+	for start > 0 {
+		c := text[start-1]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			break
+		}
+		start--
+	}
+	return text[start:end]
+}
+
+// sortEntities orders entities by role, subjects first, then by confidence
+func sortEntities(entities []StockEntity) {
+	rank := map[string]int{RoleSubject: 0, RolePeer: 1, RoleMentioned: 2}
+	sort.Slice(entities, func(i, j int) bool {
+		if rank[entities[i].Role] != rank[entities[j].Role] {
+			return rank[entities[i].Role] < rank[entities[j].Role]
+		}
+		if entities[i].Confidence != entities[j].Confidence {
+			return entities[i].Confidence > entities[j].Confidence
+		}
+		return entities[i].Symbol < entities[j].Symbol
+	})
+}
+
+// trimCorporateSuffix drops a trailing "Ltd" or "Limited" from a company name
+func trimCorporateSuffix(name string) string {
+	normalized, _ := normalizeMatchText(name)
+	normalized = strings.TrimSpace(normalized)
+	for _, suffix := range corporateSuffixes {
+		if trimmed := strings.TrimSuffix(normalized, suffix); trimmed != normalized && trimmed != "" {
+			return trimmed
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,253 @@
+package news
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testDictionaryCSV = `symbol,name,aliases
+NIFTY,Nifty 50,Nifty|Sensex
+TCS,Tata Consultancy Services Ltd,Tata
+TATAMOTORS,Tata Motors Ltd,Tata Motors|JLR|Tata
+TATASTEEL,Tata Steel Ltd,Tata Steel|Tata
+TITAN,Titan Company Ltd,Titan|Tanishq
+LT,Larsen & Toubro Ltd,L&T
+INFY,Infosys Ltd,
+`
+
+func TestAhoCorasick(t *testing.T) {
+	matcher := newAhoCorasick([]string{"he", "she", "his", "hers"})
+
+	var found []string
+	matcher.match("ushers", func(pattern, start, end int) {
+		found = append(found, matcher.patterns[pattern]+"@"+string(rune('0'+start)))
+	})
+	sort.Strings(found)
+	assert.Equal(t, []string{"he@2", "hers@2", "she@1"}, found)
+}
+
+func TestDictionaryStockResolver(t *testing.T) {
+	resolver, err := LoadStockDictionary(strings.NewReader(testDictionaryCSV))
+	assert.NoError(t, err)
+	assert.Equal(t, 7, resolver.Len())
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{name: "company name", text: "Infosys raises revenue guidance", expected: "INFY"},
+		{name: "name without corporate suffix", text: "Tata Consultancy Services wins deal", expected: "TCS"},
+		{name: "brand alias", text: "Tanishq sales jump 20% in festive quarter", expected: "TITAN"},
+		{name: "alias with punctuation", text: "L&T bags order worth Rs 5,000 crore", expected: "LT"},
+		{name: "ticker in capitals", text: "INFY gains 3% after results", expected: "INFY"},
+		{name: "ticker in lower case is a word", text: "Stocks to watch: lt, ok", expected: ""},
+		{name: "whole words only", text: "Titanic losses for Titanium makers", expected: ""},
+		{name: "longer match wins", text: "Tata Motors' JLR sales rise", expected: "TATAMOTORS"},
+		{name: "ambiguous name", text: "Tata group shares in focus", expected: ""},
+		{name: "ambiguous name outscored", text: "Tata Steel output rises; Tata plans expansion", expected: "TATASTEEL"},
+		{name: "market when no company", text: "Sensex, Nifty end higher", expected: MarketSymbol},
+		{name: "company over market", text: "Sensex gains led by Infosys", expected: "INFY"},
+		{name: "no match", text: "Monsoon arrives early in Kerala", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			symbol, err := resolver.ResolveSymbol(context.Background(), tt.text)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, symbol)
+		})
+	}
+}
+
+func TestDictionaryStockResolverScores(t *testing.T) {
+	resolver, err := LoadStockDictionary(strings.NewReader(testDictionaryCSV))
+	assert.NoError(t, err)
+
+	// Names weigh more than aliases
+	matches := resolver.Match("Infosys and Titan gain; Infosys leads")
+	assert.Len(t, matches, 2)
+	assert.Equal(t, "INFY", matches[0].Symbol)
+	assert.InDelta(t, 2.0/2.8, matches[0].Score, 1e-9)
+	assert.Equal(t, "TITAN", matches[1].Symbol)
+	assert.InDelta(t, 0.8/2.8, matches[1].Score, 1e-9)
+
+	matches = resolver.Match("Tata")
+	assert.Len(t, matches, 3)
+	for _, match := range matches {
+		assert.InDelta(t, 1.0/3.0, match.Score, 1e-9)
+	}
+}
+
+func TestLoadStockDictionary(t *testing.T) {
+	_, err := LoadStockDictionary(strings.NewReader("symbol,name\nINFY,Infosys Ltd\n"))
+	assert.ErrorIs(t, err, ErrInvalidDictionary)
+
+	resolver, err := LoadStockDictionaryFile("../../data/stock_dictionary.csv")
+	assert.NoError(t, err)
+	assert.Greater(t, resolver.Len(), 0)
+
+	symbol, err := resolver.ResolveSymbol(context.Background(), "Reliance Jio adds subscribers")
+	assert.NoError(t, err)
+	assert.Equal(t, "RELIANCE", symbol)
+}
+
+func TestDictionaryResolveEntities(t *testing.T) {
+	resolver, err := LoadStockDictionary(strings.NewReader(testDictionaryCSV + "WIPRO,Wipro Ltd,\nHCLTECH,HCL Technologies Ltd,HCL Tech\n"))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		headline string
+		body     string
+		expected map[string]string
+	}{
+		{
+			name:     "several subjects in the headline",
+			headline: "TCS, Infosys and Wipro fall after Accenture guidance cut",
+			body:     "HCL Tech also slipped in early trade.",
+			expected: map[string]string{"TCS": RoleSubject, "INFY": RoleSubject, "WIPRO": RoleSubject, "HCLTECH": RoleMentioned},
+		},
+		{
+			name:     "peers in a later headline clause",
+			headline: "Infosys rallies 5%; Wipro, TCS follow",
+			expected: map[string]string{"INFY": RoleSubject, "WIPRO": RolePeer, "TCS": RolePeer},
+		},
+		{
+			name:     "subject from the body",
+			headline: "IT stocks in focus",
+			body:     "Infosys and HCL Tech report results today. Titan is unchanged.",
+			expected: map[string]string{"INFY": RoleSubject, "HCLTECH": RoleSubject, "TITAN": RoleMentioned},
+		},
+		{
+			name:     "peer sharing a body clause with the subject",
+			headline: "Infosys wins $2 billion deal",
+			body:     "Infosys beat Wipro to the contract. Titan also gained.",
+			expected: map[string]string{"INFY": RoleSubject, "WIPRO": RolePeer, "TITAN": RoleMentioned},
+		},
+		{
+			name:     "market only",
+			headline: "Sensex, Nifty end flat",
+			expected: map[string]string{MarketSymbol: RoleSubject},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entities, err := resolver.ResolveEntities(context.Background(), tt.headline, tt.body)
+			assert.NoError(t, err)
+			roles := make(map[string]string)
+			for _, entity := range entities {
+				roles[entity.Symbol] = entity.Role
+			}
+			assert.Equal(t, tt.expected, roles)
+			assert.Equal(t, RoleSubject, entities[0].Role)
+		})
+	}
+
+	// Each stock's context is the clauses mentioning it
+	entities, err := resolver.ResolveEntities(context.Background(), "Infosys rises while Wipro falls", "")
+	assert.NoError(t, err)
+	assert.Len(t, entities, 2)
+	for _, entity := range entities {
+		switch entity.Symbol {
+		case "INFY":
+			assert.Equal(t, "Infosys rises", entity.Context)
+		case "WIPRO":
+			assert.Equal(t, "Wipro falls", entity.Context)
+		}
+	}
+
+	// An ambiguous name alone leaves each company unconfident
+	entities, err = resolver.ResolveEntities(context.Background(), "Tata group shares in focus", "")
+	assert.NoError(t, err)
+	for _, entity := range entities {
+		assert.Less(t, entity.Confidence, MinEntityConfidence)
+	}
+}
+
+func TestSplitClauses(t *testing.T) {
+	clauses := splitClauses("Dr. Reddy's gains 2.5% on U.S. approval. Cipla falls; Lupin flat while Sun Pharma rises\nMarkets close higher")
+	assert.Equal(t, []string{"Dr. Reddy's gains 2.5% on U.S. approval", "Cipla falls", "Lupin flat", "Sun Pharma rises", "Markets close higher"}, clauses)
+}
+
+func TestParseEntities(t *testing.T) {
+	entities, err := parseEntities("```json\n[{\"symbol\": \"infy\", \"role\": \"peer\", \"confidence\": 0.7}, {\"symbol\": \"TCS\", \"role\": \"Subject\", \"confidence\": 1.4}, {\"symbol\": \"\", \"role\": \"subject\"}, {\"symbol\": \"WIPRO\", \"role\": \"other\", \"confidence\": 0.6}]\n```")
+	assert.NoError(t, err)
+	assert.Equal(t, []StockEntity{
+		{Symbol: "TCS", Role: RoleSubject, Confidence: 1},
+		{Symbol: "INFY", Role: RolePeer, Confidence: 0.7},
+		{Symbol: "WIPRO", Role: RoleMentioned, Confidence: 0.6},
+	}, entities)
+
+	_, err = parseEntities("TCS")
+	assert.Error(t, err)
+}
+
+func TestChainStockResolver(t *testing.T) {
+	failing := &MockOpenAIResolver{}
+	dictionary, err := LoadStockDictionary(strings.NewReader(testDictionaryCSV))
+	assert.NoError(t, err)
+	fallback := NewMockOpenAIResolver()
+
+	resolver := NewChainStockResolver(dictionary, fallback)
+
+	// The dictionary answers what it knows; the rest falls through
+	symbol, err := resolver.ResolveSymbol(context.Background(), "Infosys wins deal")
+	assert.NoError(t, err)
+	assert.Equal(t, "INFY", symbol)
+
+	symbol, err = resolver.ResolveSymbol(context.Background(), "Bharti Airtel raises tariffs")
+	assert.NoError(t, err)
+	assert.Equal(t, "BHARTIARTL", symbol)
+
+	// Errors surface only when no resolver finds a symbol
+	resolver = NewChainStockResolver(errorStockResolver{}, failing)
+	symbol, err = resolver.ResolveSymbol(context.Background(), "Monsoon arrives early")
+	assert.Error(t, err)
+	assert.Empty(t, symbol)
+
+	resolver = NewChainStockResolver(errorStockResolver{}, dictionary)
+	symbol, err = resolver.ResolveSymbol(context.Background(), "Infosys wins deal")
+	assert.NoError(t, err)
+	assert.Equal(t, "INFY", symbol)
+}
+
+func TestNewStockResolver(t *testing.T) {
+	resolver, err := NewStockResolver(StockResolverConfig{DictionaryPath: "../../data/stock_dictionary.csv"})
+	assert.NoError(t, err)
+	assert.IsType(t, &DictionaryStockResolver{}, resolver)
+
+	resolver, err = NewStockResolver(StockResolverConfig{
+		Resolvers:      []string{"dictionary", "llm"},
+		DictionaryPath: "../../data/stock_dictionary.csv",
+		LLM:            NewFakeLLMProvider(),
+	})
+	assert.NoError(t, err)
+	assert.IsType(t, &ChainStockResolver{}, resolver)
+
+	_, err = NewStockResolver(StockResolverConfig{Resolvers: []string{"openai"}})
+	assert.Error(t, err, "the llm resolver needs a provider")
+
+	_, err = NewStockResolver(StockResolverConfig{Resolvers: []string{"regex"}})
+	assert.Error(t, err)
+
+	_, err = NewStockResolver(StockResolverConfig{DictionaryPath: "missing.csv"})
+	assert.Error(t, err)
+}
+
+// errorStockResolver is a StockResolver that always fails
+type errorStockResolver struct{}
+
+func (errorStockResolver) ResolveSymbol(ctx context.Context, text string) (string, error) {
+	return "", errors.New("resolver unavailable")
+}
+
+func (errorStockResolver) ResolveEntities(ctx context.Context, headline, body string) ([]StockEntity, error) {
+	return nil, errors.New("resolver unavailable")
+}
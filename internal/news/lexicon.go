@@ -0,0 +1,335 @@
+package news
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Common lexicon errors
+var (
+	ErrInvalidLexicon = errors.New("invalid sentiment lexicon")
+)
+
+// Word list files of a lexicon directory
+const (
+	LexiconPositiveFile    = "positive.txt"
+	LexiconNegativeFile    = "negative.txt"
+	LexiconNegationFile    = "negation.txt"
+	LexiconIntensifierFile = "intensifiers.txt"
+)
+
+// Lexicon analyzer defaults
+const (
+	// DefaultHeadlineWeight and DefaultBodyWeight weigh the terms of a headline against those of the body
+	DefaultHeadlineWeight = 2.0
+	DefaultBodyWeight     = 1.0
+
+	// DefaultNegationWindow is the number of words after a negation whose sentiment it reverses
+	DefaultNegationWindow = 3
+
+	// negationWeight reverses and softens negated terms, since "no loss" is less good than "gain" is
+	negationWeight = -0.75
+
+	// intensifierWindow is the number of words after an intensifier it scales
+	intensifierWindow = 2
+
+	// lexiconSmoothing keeps a single term from making a text wholly positive or negative
+	lexiconSmoothing = 1.0
+)
+
+// lexiconTermKind is the list a lexicon term comes from
+type lexiconTermKind int
+
+const (
+	termSentiment lexiconTermKind = iota
+	termNegation
+	termIntensifier
+)
+
+// lexiconTerm is a word or phrase of a lexicon
+type lexiconTerm struct {
+	kind lexiconTermKind
+
+	// weight is the signed sentiment of a sentiment term or the multiplier of an intensifier
+	weight float64
+}
+
+// clauseBoundary is the token punctuation ending a clause becomes; phrases and windows do not cross it
+const clauseBoundary = "."
+
+// Lexicon is a finance-specific sentiment lexicon of positive and negative words and phrases,
+// negations and intensifiers
+type Lexicon struct {
+	terms     map[string]lexiconTerm
+	maxPhrase int
+}
+
+// LoadLexiconDir reads a lexicon from the word list files of a directory
+func LoadLexiconDir(dir string) (*Lexicon, error) {
+	var readers []io.Reader
+	for _, name := range []string{LexiconPositiveFile, LexiconNegativeFile, LexiconNegationFile, LexiconIntensifierFile} {
+		file, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		readers = append(readers, file)
+	}
+	return LoadLexicon(readers[0], readers[1], readers[2], readers[3])
+}
+
+// LoadLexicon reads a lexicon from word lists with one word or phrase per line, optionally followed by a comma and
+// a weight: the strength of positive and negative terms (default 1) or the multiplier of intensifiers.
+// Blank lines and lines starting with "#" are skipped, and a term in several lists keeps the last.
+func LoadLexicon(positive, negative, negation, intensifiers io.Reader) (*Lexicon, error) {
+	lexicon := &Lexicon{terms: make(map[string]lexiconTerm)}
+	lists := []struct {
+		name   string
+		r      io.Reader
+		kind   lexiconTermKind
+		weight float64
+	}{
+		{LexiconPositiveFile, positive, termSentiment, 1},
+		{LexiconNegativeFile, negative, termSentiment, -1},
+		{LexiconNegationFile, negation, termNegation, 0},
+		{LexiconIntensifierFile, intensifiers, termIntensifier, 1},
+	}
+	for _, list := range lists {
+		scanner := bufio.NewScanner(list.r)
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" || strings.HasPrefix(text, "#") {
+				continue
+			}
+
+			phrase, weightText, hasWeight := strings.Cut(text, ",")
+			weight := 1.0
+			if hasWeight {
+				var err error
+				weight, err = strconv.ParseFloat(strings.TrimSpace(weightText), 64)
+				if err != nil || weight <= 0 {
+					return nil, fmt.Errorf("%w: %s line %d: weight %q is not a positive number", ErrInvalidLexicon, list.name, line, weightText)
+				}
+			}
+			tokens := tokenizeSentiment(phrase)
+			if len(tokens) == 0 {
+				return nil, fmt.Errorf("%w: %s line %d: %q has no words", ErrInvalidLexicon, list.name, line, phrase)
+			}
+
+			term := lexiconTerm{kind: list.kind, weight: weight}
+			if list.kind == termSentiment {
+				term.weight *= list.weight
+			}
+			lexicon.terms[strings.Join(tokens, " ")] = term
+			if len(tokens) > lexicon.maxPhrase {
+				lexicon.maxPhrase = len(tokens)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidLexicon, list.name, err)
+		}
+	}
+	return lexicon, nil
+}
+
+// Len returns the number of terms in the lexicon
+func (l *Lexicon) Len() int {
+	return len(l.terms)
+}
+
+// match returns the longest term starting at a token and the number of tokens it spans
+func (l *Lexicon) match(tokens []string, start int) (lexiconTerm, int, bool) {
+	for n := l.maxPhrase; n > 0; n-- {
+		end := start + n
+		if end > len(tokens) {
+			continue
+		}
+		phrase := tokens[start:end]
+		if n > 1 && containsBoundary(phrase) {
+			continue
+		}
+		if term, ok := l.terms[strings.Join(phrase, " ")]; ok {
+			return term, n, true
+		}
+	}
+	return lexiconTerm{}, 0, false
+}
+
+// containsBoundary reports whether tokens cross a clause boundary
+func containsBoundary(tokens []string) bool {
+	for _, token := range tokens {
+		if token == clauseBoundary {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenizeSentiment splits text into lower-case words, dropping apostrophes inside words ("didn't" is "didnt")
+// and turning punctuation that ends a clause into clauseBoundary tokens
+func tokenizeSentiment(text string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+
+	runes := []rune(text)
+	for i, r := range runes {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(unicode.ToLower(r))
+		case (r == '\'' || r == '’') && word.Len() > 0 && i+1 < len(runes) && unicode.IsLetter(runes[i+1]):
+			// Apostrophe inside a word
+		case r == '.' && word.Len() > 0 && unicode.IsDigit(runes[i-1]) && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			// Decimal point
+			word.WriteRune(r)
+		case strings.ContainsRune(".,;:!?", r):
+			flush()
+			if len(tokens) > 0 && tokens[len(tokens)-1] != clauseBoundary {
+				tokens = append(tokens, clauseBoundary)
+			}
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// LexiconSentimentAnalyzerConfig holds configuration for the lexicon sentiment analyzer
+type LexiconSentimentAnalyzerConfig struct {
+	Lexicon *Lexicon
+
+	// HeadlineWeight and BodyWeight weigh the terms of a headline against those of the body
+	// (default: DefaultHeadlineWeight and DefaultBodyWeight)
+	HeadlineWeight float64
+	BodyWeight     float64
+
+	// NegationWindow is the number of words after a negation whose sentiment it reverses (default: DefaultNegationWindow)
+	NegationWindow int
+}
+
+// LexiconSentimentAnalyzer implements SentimentAnalyzer with a finance-specific lexicon. It scores whole words and
+// phrases, so "enterprise" does not count as "rise", reverses terms shortly after a negation ("no growth"), scales
+// terms shortly after an intensifier ("sharply lower"), and weighs headlines above bodies.
+type LexiconSentimentAnalyzer struct {
+	lexicon        *Lexicon
+	headlineWeight float64
+	bodyWeight     float64
+	negationWindow int
+}
+
+// NewLexiconSentimentAnalyzer creates a new lexicon sentiment analyzer
+func NewLexiconSentimentAnalyzer(config LexiconSentimentAnalyzerConfig) *LexiconSentimentAnalyzer {
+	if config.HeadlineWeight <= 0 {
+		config.HeadlineWeight = DefaultHeadlineWeight
+	}
+	if config.BodyWeight <= 0 {
+		config.BodyWeight = DefaultBodyWeight
+	}
+	if config.NegationWindow <= 0 {
+		config.NegationWindow = DefaultNegationWindow
+	}
+
+	return &LexiconSentimentAnalyzer{
+		lexicon:        config.Lexicon,
+		headlineWeight: config.HeadlineWeight,
+		bodyWeight:     config.BodyWeight,
+		negationWindow: config.NegationWindow,
+	}
+}
+
+// Analyze implements the SentimentAnalyzer interface. An item's preset sentiment is kept, and the sentiment towards
+// a stock is scored from the text mentioning it; subjects fall back to the sentiment of the whole item when that text
+// carries none, since the item is about them.
+func (a *LexiconSentimentAnalyzer) Analyze(ctx context.Context, item NewsItem, entities []StockEntity) (Analysis, error) {
+	analysis := Analysis{Sentiment: item.Sentiment}
+	if analysis.Sentiment == 0 {
+		analysis.Sentiment = a.Score(item)
+	}
+
+	for _, entity := range entities {
+		sentiment := analysis.Sentiment
+		if entity.Context != "" {
+			sentiment = a.Score(NewsItem{Description: entity.Context})
+			if sentiment == NeutralSentimentScore && entity.Role == RoleSubject {
+				sentiment = analysis.Sentiment
+			}
+		}
+		analysis.Stocks = append(analysis.Stocks, StockAnalysis{Symbol: entity.Symbol, Sentiment: sentiment})
+	}
+	return analysis, nil
+}
+
+// Score returns the sentiment of a news item from -1 to 1: the weighted sum of its terms' sentiment over the
+// weighted sum of their strength, smoothed so that a text with few terms stays short of the bounds
+func (a *LexiconSentimentAnalyzer) Score(item NewsItem) float64 {
+	headlineSum, headlineStrength := a.scoreText(item.Title)
+	bodySum, bodyStrength := a.scoreText(item.Description)
+
+	sum := a.headlineWeight*headlineSum + a.bodyWeight*bodySum
+	strength := a.headlineWeight*headlineStrength + a.bodyWeight*bodyStrength
+	if strength == 0 {
+		return NeutralSentimentScore
+	}
+	return sum / (strength + lexiconSmoothing)
+}
+
+// scoreText returns the sum of the sentiment of the terms in a text and the sum of their strength
+func (a *LexiconSentimentAnalyzer) scoreText(text string) (sum, strength float64) {
+	tokens := tokenizeSentiment(text)
+	negated, intensified := 0, 0
+	intensity := 1.0
+
+	for i := 0; i < len(tokens); {
+		if tokens[i] == clauseBoundary {
+			negated, intensified = 0, 0
+			i++
+			continue
+		}
+
+		term, n, ok := a.lexicon.match(tokens, i)
+		if !ok {
+			n = 1
+		}
+		i += n
+
+		switch {
+		case !ok:
+		case term.kind == termNegation:
+			negated = a.negationWindow
+			continue
+		case term.kind == termIntensifier:
+			intensity, intensified = term.weight, intensifierWindow
+			negated -= n
+			continue
+		default:
+			score := term.weight
+			if intensified > 0 {
+				score *= intensity
+				intensified = 0
+			}
+			if negated > 0 {
+				score *= negationWeight
+			}
+			sum += score
+			strength += math.Abs(score)
+		}
+		negated -= n
+		intensified -= n
+	}
+	return sum, strength
+}
@@ -0,0 +1,149 @@
+package news
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// minLexiconAccuracy is the share of the labeled headlines the lexicon analyzer must classify correctly
+const minLexiconAccuracy = 0.85
+
+func loadTestLexicon(t *testing.T) *LexiconSentimentAnalyzer {
+	t.Helper()
+	lexicon, err := LoadLexiconDir("../../data/lexicon")
+	if err != nil {
+		t.Fatalf("Failed to load lexicon: %v", err)
+	}
+	return NewLexiconSentimentAnalyzer(LexiconSentimentAnalyzerConfig{Lexicon: lexicon})
+}
+
+// sentimentLabel classifies a sentiment score as the processor's action thresholds do
+func sentimentLabel(sentiment float64) string {
+	switch {
+	case sentiment > PositiveSentimentThreshold:
+		return "positive"
+	case sentiment < NegativeSentimentThreshold:
+		return "negative"
+	default:
+		return "neutral"
+	}
+}
+
+func TestLoadLexicon(t *testing.T) {
+	lexicon, err := LoadLexicon(
+		strings.NewReader("# comment\ngain\nupper circuit, 1.5\n\n"),
+		strings.NewReader("fall\n"),
+		strings.NewReader("not\n"),
+		strings.NewReader("sharply,1.5\n"),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, lexicon.Len())
+	assert.Equal(t, lexiconTerm{kind: termSentiment, weight: 1.5}, lexicon.terms["upper circuit"])
+	assert.Equal(t, lexiconTerm{kind: termSentiment, weight: -1}, lexicon.terms["fall"])
+
+	_, err = LoadLexicon(strings.NewReader("gain,lots\n"), strings.NewReader(""), strings.NewReader(""), strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrInvalidLexicon)
+
+	_, err = LoadLexicon(strings.NewReader("!!!\n"), strings.NewReader(""), strings.NewReader(""), strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrInvalidLexicon)
+
+	_, err = LoadLexiconDir(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestTokenizeSentiment(t *testing.T) {
+	assert.Equal(t,
+		[]string{"tata", "motors", "didnt", "hit", "52", "week", "high", ".", "shares", "up", "3.5"},
+		tokenizeSentiment("Tata Motors didn't hit 52-week high; shares up 3.5%"))
+	assert.Empty(t, tokenizeSentiment("..."))
+}
+
+func TestLexiconSentimentAnalyzer(t *testing.T) {
+	analyzer := loadTestLexicon(t)
+
+	tests := []struct {
+		name     string
+		item     NewsItem
+		expected string
+	}{
+		{name: "whole words", item: NewsItem{Title: "Enterprise software executive joins board"}, expected: "neutral"},
+		{name: "negated positive", item: NewsItem{Title: "Company reports no growth in orders"}, expected: "negative"},
+		{name: "negated negative", item: NewsItem{Title: "Lender says asset quality did not deteriorate"}, expected: "positive"},
+		{name: "negation ends with the clause", item: NewsItem{Title: "No surprises, shares rally"}, expected: "positive"},
+		{name: "phrase over its words", item: NewsItem{Title: "RBI rate cut cheers borrowers"}, expected: "positive"},
+		{name: "Indian vocabulary", item: NewsItem{Title: "Stock hits upper circuit"}, expected: "positive"},
+		{name: "stake sales", item: NewsItem{Title: "Government OFS in NHPC opens today"}, expected: "negative"},
+		{name: "profit decline", item: NewsItem{Title: "Profit decline at Infosys"}, expected: "negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, sentimentLabel(analyzer.Score(tt.item)))
+		})
+	}
+
+	// Intensifiers scale the terms after them
+	plain := analyzer.Score(NewsItem{Title: "Shares fall"})
+	sharp := analyzer.Score(NewsItem{Title: "Shares fall sharply"})
+	intensified := analyzer.Score(NewsItem{Title: "Shares sharply fall"})
+	assert.Equal(t, plain, sharp, "intensifiers only scale the words after them")
+	assert.Less(t, intensified, plain)
+
+	// Headlines weigh more than bodies
+	item := NewsItem{Title: "Shares rally", Description: "Analysts remain cautious"}
+	assert.Greater(t, analyzer.Score(item), 0.0)
+	assert.Greater(t, analyzer.Score(NewsItem{Title: "Shares rally"}), analyzer.Score(NewsItem{Description: "Shares rally"}))
+	assert.Equal(t, 0.0, analyzer.Score(NewsItem{Title: "Board meeting on Friday"}))
+}
+
+func TestLexiconAnalyzeEntities(t *testing.T) {
+	analyzer := loadTestLexicon(t)
+	item := NewsItem{Title: "Infosys rises while Wipro falls"}
+	analysis, err := analyzer.Analyze(context.Background(), item, []StockEntity{
+		{Symbol: "INFY", Role: RoleSubject, Context: "Infosys rises"},
+		{Symbol: "WIPRO", Role: RolePeer, Context: "Wipro falls"},
+		{Symbol: "TCS", Role: RoleSubject, Context: "TCS declared results"},
+	})
+	assert.NoError(t, err)
+	assert.Greater(t, analysis.Stock("INFY").Sentiment, 0.0)
+	assert.Less(t, analysis.Stock("WIPRO").Sentiment, 0.0)
+	assert.Equal(t, analysis.Sentiment, analysis.Stock("TCS").Sentiment, "subjects fall back to the item's sentiment")
+}
+
+func TestLexiconAccuracy(t *testing.T) {
+	file, err := os.Open("testdata/sentiment_headlines.csv")
+	if err != nil {
+		t.Fatalf("Failed to open fixture: %v", err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	rows = rows[1:]
+
+	lexicon := loadTestLexicon(t)
+	keyword := NewKeywordSentimentAnalyzer()
+	var lexiconCorrect, keywordCorrect int
+	for _, row := range rows {
+		label, item := row[0], NewsItem{Title: row[1]}
+		if got := sentimentLabel(lexicon.Score(item)); got == label {
+			lexiconCorrect++
+		} else {
+			t.Logf("lexicon: %q labeled %s, scored %s", row[1], label, got)
+		}
+		if sentimentLabel(keyword.Score(item)) == label {
+			keywordCorrect++
+		}
+	}
+
+	lexiconAccuracy := float64(lexiconCorrect) / float64(len(rows))
+	keywordAccuracy := float64(keywordCorrect) / float64(len(rows))
+	t.Logf("Accuracy on %d labeled headlines: lexicon %.1f%%, keyword %.1f%%", len(rows), 100*lexiconAccuracy, 100*keywordAccuracy)
+	assert.GreaterOrEqual(t, lexiconAccuracy, minLexiconAccuracy)
+	assert.Greater(t, lexiconAccuracy, keywordAccuracy)
+}
@@ -0,0 +1,311 @@
+package news
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// LLM provider names, as set in LLMConfig
+const (
+	ProviderOpenAI           = "openai"
+	ProviderOpenAICompatible = "openai-compatible"
+	ProviderFake             = "fake"
+)
+
+// LLM defaults
+const (
+	// DefaultLLMModel is the model asked when none is configured
+	DefaultLLMModel = openai.GPT3Dot5Turbo
+
+	// DefaultLLMTimeout bounds a single completion
+	DefaultLLMTimeout = 30 * time.Second
+
+	// DefaultLLMMaxTokens caps the tokens generated by a single completion
+	DefaultLLMMaxTokens = 512
+
+	// llmTemperature keeps completions close to deterministic
+	llmTemperature = 0.1
+)
+
+// ErrLLMBudgetExceeded is returned when a request would exceed the per-minute limits and the context ends before they reset
+var ErrLLMBudgetExceeded = errors.New("LLM rate limit exceeded")
+
+// LLMProvider defines the interface for completing prompts with a large language model
+type LLMProvider interface {
+	// Complete returns the model's reply to a request
+	Complete(ctx context.Context, request LLMRequest) (string, error)
+}
+
+// LLMRequest is a prompt for a large language model
+type LLMRequest struct {
+	System string
+	Prompt string
+
+	// JSON asks for a reply that is a single JSON object
+	JSON bool
+}
+
+// LLMConfig holds configuration for a large language model provider
+type LLMConfig struct {
+	// Provider is openai, openai-compatible or fake (default: openai)
+	Provider string
+
+	// BaseURL is the endpoint of an OpenAI-compatible server such as Ollama, vLLM or LM Studio,
+	// e.g. http://localhost:11434/v1 (required for openai-compatible)
+	BaseURL string
+
+	// APIKey authenticates with the provider (required for openai)
+	APIKey string
+
+	// Model is the model name (default: DefaultLLMModel)
+	Model string
+
+	// Timeout bounds a single completion (default: DefaultLLMTimeout)
+	Timeout time.Duration
+
+	// MaxTokens caps the tokens generated by a single completion (default: DefaultLLMMaxTokens)
+	MaxTokens int
+
+	// RequestsPerMinute and TokensPerMinute limit usage; requests wait for the next minute once
+	// either is reached (default: unlimited)
+	RequestsPerMinute int
+	TokensPerMinute   int
+}
+
+// NewLLMProvider creates the provider a configuration names
+func NewLLMProvider(config LLMConfig) (LLMProvider, error) {
+	switch strings.ToLower(strings.TrimSpace(config.Provider)) {
+	case "", ProviderOpenAI:
+		if config.APIKey == "" {
+			return nil, errors.New("an API key is required for the openai LLM provider")
+		}
+		config.BaseURL = ""
+		return NewOpenAIProvider(config), nil
+	case ProviderOpenAICompatible:
+		if config.BaseURL == "" {
+			return nil, errors.New("a base URL is required for the openai-compatible LLM provider")
+		}
+		return NewOpenAIProvider(config), nil
+	case ProviderFake:
+		return NewFakeLLMProvider(), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", config.Provider)
+	}
+}
+
+// OpenAIProvider implements LLMProvider with the chat completions API of OpenAI or an OpenAI-compatible server
+type OpenAIProvider struct {
+	client    *openai.Client
+	model     string
+	timeout   time.Duration
+	maxTokens int
+	limiter   *llmLimiter
+}
+
+// NewOpenAIProvider creates a new OpenAI provider; an empty BaseURL means the public OpenAI endpoint
+func NewOpenAIProvider(config LLMConfig) *OpenAIProvider {
+	if config.Model == "" {
+		config.Model = DefaultLLMModel
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultLLMTimeout
+	}
+	if config.MaxTokens <= 0 {
+		config.MaxTokens = DefaultLLMMaxTokens
+	}
+
+	clientConfig := openai.DefaultConfig(config.APIKey)
+	if config.BaseURL != "" {
+		clientConfig.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	}
+
+	return &OpenAIProvider{
+		client:    openai.NewClientWithConfig(clientConfig),
+		model:     config.Model,
+		timeout:   config.Timeout,
+		maxTokens: config.MaxTokens,
+		limiter:   newLLMLimiter(config.RequestsPerMinute, config.TokensPerMinute),
+	}
+}
+
+// Complete implements the LLMProvider interface
+func (p *OpenAIProvider) Complete(ctx context.Context, request LLMRequest) (string, error) {
+	if err := p.limiter.wait(ctx); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var messages []openai.ChatCompletionMessage
+	if request.System != "" {
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: request.System,
+		})
+	}
+	messages = append(messages, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleUser,
+		Content: request.Prompt,
+	})
+
+	completion := openai.ChatCompletionRequest{
+		Model:       p.model,
+		Messages:    messages,
+		MaxTokens:   p.maxTokens,
+		Temperature: llmTemperature,
+	}
+	if request.JSON {
+		completion.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		}
+	}
+
+	resp, err := p.client.CreateChatCompletion(ctx, completion)
+	if err != nil {
+		return "", fmt.Errorf("failed to get completion from %s: %w", p.model, err)
+	}
+	p.limiter.record(resp.Usage.TotalTokens)
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("%s returned no completion", p.model)
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
+
+// FakeLLMProvider implements LLMProvider deterministically for tests and offline use.
+// It replies with the reply of the first rule whose text the prompt contains, or the default reply.
+type FakeLLMProvider struct {
+	mu       sync.Mutex
+	rules    []fakeLLMRule
+	reply    string
+	requests []LLMRequest
+}
+
+// fakeLLMRule is a canned reply to prompts containing a text
+type fakeLLMRule struct {
+	contains string
+	reply    string
+	err      error
+}
+
+// NewFakeLLMProvider creates a fake provider replying with an empty string to every prompt
+func NewFakeLLMProvider() *FakeLLMProvider {
+	return &FakeLLMProvider{}
+}
+
+// On makes the provider reply to prompts containing a text; earlier rules take precedence
+func (p *FakeLLMProvider) On(contains, reply string) *FakeLLMProvider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.rules = append(p.rules, fakeLLMRule{contains: contains, reply: reply})
+	return p
+}
+
+// OnError makes the provider fail on prompts containing a text
+func (p *FakeLLMProvider) OnError(contains string, err error) *FakeLLMProvider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.rules = append(p.rules, fakeLLMRule{contains: contains, err: err})
+	return p
+}
+
+// Default sets the reply to prompts no rule matches
+func (p *FakeLLMProvider) Default(reply string) *FakeLLMProvider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.reply = reply
+	return p
+}
+
+// Requests returns the requests the provider has received
+func (p *FakeLLMProvider) Requests() []LLMRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]LLMRequest(nil), p.requests...)
+}
+
+// Complete implements the LLMProvider interface
+func (p *FakeLLMProvider) Complete(ctx context.Context, request LLMRequest) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.requests = append(p.requests, request)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	for _, rule := range p.rules {
+		if strings.Contains(request.Prompt, rule.contains) {
+			return rule.reply, rule.err
+		}
+	}
+	return p.reply, nil
+}
+
+// llmLimiter limits requests and tokens per minute over fixed one-minute windows
+type llmLimiter struct {
+	requestsPerMinute int
+	tokensPerMinute   int
+	now               func() time.Time
+
+	mu          sync.Mutex
+	windowStart time.Time
+	requests    int
+	tokens      int
+}
+
+// newLLMLimiter creates a limiter; a limit of zero or less is unlimited
+func newLLMLimiter(requestsPerMinute, tokensPerMinute int) *llmLimiter {
+	return &llmLimiter{
+		requestsPerMinute: requestsPerMinute,
+		tokensPerMinute:   tokensPerMinute,
+		now:               time.Now,
+	}
+}
+
+// wait blocks until a request fits in the current window and counts it
+func (l *llmLimiter) wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := l.now()
+		if now.Sub(l.windowStart) >= time.Minute {
+			l.windowStart = now
+			l.requests = 0
+			l.tokens = 0
+		}
+		full := (l.requestsPerMinute > 0 && l.requests >= l.requestsPerMinute) ||
+			(l.tokensPerMinute > 0 && l.tokens >= l.tokensPerMinute)
+		if !full {
+			l.requests++
+			l.mu.Unlock()
+			return nil
+		}
+		delay := l.windowStart.Add(time.Minute).Sub(now)
+		l.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w: %v", ErrLLMBudgetExceeded, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// record counts the tokens a completion used
+func (l *llmLimiter) record(tokens int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens += tokens
+}
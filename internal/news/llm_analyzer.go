@@ -0,0 +1,324 @@
+package news
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LLM analyzer defaults
+const (
+	// DefaultAnalysisRetries is the number of further attempts after a failed request or an invalid reply
+	DefaultAnalysisRetries = 2
+
+	// DefaultAnalysisRetryDelay is the wait before retrying after a provider error; it doubles with each further error
+	DefaultAnalysisRetryDelay = time.Second
+
+	// DefaultAnalysisCacheTTL is how long an article's analysis is reused
+	DefaultAnalysisCacheTTL = 24 * time.Hour
+
+	// DefaultAnalysisCacheSize is the number of analyses kept
+	DefaultAnalysisCacheSize = 1000
+
+	// maxRationaleLength bounds the one-line rationale of a stock
+	maxRationaleLength = 300
+)
+
+// ErrInvalidAnalysis is returned when a model's reply does not follow the analysis schema
+var ErrInvalidAnalysis = errors.New("invalid analysis")
+
+// eventTypes and horizons are the values an analysis may report
+var (
+	eventTypes = map[string]bool{
+		EventEarnings: true, EventGuidance: true, EventRating: true, EventDeal: true, EventCorporateAction: true,
+		EventManagement: true, EventRegulatory: true, EventMacro: true, EventOther: true,
+	}
+	horizons = map[string]bool{
+		HorizonIntraday: true, HorizonShortTerm: true, HorizonMediumTerm: true, HorizonLongTerm: true,
+	}
+)
+
+// LLMSentimentAnalyzerConfig holds configuration for the LLM sentiment analyzer
+type LLMSentimentAnalyzerConfig struct {
+	Provider LLMProvider
+
+	// Prompts are the prompt templates; empty templates keep their defaults
+	Prompts PromptTemplates
+
+	// Retries is the number of further attempts after a failed request or an invalid reply; nil keeps
+	// DefaultAnalysisRetries and zero disables retrying
+	Retries *int
+
+	// RetryDelay is the wait before retrying after a provider error, doubling with each further error
+	// (default: DefaultAnalysisRetryDelay). Invalid replies are retried at once.
+	RetryDelay time.Duration
+
+	// CacheTTL and CacheSize bound the analyses kept by article (default: DefaultAnalysisCacheTTL and DefaultAnalysisCacheSize)
+	CacheTTL  time.Duration
+	CacheSize int
+}
+
+// LLMSentimentAnalyzer implements SentimentAnalyzer by asking a large language model for a structured analysis
+// with the sentiment, event type, impact horizon and rationale of each stock
+type LLMSentimentAnalyzer struct {
+	provider   LLMProvider
+	prompts    PromptTemplates
+	retries    int
+	retryDelay time.Duration
+	cache      *analysisCache
+}
+
+// NewLLMSentimentAnalyzer creates a new LLM sentiment analyzer
+func NewLLMSentimentAnalyzer(config LLMSentimentAnalyzerConfig) *LLMSentimentAnalyzer {
+	retries := DefaultAnalysisRetries
+	if config.Retries != nil && *config.Retries >= 0 {
+		retries = *config.Retries
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = DefaultAnalysisRetryDelay
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = DefaultAnalysisCacheTTL
+	}
+	if config.CacheSize <= 0 {
+		config.CacheSize = DefaultAnalysisCacheSize
+	}
+
+	return &LLMSentimentAnalyzer{
+		provider:   config.Provider,
+		prompts:    DefaultPromptTemplates().merge(config.Prompts),
+		retries:    retries,
+		retryDelay: config.RetryDelay,
+		cache:      newAnalysisCache(config.CacheTTL, config.CacheSize),
+	}
+}
+
+// Analyze implements the SentimentAnalyzer interface. Replies that do not follow the schema are retried at once with
+// the problem pointed out, provider errors after a growing delay, and analyses are cached by a hash of the article
+// and its stocks.
+func (a *LLMSentimentAnalyzer) Analyze(ctx context.Context, item NewsItem, entities []StockEntity) (Analysis, error) {
+	symbols := entitySymbols(entities)
+	key := articleHash(item, symbols)
+	if analysis, ok := a.cache.get(key); ok {
+		return analysis, nil
+	}
+
+	system, err := renderPrompt("system", a.prompts.System, nil)
+	if err != nil {
+		return Analysis{}, err
+	}
+	prompt, err := renderPrompt("analysis", a.prompts.Analysis, struct{ Headline, Body, Symbols string }{
+		item.Title, item.Description, strings.Join(symbols, ", "),
+	})
+	if err != nil {
+		return Analysis{}, err
+	}
+
+	var lastErr error
+	attempts := 0
+	delay := a.retryDelay
+	for attempts <= a.retries {
+		attempts++
+		request := LLMRequest{System: system, Prompt: prompt, JSON: true}
+		if errors.Is(lastErr, ErrInvalidAnalysis) {
+			request.Prompt += fmt.Sprintf("\n\nYour previous reply was rejected (%v). Respond with only the JSON object.", lastErr)
+		}
+
+		reply, err := a.provider.Complete(ctx, request)
+		if err == nil {
+			var analysis Analysis
+			if analysis, err = parseAnalysis(reply, symbols); err == nil {
+				a.cache.set(key, analysis)
+				return analysis, nil
+			}
+		}
+		lastErr = err
+
+		// Waiting out a budget or a cancelled context will not go better the next time
+		if ctx.Err() != nil || errors.Is(err, ErrLLMBudgetExceeded) {
+			break
+		}
+
+		// Give a failing provider time to recover before asking again
+		if !errors.Is(err, ErrInvalidAnalysis) && attempts <= a.retries {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return Analysis{}, fmt.Errorf("failed to analyze news after %d attempts: %w", attempts, ctx.Err())
+			case <-timer.C:
+			}
+			delay *= 2
+		}
+	}
+	return Analysis{}, fmt.Errorf("failed to analyze news after %d attempts: %w", attempts, lastErr)
+}
+
+// entitySymbols returns the distinct symbols of entities, in order
+func entitySymbols(entities []StockEntity) []string {
+	var symbols []string
+	seen := make(map[string]bool)
+	for _, entity := range entities {
+		if entity.Symbol != "" && !seen[entity.Symbol] {
+			seen[entity.Symbol] = true
+			symbols = append(symbols, entity.Symbol)
+		}
+	}
+	return symbols
+}
+
+// articleHash identifies an article and the stocks it is analyzed for
+func articleHash(item NewsItem, symbols []string) string {
+	hash := sha256.New()
+	for _, part := range []string{item.Title, item.Description, strings.Join(symbols, ",")} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// llmAnalysis is the schema of an analysis reply; pointers tell missing scores from zero
+type llmAnalysis struct {
+	Sentiment *float64 `json:"sentiment"`
+	Stocks    []struct {
+		Symbol    string   `json:"symbol"`
+		Sentiment *float64 `json:"sentiment"`
+		EventType string   `json:"event_type"`
+		Horizon   string   `json:"horizon"`
+		Rationale string   `json:"rationale"`
+	} `json:"stocks"`
+}
+
+// parseAnalysis reads an analysis reply, checking it against the schema and that it covers exactly the given symbols
+func parseAnalysis(content string, symbols []string) (Analysis, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(trimCodeFence(content))))
+	decoder.DisallowUnknownFields()
+	var reply llmAnalysis
+	if err := decoder.Decode(&reply); err != nil {
+		return Analysis{}, fmt.Errorf("%w: %v", ErrInvalidAnalysis, err)
+	}
+
+	if err := validateScore("sentiment", reply.Sentiment); err != nil {
+		return Analysis{}, err
+	}
+	analysis := Analysis{Sentiment: *reply.Sentiment}
+
+	wanted := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		wanted[symbol] = true
+	}
+	for _, stock := range reply.Stocks {
+		symbol := strings.ToUpper(strings.TrimSpace(stock.Symbol))
+		if !wanted[symbol] {
+			return Analysis{}, fmt.Errorf("%w: unexpected or repeated stock %q", ErrInvalidAnalysis, stock.Symbol)
+		}
+		delete(wanted, symbol)
+
+		if err := validateScore(symbol+" sentiment", stock.Sentiment); err != nil {
+			return Analysis{}, err
+		}
+		eventType := strings.ToLower(strings.TrimSpace(stock.EventType))
+		if !eventTypes[eventType] {
+			return Analysis{}, fmt.Errorf("%w: unknown event_type %q for %s", ErrInvalidAnalysis, stock.EventType, symbol)
+		}
+		horizon := strings.ToLower(strings.TrimSpace(stock.Horizon))
+		if !horizons[horizon] {
+			return Analysis{}, fmt.Errorf("%w: unknown horizon %q for %s", ErrInvalidAnalysis, stock.Horizon, symbol)
+		}
+		rationale := strings.TrimSpace(stock.Rationale)
+		if rationale == "" || strings.ContainsAny(rationale, "\r\n") || len(rationale) > maxRationaleLength {
+			return Analysis{}, fmt.Errorf("%w: the rationale for %s must be a single line of at most %d characters",
+				ErrInvalidAnalysis, symbol, maxRationaleLength)
+		}
+
+		analysis.Stocks = append(analysis.Stocks, StockAnalysis{
+			Symbol:    symbol,
+			Sentiment: *stock.Sentiment,
+			EventType: eventType,
+			Horizon:   horizon,
+			Rationale: rationale,
+		})
+	}
+
+	for _, symbol := range symbols {
+		if wanted[symbol] {
+			return Analysis{}, fmt.Errorf("%w: missing stock %s", ErrInvalidAnalysis, symbol)
+		}
+	}
+	return analysis, nil
+}
+
+// validateScore checks a sentiment score is present and between -1 and 1
+func validateScore(name string, score *float64) error {
+	if score == nil {
+		return fmt.Errorf("%w: %s is required", ErrInvalidAnalysis, name)
+	}
+	if *score < MinSentimentScore || *score > MaxSentimentScore {
+		return fmt.Errorf("%w: %s %v is not between -1 and 1", ErrInvalidAnalysis, name, *score)
+	}
+	return nil
+}
+
+// analysisCache keeps analyses by article hash for a while, evicting the soonest to expire when full
+type analysisCache struct {
+	ttl     time.Duration
+	size    int
+	mu      sync.Mutex
+	entries map[string]analysisCacheEntry
+}
+
+// analysisCacheEntry is a cached analysis and when it expires
+type analysisCacheEntry struct {
+	analysis  Analysis
+	expiresAt time.Time
+}
+
+// newAnalysisCache creates a cache keeping up to size analyses for ttl
+func newAnalysisCache(ttl time.Duration, size int) *analysisCache {
+	return &analysisCache{
+		ttl:     ttl,
+		size:    size,
+		entries: make(map[string]analysisCacheEntry),
+	}
+}
+
+// get returns the analysis cached for a key, if it has not expired
+func (c *analysisCache) get(key string) (Analysis, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return Analysis{}, false
+	}
+	return entry.analysis, true
+}
+
+// set caches an analysis, making room when the cache is full
+func (c *analysisCache) set(key string, analysis Analysis) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.size {
+		var oldest string
+		for k, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, k)
+			} else if oldest == "" || entry.expiresAt.Before(c.entries[oldest].expiresAt) {
+				oldest = k
+			}
+		}
+		if len(c.entries) >= c.size {
+			delete(c.entries, oldest)
+		}
+	}
+	c.entries[key] = analysisCacheEntry{analysis: analysis, expiresAt: now.Add(c.ttl)}
+}
@@ -0,0 +1,194 @@
+package news
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const validAnalysisReply = "```json\n" + `{"sentiment": -0.6, "stocks": [
+	{"symbol": "INFY", "sentiment": -0.7, "event_type": "earnings", "horizon": "short_term", "rationale": "Profit declined for a second quarter."},
+	{"symbol": "TCS", "sentiment": 0.2, "event_type": "Earnings", "horizon": "medium_term", "rationale": "Peers may gain share."}
+]}` + "\n```"
+
+func TestParseAnalysis(t *testing.T) {
+	symbols := []string{"INFY", "TCS"}
+
+	analysis, err := parseAnalysis(validAnalysisReply, symbols)
+	assert.NoError(t, err)
+	assert.Equal(t, -0.6, analysis.Sentiment)
+	assert.Equal(t, StockAnalysis{
+		Symbol:    "INFY",
+		Sentiment: -0.7,
+		EventType: EventEarnings,
+		Horizon:   HorizonShortTerm,
+		Rationale: "Profit declined for a second quarter.",
+	}, analysis.Stock("INFY"))
+	assert.Equal(t, EventEarnings, analysis.Stock("TCS").EventType, "enumerations are case-insensitive")
+	assert.Equal(t, -0.6, analysis.Stock("WIPRO").Sentiment, "unknown stocks get the article's sentiment")
+
+	tests := []struct {
+		name  string
+		reply string
+	}{
+		{name: "not JSON", reply: "Infosys looks weak"},
+		{name: "unknown field", reply: `{"sentiment": 0.1, "stocks": [], "confidence": 0.9}`},
+		{name: "missing sentiment", reply: `{"stocks": []}`},
+		{name: "sentiment out of range", reply: `{"sentiment": 1.5, "stocks": []}`},
+		{name: "missing stock", reply: `{"sentiment": 0.1, "stocks": []}`},
+		{name: "unexpected stock", reply: `{"sentiment": 0.1, "stocks": [{"symbol": "WIPRO", "sentiment": 0.1, "event_type": "other", "horizon": "intraday", "rationale": "Flat."}]}`},
+		{name: "unknown event type", reply: `{"sentiment": 0.1, "stocks": [{"symbol": "INFY", "sentiment": 0.1, "event_type": "rumour", "horizon": "intraday", "rationale": "Flat."}]}`},
+		{name: "unknown horizon", reply: `{"sentiment": 0.1, "stocks": [{"symbol": "INFY", "sentiment": 0.1, "event_type": "other", "horizon": "forever", "rationale": "Flat."}]}`},
+		{name: "missing stock sentiment", reply: `{"sentiment": 0.1, "stocks": [{"symbol": "INFY", "event_type": "other", "horizon": "intraday", "rationale": "Flat."}]}`},
+		{name: "multi-line rationale", reply: `{"sentiment": 0.1, "stocks": [{"symbol": "INFY", "sentiment": 0.1, "event_type": "other", "horizon": "intraday", "rationale": "Flat.\nReally."}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseAnalysis(tt.reply, []string{"INFY"})
+			assert.ErrorIs(t, err, ErrInvalidAnalysis)
+		})
+	}
+}
+
+func TestLLMSentimentAnalyzer(t *testing.T) {
+	item := NewsItem{Title: "Infosys profit declines, TCS holds steady", Description: "Infosys reported lower profit."}
+	entities := []StockEntity{{Symbol: "INFY", Role: RoleSubject}, {Symbol: "TCS", Role: RolePeer}}
+	ctx := context.Background()
+
+	// An invalid reply is retried with the problem pointed out
+	provider := NewFakeLLMProvider().
+		On("previous reply was rejected", validAnalysisReply).
+		Default(`{"sentiment": "negative"}`)
+	analyzer := NewLLMSentimentAnalyzer(LLMSentimentAnalyzerConfig{Provider: provider})
+
+	analysis, err := analyzer.Analyze(ctx, item, entities)
+	assert.NoError(t, err)
+	assert.Equal(t, -0.7, analysis.Stock("INFY").Sentiment)
+	requests := provider.Requests()
+	if assert.Len(t, requests, 2) {
+		assert.True(t, requests[0].JSON, "the reply is asked for as a JSON object")
+		assert.Contains(t, requests[0].Prompt, "List exactly these stocks, once each: INFY, TCS.")
+		assert.Contains(t, requests[0].Prompt, "Headline: "+item.Title)
+	}
+
+	// The analysis is cached by article
+	_, err = analyzer.Analyze(ctx, item, entities)
+	assert.NoError(t, err)
+	assert.Len(t, provider.Requests(), 2)
+
+	// Failures are retried after a delay, then reported
+	unavailable := errors.New("model unavailable")
+	failing := NewFakeLLMProvider().OnError("", unavailable)
+	retries := 1
+	analyzer = NewLLMSentimentAnalyzer(LLMSentimentAnalyzerConfig{
+		Provider:   failing,
+		Retries:    &retries,
+		RetryDelay: 20 * time.Millisecond,
+	})
+	start := time.Now()
+	_, err = analyzer.Analyze(ctx, item, entities)
+	assert.ErrorIs(t, err, unavailable)
+	assert.Len(t, failing.Requests(), 2)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	// Zero retries disables retrying
+	retries = 0
+	failing = NewFakeLLMProvider().OnError("", unavailable)
+	analyzer = NewLLMSentimentAnalyzer(LLMSentimentAnalyzerConfig{Provider: failing, Retries: &retries})
+	_, err = analyzer.Analyze(ctx, item, entities)
+	assert.ErrorIs(t, err, unavailable)
+	assert.Len(t, failing.Requests(), 1)
+
+	// A cancelled context is not retried
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	failing = NewFakeLLMProvider()
+	analyzer = NewLLMSentimentAnalyzer(LLMSentimentAnalyzerConfig{Provider: failing})
+	_, err = analyzer.Analyze(cancelled, item, entities)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, failing.Requests(), 1)
+}
+
+func TestAnalysisCache(t *testing.T) {
+	cache := newAnalysisCache(time.Hour, 2)
+	cache.set("a", Analysis{Sentiment: 0.1})
+	cache.set("b", Analysis{Sentiment: 0.2})
+	cache.set("c", Analysis{Sentiment: 0.3})
+
+	_, ok := cache.get("a")
+	assert.False(t, ok, "the soonest to expire is evicted when full")
+	analysis, ok := cache.get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 0.3, analysis.Sentiment)
+
+	expired := newAnalysisCache(-time.Second, 2)
+	expired.set("a", Analysis{})
+	_, ok = expired.get("a")
+	assert.False(t, ok)
+}
+
+func TestNewSentimentAnalyzer(t *testing.T) {
+	analyzer, err := NewSentimentAnalyzer(SentimentAnalyzerConfig{})
+	assert.NoError(t, err)
+	assert.IsType(t, &KeywordSentimentAnalyzer{}, analyzer)
+
+	analyzer, err = NewSentimentAnalyzer(SentimentAnalyzerConfig{Analyzer: "llm", LLM: NewFakeLLMProvider()})
+	assert.NoError(t, err)
+	assert.IsType(t, &LLMSentimentAnalyzer{}, analyzer)
+
+	_, err = NewSentimentAnalyzer(SentimentAnalyzerConfig{Analyzer: "llm"})
+	assert.Error(t, err, "the llm analyzer needs a provider")
+
+	_, err = NewSentimentAnalyzer(SentimentAnalyzerConfig{Analyzer: "magic"})
+	assert.Error(t, err)
+}
+
+func TestProcessNewsWithLLMAnalyzer(t *testing.T) {
+	// Keywords see "profit" and "decline" and call this neutral
+	item := NewsItem{
+		Title:             "Infosys reports profit decline as deal wins slow",
+		Description:       "Infosys said net profit fell 12% on weaker demand from US banks.",
+		Link:              "http://example.com/infy",
+		Source:            "MoneyControl",
+		SourceReliability: TrustedSourceReliability,
+		PublishedAt:       time.Now(),
+	}
+	resolver := NewMockStockResolver()
+	resolver.Symbols["INFY"] = "Infosys"
+
+	provider := NewFakeLLMProvider().Default(`{"sentiment": -0.8, "stocks": [
+		{"symbol": "INFY", "sentiment": -0.8, "event_type": "earnings", "horizon": "short_term", "rationale": "Net profit fell 12%."}]}`)
+	processor := NewProcessorWithConfig(ProcessorConfig{
+		Cache: NewRecommendationCache(CacheConfig{
+			TTL:             24 * time.Hour,
+			MaxItems:        1000,
+			CleanupInterval: 1 * time.Hour,
+		}),
+		StockResolver:     resolver,
+		SentimentAnalyzer: NewLLMSentimentAnalyzer(LLMSentimentAnalyzerConfig{Provider: provider}),
+	})
+
+	recommendations := processor.ProcessNews(context.Background(), []NewsItem{item})
+	if assert.Len(t, recommendations, 1) {
+		rec := recommendations[0]
+		assert.Equal(t, ActionSell, rec.Action)
+		assert.Equal(t, -0.8, rec.Sentiment)
+		assert.Equal(t, EventEarnings, rec.EventType)
+		assert.Equal(t, HorizonShortTerm, rec.Horizon)
+		assert.Contains(t, rec.Reason, "Net profit fell 12%.")
+	}
+
+	// A failing analyzer falls back to keywords
+	processor.sentimentAnalyzer = NewLLMSentimentAnalyzer(LLMSentimentAnalyzerConfig{
+		Provider:   NewFakeLLMProvider().OnError("", errors.New("model unavailable")),
+		RetryDelay: time.Millisecond,
+	})
+	recommendations = processor.processNewsItem(context.Background(), item)
+	if assert.Len(t, recommendations, 1) {
+		assert.Equal(t, NewKeywordSentimentAnalyzer().Score(item), recommendations[0].Sentiment)
+		assert.Empty(t, recommendations[0].EventType)
+	}
+}
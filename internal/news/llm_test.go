@@ -0,0 +1,203 @@
+package news
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLLMProvider(t *testing.T) {
+	_, err := NewLLMProvider(LLMConfig{})
+	assert.Error(t, err, "openai needs an API key")
+
+	provider, err := NewLLMProvider(LLMConfig{APIKey: "key"})
+	assert.NoError(t, err)
+	assert.IsType(t, &OpenAIProvider{}, provider)
+
+	_, err = NewLLMProvider(LLMConfig{Provider: ProviderOpenAICompatible})
+	assert.Error(t, err, "openai-compatible needs a base URL")
+
+	provider, err = NewLLMProvider(LLMConfig{Provider: ProviderOpenAICompatible, BaseURL: "http://localhost:11434/v1/", Model: "llama3"})
+	assert.NoError(t, err)
+	assert.Equal(t, "llama3", provider.(*OpenAIProvider).model)
+
+	provider, err = NewLLMProvider(LLMConfig{Provider: "Fake"})
+	assert.NoError(t, err)
+	assert.IsType(t, &FakeLLMProvider{}, provider)
+
+	_, err = NewLLMProvider(LLMConfig{Provider: "anthropic"})
+	assert.Error(t, err)
+}
+
+func TestFakeLLMProvider(t *testing.T) {
+	unavailable := errors.New("model unavailable")
+	provider := NewFakeLLMProvider().
+		On("Headline: IT stocks rally", `[{"symbol":"INFY","role":"subject","confidence":0.9},{"symbol":"ACME","role":"peer","confidence":0.8},{"symbol":"infy","role":"mentioned","confidence":0.5}]`).
+		On("Infosys", "INFY").
+		OnError("outage", unavailable).
+		Default("NIFTY")
+	ctx := context.Background()
+
+	reply, err := provider.Complete(ctx, LLMRequest{Prompt: "Infosys wins a deal"})
+	assert.NoError(t, err)
+	assert.Equal(t, "INFY", reply)
+
+	_, err = provider.Complete(ctx, LLMRequest{Prompt: "exchange outage"})
+	assert.ErrorIs(t, err, unavailable)
+
+	reply, err = provider.Complete(ctx, LLMRequest{Prompt: "markets close flat"})
+	assert.NoError(t, err)
+	assert.Equal(t, "NIFTY", reply)
+
+	assert.Len(t, provider.Requests(), 3)
+}
+
+func TestLLMLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	limiter := newLLMLimiter(2, 100)
+	limiter.now = func() time.Time { return now }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Requests per minute
+	assert.NoError(t, limiter.wait(ctx))
+	assert.NoError(t, limiter.wait(ctx))
+	assert.ErrorIs(t, limiter.wait(ctx), ErrLLMBudgetExceeded)
+
+	// The next window starts afresh
+	now = now.Add(time.Minute)
+	assert.NoError(t, limiter.wait(ctx))
+
+	// Tokens per minute
+	limiter.record(100)
+	assert.ErrorIs(t, limiter.wait(ctx), ErrLLMBudgetExceeded)
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, limiter.wait(ctx))
+
+	// No limits
+	unlimited := newLLMLimiter(0, 0)
+	for i := 0; i < 10; i++ {
+		unlimited.record(1000)
+		assert.NoError(t, unlimited.wait(ctx))
+	}
+}
+
+func TestLLMStockResolver(t *testing.T) {
+	dictionary, err := LoadStockDictionary(strings.NewReader(testDictionaryCSV))
+	assert.NoError(t, err)
+
+	provider := NewFakeLLMProvider().
+		On("Headline: IT stocks rally", `[{"symbol":"INFY","role":"subject","confidence":0.9},{"symbol":"ACME","role":"peer","confidence":0.8},{"symbol":"infy","role":"mentioned","confidence":0.5}]`).
+		On("Infosys", " \"NSE:infy\"\n").
+		On("Acme", "ACME").
+		On("Reliance", "The symbol is RELIANCE")
+	resolver := NewLLMStockResolver(LLMStockResolverConfig{
+		Provider: provider,
+		Symbols:  dictionary,
+	})
+	ctx := context.Background()
+
+	symbol, err := resolver.ResolveSymbol(ctx, "Infosys wins a large deal")
+	assert.NoError(t, err)
+	assert.Equal(t, "INFY", symbol, "the answer is cleaned up")
+
+	symbol, err = resolver.ResolveSymbol(ctx, "Acme Corp surges")
+	assert.NoError(t, err)
+	assert.Empty(t, symbol, "unknown symbols are rejected")
+
+	symbol, err = resolver.ResolveSymbol(ctx, "Reliance gains")
+	assert.NoError(t, err)
+	assert.Empty(t, symbol, "answers that are not a symbol are rejected")
+
+	entities, err := resolver.ResolveEntities(ctx, "IT stocks rally", "Infosys leads gains")
+	assert.NoError(t, err)
+	if assert.Len(t, entities, 1) {
+		assert.Equal(t, "INFY", entities[0].Symbol)
+		assert.Equal(t, RoleSubject, entities[0].Role)
+	}
+
+	// Cached answers are not asked again
+	requests := len(provider.Requests())
+	_, err = resolver.ResolveSymbol(ctx, "Infosys wins a large deal")
+	assert.NoError(t, err)
+	assert.Len(t, provider.Requests(), requests)
+
+	// Prompts come from the templates, with the system prompt on every request
+	last := provider.Requests()[requests-1]
+	assert.Equal(t, DefaultPromptTemplates().System, last.System)
+	assert.Contains(t, last.Prompt, "Body: Infosys leads gains")
+}
+
+func TestLLMStockResolverWithoutValidator(t *testing.T) {
+	provider := NewFakeLLMProvider().Default("acme")
+	resolver := NewLLMStockResolver(LLMStockResolverConfig{
+		Provider: provider,
+		Prompts:  PromptTemplates{Symbol: "Symbol of: {{.Text}}"},
+	})
+
+	symbol, err := resolver.ResolveSymbol(context.Background(), "Acme Corp surges")
+	assert.NoError(t, err)
+	assert.Equal(t, "ACME", symbol, "well-formed symbols are accepted without a validator")
+	assert.Equal(t, "Symbol of: Acme Corp surges", provider.Requests()[0].Prompt)
+	assert.Equal(t, DefaultPromptTemplates().System, provider.Requests()[0].System)
+}
+
+func TestInstrumentSymbolValidator(t *testing.T) {
+	validator := NewInstrumentSymbolValidator(fakeInstrumentLookup{})
+
+	symbol, ok := validator.ValidSymbol("Infosys")
+	assert.True(t, ok)
+	assert.Equal(t, "INFY", symbol)
+
+	_, ok = validator.ValidSymbol("BSEONLY")
+	assert.False(t, ok, "instruments not listed on the NSE are rejected")
+
+	_, ok = validator.ValidSymbol("ACME")
+	assert.False(t, ok)
+}
+
+func TestLoadPromptTemplatesFile(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "prompts.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"symbol": "Ticker for {{.Text}}?"}`), 0o644))
+	prompts, err := LoadPromptTemplatesFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "Ticker for {{.Text}}?", prompts.Symbol)
+	assert.Equal(t, DefaultPromptTemplates().Entities, prompts.Entities, "missing templates keep their defaults")
+
+	invalid := filepath.Join(dir, "invalid.json")
+	assert.NoError(t, os.WriteFile(invalid, []byte(`{"symbol": "Ticker for {{.Text"}`), 0o644))
+	prompts, err = LoadPromptTemplatesFile(invalid)
+	assert.Error(t, err)
+	assert.Equal(t, DefaultPromptTemplates(), prompts)
+
+	_, err = LoadPromptTemplatesFile(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+
+	prompts, err = LoadPromptTemplatesFile("../../data/llm_prompts.json")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultPromptTemplates(), prompts)
+}
+
+// fakeInstrumentLookup is an InstrumentLookup over a fixed set of instruments
+type fakeInstrumentLookup struct{}
+
+func (fakeInstrumentLookup) Lookup(identifier string) (models.Instrument, bool) {
+	switch identifier {
+	case "INFY", "Infosys":
+		return models.Instrument{NSESymbol: "INFY", BSECode: "500209"}, true
+	case "BSEONLY":
+		return models.Instrument{BSECode: "500001"}, true
+	}
+	return models.Instrument{}, false
+}
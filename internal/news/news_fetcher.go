@@ -3,8 +3,12 @@ package news
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/mmcdole/gofeed"
@@ -16,91 +20,342 @@ var (
 	ErrSourceNotFound = errors.New("source not found")
 )
 
+// Fetcher defaults
+const (
+	// DefaultFetchWorkers is the number of feeds fetched at the same time
+	DefaultFetchWorkers = 4
+
+	// DefaultFetchTimeout bounds a single feed request
+	DefaultFetchTimeout = 20 * time.Second
+
+	// DefaultRefreshInterval is how often a source without its own interval is fetched
+	DefaultRefreshInterval = 15 * time.Minute
+
+	// DefaultRetryDelay is the wait after a source's first failure; it doubles with each further failure
+	DefaultRetryDelay = time.Minute
+
+	// DefaultMaxBackoff caps the wait between attempts on a failing source
+	DefaultMaxBackoff = time.Hour
+)
+
+// FetcherConfig holds configuration for the news fetcher
+type FetcherConfig struct {
+	// Repository supplies the enabled sources of every owner on each fetch
+	Repository SourceRepository
+
+	// HTTPClient fetches the feeds (default: a client refusing to connect to non-public addresses)
+	HTTPClient *http.Client
+
+	// Workers is the number of feeds fetched at the same time (default: DefaultFetchWorkers)
+	Workers int
+
+	// Timeout bounds a single feed request (default: DefaultFetchTimeout)
+	Timeout time.Duration
+
+	// RetryDelay and MaxBackoff set the exponential backoff of failing sources
+	// (default: DefaultRetryDelay and DefaultMaxBackoff)
+	RetryDelay time.Duration
+	MaxBackoff time.Duration
+}
+
+// SourceHealth reports how fetching a source has gone
+type SourceHealth struct {
+	LastFetchAt         time.Time `json:"lastFetchAt"`
+	LastSuccessAt       time.Time `json:"lastSuccessAt"`
+	LastErrorAt         time.Time `json:"lastErrorAt"`
+	LastError           string    `json:"lastError,omitempty"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	LastItemCount       int       `json:"lastItemCount"`
+	TotalItemCount      int       `json:"totalItemCount"`
+	NotModifiedCount    int       `json:"notModifiedCount"`
+	NextFetchAt         time.Time `json:"nextFetchAt"`
+}
+
+// SourceStatus is a source with the health of its fetches
+type SourceStatus struct {
+	Source
+	Health SourceHealth `json:"health"`
+}
+
+// sourceState is the fetch state of a feed, keyed by its URL
+type sourceState struct {
+	etag         string
+	lastModified string
+	health       SourceHealth
+}
+
 // NewsFetcher handles fetching news from RSS feeds
 type NewsFetcher struct {
-	parser    *gofeed.Parser
-	sources   []Source
-	sourcesMu sync.RWMutex
+	parser     *gofeed.Parser
+	client     *http.Client
+	workers    int
+	timeout    time.Duration
+	retryDelay time.Duration
+	maxBackoff time.Duration
+
+	repository SourceRepository
+
+	states   map[string]*sourceState
+	statesMu sync.Mutex
 }
 
-// NewNewsFetcher creates a new news fetcher
-func NewNewsFetcher() *NewsFetcher {
+// NewNewsFetcher creates a new news fetcher fetching the sources of a repository
+func NewNewsFetcher(repository SourceRepository) *NewsFetcher {
+	return NewNewsFetcherWithConfig(FetcherConfig{Repository: repository})
+}
+
+// NewNewsFetcherWithConfig creates a new news fetcher from a configuration
+func NewNewsFetcherWithConfig(config FetcherConfig) *NewsFetcher {
+	if config.HTTPClient == nil {
+		config.HTTPClient = newFeedClient()
+	}
+	if config.Workers <= 0 {
+		config.Workers = DefaultFetchWorkers
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultFetchTimeout
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = DefaultRetryDelay
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+
 	return &NewsFetcher{
-		parser:  gofeed.NewParser(),
-		sources: GetDefaultSources(),
+		parser:     gofeed.NewParser(),
+		client:     config.HTTPClient,
+		workers:    config.Workers,
+		timeout:    config.Timeout,
+		retryDelay: config.RetryDelay,
+		maxBackoff: config.MaxBackoff,
+		repository: config.Repository,
+		states:     make(map[string]*sourceState),
 	}
 }
 
-// GetSources returns all configured sources
-func (f *NewsFetcher) GetSources() []Source {
-	f.sourcesMu.RLock()
-	defer f.sourcesMu.RUnlock()
-	return f.sources
+// Health returns the health of fetches of a feed
+func (f *NewsFetcher) Health(url string) SourceHealth {
+	f.statesMu.Lock()
+	defer f.statesMu.Unlock()
+
+	if state, ok := f.states[url]; ok {
+		return state.health
+	}
+	return SourceHealth{}
 }
 
-// AddSource adds a new source
-func (f *NewsFetcher) AddSource(source Source) error {
-	f.sourcesMu.Lock()
-	defer f.sourcesMu.Unlock()
+// FetchNews fetches news from the sources that are due, several at a time.
+// A source is due when its refresh interval has passed since its last fetch, or its backoff since its last failure.
+// Feeds that have not changed since the last fetch yield no items. Failing sources are logged and skipped.
+// Items carry the owners of the user sources they were fetched from, and none when a global source carries the feed.
+func (f *NewsFetcher) FetchNews(ctx context.Context) ([]NewsItem, error) {
+	sources, err := f.activeSources()
+	if err != nil {
+		return nil, err
+	}
 
-	// Check if source already exists
-	for _, s := range f.sources {
-		if s.Name == source.Name {
-			return ErrSourceExists
+	// Sources sharing a feed fetch it once, for all their owners
+	now := time.Now()
+	var due []int
+	seen := make(map[string]bool)
+	owners := make(map[string][]string)
+	global := make(map[string]bool)
+	for i, source := range sources {
+		if !seen[source.URL] && f.isDue(source, now) {
+			due = append(due, i)
+		}
+		seen[source.URL] = true
+		if source.OwnerID == "" {
+			global[source.URL] = true
+		} else if !containsOwner(owners[source.URL], source.OwnerID) {
+			owners[source.URL] = append(owners[source.URL], source.OwnerID)
 		}
 	}
+	for url := range global {
+		delete(owners, url)
+	}
 
-	f.sources = append(f.sources, source)
-	return nil
-}
+	results := make([][]NewsItem, len(sources))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < f.workers && w < len(due); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = f.fetchSource(ctx, sources[i], owners[sources[i].URL])
+			}
+		}()
+	}
+	for _, i := range due {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
 
-// RemoveSource removes a source by name
-func (f *NewsFetcher) RemoveSource(name string) error {
-	f.sourcesMu.Lock()
-	defer f.sourcesMu.Unlock()
+	var allNews []NewsItem
+	for _, items := range results {
+		allNews = append(allNews, items...)
+	}
+	return allNews, nil
+}
 
-	for i, s := range f.sources {
-		if s.Name == name {
-			f.sources = append(f.sources[:i], f.sources[i+1:]...)
-			return nil
-		}
+// activeSources returns the sources to fetch, the enabled sources in the repository with global sources first
+func (f *NewsFetcher) activeSources() ([]Source, error) {
+	sources, err := f.repository.GetEnabledSources()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load news sources: %w", err)
 	}
+	return sources, nil
+}
+
+// isDue reports whether a source should be fetched at the given time
+func (f *NewsFetcher) isDue(source Source, now time.Time) bool {
+	f.statesMu.Lock()
+	defer f.statesMu.Unlock()
 
-	return ErrSourceNotFound
+	state, ok := f.states[source.URL]
+	return !ok || !now.Before(state.health.NextFetchAt)
 }
 
-// FetchNews fetches news from all configured sources
-func (f *NewsFetcher) FetchNews(ctx context.Context) ([]NewsItem, error) {
-	f.sourcesMu.RLock()
-	sources := f.sources
-	f.sourcesMu.RUnlock()
+// fetchSource fetches and parses one feed for its owners, recording the outcome in its health
+func (f *NewsFetcher) fetchSource(ctx context.Context, source Source, owners []string) []NewsItem {
+	f.statesMu.Lock()
+	state, ok := f.states[source.URL]
+	if !ok {
+		state = &sourceState{}
+		f.states[source.URL] = state
+	}
+	etag, lastModified := state.etag, state.lastModified
+	f.statesMu.Unlock()
 
-	var allNews []NewsItem
-	for _, source := range sources {
-		feed, err := f.parser.ParseURLWithContext(source.URL, ctx)
-		if err != nil {
-			log.Printf("Error fetching from %s: %v", source.Name, err)
-			continue
-		}
+	feed, response, err := f.requestFeed(ctx, source.URL, etag, lastModified)
+	if ctx.Err() != nil {
+		// Cancellation says nothing about the source
+		return nil
+	}
 
-		for _, item := range feed.Items {
-			pubDate := time.Now()
-			if item.PublishedParsed != nil {
-				pubDate = *item.PublishedParsed
-			}
+	f.statesMu.Lock()
+	defer f.statesMu.Unlock()
 
-			newsItem := NewsItem{
-				Title:       item.Title,
-				Description: item.Description,
-				Link:        item.Link,
-				Source:      source.Name,
-				Category:    source.Category,
-				PublishedAt: pubDate,
-			}
+	now := time.Now()
+	health := &state.health
+	health.LastFetchAt = now
+	if err != nil {
+		health.LastErrorAt = now
+		health.LastError = err.Error()
+		health.ConsecutiveFailures++
+		health.NextFetchAt = now.Add(f.backoff(health.ConsecutiveFailures))
+		log.Printf("Error fetching from %s: %v", source.Name, err)
+		return nil
+	}
+
+	health.LastSuccessAt = now
+	health.ConsecutiveFailures = 0
+	health.NextFetchAt = now.Add(source.refreshInterval())
+	if response.StatusCode == http.StatusNotModified {
+		health.LastItemCount = 0
+		health.NotModifiedCount++
+		return nil
+	}
+	state.etag = response.Header.Get("ETag")
+	state.lastModified = response.Header.Get("Last-Modified")
 
-			allNews = append(allNews, newsItem)
+	items := make([]NewsItem, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		pubDate := time.Now()
+		if item.PublishedParsed != nil {
+			pubDate = *item.PublishedParsed
 		}
+
+		items = append(items, NewsItem{
+			Title:       item.Title,
+			Description: item.Description,
+			Link:        item.Link,
+			Source:      source.Name,
+			Category:    source.Category,
+			PublishedAt: pubDate,
+
+			SourceReliability: source.Reliability,
+			Owners:            owners,
+		})
 	}
+	health.LastItemCount = len(items)
+	health.TotalItemCount += len(items)
+	return items
+}
 
-	return allNews, nil
+// requestFeed requests a feed conditionally on the validators of the last fetch and parses it.
+// A not modified response has no feed.
+func (f *NewsFetcher) requestFeed(ctx context.Context, url, etag, lastModified string) (*gofeed.Feed, *http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", "FinSight/1.0")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, resp, nil
+	default:
+		return nil, nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	feed, err := f.parser.Parse(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return feed, resp, nil
+}
+
+// newFeedClient creates the default feed client, which refuses to connect to non-public addresses so that
+// sources cannot reach internal services, whatever their host names resolve to or redirect to
+func newFeedClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("%w: %s is not a public address", ErrInvalidSource, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // a proxy would be the only address checked
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Transport: transport}
+}
+
+// backoff returns the wait before retrying a source after the given number of consecutive failures
+func (f *NewsFetcher) backoff(failures int) time.Duration {
+	delay := f.retryDelay
+	for i := 1; i < failures && delay < f.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > f.maxBackoff {
+		delay = f.maxBackoff
+	}
+	return delay
 }
@@ -5,10 +5,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync/atomic"
 	"testing"
 	"time"
 
-	"github.com/mmcdole/gofeed"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,122 +25,25 @@ const feedXML = `
   </channel>
 </rss>`
 
-func TestNewNewsFetcher(t *testing.T) {
-	// Create a mock RSS feed XML
-	server, client := newMockRSSFeedServer(feedXML)
-	defer server.Close()
-	parser := gofeed.NewParser()
-	parser.Client = client
-
-	// Create a NewsFetcher with the mock server as its source
-	fetcher := &NewsFetcher{
-		parser:  parser,
-		sources: []Source{{Name: "MockSource", URL: server.URL}},
-	}
-
-	if len(fetcher.sources) == 0 {
-		t.Error("Expected default sources to be initialized")
-	}
-}
-
-func TestAddSource(t *testing.T) {
-	fetcher := NewNewsFetcher()
-
-	// Test adding a valid source
-	err := fetcher.AddSource(Source{
-		Name: "Test Source",
-		URL:  "http://example.com/feed",
-	})
-	if err != nil {
-		t.Errorf("Expected no error when adding valid source, got %v", err)
-	}
-
-	// Test adding duplicate source
-	err = fetcher.AddSource(Source{
-		Name: "Test Source",
-		URL:  "http://example.com/feed",
-	})
-	if err != ErrSourceExists {
-		t.Errorf("Expected ErrSourceExists when adding duplicate source, got %v", err)
-	}
-}
-
-func TestRemoveSource(t *testing.T) {
-	fetcher := NewNewsFetcher()
-
-	// Add a test source
-	err := fetcher.AddSource(Source{
-		Name: "Test Source",
-		URL:  "http://example.com/feed",
-	})
-	if err != nil {
-		t.Fatalf("Failed to add test source: %v", err)
-	}
-
-	// Test removing existing source
-	err = fetcher.RemoveSource("Test Source")
-	if err != nil {
-		t.Errorf("Expected no error when removing existing source, got %v", err)
-	}
-
-	// Test removing non-existent source
-	err = fetcher.RemoveSource("Non-existent Source")
-	if err != ErrSourceNotFound {
-		t.Errorf("Expected ErrSourceNotFound when removing non-existent source, got %v", err)
-	}
-}
-
-func TestGetSources(t *testing.T) {
-	fetcher := NewNewsFetcher()
-
-	// Add test sources
-	testSources := []Source{
-		{
-			Name: "Source 1",
-			URL:  "http://example.com/feed1",
-		},
-		{
-			Name: "Source 2",
-			URL:  "http://example.com/feed2",
-		},
-	}
-
-	for _, source := range testSources {
-		err := fetcher.AddSource(source)
-		if err != nil {
-			t.Fatalf("Failed to add test source %s: %v", source.Name, err)
-		}
-	}
-
-	// Get sources
-	sources := fetcher.GetSources()
-
-	// Verify all test sources are present
-	for _, testSource := range testSources {
-		found := false
-		for _, source := range sources {
-			if source.Name == testSource.Name && source.URL == testSource.URL {
-				found = true
-				break
-			}
-		}
-		if !found {
-			t.Errorf("Expected to find source %s with URL %s", testSource.Name, testSource.URL)
-		}
+// sourceRepository returns a fake repository holding the given global sources, enabled
+func sourceRepository(sources ...Source) *fakeSourceRepository {
+	repo := newFakeSourceRepository()
+	for _, source := range sources {
+		source.Enabled = true
+		repo.SaveSource(&source)
 	}
+	return repo
 }
 
 func TestFetchNews(t *testing.T) {
 	server, client := newMockRSSFeedServer(feedXML)
 	defer server.Close()
-	parser := gofeed.NewParser()
-	parser.Client = client
 
 	// Create a NewsFetcher with the mock server as its source
-	fetcher := &NewsFetcher{
-		parser:  parser,
-		sources: []Source{{Name: "MockSource", URL: server.URL}},
-	}
+	fetcher := NewNewsFetcherWithConfig(FetcherConfig{
+		Repository: sourceRepository(Source{Name: "MockSource", URL: server.URL}),
+		HTTPClient: client,
+	})
 
 	// Test fetching with valid source (mock test)
 	// Note: In a real test, you would use a mock HTTP server to test actual fetching
@@ -154,16 +57,10 @@ func TestFetchNews(t *testing.T) {
 }
 
 func TestFetchNewsWithTimeout(t *testing.T) {
-	fetcher := NewNewsFetcher()
-
-	// Add a source that will timeout
-	err := fetcher.AddSource(Source{
+	fetcher := NewNewsFetcher(sourceRepository(Source{
 		Name: "Timeout Source",
 		URL:  "http://example.com/timeout",
-	})
-	if err != nil {
-		t.Fatalf("Failed to add timeout source: %v", err)
-	}
+	}))
 
 	// Create a context with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
@@ -177,16 +74,10 @@ func TestFetchNewsWithTimeout(t *testing.T) {
 }
 
 func TestFetchNewsWithCancellation(t *testing.T) {
-	fetcher := NewNewsFetcher()
-
-	// Add a source
-	err := fetcher.AddSource(Source{
+	fetcher := NewNewsFetcher(sourceRepository(Source{
 		Name: "Test Source",
 		URL:  "http://example.com/feed",
-	})
-	if err != nil {
-		t.Fatalf("Failed to add test source: %v", err)
-	}
+	}))
 
 	// Create a cancellable context
 	ctx, cancel := context.WithCancel(context.Background())
@@ -215,3 +106,171 @@ func newMockRSSFeedServer(feedXML string) (*httptest.Server, *http.Client) {
 	client := &http.Client{Transport: transport}
 	return server, client
 }
+
+// newConditionalFeedServer serves feedXML with an ETag, answering matching conditional requests with 304
+func newConditionalFeedServer(requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(feedXML))
+	}))
+}
+
+func TestFetchNewsConditionalRequests(t *testing.T) {
+	var requests int32
+	server := newConditionalFeedServer(&requests)
+	defer server.Close()
+
+	fetcher := NewNewsFetcherWithConfig(FetcherConfig{
+		Repository: sourceRepository(Source{Name: "MockSource", URL: server.URL, Category: "Business"}),
+		HTTPClient: server.Client(),
+	})
+
+	newsItems, err := fetcher.FetchNews(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, newsItems, 1)
+	assert.Equal(t, "MockSource", newsItems[0].Source)
+	assert.Equal(t, "Business", newsItems[0].Category)
+
+	// The source is not due again until its refresh interval has passed
+	newsItems, err = fetcher.FetchNews(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, newsItems)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// Once due, an unchanged feed answers 304 and yields nothing
+	fetcher.states[server.URL].health.NextFetchAt = time.Time{}
+	newsItems, err = fetcher.FetchNews(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, newsItems)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	health := fetcher.Health(server.URL)
+	assert.Equal(t, 1, health.TotalItemCount)
+	assert.Equal(t, 0, health.LastItemCount)
+	assert.Equal(t, 1, health.NotModifiedCount)
+	assert.Equal(t, 0, health.ConsecutiveFailures)
+	assert.True(t, health.NextFetchAt.After(time.Now().Add(DefaultRefreshInterval-time.Minute)))
+}
+
+func TestFetchNewsBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	fetcher := NewNewsFetcherWithConfig(FetcherConfig{
+		Repository: sourceRepository(Source{Name: "Failing", URL: server.URL}),
+		HTTPClient: server.Client(),
+		RetryDelay: time.Minute,
+		MaxBackoff: 3 * time.Minute,
+	})
+
+	for i := 0; i < 3; i++ {
+		if state, ok := fetcher.states[server.URL]; ok {
+			state.health.NextFetchAt = time.Time{}
+		}
+		newsItems, err := fetcher.FetchNews(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, newsItems)
+	}
+
+	health := fetcher.Health(server.URL)
+	assert.Equal(t, 3, health.ConsecutiveFailures)
+	assert.Contains(t, health.LastError, "503")
+	assert.True(t, health.LastSuccessAt.IsZero())
+
+	// The wait doubles with each failure up to the maximum
+	assert.Equal(t, time.Minute, fetcher.backoff(1))
+	assert.Equal(t, 2*time.Minute, fetcher.backoff(2))
+	assert.Equal(t, 3*time.Minute, fetcher.backoff(3))
+	assert.Equal(t, 3*time.Minute, fetcher.backoff(10))
+	assert.WithinDuration(t, health.LastErrorAt.Add(3*time.Minute), health.NextFetchAt, time.Second)
+}
+
+func TestFetchNewsRefusesNonPublicAddresses(t *testing.T) {
+	var requests int32
+	server := newConditionalFeedServer(&requests)
+	defer server.Close()
+
+	// The default client will not connect to the loopback test server
+	fetcher := NewNewsFetcherWithConfig(FetcherConfig{
+		Repository: sourceRepository(Source{Name: "Internal", URL: server.URL}),
+	})
+
+	newsItems, err := fetcher.FetchNews(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, newsItems)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+
+	health := fetcher.Health(server.URL)
+	assert.Equal(t, 1, health.ConsecutiveFailures)
+	assert.Contains(t, health.LastError, "not a public address")
+}
+
+func TestFetchNewsSlowSource(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	var requests int32
+	fast := newConditionalFeedServer(&requests)
+	defer fast.Close()
+
+	fetcher := NewNewsFetcherWithConfig(FetcherConfig{
+		Repository: sourceRepository(
+			Source{Name: "Slow", URL: slow.URL},
+			Source{Name: "Fast", URL: fast.URL},
+		),
+		HTTPClient: http.DefaultClient,
+		Timeout:    200 * time.Millisecond,
+	})
+
+	// The slow feed times out without holding up the other
+	start := time.Now()
+	newsItems, err := fetcher.FetchNews(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, newsItems, 1)
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	assert.Equal(t, 1, fetcher.Health(slow.URL).ConsecutiveFailures)
+	assert.Equal(t, 1, fetcher.Health(fast.URL).LastItemCount)
+}
+
+func TestFetchNewsSharedFeedOwners(t *testing.T) {
+	server, client := newMockRSSFeedServer(feedXML)
+	defer server.Close()
+
+	// Users sharing a feed each see its news
+	repo := sourceRepository()
+	repo.SaveSource(&Source{OwnerID: "alice", Name: "Feed", URL: server.URL, Enabled: true})
+	repo.SaveSource(&Source{OwnerID: "bob", Name: "Feed", URL: server.URL, Enabled: true})
+	fetcher := NewNewsFetcherWithConfig(FetcherConfig{Repository: repo, HTTPClient: client})
+
+	newsItems, err := fetcher.FetchNews(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, newsItems, 1) {
+		assert.Equal(t, []string{"alice", "bob"}, newsItems[0].Owners)
+	}
+
+	// A global source carrying the feed makes its news everyone's
+	repo.SaveSource(&Source{Name: "Feed", URL: server.URL, Enabled: true})
+	fetcher = NewNewsFetcherWithConfig(FetcherConfig{Repository: repo, HTTPClient: client})
+
+	newsItems, err = fetcher.FetchNews(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, newsItems, 1) {
+		assert.Empty(t, newsItems[0].Owners)
+	}
+}
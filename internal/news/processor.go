@@ -2,124 +2,259 @@ package news
 
 import (
 	"context"
-	"fmt"
+	"log"
+	"sort"
 	"strings"
 	"time"
 )
 
+// maxStockRecommendations is the number of recommendations GetRecommendationsByStock reads from the archive
+const maxStockRecommendations = 100
+
+// ProcessorConfig holds configuration for the news processor
+type ProcessorConfig struct {
+	Cache *RecommendationCache
+
+	// Repository archives news items and recommendations (optional; without it recommendations only live in the cache)
+	Repository NewsRepository
+
+	// StockResolver finds the stock a news item is about (default: an LLMStockResolver asking OpenAI with OpenAIAPIKey)
+	StockResolver StockResolver
+
+	// SentimentAnalyzer scores the sentiment of news items (default: a KeywordSentimentAnalyzer)
+	SentimentAnalyzer SentimentAnalyzer
+
+	OpenAIAPIKey string
+}
+
 // Processor handles filtering and processing of news items
 type Processor struct {
-	cache         *RecommendationCache
-	stockResolver StockResolver
+	cache             *RecommendationCache
+	repository        NewsRepository
+	stockResolver     StockResolver
+	sentimentAnalyzer SentimentAnalyzer
 }
 
 // NewProcessor creates a new news processor
 func NewProcessor(cache *RecommendationCache, openAIKey string) *Processor {
+	return NewProcessorWithConfig(ProcessorConfig{
+		Cache:        cache,
+		OpenAIAPIKey: openAIKey,
+	})
+}
+
+// NewProcessorWithConfig creates a new news processor from a configuration
+func NewProcessorWithConfig(config ProcessorConfig) *Processor {
+	if config.StockResolver == nil {
+		config.StockResolver = NewOpenAIStockResolver(config.OpenAIAPIKey)
+	}
+	if config.SentimentAnalyzer == nil {
+		config.SentimentAnalyzer = NewKeywordSentimentAnalyzer()
+	}
+
 	return &Processor{
-		cache:         cache,
-		stockResolver: NewOpenAIStockResolver(openAIKey),
+		cache:             config.Cache,
+		repository:        config.Repository,
+		stockResolver:     config.StockResolver,
+		sentimentAnalyzer: config.SentimentAnalyzer,
 	}
 }
 
-// ProcessNews processes a list of news items and returns recommendations
+// ProcessNews processes a list of news items and returns recommendations, one for each stock an item refers to.
+// Processed items and all their recommendations are written through to the repository; only high confidence
+// recommendations are returned and cached. Items already processed are not analyzed again, but their recommendations
+// are shared with the owners of the sources carrying them this time.
 func (p *Processor) ProcessNews(ctx context.Context, newsItems []NewsItem) []Recommendation {
 	var recommendations []Recommendation
+	var processed []NewsItem
+	var shared []NewsItem
+	var archived []Recommendation
 
+	// Skip items already processed, whether still cached or archived before a restart
+	cached := p.cachedByLink()
+	stored := p.storedLinks(newsItems)
 	for _, item := range newsItems {
-		// Skip if already in cache
-		if _, found := p.cache.Get(item.Link); found {
+		if itemRecommendations, ok := cached[item.Link]; ok || stored[item.Link] {
+			cached[item.Link] = p.share(itemRecommendations, item.Owners)
+			shared = append(shared, item)
 			continue
 		}
+		stored[item.Link] = true
 
 		// Process the news item
-		recommendation := p.processNewsItem(item)
-		if recommendation.Confidence > 0.5 { // Only keep high confidence recommendations
-			recommendations = append(recommendations, recommendation)
-			p.cache.Set(item.Link, recommendation)
+		itemRecommendations := p.processNewsItem(ctx, item)
+		processed = append(processed, itemRecommendations[0].NewsItem)
+		for _, recommendation := range itemRecommendations {
+			if recommendation.Confidence > MinRecommendationConfidence { // Only serve high confidence recommendations
+				recommendations = append(recommendations, recommendation)
+				p.cache.Set(recommendationKey(item.Link, recommendation.StockSymbol), recommendation)
+				cached[item.Link] = append(cached[item.Link], recommendation)
+				archived = append(archived, recommendation)
+			} else if recommendation.StockSymbol != "" {
+				// Keep mentions of a stock for lookups by stock
+				archived = append(archived, recommendation)
+			}
 		}
 	}
 
+	p.archive(append(processed, shared...), archived)
 	return recommendations
 }
 
-// analyzeSentiment performs basic sentiment analysis on a news item
-func (p *Processor) analyzeSentiment(item NewsItem) float64 {
-	// Convert text to lowercase for case-insensitive matching
-	title := strings.ToLower(item.Title)
-	description := strings.ToLower(item.Description)
-
-	// Count positive and negative matches
-	positiveCount := 0
-	negativeCount := 0
+// share extends cached recommendations of a news item to the owners of another source carrying it
+func (p *Processor) share(recommendations []Recommendation, owners []string) []Recommendation {
+	for i := range recommendations {
+		recommendation := &recommendations[i]
+		recommendation.NewsItem.Owners = mergeOwners(recommendation.NewsItem.Owners, owners)
+		p.cache.Set(recommendationKey(recommendation.NewsItem.Link, recommendation.StockSymbol), *recommendation)
+	}
+	return recommendations
+}
 
-	// Check title and description for keywords
-	text := title + " " + description
-	for _, keyword := range PositiveKeywords {
-		if strings.Contains(text, keyword) {
-			positiveCount++
-		}
+// WarmCache loads recommendations archived within the cache TTL into the cache, returning the number loaded
+func (p *Processor) WarmCache(limit int) (int, error) {
+	if p.repository == nil {
+		return 0, nil
 	}
-	for _, keyword := range NegativeKeywords {
-		if strings.Contains(text, keyword) {
-			negativeCount++
-		}
+	recommendations, err := p.repository.GetRecentRecommendations(limit)
+	if err != nil {
+		return 0, err
 	}
 
-	// Calculate sentiment score (-1 to 1)
-	totalCount := positiveCount + negativeCount
-	if totalCount == 0 {
-		return NeutralSentimentScore // Neutral if no keywords found
+	loaded := 0
+	for _, recommendation := range recommendations {
+		if time.Since(recommendation.CreatedAt) > p.cache.config.TTL {
+			continue
+		}
+		p.cache.Set(recommendationKey(recommendation.NewsItem.Link, recommendation.StockSymbol), recommendation)
+		loaded++
 	}
+	return loaded, nil
+}
 
-	// Normalize to -1 to 1 range
-	sentiment := float64(positiveCount-negativeCount) / float64(totalCount)
+// recommendationKey returns the cache key of the recommendation on a stock from a news item
+func recommendationKey(link, stockSymbol string) string {
+	return link + "#" + stockSymbol
+}
 
-	// Adjust based on source reliability
-	switch item.Source {
-	case "MoneyControl":
-		sentiment *= MoneyControlMultiplier
-	case "Economic Times":
-		sentiment *= EconomicTimesMultiplier
-	case "Business Standard", "Business Standard Markets", "Business Standard Stock Market":
-		sentiment *= BusinessStandardMultiplier
+// cachedByLink returns the cached recommendations by the link of their news item
+func (p *Processor) cachedByLink() map[string][]Recommendation {
+	byLink := make(map[string][]Recommendation)
+	for _, recommendation := range p.cache.GetAll() {
+		byLink[recommendation.NewsItem.Link] = append(byLink[recommendation.NewsItem.Link], recommendation)
 	}
+	return byLink
+}
 
-	// Ensure sentiment stays within bounds
-	if sentiment > MaxSentimentScore {
-		sentiment = MaxSentimentScore
-	} else if sentiment < MinSentimentScore {
-		sentiment = MinSentimentScore
+// storedLinks returns the links of the news items already in the repository
+func (p *Processor) storedLinks(newsItems []NewsItem) map[string]bool {
+	if p.repository == nil || len(newsItems) == 0 {
+		return make(map[string]bool)
+	}
+
+	links := make([]string, 0, len(newsItems))
+	for _, item := range newsItems {
+		links = append(links, item.Link)
+	}
+	stored, err := p.repository.GetStoredLinks(links)
+	if err != nil {
+		log.Printf("Error reading archived news links: %v", err)
+		return make(map[string]bool)
 	}
+	return stored
+}
 
-	return sentiment
+// archive writes news items, with their owners, and the recommendations of those processed to the repository
+func (p *Processor) archive(newsItems []NewsItem, recommendations []Recommendation) {
+	if p.repository == nil || len(newsItems) == 0 {
+		return
+	}
+	if err := p.repository.SaveNewsItems(newsItems); err != nil {
+		log.Printf("Error archiving news items: %v", err)
+		return
+	}
+	if len(recommendations) == 0 {
+		return
+	}
+	if err := p.repository.SaveRecommendations(recommendations); err != nil {
+		log.Printf("Error archiving recommendations: %v", err)
+	}
 }
 
-// processNewsItem processes a single news item and returns a recommendation
-func (p *Processor) processNewsItem(item NewsItem) Recommendation {
-	// Calculate sentiment if not already set
+// processNewsItem processes a single news item and returns a recommendation for each stock it refers to,
+// or a single recommendation without a stock when it refers to none
+func (p *Processor) processNewsItem(ctx context.Context, item NewsItem) []Recommendation {
+	// Extract the stocks from title and description
+	entities := p.extractStockEntities(ctx, item)
+
+	// Calculate sentiment, towards the item and each stock, if not already set
+	analysis := p.analyze(ctx, item, entities)
 	if item.Sentiment == 0 {
-		item.Sentiment = p.analyzeSentiment(item)
+		item.Sentiment = analysis.Sentiment
 	}
 
 	// Calculate relevance score based on various factors
 	relevanceScore := p.calculateRelevanceScore(item)
 
-	// Determine action based on sentiment and relevance
-	action := p.determineAction(item.Sentiment, relevanceScore)
+	if len(entities) == 0 {
+		entities = []StockEntity{{}}
+	}
 
-	// Calculate confidence based on source reliability and content quality
-	confidence := p.calculateConfidence(item)
+	recommendations := make([]Recommendation, 0, len(entities))
+	for _, entity := range entities {
+		// Determine action based on the sentiment towards the stock and relevance
+		stock := analysis.Stock(entity.Symbol)
+		sentiment := stock.Sentiment
+		action := p.determineAction(sentiment, relevanceScore)
+
+		// Calculate confidence based on source reliability, content quality and the stock's role
+		scored := item
+		scored.Sentiment = sentiment
+		confidence := p.calculateConfidence(scored) * roleConfidenceWeight(entity.Role)
+
+		reason := p.generateReason(item, action, confidence)
+		if stock.Rationale != "" {
+			reason += ". " + stock.Rationale
+		}
 
-	// Extract stock symbol from title or description
-	stockSymbol := p.extractStockSymbol(item)
+		recommendations = append(recommendations, Recommendation{
+			StockSymbol: entity.Symbol,
+			Role:        entity.Role,
+			Action:      action,
+			Confidence:  confidence,
+			Sentiment:   sentiment,
+			EventType:   stock.EventType,
+			Horizon:     stock.Horizon,
+			Reason:      reason,
+			NewsItem:    item,
+			CreatedAt:   time.Now(),
+		})
+	}
+	return recommendations
+}
 
-	return Recommendation{
-		StockSymbol: stockSymbol,
-		Action:      action,
-		Confidence:  confidence,
-		Reason:      p.generateReason(item, action, confidence),
-		NewsItem:    item,
-		CreatedAt:   time.Now(),
+// analyze scores the sentiment of a news item with the configured analyzer, falling back to keywords when it fails
+func (p *Processor) analyze(ctx context.Context, item NewsItem, entities []StockEntity) Analysis {
+	if p.sentimentAnalyzer != nil {
+		analysis, err := p.sentimentAnalyzer.Analyze(ctx, item, entities)
+		if err == nil {
+			return analysis
+		}
+		log.Printf("Error analyzing news sentiment, using keywords: %v", err)
+	}
+	analysis, _ := NewKeywordSentimentAnalyzer().Analyze(ctx, item, entities)
+	return analysis
+}
+
+// roleConfidenceWeight returns how much of a recommendation's confidence a stock's role in the news keeps
+func roleConfidenceWeight(role string) float64 {
+	switch role {
+	case RolePeer:
+		return PeerConfidenceWeight
+	case RoleMentioned:
+		return MentionedConfidenceWeight
+	default:
+		return SubjectConfidenceWeight
 	}
 }
 
@@ -137,10 +272,8 @@ func (p *Processor) calculateRelevanceScore(item NewsItem) float64 {
 		}
 	}
 
-	// Check source reliability
-	if ReliableSources[item.Source] {
-		score += SourceReliabilityScore
-	}
+	// Weigh in source reliability
+	score += SourceReliabilityScore * sourceReliability(item)
 
 	// Check recency
 	age := time.Since(item.PublishedAt)
@@ -178,16 +311,7 @@ func (p *Processor) calculateConfidence(item NewsItem) float64 {
 	var confidence float64
 
 	// Source reliability
-	switch item.Source {
-	case "MoneyControl":
-		confidence += MoneyControlConfidence
-	case "Economic Times":
-		confidence += EconomicTimesConfidence
-	case "Business Standard", "Business Standard Markets", "Business Standard Stock Market":
-		confidence += BusinessStandardConfidence
-	default:
-		confidence += DefaultSourceConfidence
-	}
+	confidence += SourceConfidenceWeight * sourceReliability(item)
 
 	// Content quality
 	if len(item.Description) > 100 {
@@ -209,21 +333,30 @@ func (p *Processor) calculateConfidence(item NewsItem) float64 {
 	return confidence
 }
 
-// extractStockSymbol extracts the stock symbol from the news item
-func (p *Processor) extractStockSymbol(item NewsItem) string {
-	// Combine title and description for better context
-	text := item.Title + " " + item.Description
+// sourceReliability returns the reliability weight of the source of a news item, DefaultSourceReliability when unknown
+func sourceReliability(item NewsItem) float64 {
+	if item.SourceReliability <= 0 {
+		return DefaultSourceReliability
+	}
+	return item.SourceReliability
+}
 
-	// Use the stock resolver to get the symbol
-	symbol, err := p.stockResolver.ResolveSymbol(context.Background(), text)
+// extractStockEntities extracts the stocks the news item refers to with enough confidence
+func (p *Processor) extractStockEntities(ctx context.Context, item NewsItem) []StockEntity {
+	// Use the stock resolver to get the stocks from the title and description
+	entities, err := p.stockResolver.ResolveEntities(ctx, item.Title, item.Description)
 	if err != nil {
-		// Log the error but continue processing
-		// In a production environment, you might want to use proper logging
-		fmt.Printf("Error resolving stock symbol: %v\n", err)
-		return ""
+		log.Printf("Error resolving stock symbols: %v", err)
+		return nil
 	}
 
-	return symbol
+	confident := entities[:0]
+	for _, entity := range entities {
+		if entity.Symbol != "" && entity.Confidence >= MinEntityConfidence {
+			confident = append(confident, entity)
+		}
+	}
+	return confident
 }
 
 // generateReason generates a human-readable reason for the recommendation
@@ -247,23 +380,48 @@ func (p *Processor) generateReason(item NewsItem, action string, confidence floa
 	return reason.String()
 }
 
-// GetRecommendationsByStock returns recommendations for a specific stock
-func (p *Processor) GetRecommendationsByStock(stockSymbol string) []Recommendation {
-	allRecs := p.cache.GetAll()
+// GetRecommendationsByStock returns the recommendations for a specific stock that a user sees, newest first;
+// an empty userID returns those from global news only.
+// They are read from the repository, which also keeps those from news that only mention the stock and those older
+// than the cache, merged with the cached ones not yet archived. The cache alone is served when the repository fails.
+func (p *Processor) GetRecommendationsByStock(userID, stockSymbol string) []Recommendation {
 	var stockRecs []Recommendation
+	if p.repository != nil {
+		recommendations, err := p.repository.GetRecommendationsByStock(userID, stockSymbol, maxStockRecommendations)
+		if err != nil {
+			log.Printf("Error reading recommendations for %s: %v", stockSymbol, err)
+		}
+		stockRecs = recommendations
+	}
 
-	for _, rec := range allRecs {
-		if rec.StockSymbol == stockSymbol {
+	seen := make(map[string]bool, len(stockRecs))
+	for _, rec := range stockRecs {
+		seen[recommendationKey(rec.NewsItem.Link, rec.StockSymbol)] = true
+	}
+	for _, rec := range p.cachedFor(userID) {
+		if rec.StockSymbol == stockSymbol && !seen[recommendationKey(rec.NewsItem.Link, rec.StockSymbol)] {
 			stockRecs = append(stockRecs, rec)
 		}
 	}
 
+	sort.SliceStable(stockRecs, func(i, j int) bool {
+		return stockRecs[i].CreatedAt.After(stockRecs[j].CreatedAt)
+	})
 	return stockRecs
 }
 
-// GetLatestRecommendations returns the most recent recommendations
-func (p *Processor) GetLatestRecommendations(limit int) []Recommendation {
-	allRecs := p.cache.GetAll()
+// GetLatestRecommendations returns the most recent recommendations that a user sees; an empty userID returns
+// those from global news only. They are served from the cache when it holds enough and otherwise read from the
+// repository.
+func (p *Processor) GetLatestRecommendations(userID string, limit int) []Recommendation {
+	allRecs := p.cachedFor(userID)
+	if len(allRecs) < limit && p.repository != nil {
+		recommendations, err := p.repository.GetLatestRecommendations(userID, limit)
+		if err == nil {
+			return recommendations
+		}
+		log.Printf("Error reading latest recommendations: %v", err)
+	}
 	if len(allRecs) <= limit {
 		return allRecs
 	}
@@ -280,12 +438,13 @@ func (p *Processor) GetLatestRecommendations(limit int) []Recommendation {
 	return allRecs[:limit]
 }
 
-// FetchNews fetches news from all configured sources
-func (p *Processor) FetchNews(ctx context.Context) ([]NewsItem, error) {
-	fetcher := NewNewsFetcher()
-	newsItems, err := fetcher.FetchNews(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("error fetching news: %w", err)
+// cachedFor returns the cached recommendations that a user sees
+func (p *Processor) cachedFor(userID string) []Recommendation {
+	var visible []Recommendation
+	for _, rec := range p.cache.GetAll() {
+		if rec.NewsItem.visibleTo(userID) {
+			visible = append(visible, rec)
+		}
 	}
-	return newsItems, nil
+	return visible
 }
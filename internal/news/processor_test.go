@@ -2,6 +2,7 @@ package news
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -43,6 +44,14 @@ func (r *MockOpenAIResolver) ResolveSymbol(ctx context.Context, text string) (st
 	return "", nil
 }
 
+func (r *MockOpenAIResolver) ResolveEntities(ctx context.Context, headline, body string) ([]StockEntity, error) {
+	symbol, err := r.ResolveSymbol(ctx, headline+" "+body)
+	if err != nil || symbol == "" {
+		return nil, err
+	}
+	return []StockEntity{{Symbol: symbol, Role: RoleSubject, Confidence: 1}}, nil
+}
+
 func TestNewProcessor(t *testing.T) {
 	cache := NewRecommendationCache(CacheConfig{
 		TTL:             24 * time.Hour,
@@ -79,18 +88,20 @@ func TestProcessNews(t *testing.T) {
 	// Test processing news items
 	newsItems := []NewsItem{
 		{
-			Title:       "Positive news about NIFTY",
-			Description: "NIFTY shows strong growth potential",
-			Link:        "http://example.com/1",
-			Source:      "MoneyControl",
-			PublishedAt: time.Now(),
+			Title:             "Positive news about NIFTY",
+			Description:       "NIFTY shows strong growth potential",
+			Link:              "http://example.com/1",
+			Source:            "MoneyControl",
+			SourceReliability: TrustedSourceReliability,
+			PublishedAt:       time.Now(),
 		},
 		{
-			Title:       "Negative news about NIFTY",
-			Description: "NIFTY faces market challenges",
-			Link:        "http://example.com/2",
-			Source:      "Economic Times",
-			PublishedAt: time.Now(),
+			Title:             "Negative news about NIFTY",
+			Description:       "NIFTY faces market challenges",
+			Link:              "http://example.com/2",
+			Source:            "Economic Times",
+			SourceReliability: TrustedSourceReliability,
+			PublishedAt:       time.Now(),
 		},
 	}
 
@@ -107,11 +118,7 @@ func TestProcessNews(t *testing.T) {
 }
 
 func TestAnalyzeSentiment(t *testing.T) {
-	processor := NewProcessor(NewRecommendationCache(CacheConfig{
-		TTL:             24 * time.Hour,
-		MaxItems:        1000,
-		CleanupInterval: 1 * time.Hour,
-	}), "test-api-key")
+	analyzer := NewKeywordSentimentAnalyzer()
 
 	tests := []struct {
 		name     string
@@ -149,7 +156,7 @@ func TestAnalyzeSentiment(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			sentiment := processor.analyzeSentiment(tt.item)
+			sentiment := analyzer.Score(tt.item)
 			if sentiment == 0 && tt.expected != 0 {
 				t.Errorf("Expected non-zero sentiment, got %f", sentiment)
 			}
@@ -306,6 +313,37 @@ func TestCalculateConfidence(t *testing.T) {
 	}
 }
 
+func TestSourceReliabilityWeighting(t *testing.T) {
+	processor := NewProcessor(NewRecommendationCache(CacheConfig{
+		TTL:             24 * time.Hour,
+		MaxItems:        1000,
+		CleanupInterval: 1 * time.Hour,
+	}), "test-api-key")
+
+	item := NewsItem{
+		Title:       "Market update",
+		Description: "Regular market update",
+		Source:      "Some Feed",
+		PublishedAt: time.Now(),
+	}
+	trusted := item
+	trusted.SourceReliability = TrustedSourceReliability
+
+	// The stored reliability of the source weighs in, whatever its name
+	if processor.calculateConfidence(trusted) <= processor.calculateConfidence(item) {
+		t.Error("Expected a trusted source to give more confidence than an unknown one")
+	}
+	if processor.calculateRelevanceScore(trusted) <= processor.calculateRelevanceScore(item) {
+		t.Error("Expected a trusted source to be more relevant than an unknown one")
+	}
+
+	// Items of unknown reliability are weighed as default sources
+	expected := SourceConfidenceWeight * DefaultSourceReliability
+	if confidence := processor.calculateConfidence(item); confidence < expected-1e-9 || confidence > expected+1e-9 {
+		t.Errorf("Expected confidence %f for an unknown source, got %f", expected, confidence)
+	}
+}
+
 func TestGenerateReason(t *testing.T) {
 	processor := NewProcessor(NewRecommendationCache(CacheConfig{
 		TTL:             24 * time.Hour,
@@ -405,7 +443,7 @@ func TestGetRecommendationsByStock(t *testing.T) {
 	}
 
 	// Test getting NIFTY recommendations
-	niftyRecs := processor.GetRecommendationsByStock("NIFTY")
+	niftyRecs := processor.GetRecommendationsByStock("", "NIFTY")
 	if len(niftyRecs) != 1 {
 		t.Errorf("Expected 1 NIFTY recommendation, got %d", len(niftyRecs))
 	}
@@ -414,7 +452,7 @@ func TestGetRecommendationsByStock(t *testing.T) {
 	}
 
 	// Test getting RELIANCE recommendations
-	relianceRecs := processor.GetRecommendationsByStock("RELIANCE")
+	relianceRecs := processor.GetRecommendationsByStock("", "RELIANCE")
 	if len(relianceRecs) != 1 {
 		t.Errorf("Expected 1 RELIANCE recommendation, got %d", len(relianceRecs))
 	}
@@ -423,7 +461,7 @@ func TestGetRecommendationsByStock(t *testing.T) {
 	}
 
 	// Test getting TCS recommendations
-	tcsRecs := processor.GetRecommendationsByStock("TCS")
+	tcsRecs := processor.GetRecommendationsByStock("", "TCS")
 	if len(tcsRecs) != 1 {
 		t.Errorf("Expected 1 TCS recommendation, got %d", len(tcsRecs))
 	}
@@ -432,7 +470,7 @@ func TestGetRecommendationsByStock(t *testing.T) {
 	}
 
 	// Test getting non-existent stock recommendations
-	unknownRecs := processor.GetRecommendationsByStock("UNKNOWN")
+	unknownRecs := processor.GetRecommendationsByStock("", "UNKNOWN")
 	if len(unknownRecs) != 0 {
 		t.Error("Expected no recommendations for unknown stock")
 	}
@@ -499,7 +537,7 @@ func TestGetLatestRecommendations(t *testing.T) {
 	}
 
 	// Test getting latest 2 recommendations
-	latestRecs := processor.GetLatestRecommendations(2)
+	latestRecs := processor.GetLatestRecommendations("", 2)
 	if len(latestRecs) != 2 {
 		t.Errorf("Expected 2 latest recommendations, got %d", len(latestRecs))
 	}
@@ -511,7 +549,7 @@ func TestGetLatestRecommendations(t *testing.T) {
 	}
 
 	// Test getting all recommendations
-	allRecs := processor.GetLatestRecommendations(10)
+	allRecs := processor.GetLatestRecommendations("", 10)
 	if len(allRecs) != 3 {
 		t.Errorf("Expected 3 recommendations, got %d", len(allRecs))
 	}
@@ -620,3 +658,327 @@ func TestExtractStockSymbol(t *testing.T) {
 		})
 	}
 }
+
+// fakeNewsRepository is an in-memory NewsRepository
+type fakeNewsRepository struct {
+	items           map[string]NewsItem
+	owners          map[string][]string // owners of the archived links, "" for global sources
+	recommendations []Recommendation
+	err             error // returned by GetRecommendationsByStock when set
+}
+
+func newFakeNewsRepository() *fakeNewsRepository {
+	return &fakeNewsRepository{items: make(map[string]NewsItem), owners: make(map[string][]string)}
+}
+
+func (r *fakeNewsRepository) SaveNewsItems(items []NewsItem) error {
+	for _, item := range items {
+		if _, ok := r.items[item.Link]; !ok {
+			r.items[item.Link] = item
+		}
+		owners := item.Owners
+		if len(owners) == 0 {
+			owners = []string{""}
+		}
+		for _, owner := range owners {
+			if !containsOwner(r.owners[item.Link], owner) {
+				r.owners[item.Link] = append(r.owners[item.Link], owner)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *fakeNewsRepository) SaveRecommendations(recommendations []Recommendation) error {
+	r.recommendations = append(r.recommendations, recommendations...)
+	return nil
+}
+
+func (r *fakeNewsRepository) GetStoredLinks(links []string) (map[string]bool, error) {
+	stored := make(map[string]bool)
+	for _, link := range links {
+		if _, ok := r.items[link]; ok {
+			stored[link] = true
+		}
+	}
+	return stored, nil
+}
+
+// withOwners returns an archived recommendation with the owners of its news, none for global news
+func (r *fakeNewsRepository) withOwners(recommendation Recommendation) Recommendation {
+	owners := r.owners[recommendation.NewsItem.Link]
+	recommendation.NewsItem.Owners = nil
+	if !containsOwner(owners, "") {
+		recommendation.NewsItem.Owners = owners
+	}
+	return recommendation
+}
+
+func (r *fakeNewsRepository) find(match func(Recommendation) bool, limit int) []Recommendation {
+	var found []Recommendation
+	for i := len(r.recommendations) - 1; i >= 0 && len(found) < limit; i-- {
+		recommendation := r.withOwners(r.recommendations[i])
+		if match(recommendation) {
+			found = append(found, recommendation)
+		}
+	}
+	return found
+}
+
+func (r *fakeNewsRepository) GetRecentRecommendations(limit int) ([]Recommendation, error) {
+	return r.find(func(recommendation Recommendation) bool {
+		return recommendation.Confidence > MinRecommendationConfidence
+	}, limit), nil
+}
+
+func (r *fakeNewsRepository) GetLatestRecommendations(userID string, limit int) ([]Recommendation, error) {
+	return r.find(func(recommendation Recommendation) bool {
+		return recommendation.Confidence > MinRecommendationConfidence && recommendation.NewsItem.visibleTo(userID)
+	}, limit), nil
+}
+
+func (r *fakeNewsRepository) GetRecommendationsByStock(userID, stockSymbol string, limit int) ([]Recommendation, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.find(func(recommendation Recommendation) bool {
+		return recommendation.StockSymbol == stockSymbol && recommendation.NewsItem.visibleTo(userID)
+	}, limit), nil
+}
+
+func TestProcessNewsWritesThrough(t *testing.T) {
+	repository := newFakeNewsRepository()
+	newProcessor := func() *Processor {
+		return &Processor{
+			cache: NewRecommendationCache(CacheConfig{
+				TTL:             24 * time.Hour,
+				MaxItems:        1000,
+				CleanupInterval: 1 * time.Hour,
+			}),
+			repository:    repository,
+			stockResolver: NewMockOpenAIResolver(),
+		}
+	}
+	processor := newProcessor()
+
+	newsItems := []NewsItem{
+		{
+			Title:             "RELIANCE shows strong growth",
+			Description:       "RELIANCE reports excellent quarterly results",
+			Link:              "http://example.com/1",
+			Source:            "MoneyControl",
+			SourceReliability: TrustedSourceReliability,
+			PublishedAt:       time.Now(),
+		},
+		{
+			Title:       "General business news",
+			Description: "Nothing to see",
+			Link:        "http://example.com/2",
+			Source:      "Unknown Source",
+			PublishedAt: time.Now(),
+		},
+	}
+
+	recommendations := processor.ProcessNews(context.Background(), newsItems)
+	if len(recommendations) != 1 {
+		t.Fatalf("Expected 1 recommendation, got %d", len(recommendations))
+	}
+	// Every processed item is archived, not only those producing a recommendation
+	if len(repository.items) != 2 {
+		t.Errorf("Expected 2 archived news items, got %d", len(repository.items))
+	}
+	if repository.items["http://example.com/1"].Sentiment == 0 {
+		t.Error("Expected archived news item to carry its sentiment")
+	}
+	if len(repository.recommendations) != 1 {
+		t.Errorf("Expected 1 archived recommendation, got %d", len(repository.recommendations))
+	}
+
+	// After a restart the cache is empty, but archived items are not processed again
+	restarted := newProcessor()
+	if recommendations := restarted.ProcessNews(context.Background(), newsItems); len(recommendations) != 0 {
+		t.Errorf("Expected no recommendations for archived news items, got %d", len(recommendations))
+	}
+
+	// Recommendations are served from the archive until the cache is warm
+	if latest := restarted.GetLatestRecommendations("", 10); len(latest) != 1 {
+		t.Errorf("Expected 1 archived recommendation, got %d", len(latest))
+	}
+	if byStock := restarted.GetRecommendationsByStock("", "RELIANCE"); len(byStock) != 1 {
+		t.Errorf("Expected 1 RELIANCE recommendation, got %d", len(byStock))
+	}
+
+	loaded, err := restarted.WarmCache(10)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if loaded != 1 || len(restarted.cache.GetAll()) != 1 {
+		t.Errorf("Expected 1 recommendation loaded into the cache, got %d", loaded)
+	}
+}
+
+func TestGetRecommendationsByStockMergesArchive(t *testing.T) {
+	repository := newFakeNewsRepository()
+	processor := &Processor{
+		cache: NewRecommendationCache(CacheConfig{
+			TTL:             24 * time.Hour,
+			MaxItems:        1000,
+			CleanupInterval: 1 * time.Hour,
+		}),
+		repository: repository,
+	}
+
+	now := time.Now()
+	subject := Recommendation{
+		StockSymbol: "TCS",
+		Role:        RoleSubject,
+		Action:      ActionBuy,
+		Confidence:  0.8,
+		NewsItem:    NewsItem{Title: "TCS wins a large deal", Link: "http://example.com/tcs"},
+		CreatedAt:   now,
+	}
+	mention := Recommendation{
+		StockSymbol: "TCS",
+		Role:        RoleMentioned,
+		Action:      ActionWatch,
+		Confidence:  0.3,
+		NewsItem:    NewsItem{Title: "IT stocks were mixed", Link: "http://example.com/it"},
+		CreatedAt:   now.Add(-time.Hour),
+	}
+
+	// The subject is cached and archived, the mention only archived
+	processor.cache.Set(recommendationKey(subject.NewsItem.Link, subject.StockSymbol), subject)
+	repository.SaveRecommendations([]Recommendation{mention, subject})
+
+	byStock := processor.GetRecommendationsByStock("", "TCS")
+	if len(byStock) != 2 {
+		t.Fatalf("Expected the cached subject and the archived mention, got %d recommendations", len(byStock))
+	}
+	if byStock[0].Role != RoleSubject || byStock[1].Role != RoleMentioned {
+		t.Errorf("Expected recommendations newest first, got %s then %s", byStock[0].Role, byStock[1].Role)
+	}
+
+	// The cache is served when the archive cannot be read
+	repository.err = errors.New("database unavailable")
+	byStock = processor.GetRecommendationsByStock("", "TCS")
+	if len(byStock) != 1 || byStock[0].Role != RoleSubject {
+		t.Errorf("Expected the cached subject recommendation, got %v", byStock)
+	}
+}
+
+func TestRecommendationOwners(t *testing.T) {
+	repository := newFakeNewsRepository()
+	newProcessor := func() *Processor {
+		return &Processor{
+			cache: NewRecommendationCache(CacheConfig{
+				TTL:             24 * time.Hour,
+				MaxItems:        1000,
+				CleanupInterval: 1 * time.Hour,
+			}),
+			repository:    repository,
+			stockResolver: NewMockOpenAIResolver(),
+		}
+	}
+	processor := newProcessor()
+
+	item := NewsItem{
+		Title:             "RELIANCE shows strong growth",
+		Description:       "RELIANCE reports excellent quarterly results",
+		Link:              "http://example.com/1",
+		Source:            "Alice's Feed",
+		SourceReliability: TrustedSourceReliability,
+		PublishedAt:       time.Now(),
+		Owners:            []string{"alice"},
+	}
+	if recommendations := processor.ProcessNews(context.Background(), []NewsItem{item}); len(recommendations) != 1 {
+		t.Fatalf("Expected 1 recommendation, got %d", len(recommendations))
+	}
+
+	// News from a user's own source is only recommended to that user
+	if latest := processor.GetLatestRecommendations("alice", 10); len(latest) != 1 {
+		t.Errorf("Expected 1 recommendation for the owner, got %d", len(latest))
+	}
+	if latest := processor.GetLatestRecommendations("bob", 10); len(latest) != 0 {
+		t.Errorf("Expected no recommendations for another user, got %d", len(latest))
+	}
+	if latest := processor.GetLatestRecommendations("", 10); len(latest) != 0 {
+		t.Errorf("Expected no shared recommendations, got %d", len(latest))
+	}
+	if byStock := processor.GetRecommendationsByStock("bob", "RELIANCE"); len(byStock) != 0 {
+		t.Errorf("Expected no RELIANCE recommendations for another user, got %d", len(byStock))
+	}
+
+	// Once a global source carries the same news, everyone sees it, from the cache and after a restart
+	item.Owners = nil
+	if recommendations := processor.ProcessNews(context.Background(), []NewsItem{item}); len(recommendations) != 0 {
+		t.Errorf("Expected the news not to be processed again, got %d recommendations", len(recommendations))
+	}
+	if latest := processor.GetLatestRecommendations("bob", 10); len(latest) != 1 {
+		t.Errorf("Expected 1 cached recommendation for every user, got %d", len(latest))
+	}
+	restarted := newProcessor()
+	if byStock := restarted.GetRecommendationsByStock("", "RELIANCE"); len(byStock) != 1 {
+		t.Errorf("Expected 1 archived RELIANCE recommendation for every user, got %d", len(byStock))
+	}
+}
+
+func TestProcessNewsPerSymbol(t *testing.T) {
+	resolver, err := LoadStockDictionary(strings.NewReader(testDictionaryCSV + "WIPRO,Wipro Ltd,\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	repository := newFakeNewsRepository()
+	processor := &Processor{
+		cache: NewRecommendationCache(CacheConfig{
+			TTL:             24 * time.Hour,
+			MaxItems:        1000,
+			CleanupInterval: 1 * time.Hour,
+		}),
+		repository:    repository,
+		stockResolver: resolver,
+	}
+
+	newsItems := []NewsItem{
+		{
+			Title:             "Infosys posts record profit growth while Wipro reports weak revenue decline",
+			Description:       "Infosys beat estimates on strong deal wins and raised its revenue guidance for the year. Titan was unchanged in a quiet session for consumer stocks.",
+			Link:              "http://example.com/it",
+			Source:            "MoneyControl",
+			SourceReliability: TrustedSourceReliability,
+			PublishedAt:       time.Now(),
+		},
+	}
+
+	recommendations := processor.ProcessNews(context.Background(), newsItems)
+	bySymbol := make(map[string]Recommendation)
+	for _, recommendation := range recommendations {
+		bySymbol[recommendation.StockSymbol] = recommendation
+	}
+	if len(bySymbol) != 2 {
+		t.Fatalf("Expected recommendations for INFY and WIPRO, got %v", recommendations)
+	}
+
+	// Sentiment is attributed to each stock from the clauses mentioning it
+	if rec := bySymbol["INFY"]; rec.Action != ActionBuy || rec.Role != RoleSubject || rec.Sentiment <= 0 {
+		t.Errorf("Expected a BUY on INFY as subject with positive sentiment, got %+v", rec)
+	}
+	if rec := bySymbol["WIPRO"]; rec.Action != ActionSell || rec.Role != RolePeer || rec.Sentiment >= 0 {
+		t.Errorf("Expected a SELL on WIPRO as peer with negative sentiment, got %+v", rec)
+	}
+
+	// A stock the article only mentions is archived below the serving threshold and found by stock
+	if _, ok := bySymbol["TITAN"]; ok {
+		t.Error("Expected no served recommendation for a merely mentioned stock")
+	}
+	if byStock := processor.GetRecommendationsByStock("", "TITAN"); len(byStock) != 1 || byStock[0].Role != RoleMentioned {
+		t.Errorf("Expected 1 TITAN mention, got %v", byStock)
+	}
+	if latest := processor.GetLatestRecommendations("", 10); len(latest) != 2 {
+		t.Errorf("Expected 2 cached recommendations, got %d", len(latest))
+	}
+
+	// Items are not processed twice
+	if recommendations := processor.ProcessNews(context.Background(), newsItems); len(recommendations) != 0 {
+		t.Errorf("Expected no recommendations for processed news items, got %d", len(recommendations))
+	}
+}
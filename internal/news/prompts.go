@@ -0,0 +1,108 @@
+package news
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// PromptTemplates are the Go text/template prompts sent to the LLM
+type PromptTemplates struct {
+	// System is the system prompt of every request
+	System string `json:"system"`
+
+	// Symbol asks for the most relevant stock symbol of {{.Text}}
+	Symbol string `json:"symbol"`
+
+	// Entities asks for the stocks an article with {{.Headline}} and {{.Body}} refers to, as a JSON array
+	// of objects with the keys symbol, role and confidence
+	Entities string `json:"entities"`
+
+	// Analysis asks for the sentiment of an article with {{.Headline}} and {{.Body}} towards it and the comma-separated
+	// {{.Symbols}}, as a JSON object of the form Analysis
+	Analysis string `json:"analysis"`
+}
+
+// DefaultPromptTemplates returns the built-in prompt templates
+func DefaultPromptTemplates() PromptTemplates {
+	return PromptTemplates{
+		System: "You are a financial assistant that analyses Indian stock market news. Only respond with what is asked, nothing else.",
+		Symbol: `Extract the most relevant NSE stock symbol from the following text. If it's about the general market, return 'NIFTY'. If no specific stock is mentioned, return an empty string. Only return the symbol, nothing else.
+
+Text: {{.Text}}`,
+		Entities: `List the Indian listed companies the following news article refers to, by NSE symbol. If it's about the general market, list 'NIFTY'. For each give its role: "subject" if the article is about it, "peer" if it is named as affected alongside the subjects, or "mentioned" otherwise, and your confidence from 0 to 1 that the symbol is right. Respond with a JSON array of objects with the keys "symbol", "role" and "confidence", most relevant first, and nothing else. Respond with [] if no company is referred to.
+
+Headline: {{.Headline}}
+Body: {{.Body}}`,
+		Analysis: `Analyse the following news article for investors in Indian stocks. Respond with only a JSON object of this form:
+{"sentiment": <sentiment of the article from -1 (very negative) to 1 (very positive)>, "stocks": [{"symbol": "<NSE symbol>", "sentiment": <sentiment towards the stock from -1 to 1>, "event_type": "<one of earnings, guidance, rating, deal, corporate_action, management, regulatory, macro, other>", "horizon": "<one of intraday, short_term, medium_term, long_term>", "rationale": "<one sentence on why>"}]}
+{{if .Symbols}}List exactly these stocks, once each: {{.Symbols}}.{{else}}Leave "stocks" empty.{{end}} Judge each stock by what the article says about it: a profit decline is negative, no growth is not positive, and a stock can fall on news that lifts another.
+
+Headline: {{.Headline}}
+Body: {{.Body}}`,
+	}
+}
+
+// LoadPromptTemplatesFile reads prompt templates from a JSON file with the keys of PromptTemplates.
+// Templates missing from the file keep their defaults.
+func LoadPromptTemplatesFile(path string) (PromptTemplates, error) {
+	prompts := DefaultPromptTemplates()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return prompts, err
+	}
+	var overrides PromptTemplates
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return prompts, fmt.Errorf("failed to parse prompt templates: %w", err)
+	}
+
+	prompts = prompts.merge(overrides)
+	if err := prompts.Validate(); err != nil {
+		return DefaultPromptTemplates(), err
+	}
+	return prompts, nil
+}
+
+// Validate checks that every template parses
+func (p PromptTemplates) Validate() error {
+	templates := map[string]string{"system": p.System, "symbol": p.Symbol, "entities": p.Entities, "analysis": p.Analysis}
+	for name, text := range templates {
+		if _, err := template.New(name).Parse(text); err != nil {
+			return fmt.Errorf("invalid %s prompt template: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// merge returns the templates with the non-empty templates of overrides in place of their own
+func (p PromptTemplates) merge(overrides PromptTemplates) PromptTemplates {
+	if strings.TrimSpace(overrides.System) != "" {
+		p.System = overrides.System
+	}
+	if strings.TrimSpace(overrides.Symbol) != "" {
+		p.Symbol = overrides.Symbol
+	}
+	if strings.TrimSpace(overrides.Entities) != "" {
+		p.Entities = overrides.Entities
+	}
+	if strings.TrimSpace(overrides.Analysis) != "" {
+		p.Analysis = overrides.Analysis
+	}
+	return p
+}
+
+// renderPrompt executes a prompt template with data
+func renderPrompt(name, text string, data interface{}) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s prompt template: %w", name, err)
+	}
+	var prompt strings.Builder
+	if err := tmpl.Execute(&prompt, data); err != nil {
+		return "", fmt.Errorf("failed to render %s prompt: %w", name, err)
+	}
+	return prompt.String(), nil
+}
@@ -0,0 +1,47 @@
+package news
+
+// NewsRepository defines the interface for persisting processed news items and their recommendations
+type NewsRepository interface {
+	// SaveNewsItems archives news items, ignoring links already stored, and records their owners,
+	// adding to those of links already stored; items without owners are global
+	SaveNewsItems(items []NewsItem) error
+
+	// SaveRecommendations stores recommendations, replacing any already stored for the same link and stock
+	SaveRecommendations(recommendations []Recommendation) error
+
+	// GetStoredLinks returns which of the given links have already been archived
+	GetStoredLinks(links []string) (map[string]bool, error)
+
+	// GetRecentRecommendations retrieves the most recent recommendations with more than MinRecommendationConfidence
+	// of every owner, with the owners of their news, newest first
+	GetRecentRecommendations(limit int) ([]Recommendation, error)
+
+	// GetLatestRecommendations retrieves the most recent recommendations with more than MinRecommendationConfidence
+	// that a user sees, newest first; an empty userID retrieves those from global news only
+	GetLatestRecommendations(userID string, limit int) ([]Recommendation, error)
+
+	// GetRecommendationsByStock retrieves all recommendations for a stock that a user sees, whatever its role in the
+	// news, newest first; an empty userID retrieves those from global news only
+	GetRecommendationsByStock(userID, stockSymbol string, limit int) ([]Recommendation, error)
+}
+
+// SourceRepository defines the interface for persisting global and user-owned news sources
+type SourceRepository interface {
+	// GetSources retrieves the global sources and, when userID is set, the sources the user owns
+	GetSources(userID string) ([]Source, error)
+
+	// GetEnabledSources retrieves the enabled sources of every owner, global sources first
+	GetEnabledSources() ([]Source, error)
+
+	// CountGlobalSources returns the number of global sources
+	CountGlobalSources() (int, error)
+
+	// SaveSource creates a source and sets its ID, returning ErrSourceExists when its owner already has a source with its name
+	SaveSource(source *Source) error
+
+	// UpdateSource updates a source of its owner by ID, reporting whether it exists
+	UpdateSource(source *Source) (bool, error)
+
+	// DeleteSource deletes a source of an owner by ID, reporting whether it existed
+	DeleteSource(ownerID string, id int64) (bool, error)
+}
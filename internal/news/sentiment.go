@@ -0,0 +1,167 @@
+package news
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Sentiment analyzer names, as set in SentimentAnalyzerConfig
+const (
+	AnalyzerKeyword = "keyword"
+	AnalyzerLexicon = "lexicon"
+	AnalyzerLLM     = "llm"
+)
+
+// SentimentAnalyzer defines the interface for scoring the sentiment of news
+type SentimentAnalyzer interface {
+	// Analyze scores a news item and the sentiment towards each stock it refers to
+	Analyze(ctx context.Context, item NewsItem, entities []StockEntity) (Analysis, error)
+}
+
+// Analysis is the sentiment of a news item and towards the stocks it refers to
+type Analysis struct {
+	// Sentiment is the sentiment of the whole item, from -1 to 1
+	Sentiment float64         `json:"sentiment"`
+	Stocks    []StockAnalysis `json:"stocks"`
+}
+
+// StockAnalysis is the sentiment of a news item towards one stock
+type StockAnalysis struct {
+	Symbol    string  `json:"symbol"`
+	Sentiment float64 `json:"sentiment"` // from -1 to 1
+
+	// EventType, Horizon and Rationale are set by analyzers that can tell
+	EventType string `json:"event_type,omitempty"`
+	Horizon   string `json:"horizon,omitempty"`
+	Rationale string `json:"rationale,omitempty"`
+}
+
+// Stock returns the analysis of a stock, defaulting to the sentiment of the whole item
+func (a Analysis) Stock(symbol string) StockAnalysis {
+	for _, stock := range a.Stocks {
+		if stock.Symbol == symbol {
+			return stock
+		}
+	}
+	return StockAnalysis{Symbol: symbol, Sentiment: a.Sentiment}
+}
+
+// SentimentAnalyzerConfig holds configuration for building a sentiment analyzer
+type SentimentAnalyzerConfig struct {
+	// Analyzer is keyword, lexicon or llm (default: keyword)
+	Analyzer string
+
+	// LexiconDir holds the word lists of the lexicon analyzer
+	LexiconDir string
+
+	// LLM answers the llm analyzer with Prompts
+	LLM     LLMProvider
+	Prompts PromptTemplates
+}
+
+// NewSentimentAnalyzer builds the analyzer a configuration names
+func NewSentimentAnalyzer(config SentimentAnalyzerConfig) (SentimentAnalyzer, error) {
+	switch strings.ToLower(strings.TrimSpace(config.Analyzer)) {
+	case "", AnalyzerKeyword:
+		return NewKeywordSentimentAnalyzer(), nil
+	case AnalyzerLexicon:
+		lexicon, err := LoadLexiconDir(config.LexiconDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load sentiment lexicon: %w", err)
+		}
+		return NewLexiconSentimentAnalyzer(LexiconSentimentAnalyzerConfig{Lexicon: lexicon}), nil
+	case AnalyzerLLM:
+		if config.LLM == nil {
+			return nil, fmt.Errorf("sentiment analyzer %q needs an LLM provider", config.Analyzer)
+		}
+		return NewLLMSentimentAnalyzer(LLMSentimentAnalyzerConfig{
+			Provider: config.LLM,
+			Prompts:  config.Prompts,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown sentiment analyzer %q", config.Analyzer)
+	}
+}
+
+// KeywordSentimentAnalyzer implements SentimentAnalyzer by counting PositiveKeywords and NegativeKeywords
+type KeywordSentimentAnalyzer struct{}
+
+// NewKeywordSentimentAnalyzer creates a new keyword sentiment analyzer
+func NewKeywordSentimentAnalyzer() *KeywordSentimentAnalyzer {
+	return &KeywordSentimentAnalyzer{}
+}
+
+// Analyze implements the SentimentAnalyzer interface. An item's preset sentiment is kept, and the sentiment towards
+// a stock is scored from the text mentioning it; subjects fall back to the sentiment of the whole item when that text
+// carries none, since the item is about them.
+func (a *KeywordSentimentAnalyzer) Analyze(ctx context.Context, item NewsItem, entities []StockEntity) (Analysis, error) {
+	analysis := Analysis{Sentiment: item.Sentiment}
+	if analysis.Sentiment == 0 {
+		analysis.Sentiment = a.Score(item)
+	}
+
+	for _, entity := range entities {
+		sentiment := analysis.Sentiment
+		if entity.Context != "" {
+			sentiment = a.Score(NewsItem{Title: entity.Context, Source: item.Source})
+			if sentiment == NeutralSentimentScore && entity.Role == RoleSubject {
+				sentiment = analysis.Sentiment
+			}
+		}
+		analysis.Stocks = append(analysis.Stocks, StockAnalysis{Symbol: entity.Symbol, Sentiment: sentiment})
+	}
+	return analysis, nil
+}
+
+// Score performs basic sentiment analysis on a news item
+func (a *KeywordSentimentAnalyzer) Score(item NewsItem) float64 {
+	// Convert text to lowercase for case-insensitive matching
+	title := strings.ToLower(item.Title)
+	description := strings.ToLower(item.Description)
+
+	// Count positive and negative matches
+	positiveCount := 0
+	negativeCount := 0
+
+	// Check title and description for keywords
+	text := title + " " + description
+	for _, keyword := range PositiveKeywords {
+		if strings.Contains(text, keyword) {
+			positiveCount++
+		}
+	}
+	for _, keyword := range NegativeKeywords {
+		if strings.Contains(text, keyword) {
+			negativeCount++
+		}
+	}
+
+	// Calculate sentiment score (-1 to 1)
+	totalCount := positiveCount + negativeCount
+	if totalCount == 0 {
+		return NeutralSentimentScore // Neutral if no keywords found
+	}
+
+	// Normalize to -1 to 1 range
+	sentiment := float64(positiveCount-negativeCount) / float64(totalCount)
+
+	// Adjust based on source reliability
+	switch item.Source {
+	case "MoneyControl":
+		sentiment *= MoneyControlMultiplier
+	case "Economic Times":
+		sentiment *= EconomicTimesMultiplier
+	case "Business Standard", "Business Standard Markets", "Business Standard Stock Market":
+		sentiment *= BusinessStandardMultiplier
+	}
+
+	// Ensure sentiment stays within bounds
+	if sentiment > MaxSentimentScore {
+		sentiment = MaxSentimentScore
+	} else if sentiment < MinSentimentScore {
+		sentiment = MinSentimentScore
+	}
+
+	return sentiment
+}
@@ -0,0 +1,208 @@
+package news
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Source reliability weights
+const (
+	// DefaultSourceReliability weighs sources not listed as trusted
+	DefaultSourceReliability = 0.5
+
+	// TrustedSourceReliability weighs sources listed as trusted
+	TrustedSourceReliability = 0.9
+)
+
+// ErrInvalidSource is returned when a source fails validation
+var ErrInvalidSource = errors.New("invalid source")
+
+// SourceServiceConfig holds configuration for the news source service
+type SourceServiceConfig struct {
+	Repository SourceRepository
+
+	// Fetcher reports the health of the sources' feeds (optional)
+	Fetcher *NewsFetcher
+}
+
+// SourceService manages global and user-owned news sources
+type SourceService struct {
+	repository SourceRepository
+	fetcher    *NewsFetcher
+}
+
+// NewSourceService creates a new news source service
+func NewSourceService(config SourceServiceConfig) *SourceService {
+	return &SourceService{
+		repository: config.Repository,
+		fetcher:    config.Fetcher,
+	}
+}
+
+// Seed stores the default sources as global sources when there are none yet, returning the number stored.
+// Trusted entries are source names, whose sources get the trusted reliability, or "Name=URL" pairs, which are
+// stored as trusted sources of their own.
+func (s *SourceService) Seed(defaults []Source, trusted []string) (int, error) {
+	count, err := s.repository.CountGlobalSources()
+	if err != nil || count > 0 {
+		return 0, err
+	}
+
+	var trustedNames []string
+	sources := append([]Source(nil), defaults...)
+	for _, entry := range trusted {
+		name, feedURL, isFeed := strings.Cut(strings.TrimSpace(entry), "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		trustedNames = append(trustedNames, name)
+		if isFeed {
+			sources = append(sources, Source{Name: name, URL: strings.TrimSpace(feedURL), Category: "Business"})
+		}
+	}
+
+	seeded := 0
+	for _, source := range sources {
+		source.ID = 0
+		source.OwnerID = ""
+		source.Enabled = true
+		source.Reliability = DefaultSourceReliability
+		if isTrustedSource(source.Name, trustedNames) {
+			source.Reliability = TrustedSourceReliability
+		}
+		if err := validateSource(&source); err != nil {
+			return seeded, err
+		}
+		err := s.repository.SaveSource(&source)
+		if errors.Is(err, ErrSourceExists) {
+			continue
+		}
+		if err != nil {
+			return seeded, err
+		}
+		seeded++
+	}
+	return seeded, nil
+}
+
+// ListSources returns the global sources and, when userID is set, the user's own sources, with the health of their feeds
+func (s *SourceService) ListSources(userID string) ([]SourceStatus, error) {
+	sources, err := s.repository.GetSources(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]SourceStatus, 0, len(sources))
+	for _, source := range sources {
+		status := SourceStatus{Source: source}
+		if s.fetcher != nil {
+			status.Health = s.fetcher.Health(source.URL)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// AddSource creates a source owned by a user, or a global source when ownerID is empty
+func (s *SourceService) AddSource(ownerID string, source Source) (*Source, error) {
+	source.ID = 0
+	source.OwnerID = ownerID
+	if err := validateSource(&source); err != nil {
+		return nil, err
+	}
+	if err := s.repository.SaveSource(&source); err != nil {
+		return nil, err
+	}
+	return &source, nil
+}
+
+// UpdateSource replaces a source of an owner by ID
+func (s *SourceService) UpdateSource(ownerID string, id int64, source Source) (*Source, error) {
+	source.ID = id
+	source.OwnerID = ownerID
+	if err := validateSource(&source); err != nil {
+		return nil, err
+	}
+
+	found, err := s.repository.UpdateSource(&source)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrSourceNotFound
+	}
+	return &source, nil
+}
+
+// RemoveSource deletes a source of an owner by ID
+func (s *SourceService) RemoveSource(ownerID string, id int64) error {
+	found, err := s.repository.DeleteSource(ownerID, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrSourceNotFound
+	}
+	return nil
+}
+
+// validateSource checks a source, defaulting its reliability
+func validateSource(source *Source) error {
+	source.Name = strings.TrimSpace(source.Name)
+	if source.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidSource)
+	}
+	parsed, err := url.Parse(source.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: %q is not an http(s) URL", ErrInvalidSource, source.URL)
+	}
+	if !isPublicHost(parsed.Hostname()) {
+		return fmt.Errorf("%w: %q does not point to a public address", ErrInvalidSource, source.URL)
+	}
+	if source.Reliability < 0 || source.Reliability > 1 {
+		return fmt.Errorf("%w: reliability must be between 0 and 1", ErrInvalidSource)
+	}
+	if source.Reliability == 0 {
+		source.Reliability = DefaultSourceReliability
+	}
+	if source.RefreshMinutes < 0 {
+		return fmt.Errorf("%w: refresh interval must not be negative", ErrInvalidSource)
+	}
+	return nil
+}
+
+// isPublicHost reports whether a URL host may be a public address: host names other than localhost are accepted
+// here, their resolved addresses being checked when the feed is fetched
+func isPublicHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return isPublicIP(ip)
+	}
+	return true
+}
+
+// isPublicIP reports whether an address is reachable on the internet, rather than a loopback, private, link-local,
+// multicast or unspecified address
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// isTrustedSource reports whether a source name starts with one of the trusted names, ignoring case,
+// so that "Business Standard" covers "Business Standard Markets"
+func isTrustedSource(name string, trustedNames []string) bool {
+	name = strings.ToLower(name)
+	for _, trusted := range trustedNames {
+		if strings.HasPrefix(name, strings.ToLower(trusted)) {
+			return true
+		}
+	}
+	return false
+}
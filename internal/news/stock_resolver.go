@@ -2,75 +2,236 @@ package news
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
-	openai "github.com/sashabaranov/go-openai"
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// MarketSymbol is the symbol resolved for news about the market as a whole
+const MarketSymbol = "NIFTY"
+
+// Stock resolver names, as listed in StockResolverConfig
+const (
+	ResolverDictionary = "dictionary"
+	ResolverLLM        = "llm"
+
+	// ResolverOpenAI is the former name of ResolverLLM
+	ResolverOpenAI = "openai"
 )
 
 // StockResolver defines the interface for resolving stock symbols from text
 type StockResolver interface {
 	// ResolveSymbol extracts the most relevant stock symbol from the given text
 	ResolveSymbol(ctx context.Context, text string) (string, error)
+
+	// ResolveEntities extracts the stocks an article's headline and body refer to, subjects first
+	ResolveEntities(ctx context.Context, headline, body string) ([]StockEntity, error)
+}
+
+// StockResolverConfig holds configuration for building a stock resolver
+type StockResolverConfig struct {
+	// Resolvers are tried in order until one finds a symbol (default: dictionary)
+	Resolvers []string
+
+	// DictionaryPath is the stock dictionary CSV of the dictionary resolver
+	DictionaryPath string
+
+	// LLM answers the llm resolver with Prompts
+	LLM     LLMProvider
+	Prompts PromptTemplates
+
+	// Symbols validates the symbols the LLM suggests (default: the dictionary, when it is one of the resolvers)
+	Symbols SymbolValidator
+}
+
+// NewStockResolver builds the resolver, or chain of resolvers, a configuration names
+func NewStockResolver(config StockResolverConfig) (StockResolver, error) {
+	if len(config.Resolvers) == 0 {
+		config.Resolvers = []string{ResolverDictionary}
+	}
+
+	var dictionary *DictionaryStockResolver
+	for _, name := range config.Resolvers {
+		if strings.ToLower(strings.TrimSpace(name)) == ResolverDictionary && dictionary == nil {
+			var err error
+			dictionary, err = LoadStockDictionaryFile(config.DictionaryPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load stock dictionary: %w", err)
+			}
+		}
+	}
+	if config.Symbols == nil && dictionary != nil {
+		config.Symbols = dictionary
+	}
+
+	var resolvers []StockResolver
+	for _, name := range config.Resolvers {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case ResolverDictionary:
+			resolvers = append(resolvers, dictionary)
+		case ResolverLLM, ResolverOpenAI:
+			if config.LLM == nil {
+				return nil, fmt.Errorf("stock resolver %q needs an LLM provider", name)
+			}
+			resolvers = append(resolvers, NewLLMStockResolver(LLMStockResolverConfig{
+				Provider: config.LLM,
+				Prompts:  config.Prompts,
+				Symbols:  config.Symbols,
+			}))
+		default:
+			return nil, fmt.Errorf("unknown stock resolver %q", name)
+		}
+	}
+
+	if len(resolvers) == 1 {
+		return resolvers[0], nil
+	}
+	return NewChainStockResolver(resolvers...), nil
+}
+
+// ChainStockResolver implements StockResolver by trying resolvers in order until one finds a symbol
+type ChainStockResolver struct {
+	resolvers []StockResolver
+}
+
+// NewChainStockResolver creates a stock resolver falling back from each resolver to the next
+func NewChainStockResolver(resolvers ...StockResolver) *ChainStockResolver {
+	return &ChainStockResolver{
+		resolvers: resolvers,
+	}
+}
+
+// ResolveSymbol implements the StockResolver interface.
+// A failing resolver falls back to the next; errors are returned only when no resolver finds a symbol.
+func (r *ChainStockResolver) ResolveSymbol(ctx context.Context, text string) (string, error) {
+	var errs []error
+	for _, resolver := range r.resolvers {
+		symbol, err := resolver.ResolveSymbol(ctx, text)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if symbol != "" {
+			return symbol, nil
+		}
+	}
+	return "", errors.Join(errs...)
+}
+
+// ResolveEntities implements the StockResolver interface, returning the entities of the first resolver finding any.
+// A failing resolver falls back to the next; errors are returned only when no resolver finds an entity.
+func (r *ChainStockResolver) ResolveEntities(ctx context.Context, headline, body string) ([]StockEntity, error) {
+	var errs []error
+	for _, resolver := range r.resolvers {
+		entities, err := resolver.ResolveEntities(ctx, headline, body)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(entities) > 0 {
+			return entities, nil
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// SymbolValidator checks the stock symbols a model suggests against the stocks known to exist
+type SymbolValidator interface {
+	// ValidSymbol returns the NSE symbol a suggested symbol stands for, and whether it names a known stock
+	ValidSymbol(symbol string) (string, bool)
+}
+
+// InstrumentLookup defines the interface for resolving identifiers to instruments; *instruments.Store implements it
+type InstrumentLookup interface {
+	Lookup(identifier string) (models.Instrument, bool)
+}
+
+// InstrumentSymbolValidator implements SymbolValidator with the instrument master
+type InstrumentSymbolValidator struct {
+	instruments InstrumentLookup
+}
+
+// NewInstrumentSymbolValidator creates a symbol validator accepting the NSE-listed instruments of the master
+func NewInstrumentSymbolValidator(instruments InstrumentLookup) *InstrumentSymbolValidator {
+	return &InstrumentSymbolValidator{
+		instruments: instruments,
+	}
+}
+
+// ValidSymbol implements the SymbolValidator interface
+func (v *InstrumentSymbolValidator) ValidSymbol(symbol string) (string, bool) {
+	instrument, ok := v.instruments.Lookup(symbol)
+	if !ok || instrument.NSESymbol == "" {
+		return "", false
+	}
+	return instrument.NSESymbol, true
+}
+
+// LLMStockResolverConfig holds configuration for the LLM stock resolver
+type LLMStockResolverConfig struct {
+	Provider LLMProvider
+
+	// Prompts are the prompt templates; empty templates keep their defaults
+	Prompts PromptTemplates
+
+	// Symbols rejects symbols that do not name a known stock (optional; without it any well-formed symbol is accepted)
+	Symbols SymbolValidator
 }
 
-// OpenAIStockResolver implements StockResolver using OpenAI's GPT-3.5 Turbo
-type OpenAIStockResolver struct {
-	client *openai.Client
-	cache  *RecommendationCache
-	apiKey string
+// LLMStockResolver implements StockResolver by asking a large language model
+type LLMStockResolver struct {
+	provider LLMProvider
+	prompts  PromptTemplates
+	symbols  SymbolValidator
+	cache    *RecommendationCache
 }
 
-// NewOpenAIStockResolver creates a new OpenAI stock resolver
-func NewOpenAIStockResolver(apiKey string) *OpenAIStockResolver {
-	return &OpenAIStockResolver{
-		client: openai.NewClient(apiKey),
+// NewLLMStockResolver creates a new LLM stock resolver
+func NewLLMStockResolver(config LLMStockResolverConfig) *LLMStockResolver {
+	return &LLMStockResolver{
+		provider: config.Provider,
+		prompts:  DefaultPromptTemplates().merge(config.Prompts),
+		symbols:  config.Symbols,
 		cache: NewRecommendationCache(CacheConfig{
 			TTL:             24 * time.Hour,
 			MaxItems:        1000,
 			CleanupInterval: 1 * time.Hour,
 		}),
-		apiKey: apiKey,
 	}
 }
 
-// ResolveSymbol implements the StockResolver interface
-func (r *OpenAIStockResolver) ResolveSymbol(ctx context.Context, text string) (string, error) {
+// NewOpenAIStockResolver creates a new LLM stock resolver asking OpenAI's default model with the default prompts
+func NewOpenAIStockResolver(apiKey string) *LLMStockResolver {
+	return NewLLMStockResolver(LLMStockResolverConfig{
+		Provider: NewOpenAIProvider(LLMConfig{APIKey: apiKey}),
+	})
+}
+
+// ResolveSymbol implements the StockResolver interface, returning an empty string when the model's answer is not a known symbol
+func (r *LLMStockResolver) ResolveSymbol(ctx context.Context, text string) (string, error) {
 	// First check cache
 	if symbol, found := r.cache.Get(text); found {
 		return symbol.StockSymbol, nil
 	}
 
-	// Prepare the prompt
-	prompt := fmt.Sprintf(`Extract the most relevant stock symbol from the following text. If it's about the general market, return 'NIFTY'. If no specific stock is mentioned, return an empty string. Only return the symbol, nothing else.
-
-Text: %s`, text)
-
-	// Make the request to OpenAI
-	resp, err := r.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: openai.GPT3Dot5Turbo,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: "You are a financial assistant that extracts stock symbols from text. Only respond with the stock symbol, nothing else.",
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-			Temperature: 0.1, // Low temperature for more deterministic responses
-		},
-	)
+	prompt, err := renderPrompt("symbol", r.prompts.Symbol, struct{ Text string }{text})
+	if err != nil {
+		return "", err
+	}
+	reply, err := r.complete(ctx, prompt)
 	if err != nil {
-		return "", fmt.Errorf("failed to get completion from OpenAI: %w", err)
+		return "", err
 	}
 
-	// Get the response
-	symbol := strings.TrimSpace(resp.Choices[0].Message.Content)
+	symbol, ok := r.validSymbol(reply)
+	if !ok {
+		return "", nil
+	}
 
 	// Cache the result if we got a valid symbol
 	if symbol != "" {
@@ -83,6 +244,103 @@ Text: %s`, text)
 	return symbol, nil
 }
 
+// ResolveEntities implements the StockResolver interface, dropping entities that are not known symbols
+func (r *LLMStockResolver) ResolveEntities(ctx context.Context, headline, body string) ([]StockEntity, error) {
+	prompt, err := renderPrompt("entities", r.prompts.Entities, struct{ Headline, Body string }{headline, body})
+	if err != nil {
+		return nil, err
+	}
+	reply, err := r.complete(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	entities, err := parseEntities(reply)
+	if err != nil {
+		return nil, err
+	}
+
+	valid := entities[:0]
+	seen := make(map[string]bool)
+	for _, entity := range entities {
+		symbol, ok := r.validSymbol(entity.Symbol)
+		if !ok || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		entity.Symbol = symbol
+		valid = append(valid, entity)
+	}
+	return valid, nil
+}
+
+// complete sends a prompt to the model with the system prompt
+func (r *LLMStockResolver) complete(ctx context.Context, prompt string) (string, error) {
+	system, err := renderPrompt("system", r.prompts.System, nil)
+	if err != nil {
+		return "", err
+	}
+	return r.provider.Complete(ctx, LLMRequest{System: system, Prompt: prompt})
+}
+
+// validSymbol cleans up a symbol suggested by the model and checks it names a known stock
+func (r *LLMStockResolver) validSymbol(suggested string) (string, bool) {
+	symbol := strings.ToUpper(strings.Trim(strings.TrimSpace(suggested), "`'\". "))
+	symbol = strings.TrimPrefix(symbol, "NSE:")
+	if symbol == "" || !isSymbolLike(symbol) {
+		return "", false
+	}
+	if symbol == MarketSymbol || r.symbols == nil {
+		return symbol, true
+	}
+	return r.symbols.ValidSymbol(symbol)
+}
+
+// isSymbolLike reports whether a text could be an NSE symbol: capitals, digits, "&" and "-", at most 20 long
+func isSymbolLike(symbol string) bool {
+	if len(symbol) > 20 {
+		return false
+	}
+	for _, c := range symbol {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '&' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseEntities reads the JSON array of entities of a completion, which may be wrapped in a code fence
+func parseEntities(content string) ([]StockEntity, error) {
+	var entities []StockEntity
+	if err := json.Unmarshal([]byte(trimCodeFence(content)), &entities); err != nil {
+		return nil, fmt.Errorf("failed to parse entities from completion: %w", err)
+	}
+
+	valid := entities[:0]
+	for _, entity := range entities {
+		entity.Symbol = strings.ToUpper(strings.TrimSpace(entity.Symbol))
+		if entity.Symbol == "" {
+			continue
+		}
+		switch entity.Role = strings.ToLower(entity.Role); entity.Role {
+		case RoleSubject, RolePeer, RoleMentioned:
+		default:
+			entity.Role = RoleMentioned
+		}
+		entity.Confidence = math.Max(0, math.Min(entity.Confidence, 1))
+		valid = append(valid, entity)
+	}
+	sortEntities(valid)
+	return valid, nil
+}
+
+// trimCodeFence strips the markdown code fence models tend to wrap JSON in
+func trimCodeFence(content string) string {
+	content = strings.TrimSpace(content)
+	content = strings.TrimPrefix(content, "```json")
+	return strings.Trim(content, "`\n ")
+}
+
 // MockStockResolver implements StockResolver for testing
 type MockStockResolver struct {
 	Symbols map[string]string
@@ -115,3 +373,12 @@ func (r *MockStockResolver) ResolveSymbol(ctx context.Context, text string) (str
 
 	return "", nil
 }
+
+// ResolveEntities implements the StockResolver interface for testing, returning the resolved symbol as the subject
+func (r *MockStockResolver) ResolveEntities(ctx context.Context, headline, body string) ([]StockEntity, error) {
+	symbol, err := r.ResolveSymbol(ctx, headline+" "+body)
+	if err != nil || symbol == "" {
+		return nil, err
+	}
+	return []StockEntity{{Symbol: symbol, Role: RoleSubject, Confidence: 1}}, nil
+}
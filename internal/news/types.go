@@ -4,10 +4,27 @@ import "time"
 
 // Source represents a news source
 type Source struct {
+	ID          int64  `json:"id,omitempty"`
+	OwnerID     string `json:"ownerId,omitempty"` // user owning the source, empty for global sources
 	Name        string `json:"name"`
 	URL         string `json:"url"`
 	Description string `json:"description"`
 	Category    string `json:"category"`
+	Enabled     bool   `json:"enabled"`
+
+	// Reliability weighs the source from 0 to 1
+	Reliability float64 `json:"reliability"`
+
+	// RefreshMinutes is how often the feed is fetched (default: DefaultRefreshInterval)
+	RefreshMinutes int `json:"refreshMinutes,omitempty"`
+}
+
+// refreshInterval returns how often the source is fetched
+func (s Source) refreshInterval() time.Duration {
+	if s.RefreshMinutes <= 0 {
+		return DefaultRefreshInterval
+	}
+	return time.Duration(s.RefreshMinutes) * time.Minute
 }
 
 // NewsItem represents a news article
@@ -19,13 +36,68 @@ type NewsItem struct {
 	Category    string    `json:"category"`
 	PublishedAt time.Time `json:"published_at"`
 	Sentiment   float64   `json:"sentiment"`
+
+	// SourceReliability is the reliability weight of the source the item was fetched from (0 when unknown)
+	SourceReliability float64 `json:"-"`
+
+	// Owners are the users whose own sources carried the item; it is empty for news from global sources,
+	// which every user sees
+	Owners []string `json:"-"`
+}
+
+// visibleTo reports whether a user sees the item: everyone sees global news and only its owners see the rest.
+// An empty userID stands for the shared recommendations, which only hold global news.
+func (item NewsItem) visibleTo(userID string) bool {
+	if len(item.Owners) == 0 {
+		return true
+	}
+	return userID != "" && containsOwner(item.Owners, userID)
+}
+
+// mergeOwners returns the owners of an item carried by sources of both sets of owners;
+// an item from any global source is global
+func mergeOwners(a, b []string) []string {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	merged := append([]string(nil), a...)
+	for _, owner := range b {
+		if !containsOwner(merged, owner) {
+			merged = append(merged, owner)
+		}
+	}
+	return merged
+}
+
+// containsOwner reports whether owners lists a user
+func containsOwner(owners []string, userID string) bool {
+	for _, owner := range owners {
+		if owner == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// StockEntity is a stock a news article refers to
+type StockEntity struct {
+	Symbol     string  `json:"symbol"`
+	Role       string  `json:"role"`       // subject, peer or mentioned
+	Confidence float64 `json:"confidence"` // how sure the resolver is of the stock, from 0 to 1
+
+	// Context is the text mentioning the stock, when the resolver can tell, to attribute sentiment to it
+	Context string `json:"context,omitempty"`
 }
 
 // Recommendation represents an investment recommendation based on news
 type Recommendation struct {
 	StockSymbol string    `json:"stock_symbol"`
-	Action      string    `json:"action"` // BUY, SELL, HOLD, WATCH
+	Role        string    `json:"role,omitempty"` // role of the stock in the news: subject, peer or mentioned
+	Action      string    `json:"action"`         // BUY, SELL, HOLD, WATCH
 	Confidence  float64   `json:"confidence"`
+	Sentiment   float64   `json:"sentiment"`            // sentiment attributed to the stock
+	EventType   string    `json:"event_type,omitempty"` // kind of event the news reports, when the analyzer can tell
+	Horizon     string    `json:"horizon,omitempty"`    // how soon the news is expected to move the stock, when the analyzer can tell
 	Reason      string    `json:"reason"`
 	NewsItem    NewsItem  `json:"news_item"`
 	CreatedAt   time.Time `json:"created_at"`
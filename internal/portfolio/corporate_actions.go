@@ -0,0 +1,44 @@
+package portfolio
+
+import (
+	"io"
+	"sort"
+
+	"github.com/Kora1128/FinSight/internal/importer/corporateaction"
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// ImportCorporateActions parses a corporate actions CSV and stores its splits and bonus issues, so that cost basis,
+// returns and capital gains adjust lots bought before them. Actions listed by symbol are resolved to ISINs through
+// the instrument master; those it does not know are skipped and reported.
+func (s *UserService) ImportCorporateActions(r io.Reader) (*models.CorporateActionImportResult, error) {
+	actions, err := corporateaction.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CorporateActionImportResult{Total: len(actions), Unresolved: []string{}}
+	unresolved := make(map[string]bool)
+	for _, action := range actions {
+		if action.ISIN == "" && s.instruments != nil {
+			if instrument, ok := s.instruments.Lookup(action.Symbol); ok {
+				action.ISIN = instrument.ISIN
+			}
+		}
+		if action.ISIN == "" {
+			unresolved[action.Symbol] = true
+			continue
+		}
+
+		if err := s.corporateActionRepository.SaveCorporateAction(action.CorporateAction); err != nil {
+			return nil, err
+		}
+		result.Imported++
+	}
+
+	for symbol := range unresolved {
+		result.Unresolved = append(result.Unresolved, symbol)
+	}
+	sort.Strings(result.Unresolved)
+	return result, nil
+}
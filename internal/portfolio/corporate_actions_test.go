@@ -0,0 +1,73 @@
+package portfolio
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCorporateActions keeps corporate actions in memory
+type fakeCorporateActions struct {
+	actions []models.CorporateAction
+}
+
+func (r *fakeCorporateActions) SaveCorporateAction(action models.CorporateAction) error {
+	r.actions = append(r.actions, action)
+	return nil
+}
+
+func (r *fakeCorporateActions) GetCorporateActions(isins []string, asOf time.Time) ([]models.CorporateAction, error) {
+	return r.actions, nil
+}
+
+func TestImportCorporateActions(t *testing.T) {
+	repo := &fakeCorporateActions{}
+	service := NewUserService(UserServiceConfig{
+		CorporateActionRepository: repo,
+		Instruments:               fakeResolver{"RELIANCE": "INE002A01018"},
+	})
+
+	file := "SYMBOL,SERIES,PURPOSE,EX-DATE\n" +
+		"RELIANCE,EQ,Bonus 1:1,28-Oct-2024\n" +
+		"NEWCO,EQ,Bonus 1:2,28-Oct-2024\n"
+	result, err := service.ImportCorporateActions(strings.NewReader(file))
+	assert.NoError(t, err)
+	assert.Equal(t, &models.CorporateActionImportResult{Total: 2, Imported: 1, Unresolved: []string{"NEWCO"}}, result)
+	if assert.Len(t, repo.actions, 1) {
+		assert.Equal(t, "INE002A01018", repo.actions[0].ISIN)
+		assert.Equal(t, models.CorporateActionBonus, repo.actions[0].Type)
+	}
+}
+
+// fakeDividends keeps dividends in memory
+type fakeDividends struct {
+	dividends []models.Dividend
+}
+
+func (r *fakeDividends) SaveDividend(userID string, dividend models.Dividend) error {
+	r.dividends = append(r.dividends, dividend)
+	return nil
+}
+
+func TestImportDividends(t *testing.T) {
+	repo := &fakeDividends{}
+	service := NewUserService(UserServiceConfig{
+		DividendRepository: repo,
+		Instruments:        fakeResolver{"TCS": "INE467B01029"},
+	})
+
+	file := "platform,symbol,isin,paid_on,amount\n" +
+		"zerodha,TCS,,2024-07-19,280\n" +
+		"zerodha,NEWCO,,2024-07-19,20\n"
+	result, err := service.ImportDividends(context.Background(), "user-1", strings.NewReader(file))
+	assert.NoError(t, err)
+	assert.Equal(t, &models.DividendImportResult{Total: 2, Amount: 300, Resolved: 1}, result)
+	if assert.Len(t, repo.dividends, 2) {
+		assert.Equal(t, "INE467B01029", repo.dividends[0].ISIN)
+		assert.Empty(t, repo.dividends[1].ISIN)
+	}
+}
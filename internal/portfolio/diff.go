@@ -0,0 +1,134 @@
+package portfolio
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// DefaultPriceChangeThreshold is the price move in percent beyond which a refresh reports a price change
+const DefaultPriceChangeThreshold = 5.0
+
+// GetHoldingChanges retrieves the holding changes detected for a user between two days inclusive, newest first.
+// Zero bounds default to the last 30 days up to today; an empty change type returns all changes.
+func (s *UserService) GetHoldingChanges(ctx context.Context, userID string, from, to time.Time, changeType models.HoldingChangeType) ([]models.HoldingChange, error) {
+	if to.IsZero() {
+		to = snapshotDate(time.Now())
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -30)
+	}
+	if from.After(to) {
+		return nil, ErrInvalidHistoryRange
+	}
+
+	// Snapshot days are IST calendar days, so the range runs from IST midnight of from to IST midnight after to
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, ist)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, ist).AddDate(0, 0, 1)
+	return s.holdingChangeRepository.GetHoldingChanges(userID, start, end, changeType)
+}
+
+// holdingKey identifies a holding at a platform by ISIN, falling back to its name when the ISIN is unknown
+func holdingKey(holding models.Holding) string {
+	if holding.ISIN != "" {
+		return holding.Platform + "|" + holding.ISIN
+	}
+	return holding.Platform + "|name:" + holding.ItemName
+}
+
+// sumByKey collapses holdings sharing a key into one, summing their quantities, so a broker's delivery
+// holding and intraday position in the same instrument are compared as a single holding. Order is kept.
+func sumByKey(holdings []models.Holding) []models.Holding {
+	summed := make([]models.Holding, 0, len(holdings))
+	index := make(map[string]int, len(holdings))
+	for _, holding := range holdings {
+		key := holdingKey(holding)
+		if i, ok := index[key]; ok {
+			summed[i].Quantity += holding.Quantity
+			continue
+		}
+		index[key] = len(summed)
+		summed = append(summed, holding)
+	}
+	return summed
+}
+
+// diffHoldings compares two unmerged holdings sets and returns new positions, exits, quantity changes
+// and price moves of more than thresholdPct percent, sorted by platform and name.
+// Platforms in unfetched, whose holdings could not be fetched, are left out entirely so they are never reported as exited.
+func diffHoldings(previous, current []models.Holding, unfetched map[string]bool, thresholdPct float64, detectedAt time.Time) []models.HoldingChange {
+	previous = sumByKey(previous)
+	current = sumByKey(current)
+
+	previousByKey := make(map[string]models.Holding, len(previous))
+	for _, holding := range previous {
+		previousByKey[holdingKey(holding)] = holding
+	}
+
+	changes := []models.HoldingChange{}
+	seen := make(map[string]bool, len(current))
+	for _, holding := range current {
+		if unfetched[holding.Platform] {
+			continue
+		}
+		key := holdingKey(holding)
+		seen[key] = true
+
+		before, existed := previousByKey[key]
+		change := models.HoldingChange{
+			ItemName:   holding.ItemName,
+			ISIN:       holding.ISIN,
+			Platform:   holding.Platform,
+			Quantity:   holding.Quantity,
+			Price:      holding.LastTradedPrice,
+			DetectedAt: detectedAt,
+		}
+		if !existed {
+			change.Type = models.HoldingChangeNew
+			changes = append(changes, change)
+			continue
+		}
+
+		change.PreviousQuantity = before.Quantity
+		change.PreviousPrice = before.LastTradedPrice
+		if before.LastTradedPrice != 0 {
+			change.PriceChangePct = (holding.LastTradedPrice - before.LastTradedPrice) / before.LastTradedPrice * 100
+		}
+
+		switch {
+		case math.Abs(holding.Quantity-before.Quantity) > quantityTolerance:
+			change.Type = models.HoldingChangeQuantity
+		case math.Abs(change.PriceChangePct) > thresholdPct:
+			change.Type = models.HoldingChangePrice
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+
+	for _, holding := range previous {
+		if unfetched[holding.Platform] || seen[holdingKey(holding)] {
+			continue
+		}
+		changes = append(changes, models.HoldingChange{
+			Type:             models.HoldingChangeExit,
+			ItemName:         holding.ItemName,
+			ISIN:             holding.ISIN,
+			Platform:         holding.Platform,
+			PreviousQuantity: holding.Quantity,
+			PreviousPrice:    holding.LastTradedPrice,
+			DetectedAt:       detectedAt,
+		})
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Platform != changes[j].Platform {
+			return changes[i].Platform < changes[j].Platform
+		}
+		return changes[i].ItemName < changes[j].ItemName
+	})
+	return changes
+}
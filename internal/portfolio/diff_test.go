@@ -0,0 +1,119 @@
+package portfolio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffHoldings(t *testing.T) {
+	detectedAt := time.Date(2025, time.March, 4, 16, 0, 0, 0, ist)
+	holding := func(name, isin, platform string, quantity, price float64) models.Holding {
+		return models.Holding{ItemName: name, ISIN: isin, Platform: platform, Quantity: quantity, LastTradedPrice: price}
+	}
+
+	previous := []models.Holding{
+		holding("INFY", "INE009A01021", models.PlatformZerodha, 10, 1500),
+		holding("TCS", "INE467B01029", models.PlatformZerodha, 5, 4000),
+		holding("HDFCBANK", "INE040A01034", models.PlatformZerodha, 8, 1600),
+		holding("ITC", "INE154A01025", models.PlatformZerodha, 100, 400),
+		holding("INFY", "INE009A01021", models.PlatformICICIDirect, 4, 1500),
+		holding("Parag Parikh Flexi Cap", "", models.PlatformCAS, 50, 80),
+	}
+	current := []models.Holding{
+		// Unchanged apart from a small price move
+		holding("INFY", "INE009A01021", models.PlatformZerodha, 10, 1540),
+		// Quantity change is reported even with a large price move
+		holding("TCS", "INE467B01029", models.PlatformZerodha, 7, 3000),
+		// Price move beyond the threshold
+		holding("HDFCBANK", "INE040A01034", models.PlatformZerodha, 8, 1700),
+		// New position
+		holding("RELIANCE", "INE002A01018", models.PlatformZerodha, 3, 2900),
+		// Same ISIN at another platform is a separate holding
+		holding("INFY", "INE009A01021", models.PlatformICICIDirect, 4, 1540),
+		// Holdings without ISIN are matched by name
+		holding("Parag Parikh Flexi Cap", "", models.PlatformCAS, 50, 81),
+	}
+
+	changes := diffHoldings(previous, current, nil, DefaultPriceChangeThreshold, detectedAt)
+	assert.Len(t, changes, 4)
+
+	byName := make(map[string]models.HoldingChange)
+	for _, change := range changes {
+		assert.Equal(t, models.PlatformZerodha, change.Platform)
+		assert.Equal(t, detectedAt, change.DetectedAt)
+		byName[change.ItemName] = change
+	}
+
+	assert.Equal(t, models.HoldingChangeQuantity, byName["TCS"].Type)
+	assert.Equal(t, 5.0, byName["TCS"].PreviousQuantity)
+	assert.Equal(t, 7.0, byName["TCS"].Quantity)
+	assert.InDelta(t, -25.0, byName["TCS"].PriceChangePct, 1e-9)
+
+	assert.Equal(t, models.HoldingChangePrice, byName["HDFCBANK"].Type)
+	assert.InDelta(t, 6.25, byName["HDFCBANK"].PriceChangePct, 1e-9)
+
+	assert.Equal(t, models.HoldingChangeNew, byName["RELIANCE"].Type)
+	assert.Equal(t, 3.0, byName["RELIANCE"].Quantity)
+
+	assert.Equal(t, models.HoldingChangeExit, byName["ITC"].Type)
+	assert.Equal(t, 100.0, byName["ITC"].PreviousQuantity)
+	assert.Equal(t, 0.0, byName["ITC"].Quantity)
+
+	// Sorted by platform then name
+	assert.Equal(t, []string{"HDFCBANK", "ITC", "RELIANCE", "TCS"}, []string{changes[0].ItemName, changes[1].ItemName, changes[2].ItemName, changes[3].ItemName})
+}
+
+func TestDiffHoldingsThreshold(t *testing.T) {
+	previous := []models.Holding{{ItemName: "INFY", ISIN: "INE009A01021", Platform: models.PlatformZerodha, Quantity: 10, LastTradedPrice: 1500}}
+	current := []models.Holding{{ItemName: "INFY", ISIN: "INE009A01021", Platform: models.PlatformZerodha, Quantity: 10, LastTradedPrice: 1560}}
+
+	assert.Empty(t, diffHoldings(previous, current, nil, 5, time.Now()))
+	assert.Len(t, diffHoldings(previous, current, nil, 2, time.Now()), 1)
+	assert.Empty(t, diffHoldings(current, current, nil, 0, time.Now()))
+}
+
+func TestDiffHoldingsUnfetchedPlatform(t *testing.T) {
+	previous := []models.Holding{
+		{ItemName: "INFY", ISIN: "INE009A01021", Platform: models.PlatformZerodha, Quantity: 10, LastTradedPrice: 1500},
+		{ItemName: "TCS", ISIN: "INE467B01029", Platform: models.PlatformUpstox, Quantity: 5, LastTradedPrice: 4000},
+	}
+	current := []models.Holding{
+		{ItemName: "INFY", ISIN: "INE009A01021", Platform: models.PlatformZerodha, Quantity: 12, LastTradedPrice: 1500},
+	}
+
+	// A platform whose fetch failed reports neither exits nor changes
+	unfetched := map[string]bool{models.PlatformUpstox: true}
+	changes := diffHoldings(previous, current, unfetched, DefaultPriceChangeThreshold, time.Now())
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, models.HoldingChangeQuantity, changes[0].Type)
+	}
+
+	// Without the failure the missing holding is an exit
+	assert.Len(t, diffHoldings(previous, current, nil, DefaultPriceChangeThreshold, time.Now()), 2)
+}
+
+func TestDiffHoldingsSumsPositions(t *testing.T) {
+	holding := models.Holding{ItemName: "INFY", ISIN: "INE009A01021", Platform: models.PlatformUpstox, Quantity: 10, LastTradedPrice: 1500}
+	position := models.Holding{ItemName: "INFY", ISIN: "INE009A01021", Platform: models.PlatformUpstox, Quantity: 5, LastTradedPrice: 1500}
+
+	// A delivery holding and a position in the same instrument are one holding
+	previous := []models.Holding{holding}
+	assert.Empty(t, diffHoldings(previous, []models.Holding{holding}, nil, DefaultPriceChangeThreshold, time.Now()))
+
+	changes := diffHoldings(previous, []models.Holding{holding, position}, nil, DefaultPriceChangeThreshold, time.Now())
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, models.HoldingChangeQuantity, changes[0].Type)
+		assert.Equal(t, 10.0, changes[0].PreviousQuantity)
+		assert.Equal(t, 15.0, changes[0].Quantity)
+	}
+
+	// The position being squared off is a quantity change, not an exit
+	changes = diffHoldings([]models.Holding{holding, position}, []models.Holding{holding}, nil, DefaultPriceChangeThreshold, time.Now())
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, models.HoldingChangeQuantity, changes[0].Type)
+		assert.Equal(t, 15.0, changes[0].PreviousQuantity)
+	}
+}
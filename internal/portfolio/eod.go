@@ -0,0 +1,62 @@
+package portfolio
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// endOfDayHour and endOfDayMinute set when, in IST, the daily snapshot is taken; closing prices have settled by then
+const (
+	endOfDayHour   = 16
+	endOfDayMinute = 0
+)
+
+// nextEndOfDay returns the first weekday end-of-day snapshot time after t
+func nextEndOfDay(t time.Time) time.Time {
+	local := t.In(ist)
+	next := time.Date(local.Year(), local.Month(), local.Day(), endOfDayHour, endOfDayMinute, 0, 0, ist)
+	if !next.After(local) {
+		next = next.AddDate(0, 0, 1)
+	}
+	for next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// RunEndOfDaySnapshots takes end-of-day snapshots every weekday after market close until ctx is cancelled
+func (s *UserService) RunEndOfDaySnapshots(ctx context.Context) {
+	for {
+		timer := time.NewTimer(time.Until(nextEndOfDay(time.Now())))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.TakeEndOfDaySnapshots(ctx)
+		}
+	}
+}
+
+// TakeEndOfDaySnapshots refreshes the portfolio of every user with a connected broker, recording end-of-day snapshots.
+// A user whose refresh fails, for instance because a broker session expired, is logged and skipped without a snapshot.
+func (s *UserService) TakeEndOfDaySnapshots(ctx context.Context) {
+	userIDs, err := s.brokerManager.ConnectedUsers()
+	if err != nil {
+		log.Printf("Error listing users for end-of-day snapshots: %v", err)
+		return
+	}
+
+	taken := 0
+	for _, userID := range userIDs {
+		if _, err := s.refresh(ctx, userID, models.SnapshotSourceEndOfDay); err != nil {
+			log.Printf("Skipping end-of-day snapshot for user %s: %v", userID, err)
+			continue
+		}
+		taken++
+	}
+	log.Printf("Took end-of-day snapshots for %d of %d users", taken, len(userIDs))
+}
@@ -0,0 +1,104 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// ErrInvalidHistoryRange is returned when a history range ends before it starts
+var ErrInvalidHistoryRange = errors.New("history range start is after its end")
+
+// ist is Indian Standard Time, in which snapshot days are reckoned
+var ist = time.FixedZone("IST", 5*60*60+30*60)
+
+// snapshotDate returns the IST calendar day of t as midnight UTC, the form snapshot days are stored in
+func snapshotDate(t time.Time) time.Time {
+	year, month, day := t.In(ist).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// newSnapshot totals unmerged holdings into a portfolio snapshot taken at the given time
+func newSnapshot(takenAt time.Time, source models.SnapshotSource, holdings []models.Holding) *models.PortfolioSnapshot {
+	snapshot := &models.PortfolioSnapshot{
+		Date:          snapshotDate(takenAt),
+		TakenAt:       takenAt,
+		Source:        source,
+		HoldingsCount: len(holdings),
+	}
+	for _, holding := range holdings {
+		snapshot.TotalValue += holding.CurrentValue
+		snapshot.TotalInvestment += holding.Quantity * holding.AveragePrice
+		snapshot.TotalPnL += holding.TotalPnL
+		snapshot.TotalDayChange += holding.DayChange
+	}
+	return snapshot
+}
+
+// GetPortfolioHistory returns the portfolio value series of a user between two days inclusive.
+// Zero bounds default to the last year up to today; an empty granularity defaults to one point per day.
+func (s *UserService) GetPortfolioHistory(ctx context.Context, userID string, from, to time.Time, granularity models.HistoryGranularity) (*models.PortfolioHistory, error) {
+	if to.IsZero() {
+		to = snapshotDate(time.Now())
+	}
+	if from.IsZero() {
+		from = to.AddDate(-1, 0, 0)
+	}
+	if from.After(to) {
+		return nil, ErrInvalidHistoryRange
+	}
+	if granularity == "" {
+		granularity = models.HistoryGranularityDay
+	}
+
+	snapshots, err := s.snapshotRepository.GetPortfolioSnapshots(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PortfolioHistory{
+		From:        from,
+		To:          to,
+		Granularity: granularity,
+		Points:      historyPoints(snapshots, granularity),
+	}, nil
+}
+
+// historyPoints reduces snapshots ordered by time taken to the last snapshot of each period
+func historyPoints(snapshots []models.PortfolioSnapshot, granularity models.HistoryGranularity) []models.PortfolioHistoryPoint {
+	points := []models.PortfolioHistoryPoint{}
+	var lastPeriod time.Time
+	for _, snapshot := range snapshots {
+		point := models.PortfolioHistoryPoint{
+			Date:            snapshot.Date,
+			TotalValue:      snapshot.TotalValue,
+			TotalInvestment: snapshot.TotalInvestment,
+			TotalPnL:        snapshot.TotalPnL,
+			TotalDayChange:  snapshot.TotalDayChange,
+		}
+
+		period := periodStart(snapshot.Date, granularity)
+		if len(points) > 0 && period.Equal(lastPeriod) {
+			points[len(points)-1] = point
+			continue
+		}
+		points = append(points, point)
+		lastPeriod = period
+	}
+	return points
+}
+
+// periodStart returns the first day of the period containing day: the day itself, its ISO week's Monday or the first of its month
+func periodStart(day time.Time, granularity models.HistoryGranularity) time.Time {
+	switch granularity {
+	case models.HistoryGranularityWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case models.HistoryGranularityMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	default:
+		return day
+	}
+}
@@ -0,0 +1,120 @@
+package portfolio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestNewSnapshot(t *testing.T) {
+	// 20:00 UTC on 3 March is already 4 March in IST
+	takenAt := time.Date(2025, time.March, 3, 20, 0, 0, 0, time.UTC)
+	holdings := []models.Holding{
+		{ItemName: "INFY", Quantity: 10, AveragePrice: 1400, CurrentValue: 15000, TotalPnL: 1000, DayChange: 50, Platform: models.PlatformZerodha},
+		{ItemName: "INFY", Quantity: 5, AveragePrice: 1500, CurrentValue: 7500, TotalPnL: 0, DayChange: 25, Platform: models.PlatformICICIDirect},
+	}
+
+	snapshot := newSnapshot(takenAt, models.SnapshotSourceEndOfDay, holdings)
+	assert.Equal(t, day(2025, time.March, 4), snapshot.Date)
+	assert.Equal(t, takenAt, snapshot.TakenAt)
+	assert.Equal(t, models.SnapshotSourceEndOfDay, snapshot.Source)
+	assert.Equal(t, 22500.0, snapshot.TotalValue)
+	assert.Equal(t, 21500.0, snapshot.TotalInvestment)
+	assert.Equal(t, 1000.0, snapshot.TotalPnL)
+	assert.Equal(t, 75.0, snapshot.TotalDayChange)
+	assert.Equal(t, 2, snapshot.HoldingsCount)
+}
+
+func TestHistoryPoints(t *testing.T) {
+	snapshot := func(date time.Time, value float64) models.PortfolioSnapshot {
+		return models.PortfolioSnapshot{Date: date, TotalValue: value}
+	}
+	// Ordered by time taken: two refreshes on Mon 3 March, then Wed 5 March, Mon 10 March and Tue 1 April
+	snapshots := []models.PortfolioSnapshot{
+		snapshot(day(2025, time.March, 3), 100),
+		snapshot(day(2025, time.March, 3), 110),
+		snapshot(day(2025, time.March, 5), 120),
+		snapshot(day(2025, time.March, 10), 130),
+		snapshot(day(2025, time.April, 1), 140),
+	}
+
+	tests := []struct {
+		name        string
+		granularity models.HistoryGranularity
+		wantDates   []time.Time
+		wantValues  []float64
+	}{
+		{
+			name:        "day keeps the last snapshot of each day",
+			granularity: models.HistoryGranularityDay,
+			wantDates:   []time.Time{day(2025, time.March, 3), day(2025, time.March, 5), day(2025, time.March, 10), day(2025, time.April, 1)},
+			wantValues:  []float64{110, 120, 130, 140},
+		},
+		{
+			name:        "week keeps the last snapshot of each ISO week",
+			granularity: models.HistoryGranularityWeek,
+			wantDates:   []time.Time{day(2025, time.March, 5), day(2025, time.March, 10), day(2025, time.April, 1)},
+			wantValues:  []float64{120, 130, 140},
+		},
+		{
+			name:        "month keeps the last snapshot of each month",
+			granularity: models.HistoryGranularityMonth,
+			wantDates:   []time.Time{day(2025, time.March, 10), day(2025, time.April, 1)},
+			wantValues:  []float64{130, 140},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points := historyPoints(snapshots, tt.granularity)
+			assert.Len(t, points, len(tt.wantDates))
+			for i, point := range points {
+				assert.Equal(t, tt.wantDates[i], point.Date)
+				assert.Equal(t, tt.wantValues[i], point.TotalValue)
+			}
+		})
+	}
+
+	assert.Empty(t, historyPoints(nil, models.HistoryGranularityDay))
+}
+
+func TestNextEndOfDay(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{
+			name: "before close on a weekday",
+			now:  time.Date(2025, time.March, 4, 10, 0, 0, 0, ist),
+			want: time.Date(2025, time.March, 4, 16, 0, 0, 0, ist),
+		},
+		{
+			name: "after close on a weekday",
+			now:  time.Date(2025, time.March, 4, 16, 0, 0, 0, ist),
+			want: time.Date(2025, time.March, 5, 16, 0, 0, 0, ist),
+		},
+		{
+			name: "after close on Friday skips the weekend",
+			now:  time.Date(2025, time.March, 7, 17, 0, 0, 0, ist),
+			want: time.Date(2025, time.March, 10, 16, 0, 0, 0, ist),
+		},
+		{
+			name: "UTC time is converted to IST",
+			now:  time.Date(2025, time.March, 4, 11, 0, 0, 0, time.UTC),
+			want: time.Date(2025, time.March, 5, 16, 0, 0, 0, ist),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, tt.want.Equal(nextEndOfDay(tt.now)), "got %v", nextEndOfDay(tt.now))
+		})
+	}
+}
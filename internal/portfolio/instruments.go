@@ -0,0 +1,53 @@
+package portfolio
+
+import (
+	"github.com/Kora1128/FinSight/internal/instruments"
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// canonicalizeHoldings sets missing holding ISINs by resolving their names through the instrument store
+func (s *UserService) canonicalizeHoldings(holdings []models.Holding) {
+	if s.instruments == nil {
+		return
+	}
+	for i := range holdings {
+		if holdings[i].ISIN != "" {
+			continue
+		}
+		if instrument, ok := s.instruments.Lookup(holdings[i].ItemName); ok {
+			holdings[i].ISIN = instrument.ISIN
+		}
+	}
+}
+
+// canonicalizeTrades sets missing trade ISINs by resolving their symbols through the instrument store
+func (s *UserService) canonicalizeTrades(trades []models.Trade) {
+	if s.instruments == nil {
+		return
+	}
+	for i := range trades {
+		if trades[i].ISIN != "" {
+			continue
+		}
+		if instrument, ok := s.instruments.Lookup(trades[i].Symbol); ok {
+			trades[i].ISIN = instrument.ISIN
+		}
+	}
+}
+
+// classifyHoldings sets the type of holdings with an ISIN from the ISIN and the instrument store.
+// Fund units are ETFs when listed on an exchange or already reported as ETFs, and mutual funds otherwise.
+func (s *UserService) classifyHoldings(holdings []models.Holding) {
+	for i := range holdings {
+		if holdings[i].ISIN == "" {
+			continue
+		}
+		listed := holdings[i].Type == models.HoldingTypeETF
+		if s.instruments != nil {
+			if instrument, ok := s.instruments.Lookup(holdings[i].ISIN); ok {
+				listed = listed || instrument.NSESymbol != "" || instrument.BSECode != ""
+			}
+		}
+		holdings[i].Type = instruments.ClassifyHolding(holdings[i].ISIN, holdings[i].ItemName, listed)
+	}
+}
@@ -0,0 +1,73 @@
+package portfolio
+
+import (
+	"testing"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeResolver resolves identifiers from a fixed map to ISINs
+type fakeResolver map[string]string
+
+func (r fakeResolver) Lookup(identifier string) (models.Instrument, bool) {
+	isin, ok := r[identifier]
+	return models.Instrument{ISIN: isin}, ok
+}
+
+func TestCanonicalize(t *testing.T) {
+	service := NewUserService(UserServiceConfig{
+		Instruments: fakeResolver{"INFY": "INE009A01021", "ICIBAN": "INE090A01021"},
+	})
+
+	holdings := []models.Holding{
+		{ItemName: "INFY", Platform: models.PlatformICICIDirect},
+		{ItemName: "TCS", ISIN: "INE467B01029", Platform: models.PlatformZerodha},
+		{ItemName: "UNKNOWN", Platform: models.PlatformZerodha},
+	}
+	service.canonicalizeHoldings(holdings)
+	assert.Equal(t, "INE009A01021", holdings[0].ISIN)
+	assert.Equal(t, "INE467B01029", holdings[1].ISIN)
+	assert.Empty(t, holdings[2].ISIN)
+
+	trades := []models.Trade{{Symbol: "ICIBAN"}, {Symbol: "INFY", ISIN: "INE009A01021"}}
+	service.canonicalizeTrades(trades)
+	assert.Equal(t, "INE090A01021", trades[0].ISIN)
+
+	// Without an instrument store nothing changes
+	holdings = []models.Holding{{ItemName: "INFY"}}
+	NewUserService(UserServiceConfig{}).canonicalizeHoldings(holdings)
+	assert.Empty(t, holdings[0].ISIN)
+}
+
+func TestClassifyHoldings(t *testing.T) {
+	service := NewUserService(UserServiceConfig{
+		Instruments: listedResolver{"INF204KB17I5": {ISIN: "INF204KB17I5", NSESymbol: "GOLDBEES"}},
+	})
+
+	holdings := []models.Holding{
+		{ItemName: "Nippon India ETF Gold BeES", ISIN: "INF204KB17I5", Type: models.HoldingTypeMutualFund, Platform: models.PlatformCAS},
+		{ItemName: "HDFC Large Cap Fund", ISIN: "INF179K01YV8", Type: models.HoldingTypeMutualFund, Platform: models.PlatformZerodha},
+		{ItemName: "SETFNIF50", ISIN: "INF200KA16D8", Type: models.HoldingTypeETF, Platform: models.PlatformZerodha},
+		{ItemName: "SGBAUG28V", ISIN: "IN0020200112", Type: models.HoldingTypeStock, Platform: models.PlatformZerodha},
+		{ItemName: "Some Fund", Type: models.HoldingTypeMutualFund, Platform: models.PlatformICICIDirect},
+	}
+	service.classifyHoldings(holdings)
+
+	// Listed in the instrument store
+	assert.Equal(t, models.HoldingTypeETF, holdings[0].Type)
+	assert.Equal(t, models.HoldingTypeMutualFund, holdings[1].Type)
+	// Reported as exchange traded by the broker
+	assert.Equal(t, models.HoldingTypeETF, holdings[2].Type)
+	assert.Equal(t, models.HoldingTypeSGB, holdings[3].Type)
+	// Holdings without an ISIN keep the type their source reported
+	assert.Equal(t, models.HoldingTypeMutualFund, holdings[4].Type)
+}
+
+// listedResolver resolves identifiers from a fixed map to instruments
+type listedResolver map[string]models.Instrument
+
+func (r listedResolver) Lookup(identifier string) (models.Instrument, bool) {
+	instrument, ok := r[identifier]
+	return instrument, ok
+}
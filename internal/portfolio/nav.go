@@ -0,0 +1,56 @@
+package portfolio
+
+import (
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// revalueMutualFunds sets the price, value, day change and P&L of mutual fund holdings from their latest NAV.
+// Holdings without a known NAV keep the valuation their source reported.
+func (s *UserService) revalueMutualFunds(holdings []models.Holding) error {
+	if s.navs == nil {
+		return nil
+	}
+
+	var isins []string
+	seen := make(map[string]bool)
+	for _, holding := range holdings {
+		if holding.Type == models.HoldingTypeMutualFund && holding.ISIN != "" && !seen[holding.ISIN] {
+			seen[holding.ISIN] = true
+			isins = append(isins, holding.ISIN)
+		}
+	}
+	if len(isins) == 0 {
+		return nil
+	}
+
+	quotes, err := s.navs.LatestNAVs(isins)
+	if err != nil {
+		return err
+	}
+
+	for i := range holdings {
+		if holdings[i].Type != models.HoldingTypeMutualFund {
+			continue
+		}
+		if quote, ok := quotes[holdings[i].ISIN]; ok {
+			applyPrice(&holdings[i], quote.NAV, quote.PreviousNAV)
+		}
+	}
+	return nil
+}
+
+// applyPrice values a holding at a price, moving its P&L by the change in value.
+// The day change is computed from the previous price when it is known.
+func applyPrice(holding *models.Holding, price, previous float64) {
+	value := holding.Quantity * price
+	holding.TotalPnL += value - holding.CurrentValue
+	holding.LastTradedPrice = price
+	holding.CurrentValue = value
+
+	holding.DayChange = 0
+	holding.DayChangePercent = 0
+	if previous > 0 {
+		holding.DayChange = holding.Quantity * (price - previous)
+		holding.DayChangePercent = (price - previous) / previous * 100
+	}
+}
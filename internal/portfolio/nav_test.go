@@ -0,0 +1,61 @@
+package portfolio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeNAVs serves NAV quotes from a fixed map and records the ISINs requested
+type fakeNAVs struct {
+	quotes    map[string]models.NAVQuote
+	requested []string
+}
+
+func (f *fakeNAVs) LatestNAVs(isins []string) (map[string]models.NAVQuote, error) {
+	f.requested = isins
+	return f.quotes, nil
+}
+
+func TestRevalueMutualFunds(t *testing.T) {
+	navs := &fakeNAVs{quotes: map[string]models.NAVQuote{
+		"INF179K01YV8": {ISIN: "INF179K01YV8", NAV: 1200, PreviousNAV: 1190, Date: time.Date(2025, 10, 14, 0, 0, 0, 0, time.UTC)},
+		"INF109K016L0": {ISIN: "INF109K016L0", NAV: 120},
+	}}
+	service := NewUserService(UserServiceConfig{NAVs: navs})
+
+	holdings := []models.Holding{
+		{ItemName: "HDFC Large Cap Fund", ISIN: "INF179K01YV8", Type: models.HoldingTypeMutualFund, Quantity: 10, AveragePrice: 1000, LastTradedPrice: 1100, CurrentValue: 11000, TotalPnL: 1000},
+		{ItemName: "ICICI Prudential Bluechip Fund", ISIN: "INF109K016L0", Type: models.HoldingTypeMutualFund, Quantity: 50, CurrentValue: 5500, TotalPnL: 500, DayChange: 40},
+		{ItemName: "Unknown Fund", ISIN: "INF000000000", Type: models.HoldingTypeMutualFund, Quantity: 5, CurrentValue: 500},
+		{ItemName: "INFY", ISIN: "INE009A01021", Type: models.HoldingTypeStock, Quantity: 10, CurrentValue: 15000},
+	}
+	assert.NoError(t, service.revalueMutualFunds(holdings))
+
+	// Only mutual fund ISINs are looked up
+	assert.ElementsMatch(t, []string{"INF179K01YV8", "INF109K016L0", "INF000000000"}, navs.requested)
+
+	hdfc := holdings[0]
+	assert.Equal(t, 1200.0, hdfc.LastTradedPrice)
+	assert.Equal(t, 12000.0, hdfc.CurrentValue)
+	assert.Equal(t, 2000.0, hdfc.TotalPnL)
+	assert.Equal(t, 100.0, hdfc.DayChange)
+	assert.InDelta(t, 0.8403, hdfc.DayChangePercent, 0.0001)
+
+	// Without a previous NAV the day change is unknown
+	icici := holdings[1]
+	assert.Equal(t, 6000.0, icici.CurrentValue)
+	assert.Equal(t, 1000.0, icici.TotalPnL)
+	assert.Zero(t, icici.DayChange)
+
+	// Holdings without a NAV and stocks keep their valuation
+	assert.Equal(t, 500.0, holdings[2].CurrentValue)
+	assert.Equal(t, 15000.0, holdings[3].CurrentValue)
+
+	// Without a NAV source nothing changes
+	holdings = []models.Holding{{ISIN: "INF179K01YV8", Type: models.HoldingTypeMutualFund, Quantity: 10, CurrentValue: 11000}}
+	assert.NoError(t, NewUserService(UserServiceConfig{}).revalueMutualFunds(holdings))
+	assert.Equal(t, 11000.0, holdings[0].CurrentValue)
+}
@@ -0,0 +1,29 @@
+package portfolio
+
+import (
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// revalueFromClosingPrices values exchange-traded holdings at their latest close on or before the given day.
+// Statements report prices as of their own date; mutual funds are valued from NAVs instead.
+func (s *UserService) revalueFromClosingPrices(holdings []models.Holding, asOf time.Time) error {
+	if s.prices == nil {
+		return nil
+	}
+
+	for i := range holdings {
+		if holdings[i].ISIN == "" || holdings[i].Type == models.HoldingTypeMutualFund {
+			continue
+		}
+		closePrice, ok, err := s.prices.GetClose(holdings[i].ISIN, asOf)
+		if err != nil {
+			return err
+		}
+		if ok {
+			applyPrice(&holdings[i], closePrice, 0)
+		}
+	}
+	return nil
+}
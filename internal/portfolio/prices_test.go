@@ -0,0 +1,40 @@
+package portfolio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePrices serves closing prices from a fixed map
+type fakePrices map[string]float64
+
+func (f fakePrices) GetClose(isin string, date time.Time) (float64, bool, error) {
+	closePrice, ok := f[isin]
+	return closePrice, ok, nil
+}
+
+func TestRevalueFromClosingPrices(t *testing.T) {
+	service := NewUserService(UserServiceConfig{
+		Prices: fakePrices{"INE009A01021": 1490.20, "INF179K01YV8": 999},
+	})
+
+	holdings := []models.Holding{
+		{ItemName: "INFOSYS LIMITED", ISIN: "INE009A01021", Type: models.HoldingTypeStock, Quantity: 10, LastTradedPrice: 1400, CurrentValue: 14000, TotalPnL: 1000, DayChange: 50},
+		{ItemName: "HDFC Large Cap Fund", ISIN: "INF179K01YV8", Type: models.HoldingTypeMutualFund, Quantity: 10, CurrentValue: 11000},
+		{ItemName: "UNLISTEDCO", ISIN: "INE999Z01011", Type: models.HoldingTypeStock, Quantity: 5, CurrentValue: 500},
+	}
+	assert.NoError(t, service.revalueFromClosingPrices(holdings, time.Now()))
+
+	infy := holdings[0]
+	assert.Equal(t, 1490.20, infy.LastTradedPrice)
+	assert.InDelta(t, 14902.0, infy.CurrentValue, 1e-9)
+	assert.InDelta(t, 1902.0, infy.TotalPnL, 1e-9)
+	assert.Zero(t, infy.DayChange)
+
+	// Mutual funds and securities without a close keep their valuation
+	assert.Equal(t, 11000.0, holdings[1].CurrentValue)
+	assert.Equal(t, 500.0, holdings[2].CurrentValue)
+}
@@ -22,4 +22,77 @@ type PortfolioRepository interface {
 
 	// GetPortfolioLastUpdated gets the timestamp when the portfolio was last updated
 	GetPortfolioLastUpdated(userID string) (time.Time, bool, error)
+
+	// SaveImportedHoldings replaces the holdings imported from a source (e.g. a CAS upload) for a user
+	SaveImportedHoldings(userID string, source string, holdings []models.Holding) error
+
+	// GetImportedHoldings retrieves the holdings imported from all sources for a user
+	GetImportedHoldings(userID string) ([]models.Holding, error)
+}
+
+// TradeRepository defines the interface for storing and retrieving trade history
+type TradeRepository interface {
+	// SaveTrades stores trades for a user, skipping duplicates, and returns the number newly inserted
+	SaveTrades(userID string, trades []models.Trade) (int, error)
+
+	// GetTrades retrieves all trades for a user ordered by trade date
+	GetTrades(userID string) ([]models.Trade, error)
+
+	// GetTradesByISIN retrieves the trades in a single security for a user ordered by trade date
+	GetTradesByISIN(userID string, isin string) ([]models.Trade, error)
+}
+
+// DividendRepository defines the interface for storing dividends received by users
+type DividendRepository interface {
+	// SaveDividend stores a dividend received by a user, replacing the amount of an existing record for the same payout
+	SaveDividend(userID string, dividend models.Dividend) error
+}
+
+// CorporateActionRepository defines the interface for storing and retrieving splits and bonus issues
+type CorporateActionRepository interface {
+	// SaveCorporateAction stores a corporate action, replacing the ratio of an existing record for the same security, type and ex-date
+	SaveCorporateAction(action models.CorporateAction) error
+
+	// GetCorporateActions retrieves the actions for the given securities that took effect on or before the given date
+	GetCorporateActions(isins []string, asOf time.Time) ([]models.CorporateAction, error)
+}
+
+// SnapshotRepository defines the interface for the append-only portfolio snapshot history
+type SnapshotRepository interface {
+	// SaveSnapshot appends a portfolio snapshot and the holdings it was computed from, and sets the snapshot ID
+	SaveSnapshot(userID string, snapshot *models.PortfolioSnapshot, holdings []models.Holding) error
+
+	// GetPortfolioSnapshots retrieves all portfolio snapshots of a user between two days inclusive, ordered by time taken
+	GetPortfolioSnapshots(userID string, from, to time.Time) ([]models.PortfolioSnapshot, error)
+
+	// GetLatestSnapshotHoldings retrieves the holdings of the most recent snapshot of a user
+	GetLatestSnapshotHoldings(userID string) ([]models.Holding, bool, error)
+}
+
+// HoldingChangeRepository defines the interface for storing the holding changes detected on refresh
+type HoldingChangeRepository interface {
+	// SaveHoldingChanges stores the changes detected when the given snapshot was taken
+	SaveHoldingChanges(userID string, snapshotID int64, changes []models.HoldingChange) error
+
+	// GetHoldingChanges retrieves the changes detected for a user in a time range, newest first,
+	// optionally filtered by change type
+	GetHoldingChanges(userID string, from, to time.Time, changeType models.HoldingChangeType) ([]models.HoldingChange, error)
+}
+
+// InstrumentResolver defines the interface for canonicalizing security identifiers
+type InstrumentResolver interface {
+	// Lookup resolves an ISIN, exchange symbol, BSE code or company name to an instrument
+	Lookup(identifier string) (models.Instrument, bool)
+}
+
+// NAVSource defines the interface for the latest mutual fund NAVs
+type NAVSource interface {
+	// LatestNAVs retrieves the latest NAV of each of the given scheme ISINs, keyed by ISIN
+	LatestNAVs(isins []string) (map[string]models.NAVQuote, error)
+}
+
+// PriceProvider defines the interface for end-of-day closing prices
+type PriceProvider interface {
+	// GetClose returns the closing price of a security on the last trading day on or before the given day
+	GetClose(isin string, date time.Time) (float64, bool, error)
 }
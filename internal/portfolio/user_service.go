@@ -2,29 +2,78 @@ package portfolio
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/Kora1128/FinSight/internal/broker"
+	"github.com/Kora1128/FinSight/internal/broker/types"
+	"github.com/Kora1128/FinSight/internal/costbasis"
+	"github.com/Kora1128/FinSight/internal/importer/cas"
+	"github.com/Kora1128/FinSight/internal/importer/dividend"
+	"github.com/Kora1128/FinSight/internal/importer/tradebook"
 	"github.com/Kora1128/FinSight/internal/models"
 )
 
 // UserServiceConfig holds configuration for user-specific portfolio service
 type UserServiceConfig struct {
-	BrokerManager       *broker.BrokerManager
-	PortfolioRepository PortfolioRepository
+	BrokerManager             *broker.BrokerManager
+	PortfolioRepository       PortfolioRepository
+	TradeRepository           TradeRepository
+	DividendRepository        DividendRepository
+	CorporateActionRepository CorporateActionRepository
+	SnapshotRepository        SnapshotRepository
+	HoldingChangeRepository   HoldingChangeRepository
+
+	// Instruments resolves holding and trade symbols to ISINs when brokers omit them (optional)
+	Instruments InstrumentResolver
+
+	// NAVs values mutual fund holdings at their latest published NAV (optional)
+	NAVs NAVSource
+
+	// Prices values holdings imported from statements at the latest exchange close (optional)
+	Prices PriceProvider
+
+	// PriceChangeThreshold is the price move in percent reported as a change on refresh (default: DefaultPriceChangeThreshold)
+	PriceChangeThreshold float64
 }
 
 // UserService manages portfolios for specific users
 type UserService struct {
-	brokerManager       *broker.BrokerManager
-	portfolioRepository PortfolioRepository
+	brokerManager             *broker.BrokerManager
+	portfolioRepository       PortfolioRepository
+	tradeRepository           TradeRepository
+	dividendRepository        DividendRepository
+	corporateActionRepository CorporateActionRepository
+	snapshotRepository        SnapshotRepository
+	holdingChangeRepository   HoldingChangeRepository
+	priceChangeThreshold      float64
+	instruments               InstrumentResolver
+	navs                      NAVSource
+	prices                    PriceProvider
 }
 
 // NewUserService creates a new user-specific portfolio service
 func NewUserService(config UserServiceConfig) *UserService {
+	if config.PriceChangeThreshold <= 0 {
+		config.PriceChangeThreshold = DefaultPriceChangeThreshold
+	}
+
 	return &UserService{
-		brokerManager:       config.BrokerManager,
-		portfolioRepository: config.PortfolioRepository,
+		brokerManager:             config.BrokerManager,
+		portfolioRepository:       config.PortfolioRepository,
+		tradeRepository:           config.TradeRepository,
+		dividendRepository:        config.DividendRepository,
+		corporateActionRepository: config.CorporateActionRepository,
+		snapshotRepository:        config.SnapshotRepository,
+		holdingChangeRepository:   config.HoldingChangeRepository,
+		priceChangeThreshold:      config.PriceChangeThreshold,
+		instruments:               config.Instruments,
+		navs:                      config.NAVs,
+		prices:                    config.Prices,
 	}
 }
 
@@ -85,53 +134,115 @@ func (s *UserService) GetPortfolio(ctx context.Context, userID string, holdingTy
 	return portfolio, nil
 }
 
-// RefreshPortfolio updates the portfolio for a specific user
-func (s *UserService) RefreshPortfolio(ctx context.Context, userID string) error {
+// GetFunds fetches the cash and margin available at each connected broker that reports funds.
+// A broker whose funds cannot be fetched fails the request rather than being reported as holding no cash.
+func (s *UserService) GetFunds(ctx context.Context, userID string) ([]models.Funds, error) {
+	funds := []models.Funds{}
+	for _, spec := range s.brokerManager.SupportedBrokers() {
+		if !spec.Capabilities.Funds {
+			continue
+		}
+		client, exists := s.brokerManager.GetClient(userID, spec.Name)
+		if !exists {
+			continue
+		}
+		provider, ok := client.(types.FundsProvider)
+		if !ok {
+			continue
+		}
+		brokerFunds, err := provider.GetFunds(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch funds from %s: %w", spec.Name, err)
+		}
+		brokerFunds.Platform = spec.Name
+		funds = append(funds, *brokerFunds)
+	}
+	return funds, nil
+}
+
+// RefreshPortfolio updates the portfolio for a specific user and returns how the holdings changed since the last snapshot
+func (s *UserService) RefreshPortfolio(ctx context.Context, userID string) ([]models.HoldingChange, error) {
+	return s.refresh(ctx, userID, models.SnapshotSourceRefresh)
+}
+
+// refresh fetches the holdings of a user from every source, stores the merged portfolio,
+// appends a snapshot recorded as coming from the given source and records the changes since the previous snapshot.
+// A broker whose holdings cannot be fetched, such as one with an expired session, keeps its holdings from the previous
+// snapshot; without a previous snapshot, or for an end-of-day snapshot that must reflect closing values, the refresh fails.
+func (s *UserService) refresh(ctx context.Context, userID string, source models.SnapshotSource) ([]models.HoldingChange, error) {
 	allHoldings := []models.Holding{}
+	unfetched := map[string]bool{}
 
-	// Get zerodha client if available
-	if zerodhaClient, exists := s.brokerManager.GetClient(userID, broker.ClientTypeZerodha); exists {
-		zerodhaHoldings, err := zerodhaClient.GetHoldings(ctx)
-		if err == nil {
-			// Update platform info
-			for i := range zerodhaHoldings {
-				zerodhaHoldings[i].Platform = models.PlatformZerodha
-				zerodhaHoldings[i].LastUpdated = time.Now()
+	// Load the previous snapshot to carry forward unreachable brokers and diff against before appending the new one
+	previousHoldings, hasPrevious, err := s.snapshotRepository.GetLatestSnapshotHoldings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect holdings from every connected broker
+	for _, spec := range s.brokerManager.SupportedBrokers() {
+		client, exists := s.brokerManager.GetClient(userID, spec.Name)
+		if !exists {
+			continue
+		}
+		brokerHoldings, err := fetchBrokerHoldings(ctx, client, spec)
+		if err != nil {
+			if !hasPrevious || source == models.SnapshotSourceEndOfDay {
+				return nil, err
 			}
-			allHoldings = append(allHoldings, zerodhaHoldings...)
+			log.Printf("Keeping previous %s holdings for user %s: %v", spec.Name, userID, err)
+			unfetched[spec.Name] = true
+			allHoldings = append(allHoldings, platformHoldings(previousHoldings, spec.Name)...)
+			continue
 		}
+		s.canonicalizeHoldings(brokerHoldings)
+		s.classifyHoldings(brokerHoldings)
+		allHoldings = append(allHoldings, brokerHoldings...)
 
-		zerodhaPositions, err := zerodhaClient.GetPositions(ctx)
-		if err == nil {
-			// Update platform info
-			for i := range zerodhaPositions {
-				zerodhaPositions[i].Platform = models.PlatformZerodha
-				zerodhaPositions[i].LastUpdated = time.Now()
+		// Record the broker's recent trades so cost basis stays current between tradebook imports
+		if spec.Capabilities.Trades {
+			if err := s.syncBrokerTrades(ctx, userID, client, brokerHoldings); err != nil {
+				return nil, err
 			}
-			allHoldings = append(allHoldings, zerodhaPositions...)
 		}
 	}
 
-	// Get ICICI Direct client if available
-	if iciciClient, exists := s.brokerManager.GetClient(userID, broker.ClientTypeICICIDirect); exists {
-		iciciHoldings, err := iciciClient.GetHoldings(ctx)
-		if err == nil {
-			// Update platform info
-			for i := range iciciHoldings {
-				iciciHoldings[i].Platform = models.PlatformICICIDirect
-				iciciHoldings[i].LastUpdated = time.Now()
-			}
-			allHoldings = append(allHoldings, iciciHoldings...)
-		}
+	// Add holdings imported from statements
+	importedHoldings, err := s.portfolioRepository.GetImportedHoldings(userID)
+	if err != nil {
+		return nil, err
+	}
+	s.canonicalizeHoldings(importedHoldings)
+	s.classifyHoldings(importedHoldings)
+	if err := s.revalueFromClosingPrices(importedHoldings, time.Now()); err != nil {
+		return nil, err
+	}
+	allHoldings = append(allHoldings, withoutLiveHoldings(importedHoldings, allHoldings)...)
 
-		iciciPositions, err := iciciClient.GetPositions(ctx)
-		if err == nil {
-			// Update platform info
-			for i := range iciciPositions {
-				iciciPositions[i].Platform = models.PlatformICICIDirect
-				iciciPositions[i].LastUpdated = time.Now()
+	// Value mutual funds at their latest NAV, which statements and brokers may report stale
+	if err := s.revalueMutualFunds(allHoldings); err != nil {
+		return nil, err
+	}
+
+	// Replace broker averages with cost basis from trade history where the history explains the holding
+	if err := s.applyCostBasis(userID, allHoldings); err != nil {
+		return nil, err
+	}
+
+	// Append the per-platform valuation to the snapshot history
+	snapshot := newSnapshot(time.Now(), source, allHoldings)
+	if err := s.snapshotRepository.SaveSnapshot(userID, snapshot, allHoldings); err != nil {
+		return nil, err
+	}
+
+	// Record what changed; the first snapshot of a user has nothing to compare with
+	changes := []models.HoldingChange{}
+	if hasPrevious {
+		changes = diffHoldings(previousHoldings, allHoldings, unfetched, s.priceChangeThreshold, snapshot.TakenAt)
+		if len(changes) > 0 {
+			if err := s.holdingChangeRepository.SaveHoldingChanges(userID, snapshot.ID, changes); err != nil {
+				return nil, err
 			}
-			allHoldings = append(allHoldings, iciciPositions...)
 		}
 	}
 
@@ -140,12 +251,232 @@ func (s *UserService) RefreshPortfolio(ctx context.Context, userID string) error
 
 	// Save to database
 	if err := s.portfolioRepository.SaveHoldings(userID, mergedHoldings); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// ImportCAS parses a password-protected CAS PDF, stores its holdings under the cas platform and refreshes the portfolio
+func (s *UserService) ImportCAS(ctx context.Context, userID string, r io.ReaderAt, size int64, password string) (*cas.Statement, error) {
+	statement, err := cas.Parse(r, size, password)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.portfolioRepository.SaveImportedHoldings(userID, models.PlatformCAS, statement.Holdings); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.RefreshPortfolio(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return statement, nil
+}
+
+// ImportTradebook parses a broker tradebook CSV and stores its trades.
+// Trades already imported are skipped, so uploading the same or an overlapping file again is safe.
+func (s *UserService) ImportTradebook(ctx context.Context, userID string, r io.Reader) (*models.TradeImportResult, error) {
+	book, err := tradebook.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	s.canonicalizeTrades(book.Trades)
+	imported, err := s.tradeRepository.SaveTrades(userID, book.Trades)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TradeImportResult{
+		Platform:   book.Platform,
+		Total:      len(book.Trades),
+		Imported:   imported,
+		Duplicates: len(book.Trades) - imported,
+	}, nil
+}
+
+// ImportDividends parses a dividend statement CSV and stores its dividends so returns include them.
+// Dividends already imported are replaced, so uploading the same or an overlapping statement again is safe.
+func (s *UserService) ImportDividends(ctx context.Context, userID string, r io.Reader) (*models.DividendImportResult, error) {
+	dividends, err := dividend.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.DividendImportResult{Total: len(dividends)}
+	for _, d := range dividends {
+		if d.ISIN == "" && s.instruments != nil {
+			if instrument, ok := s.instruments.Lookup(d.Symbol); ok {
+				d.ISIN = instrument.ISIN
+			}
+		}
+		if d.ISIN != "" {
+			result.Resolved++
+		}
+		if err := s.dividendRepository.SaveDividend(userID, d); err != nil {
+			return nil, fmt.Errorf("failed to save dividend for %s: %w", d.Symbol, err)
+		}
+		result.Amount += d.Amount
+	}
+
+	return result, nil
+}
+
+// syncBrokerTrades stores the trades reported by a broker, resolving their ISINs from the broker's holdings
+func (s *UserService) syncBrokerTrades(ctx context.Context, userID string, client types.Client, holdings []models.Holding) error {
+	provider, ok := client.(types.TradesProvider)
+	if !ok {
+		return nil
+	}
+
+	trades, err := provider.GetTrades(ctx)
+	if err != nil || len(trades) == 0 {
+		return nil
+	}
+
+	fillTradeISINs(trades, holdings)
+	s.canonicalizeTrades(trades)
+	_, err = s.tradeRepository.SaveTrades(userID, trades)
+	return err
+}
+
+// applyCostBasis sets the average price and P&L of holdings from FIFO lots built from the user's trade history.
+// Holdings are only updated when the open lots account for the full quantity held.
+func (s *UserService) applyCostBasis(userID string, holdings []models.Holding) error {
+	trades, err := s.tradeRepository.GetTrades(userID)
+	if err != nil {
+		return err
+	}
+	if len(trades) == 0 {
+		return nil
+	}
+
+	fillTradeISINs(trades, holdings)
+
+	actions, err := s.corporateActionRepository.GetCorporateActions(tradeISINs(trades), time.Now())
+	if err != nil {
 		return err
 	}
 
+	book := costbasis.Build(trades, actions)
+	for i := range holdings {
+		holding := &holdings[i]
+		position, ok := book.Position(holding.Platform, holding.ISIN, holding.ItemName)
+		if !ok || !position.Complete() || math.Abs(position.Quantity()-holding.Quantity) > quantityTolerance {
+			continue
+		}
+		holding.AveragePrice = position.AverageCost()
+		holding.TotalPnL = holding.CurrentValue - position.CostBasis()
+	}
+
 	return nil
 }
 
+// quantityTolerance is the largest difference between lot and holding quantities treated as equal
+const quantityTolerance = 1e-6
+
+// fillTradeISINs sets missing trade ISINs by matching trade symbols to holdings at the same platform
+func fillTradeISINs(trades []models.Trade, holdings []models.Holding) {
+	isinBySymbol := make(map[string]string)
+	for _, holding := range holdings {
+		if holding.ISIN != "" {
+			isinBySymbol[holding.Platform+"|"+strings.ToUpper(holding.ItemName)] = holding.ISIN
+		}
+	}
+
+	for i := range trades {
+		if trades[i].ISIN != "" {
+			continue
+		}
+		if isin, ok := isinBySymbol[trades[i].Platform+"|"+strings.ToUpper(trades[i].Symbol)]; ok {
+			trades[i].ISIN = isin
+		}
+	}
+}
+
+// tradeISINs returns the distinct ISINs traded
+func tradeISINs(trades []models.Trade) []string {
+	seen := make(map[string]bool)
+	var isins []string
+	for _, trade := range trades {
+		if trade.ISIN != "" && !seen[trade.ISIN] {
+			seen[trade.ISIN] = true
+			isins = append(isins, trade.ISIN)
+		}
+	}
+	return isins
+}
+
+// withoutLiveHoldings drops imported holdings for ISINs a connected broker already reports.
+// A CAS covers every demat account, so holdings at connected brokers would otherwise be counted twice.
+func withoutLiveHoldings(imported []models.Holding, live []models.Holding) []models.Holding {
+	liveISINs := make(map[string]bool)
+	for _, holding := range live {
+		if holding.ISIN != "" {
+			liveISINs[holding.ISIN] = true
+		}
+	}
+
+	var holdings []models.Holding
+	for _, holding := range imported {
+		if holding.ISIN != "" && liveISINs[holding.ISIN] {
+			continue
+		}
+		holdings = append(holdings, holding)
+	}
+	return holdings
+}
+
+// fetchBrokerHoldings fetches holdings, and positions where supported, from a single broker client.
+// A failure of any of the calls fails the fetch, so a partial result is never mistaken for the broker's full holdings.
+func fetchBrokerHoldings(ctx context.Context, client types.Client, spec types.BrokerSpec) ([]models.Holding, error) {
+	var holdings []models.Holding
+
+	brokerHoldings, err := client.GetHoldings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch holdings from %s: %w", spec.Name, err)
+	}
+	holdings = append(holdings, brokerHoldings...)
+
+	if spec.Capabilities.Positions {
+		brokerPositions, err := client.GetPositions(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch positions from %s: %w", spec.Name, err)
+		}
+		holdings = append(holdings, brokerPositions...)
+	}
+
+	// Mutual funds some brokers keep apart from equity holdings; units already reported as holdings are not counted twice
+	if provider, ok := client.(types.MutualFundsProvider); ok && spec.Capabilities.MutualFunds {
+		fundHoldings, err := provider.GetMFHoldings(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch mutual fund holdings from %s: %w", spec.Name, err)
+		}
+		holdings = append(holdings, withoutLiveHoldings(fundHoldings, holdings)...)
+	}
+
+	// Update platform info
+	for i := range holdings {
+		holdings[i].Platform = spec.Name
+		holdings[i].LastUpdated = time.Now()
+	}
+
+	return holdings, nil
+}
+
+// platformHoldings returns the holdings held at a platform
+func platformHoldings(holdings []models.Holding, platform string) []models.Holding {
+	var held []models.Holding
+	for _, holding := range holdings {
+		if holding.Platform == platform {
+			held = append(held, holding)
+		}
+	}
+	return held
+}
+
 // Helper function to merge holdings with the same ISIN
 func mergeHoldings(holdings []models.Holding) []models.Holding {
 	// Group by ISIN
@@ -177,16 +508,26 @@ func mergeHoldings(holdings []models.Holding) []models.Holding {
 			LastUpdated: time.Now(),
 		}
 
+		costBasis := 0.0
+		costKnown := true
 		for _, h := range holdingsGroup {
 			merged.Quantity += h.Quantity
 			merged.CurrentValue += h.CurrentValue
 			merged.DayChange += h.DayChange
 			merged.TotalPnL += h.TotalPnL
+			costBasis += h.AveragePrice * h.Quantity
+			if h.AveragePrice <= 0 && h.Quantity > 0 {
+				costKnown = false
+			}
 		}
 
-		// Recalculate average price and day change percent
+		// Recalculate average cost, market price and day change percent. Units without a cost, such as those
+		// imported from a CAS, would dilute the average, so it is left unset unless every part has one.
 		if merged.Quantity > 0 {
-			merged.AveragePrice = merged.CurrentValue / merged.Quantity
+			if costKnown {
+				merged.AveragePrice = costBasis / merged.Quantity
+			}
+			merged.LastTradedPrice = merged.CurrentValue / merged.Quantity
 		}
 		if merged.CurrentValue > 0 {
 			merged.DayChangePercent = (merged.DayChange / (merged.CurrentValue - merged.DayChange)) * 100
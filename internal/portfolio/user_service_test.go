@@ -0,0 +1,113 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Kora1128/FinSight/internal/broker/types"
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeFundsClient is a broker client reporting fixed equity and mutual fund holdings
+type fakeFundsClient struct {
+	holdings     []models.Holding
+	fundHoldings []models.Holding
+	fundsErr     error
+}
+
+func (c *fakeFundsClient) GetHoldings(ctx context.Context) ([]models.Holding, error) {
+	return c.holdings, nil
+}
+
+func (c *fakeFundsClient) GetPositions(ctx context.Context) ([]models.Holding, error) {
+	return nil, nil
+}
+
+func (c *fakeFundsClient) GetMFHoldings(ctx context.Context) ([]models.Holding, error) {
+	return c.fundHoldings, c.fundsErr
+}
+
+func (c *fakeFundsClient) Login() error                      { return nil }
+func (c *fakeFundsClient) CanAutoRefresh() bool              { return false }
+func (c *fakeFundsClient) RefreshToken() error               { return nil }
+func (c *fakeFundsClient) GetAccessToken() string            { return "" }
+func (c *fakeFundsClient) SetAccessToken(accessToken string) {}
+
+func TestFetchBrokerHoldingsMutualFunds(t *testing.T) {
+	client := &fakeFundsClient{
+		holdings: []models.Holding{
+			{ItemName: "INFY", ISIN: "INE009A01021", Type: models.HoldingTypeStock},
+			{ItemName: "GOLDBEES", ISIN: "INF204KB17I5", Type: models.HoldingTypeETF},
+		},
+		fundHoldings: []models.Holding{
+			{ItemName: "HDFC Large Cap Fund", ISIN: "INF179K01YV8", Type: models.HoldingTypeMutualFund},
+			// Demat fund units can also be reported as equity holdings
+			{ItemName: "GOLDBEES", ISIN: "INF204KB17I5", Type: models.HoldingTypeMutualFund},
+		},
+	}
+
+	spec := types.BrokerSpec{Name: models.PlatformZerodha, Capabilities: types.Capabilities{MutualFunds: true}}
+	holdings, err := fetchBrokerHoldings(context.Background(), client, spec)
+	assert.NoError(t, err)
+	assert.Len(t, holdings, 3)
+	assert.Equal(t, "INF179K01YV8", holdings[2].ISIN)
+	assert.Equal(t, models.HoldingTypeMutualFund, holdings[2].Type)
+	for _, holding := range holdings {
+		assert.Equal(t, models.PlatformZerodha, holding.Platform)
+	}
+
+	// Mutual funds are only fetched from brokers declaring the capability
+	spec.Capabilities.MutualFunds = false
+	holdings, err = fetchBrokerHoldings(context.Background(), client, spec)
+	assert.NoError(t, err)
+	assert.Len(t, holdings, 2)
+}
+
+func TestFetchBrokerHoldingsError(t *testing.T) {
+	client := &fakeFundsClient{
+		holdings: []models.Holding{{ItemName: "INFY", ISIN: "INE009A01021", Type: models.HoldingTypeStock}},
+		fundsErr: errors.New("token expired"),
+	}
+
+	// A partial fetch fails rather than reporting the broker's funds as sold
+	spec := types.BrokerSpec{Name: models.PlatformZerodha, Capabilities: types.Capabilities{MutualFunds: true}}
+	holdings, err := fetchBrokerHoldings(context.Background(), client, spec)
+	assert.Error(t, err)
+	assert.Nil(t, holdings)
+}
+
+func TestPlatformHoldings(t *testing.T) {
+	holdings := []models.Holding{
+		{ItemName: "INFY", Platform: models.PlatformZerodha},
+		{ItemName: "TCS", Platform: models.PlatformUpstox},
+		{ItemName: "ITC", Platform: models.PlatformZerodha},
+	}
+	held := platformHoldings(holdings, models.PlatformZerodha)
+	if assert.Len(t, held, 2) {
+		assert.Equal(t, "ITC", held[1].ItemName)
+	}
+	assert.Empty(t, platformHoldings(holdings, models.PlatformAngelOne))
+}
+
+func TestMergeHoldingsAveragePrice(t *testing.T) {
+	merged := mergeHoldings([]models.Holding{
+		{ItemName: "INFY", ISIN: "INE009A01021", Quantity: 10, AveragePrice: 1400, CurrentValue: 15000},
+		{ItemName: "INFY", ISIN: "INE009A01021", Quantity: 10, AveragePrice: 1600, CurrentValue: 15000},
+	})
+	if assert.Len(t, merged, 1) {
+		assert.Equal(t, 1500.0, merged[0].AveragePrice)
+		assert.Equal(t, 1500.0, merged[0].LastTradedPrice)
+	}
+
+	// Units without a cost leave the average unset rather than diluting it
+	merged = mergeHoldings([]models.Holding{
+		{ItemName: "INFY", ISIN: "INE009A01021", Quantity: 10, AveragePrice: 1400, CurrentValue: 15000},
+		{ItemName: "INFY", ISIN: "INE009A01021", Quantity: 10, CurrentValue: 15000},
+	})
+	if assert.Len(t, merged, 1) {
+		assert.Equal(t, 0.0, merged[0].AveragePrice)
+		assert.Equal(t, 20.0, merged[0].Quantity)
+	}
+}
@@ -0,0 +1,235 @@
+package rebalance
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// DefaultMinTradeValue is the smallest trade value a plan includes unless the request sets another
+const DefaultMinTradeValue = 500.0
+
+// weightTolerance is the slack allowed when checking that target weights add up to 100
+const weightTolerance = 0.01
+
+// Common rebalancing errors
+var (
+	ErrNoTargets           = errors.New("no allocation targets set")
+	ErrTargetWeights       = errors.New("target weights must add up to 100")
+	ErrTargetWeightsExceed = errors.New("target weights of a kind cannot add up to more than 100")
+	ErrInvalidTarget       = errors.New("invalid allocation target")
+	ErrTargetNotFound      = errors.New("allocation target not found")
+	ErrNothingToRebalance  = errors.New("portfolio has no holdings or new money to rebalance")
+)
+
+// Classifier classifies holdings for asset class targets
+type Classifier interface {
+	// Classify returns the classification of a holding
+	Classify(holding models.Holding) models.InstrumentClassification
+}
+
+// position is a holding that can be traded, with the bucket it counts towards
+type position struct {
+	holding models.Holding
+	bucket  string
+	price   float64
+}
+
+// bucket collects the positions counting towards one target key
+type bucket struct {
+	drift     models.TargetDrift
+	positions []*position
+	change    float64
+}
+
+// Plan computes the drift of holdings from targets of a single kind and the trades that correct it.
+// In full mode every bucket is bought or sold to its target value; in new money only mode nothing is sold
+// and new money goes to underweight buckets in proportion to their shortfall. Amounts are split across the
+// holdings of a bucket by current value, rounded down to whole shares (or thousandths of a mutual fund unit),
+// and trades below the minimum value are dropped. Buys are capped by the cash that new money and sales provide.
+// For asset class targets, holdings whose asset class is unknown are left out of the total and the trades and
+// reported in the plan's warnings, rather than being sold off against a target they cannot have.
+func Plan(holdings []models.Holding, targets []models.AllocationTarget, classifier Classifier, req models.RebalancePlanRequest) (*models.RebalancePlan, error) {
+	if len(targets) == 0 {
+		return nil, ErrNoTargets
+	}
+	kind := targets[0].Kind
+	totalWeight := 0.0
+	for _, target := range targets {
+		totalWeight += target.Weight
+	}
+	if math.Abs(totalWeight-100) > weightTolerance {
+		return nil, ErrTargetWeights
+	}
+	if req.Mode == "" {
+		req.Mode = models.RebalanceModeFull
+	}
+	if req.MinTradeValue == 0 {
+		req.MinTradeValue = DefaultMinTradeValue
+	}
+
+	// Group tradable holdings into buckets by target key
+	buckets := make(map[string]*bucket)
+	bucketFor := func(key string) *bucket {
+		b, ok := buckets[key]
+		if !ok {
+			b = &bucket{drift: models.TargetDrift{Key: key}}
+			buckets[key] = b
+		}
+		return b
+	}
+	total := req.NewMoney
+	warnings := []string{}
+	for _, holding := range holdings {
+		price := holding.LastTradedPrice
+		if price <= 0 && holding.Quantity > 0 {
+			price = holding.CurrentValue / holding.Quantity
+		}
+		if holding.CurrentValue <= 0 || price <= 0 {
+			continue
+		}
+
+		key := holding.ISIN
+		if kind == models.TargetKindAssetClass {
+			class := classifier.Classify(holding).AssetClass
+			if class == "" || class == models.AllocationClassUnclassified {
+				warnings = append(warnings, fmt.Sprintf("%s (%s) worth %.2f is left out because its asset class is unknown",
+					holding.ItemName, holding.Platform, holding.CurrentValue))
+				continue
+			}
+			key = string(class)
+		} else if key == "" {
+			key = holding.ItemName
+		}
+
+		b := bucketFor(key)
+		b.positions = append(b.positions, &position{holding: holding, bucket: key, price: price})
+		b.drift.CurrentValue += holding.CurrentValue
+		if kind == models.TargetKindHolding && b.drift.Name == "" {
+			b.drift.Name = holding.ItemName
+		}
+		total += holding.CurrentValue
+	}
+	if total <= 0 {
+		return nil, ErrNothingToRebalance
+	}
+	for _, target := range targets {
+		bucketFor(target.Key).drift.TargetWeight += target.Weight
+	}
+
+	// Measure drift and the change each bucket needs
+	ordered := make([]*bucket, 0, len(buckets))
+	shortfall := 0.0
+	for _, b := range buckets {
+		b.drift.CurrentWeight = b.drift.CurrentValue / total * 100
+		b.drift.TargetValue = b.drift.TargetWeight / 100 * total
+		b.drift.Drift = b.drift.CurrentWeight - b.drift.TargetWeight
+		b.change = b.drift.TargetValue - b.drift.CurrentValue
+		if b.change > 0 {
+			shortfall += b.change
+		}
+		ordered = append(ordered, b)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].drift.TargetWeight != ordered[j].drift.TargetWeight {
+			return ordered[i].drift.TargetWeight > ordered[j].drift.TargetWeight
+		}
+		return ordered[i].drift.Key < ordered[j].drift.Key
+	})
+	if req.Mode == models.RebalanceModeNewMoneyOnly {
+		scale := 1.0
+		if shortfall > req.NewMoney {
+			scale = req.NewMoney / shortfall
+		}
+		for _, b := range ordered {
+			b.change = math.Max(b.change, 0) * scale
+		}
+	}
+
+	plan := &models.RebalancePlan{
+		Kind:          kind,
+		Mode:          req.Mode,
+		TotalValue:    total,
+		NewMoney:      req.NewMoney,
+		MinTradeValue: req.MinTradeValue,
+		Drift:         make([]models.TargetDrift, 0, len(ordered)),
+		Trades:        []models.RebalanceTrade{},
+		Unplaced:      []models.TargetDrift{},
+		Warnings:      warnings,
+	}
+	for _, b := range ordered {
+		plan.Drift = append(plan.Drift, b.drift)
+	}
+
+	// Sell first so proceeds can fund the buys
+	cash := req.NewMoney
+	for _, b := range ordered {
+		if b.change >= 0 {
+			continue
+		}
+		for _, p := range b.positions {
+			amount := -b.change * p.holding.CurrentValue / b.drift.CurrentValue
+			quantity := math.Min(roundQuantity(amount/p.price, p.holding.Type), p.holding.Quantity)
+			if trade, ok := newTrade(p, models.TradeSideSell, quantity, req.MinTradeValue); ok {
+				plan.Trades = append(plan.Trades, trade)
+				cash += trade.Value
+			}
+		}
+	}
+
+	// Buy the most underweight buckets first while cash lasts
+	buying := make([]*bucket, 0, len(ordered))
+	for _, b := range ordered {
+		if b.change > 0 {
+			buying = append(buying, b)
+		}
+	}
+	sort.SliceStable(buying, func(i, j int) bool {
+		return buying[i].change > buying[j].change
+	})
+	for _, b := range buying {
+		if len(b.positions) == 0 {
+			plan.Unplaced = append(plan.Unplaced, b.drift)
+			continue
+		}
+		for _, p := range b.positions {
+			amount := math.Min(b.change*p.holding.CurrentValue/b.drift.CurrentValue, cash)
+			quantity := roundQuantity(amount/p.price, p.holding.Type)
+			if trade, ok := newTrade(p, models.TradeSideBuy, quantity, req.MinTradeValue); ok {
+				plan.Trades = append(plan.Trades, trade)
+				cash -= trade.Value
+			}
+		}
+	}
+	plan.CashRemaining = cash
+
+	return plan, nil
+}
+
+// newTrade builds a trade for a position, reporting false when it is below the minimum trade value
+func newTrade(p *position, side models.TradeSide, quantity, minTradeValue float64) (models.RebalanceTrade, bool) {
+	value := quantity * p.price
+	if quantity <= 0 || value < minTradeValue {
+		return models.RebalanceTrade{}, false
+	}
+	return models.RebalanceTrade{
+		ItemName: p.holding.ItemName,
+		ISIN:     p.holding.ISIN,
+		Platform: p.holding.Platform,
+		Side:     side,
+		Quantity: quantity,
+		Price:    p.price,
+		Value:    value,
+	}, true
+}
+
+// roundQuantity rounds a quantity down to what can be traded: whole shares, or thousandths of a mutual fund unit
+func roundQuantity(quantity float64, holdingType models.HoldingType) float64 {
+	if holdingType == models.HoldingTypeMutualFund {
+		return math.Floor(quantity*1000+1e-9) / 1000
+	}
+	return math.Floor(quantity + 1e-9)
+}
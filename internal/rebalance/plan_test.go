@@ -0,0 +1,254 @@
+package rebalance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// classByISIN is a classifier backed by a fixed ISIN to asset class map
+type classByISIN map[string]models.AllocationClass
+
+func (c classByISIN) Classify(holding models.Holding) models.InstrumentClassification {
+	return models.InstrumentClassification{AssetClass: c[holding.ISIN]}
+}
+
+func stock(name, isin string, quantity, price float64) models.Holding {
+	return models.Holding{
+		ItemName:        name,
+		ISIN:            isin,
+		Quantity:        quantity,
+		LastTradedPrice: price,
+		CurrentValue:    quantity * price,
+		Platform:        models.PlatformZerodha,
+		Type:            models.HoldingTypeStock,
+	}
+}
+
+var testClasses = classByISIN{
+	"INE009A01021": models.AllocationClassEquity,
+	"INE040A01034": models.AllocationClassEquity,
+	"INF000DEBT01": models.AllocationClassDebt,
+	"INF204KB17I5": models.AllocationClassGold,
+}
+
+func assetClassTargets(equity, debt, gold float64) []models.AllocationTarget {
+	return []models.AllocationTarget{
+		{Kind: models.TargetKindAssetClass, Key: "equity", Weight: equity},
+		{Kind: models.TargetKindAssetClass, Key: "debt", Weight: debt},
+		{Kind: models.TargetKindAssetClass, Key: "gold", Weight: gold},
+	}
+}
+
+func tradesByName(plan *models.RebalancePlan) map[string]models.RebalanceTrade {
+	trades := make(map[string]models.RebalanceTrade)
+	for _, trade := range plan.Trades {
+		trades[trade.ItemName] = trade
+	}
+	return trades
+}
+
+func TestPlanFull(t *testing.T) {
+	// 80% equity, 20% debt, no gold against a 60/30/10 target
+	holdings := []models.Holding{
+		stock("INFY", "INE009A01021", 30, 100),
+		stock("HDFCBANK", "INE040A01034", 50, 100),
+		{ItemName: "Debt Fund", ISIN: "INF000DEBT01", Quantity: 200, LastTradedPrice: 10, CurrentValue: 2000, Platform: models.PlatformCAS, Type: models.HoldingTypeMutualFund},
+	}
+
+	plan, err := Plan(holdings, assetClassTargets(60, 30, 10), testClasses, models.RebalancePlanRequest{MinTradeValue: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, models.RebalanceModeFull, plan.Mode)
+	assert.Equal(t, 10000.0, plan.TotalValue)
+
+	assert.Len(t, plan.Drift, 3)
+	assert.Equal(t, "equity", plan.Drift[0].Key)
+	assert.InDelta(t, 20.0, plan.Drift[0].Drift, 1e-9)
+	assert.InDelta(t, 6000.0, plan.Drift[0].TargetValue, 1e-9)
+	assert.InDelta(t, -10.0, plan.Drift[2].Drift, 1e-9)
+
+	// Equity is cut by 2000 pro rata to holding value, debt is topped up by 1000
+	trades := tradesByName(plan)
+	assert.Equal(t, models.TradeSideSell, trades["INFY"].Side)
+	assert.Equal(t, 7.0, trades["INFY"].Quantity)
+	assert.Equal(t, models.TradeSideSell, trades["HDFCBANK"].Side)
+	assert.Equal(t, 12.0, trades["HDFCBANK"].Quantity)
+	assert.Equal(t, models.TradeSideBuy, trades["Debt Fund"].Side)
+	assert.InDelta(t, 100.0, trades["Debt Fund"].Quantity, 1e-9)
+
+	// Gold has no holding to buy into
+	assert.Len(t, plan.Unplaced, 1)
+	assert.Equal(t, "gold", plan.Unplaced[0].Key)
+	assert.InDelta(t, 900.0, plan.CashRemaining, 1e-9)
+}
+
+func TestPlanNewMoneyOnly(t *testing.T) {
+	holdings := []models.Holding{
+		stock("INFY", "INE009A01021", 70, 100),
+		stock("GOLDBEES", "INF204KB17I5", 100, 10),
+		{ItemName: "Debt Fund", ISIN: "INF000DEBT01", Quantity: 200, LastTradedPrice: 10, CurrentValue: 2000, Platform: models.PlatformCAS, Type: models.HoldingTypeMutualFund},
+	}
+
+	// Total with new money is 12000: debt is 1600 short, equity and gold 200 short each
+	plan, err := Plan(holdings, assetClassTargets(60, 30, 10), testClasses, models.RebalancePlanRequest{
+		Mode:     models.RebalanceModeNewMoneyOnly,
+		NewMoney: 2000,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultMinTradeValue, plan.MinTradeValue)
+
+	// The equity and gold top-ups are below the minimum trade value
+	assert.Len(t, plan.Trades, 1)
+	assert.Equal(t, "Debt Fund", plan.Trades[0].ItemName)
+	assert.Equal(t, models.TradeSideBuy, plan.Trades[0].Side)
+	assert.InDelta(t, 160.0, plan.Trades[0].Quantity, 1e-9)
+	assert.InDelta(t, 400.0, plan.CashRemaining, 1e-9)
+
+	// With a lower minimum trade value every shortfall is bought
+	plan, err = Plan(holdings, assetClassTargets(60, 30, 10), testClasses, models.RebalancePlanRequest{
+		Mode:          models.RebalanceModeNewMoneyOnly,
+		NewMoney:      2000,
+		MinTradeValue: 1,
+	})
+	assert.NoError(t, err)
+	trades := tradesByName(plan)
+	assert.Len(t, trades, 3)
+	assert.Equal(t, 2.0, trades["INFY"].Quantity)
+	assert.Equal(t, 20.0, trades["GOLDBEES"].Quantity)
+	assert.InDelta(t, 0.0, plan.CashRemaining, 1e-9)
+}
+
+func TestPlanUnclassified(t *testing.T) {
+	holdings := []models.Holding{
+		stock("INFY", "INE009A01021", 60, 100),
+		{ItemName: "Debt Fund", ISIN: "INF000DEBT01", Quantity: 400, LastTradedPrice: 10, CurrentValue: 4000, Platform: models.PlatformCAS, Type: models.HoldingTypeMutualFund},
+		{ItemName: "Unknown Fund", ISIN: "INF999Z01011", Quantity: 100, LastTradedPrice: 50, CurrentValue: 5000, Platform: models.PlatformCAS, Type: models.HoldingTypeMutualFund},
+	}
+
+	// The unknown fund is neither counted nor sold; the rest already matches a 60/40 target
+	plan, err := Plan(holdings, assetClassTargets(60, 40, 0), testClasses, models.RebalancePlanRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, 10000.0, plan.TotalValue)
+	assert.Empty(t, plan.Trades)
+	assert.Len(t, plan.Drift, 3)
+	for _, drift := range plan.Drift {
+		assert.NotEqual(t, string(models.AllocationClassUnclassified), drift.Key)
+	}
+	if assert.Len(t, plan.Warnings, 1) {
+		assert.Contains(t, plan.Warnings[0], "Unknown Fund")
+	}
+}
+
+func TestPlanHoldingTargets(t *testing.T) {
+	holdings := []models.Holding{
+		stock("INFY", "INE009A01021", 10, 1500),
+		stock("HDFCBANK", "INE040A01034", 10, 1500),
+	}
+	targets := []models.AllocationTarget{
+		{Kind: models.TargetKindHolding, Key: "INE009A01021", Weight: 75},
+		{Kind: models.TargetKindHolding, Key: "INE040A01034", Weight: 25},
+	}
+
+	plan, err := Plan(holdings, targets, nil, models.RebalancePlanRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, models.TargetKindHolding, plan.Kind)
+	assert.Equal(t, "INFY", plan.Drift[0].Name)
+
+	trades := tradesByName(plan)
+	assert.Equal(t, 5.0, trades["HDFCBANK"].Quantity)
+	assert.Equal(t, models.TradeSideSell, trades["HDFCBANK"].Side)
+	assert.Equal(t, 5.0, trades["INFY"].Quantity)
+	assert.Equal(t, models.TradeSideBuy, trades["INFY"].Side)
+	assert.InDelta(t, 0.0, plan.CashRemaining, 1e-9)
+}
+
+func TestPlanErrors(t *testing.T) {
+	holdings := []models.Holding{stock("INFY", "INE009A01021", 10, 100)}
+
+	_, err := Plan(holdings, nil, testClasses, models.RebalancePlanRequest{})
+	assert.ErrorIs(t, err, ErrNoTargets)
+
+	_, err = Plan(holdings, assetClassTargets(60, 30, 0), testClasses, models.RebalancePlanRequest{})
+	assert.ErrorIs(t, err, ErrTargetWeights)
+
+	_, err = Plan(nil, assetClassTargets(60, 30, 10), testClasses, models.RebalancePlanRequest{})
+	assert.ErrorIs(t, err, ErrNothingToRebalance)
+}
+
+func TestNewTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     models.AllocationTargetRequest
+		wantKey string
+		wantErr bool
+	}{
+		{name: "asset class is lowercased", req: models.AllocationTargetRequest{Kind: models.TargetKindAssetClass, Key: " Equity ", Weight: 60}, wantKey: "equity"},
+		{name: "ISIN is uppercased", req: models.AllocationTargetRequest{Kind: models.TargetKindHolding, Key: "ine009a01021", Weight: 10}, wantKey: "INE009A01021"},
+		{name: "unknown asset class", req: models.AllocationTargetRequest{Kind: models.TargetKindAssetClass, Key: "crypto", Weight: 10}, wantErr: true},
+		{name: "malformed ISIN", req: models.AllocationTargetRequest{Kind: models.TargetKindHolding, Key: "INFY", Weight: 10}, wantErr: true},
+		{name: "weight above 100", req: models.AllocationTargetRequest{Kind: models.TargetKindAssetClass, Key: "debt", Weight: 101}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := newTarget(tt.req)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidTarget)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantKey, target.Key)
+		})
+	}
+}
+
+// fakeTargets keeps allocation targets in memory
+type fakeTargets struct {
+	targets []models.AllocationTarget
+}
+
+func (r *fakeTargets) GetTargets(userID string) ([]models.AllocationTarget, error) {
+	return r.targets, nil
+}
+
+func (r *fakeTargets) SaveTarget(userID string, target *models.AllocationTarget) error {
+	target.ID = int64(len(r.targets) + 1)
+	r.targets = append(r.targets, *target)
+	return nil
+}
+
+func (r *fakeTargets) UpdateTarget(userID string, target *models.AllocationTarget) (bool, error) {
+	for i := range r.targets {
+		if r.targets[i].ID == target.ID {
+			r.targets[i] = *target
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeTargets) DeleteTarget(userID string, targetID int64) (bool, error) {
+	return false, nil
+}
+
+func TestUpdateTargetKeyCollision(t *testing.T) {
+	repo := &fakeTargets{targets: []models.AllocationTarget{
+		{ID: 1, Kind: models.TargetKindAssetClass, Key: "equity", Weight: 60},
+		{ID: 2, Kind: models.TargetKindAssetClass, Key: "debt", Weight: 30},
+	}}
+	service := NewService(ServiceConfig{TargetRepository: repo})
+
+	// Moving the debt target onto equity would collide with the existing equity target
+	_, err := service.UpdateTarget(context.Background(), "user-1", 2, models.AllocationTargetRequest{Kind: models.TargetKindAssetClass, Key: "equity", Weight: 30})
+	assert.ErrorIs(t, err, ErrInvalidTarget)
+
+	target, err := service.UpdateTarget(context.Background(), "user-1", 2, models.AllocationTargetRequest{Kind: models.TargetKindAssetClass, Key: "gold", Weight: 30})
+	assert.NoError(t, err)
+	assert.Equal(t, "gold", target.Key)
+
+	// Setting an existing key replaces its weight
+	_, err = service.SetTarget(context.Background(), "user-1", models.AllocationTargetRequest{Kind: models.TargetKindAssetClass, Key: "equity", Weight: 70})
+	assert.NoError(t, err)
+}
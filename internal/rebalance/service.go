@@ -0,0 +1,222 @@
+package rebalance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// TargetRepository defines the interface for storing and retrieving allocation targets
+type TargetRepository interface {
+	// GetTargets retrieves all allocation targets of a user
+	GetTargets(userID string) ([]models.AllocationTarget, error)
+
+	// SaveTarget creates or replaces the target of a user for a kind and key, and sets the target ID
+	SaveTarget(userID string, target *models.AllocationTarget) error
+
+	// UpdateTarget updates a target of a user by ID, reporting whether it exists
+	UpdateTarget(userID string, target *models.AllocationTarget) (bool, error)
+
+	// DeleteTarget deletes a target of a user by ID, reporting whether it existed
+	DeleteTarget(userID string, targetID int64) (bool, error)
+}
+
+// HoldingsRepository defines the holdings the rebalancing service plans against
+type HoldingsRepository interface {
+	// GetHoldings retrieves portfolio holdings for a user
+	GetHoldings(userID string) ([]models.Holding, error)
+}
+
+// ServiceConfig holds configuration for the rebalancing service
+type ServiceConfig struct {
+	TargetRepository   TargetRepository
+	HoldingsRepository HoldingsRepository
+	Classifier         Classifier
+}
+
+// Service manages users' allocation targets and rebalancing plans
+type Service struct {
+	targetRepository   TargetRepository
+	holdingsRepository HoldingsRepository
+	classifier         Classifier
+}
+
+// NewService creates a new rebalancing service
+func NewService(config ServiceConfig) *Service {
+	return &Service{
+		targetRepository:   config.TargetRepository,
+		holdingsRepository: config.HoldingsRepository,
+		classifier:         config.Classifier,
+	}
+}
+
+// ListTargets returns the allocation targets of a user
+func (s *Service) ListTargets(ctx context.Context, userID string) ([]models.AllocationTarget, error) {
+	targets, err := s.targetRepository.GetTargets(userID)
+	if err != nil {
+		return nil, err
+	}
+	if targets == nil {
+		targets = []models.AllocationTarget{}
+	}
+	return targets, nil
+}
+
+// SetTarget creates the target of a user for a kind and key, or replaces its weight if it exists
+func (s *Service) SetTarget(ctx context.Context, userID string, req models.AllocationTargetRequest) (*models.AllocationTarget, error) {
+	target, err := newTarget(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkWeights(userID, target); err != nil {
+		return nil, err
+	}
+
+	if err := s.targetRepository.SaveTarget(userID, target); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// UpdateTarget replaces a target of a user by ID
+func (s *Service) UpdateTarget(ctx context.Context, userID string, targetID int64, req models.AllocationTargetRequest) (*models.AllocationTarget, error) {
+	target, err := newTarget(req)
+	if err != nil {
+		return nil, err
+	}
+	target.ID = targetID
+	if err := s.checkWeights(userID, target); err != nil {
+		return nil, err
+	}
+
+	found, err := s.targetRepository.UpdateTarget(userID, target)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrTargetNotFound
+	}
+	return target, nil
+}
+
+// DeleteTarget removes a target of a user by ID
+func (s *Service) DeleteTarget(ctx context.Context, userID string, targetID int64) error {
+	found, err := s.targetRepository.DeleteTarget(userID, targetID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrTargetNotFound
+	}
+	return nil
+}
+
+// RebalancePlan computes the drift of a user's holdings from their targets and the trades that correct it.
+// Without an explicit kind, holding targets are used when the user has any, and asset class targets otherwise.
+func (s *Service) RebalancePlan(ctx context.Context, userID string, req models.RebalancePlanRequest) (*models.RebalancePlan, error) {
+	targets, err := s.targetRepository.GetTargets(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	kind := req.Kind
+	if kind == "" {
+		kind = models.TargetKindAssetClass
+		for _, target := range targets {
+			if target.Kind == models.TargetKindHolding {
+				kind = models.TargetKindHolding
+				break
+			}
+		}
+	}
+	selected := make([]models.AllocationTarget, 0, len(targets))
+	for _, target := range targets {
+		if target.Kind == kind {
+			selected = append(selected, target)
+		}
+	}
+
+	holdings, err := s.holdingsRepository.GetHoldings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return Plan(holdings, selected, s.classifier, req)
+}
+
+// checkWeights ensures a new or changed target keeps the weights of its kind within 100.
+// A new target replaces the one with the same kind and key, while an updated target may not take the key of another.
+func (s *Service) checkWeights(userID string, target *models.AllocationTarget) error {
+	existing, err := s.targetRepository.GetTargets(userID)
+	if err != nil {
+		return err
+	}
+
+	total := target.Weight
+	for _, other := range existing {
+		if other.Kind != target.Kind || other.ID == target.ID {
+			continue
+		}
+		if other.Key == target.Key {
+			if target.ID != 0 {
+				return fmt.Errorf("%w: a %s target for %q already exists", ErrInvalidTarget, target.Kind, target.Key)
+			}
+			continue
+		}
+		total += other.Weight
+	}
+	if total > 100+weightTolerance {
+		return fmt.Errorf("%w: %s targets would add up to %.2f", ErrTargetWeightsExceed, target.Kind, total)
+	}
+	return nil
+}
+
+// newTarget validates a target request and normalizes its key
+func newTarget(req models.AllocationTargetRequest) (*models.AllocationTarget, error) {
+	if req.Weight <= 0 || req.Weight > 100 || math.IsNaN(req.Weight) {
+		return nil, fmt.Errorf("%w: weight must be above 0 and at most 100", ErrInvalidTarget)
+	}
+
+	key := strings.TrimSpace(req.Key)
+	switch req.Kind {
+	case models.TargetKindAssetClass:
+		key = strings.ToLower(key)
+		switch models.AllocationClass(key) {
+		case models.AllocationClassEquity, models.AllocationClassDebt, models.AllocationClassGold,
+			models.AllocationClassCash, models.AllocationClassHybrid:
+		default:
+			return nil, fmt.Errorf("%w: unknown asset class %q", ErrInvalidTarget, req.Key)
+		}
+	case models.TargetKindHolding:
+		key = strings.ToUpper(key)
+		if !isISIN(key) {
+			return nil, fmt.Errorf("%w: %q is not an ISIN", ErrInvalidTarget, req.Key)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidTarget, req.Kind)
+	}
+
+	return &models.AllocationTarget{
+		Kind:   req.Kind,
+		Key:    key,
+		Weight: req.Weight,
+	}, nil
+}
+
+// isISIN reports whether s has the shape of an ISIN: a country code followed by ten alphanumeric characters
+func isISIN(s string) bool {
+	if len(s) != 12 {
+		return false
+	}
+	for i, r := range s {
+		isLetter := r >= 'A' && r <= 'Z'
+		isDigit := r >= '0' && r <= '9'
+		if (i < 2 && !isLetter) || (!isLetter && !isDigit) {
+			return false
+		}
+	}
+	return true
+}
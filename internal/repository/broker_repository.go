@@ -20,6 +20,9 @@ type BrokerCredentialsRepository interface {
 	// HasCredentials checks if the user has credentials for a specific broker
 	HasCredentials(userID string, brokerType string) (bool, error)
 
+	// ListBrokerTypes retrieves the broker types the user has stored credentials for
+	ListBrokerTypes(userID string) ([]string, error)
+
 	// DeleteCredentials deletes broker credentials from the repository
 	DeleteCredentials(userID string, brokerType string) error
 
@@ -0,0 +1,293 @@
+package returns
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/costbasis"
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// quantityTolerance is the largest difference between lot and holding quantities treated as equal
+const quantityTolerance = 1e-6
+
+// ist is Indian Standard Time, in which trade and snapshot days are reckoned
+var ist = time.FixedZone("IST", 5*60*60+30*60)
+
+// Input collects the history that returns are computed from
+type Input struct {
+	// Snapshots are the daily holding valuations; the latest day is the current portfolio
+	Snapshots []models.HoldingSnapshot
+
+	// Trades is the user's full trade history
+	Trades []models.Trade
+
+	// Dividends are the payouts received
+	Dividends []models.Dividend
+
+	// Actions are the splits and bonus issues affecting traded securities
+	Actions []models.CorporateAction
+}
+
+// Compute measures returns at portfolio, platform and holding level.
+// XIRR includes only holdings whose trade history fully explains the quantity held, plus fully exited positions;
+// TWR uses the daily snapshots with trades and dividends between snapshots as external flows.
+func Compute(in Input) *models.PortfolioReturns {
+	result := &models.PortfolioReturns{
+		Platforms: []models.PlatformReturns{},
+		Holdings:  []models.HoldingReturns{},
+	}
+	if len(in.Snapshots) == 0 {
+		return result
+	}
+
+	days, byDay := groupByDay(in.Snapshots)
+	latestDay := days[len(days)-1]
+	current := byDay[latestDay]
+	result.AsOf = current[0].Date
+
+	book := costbasis.Build(in.Trades, in.Actions)
+	c := &calculator{
+		in:        in,
+		book:      book,
+		days:      days,
+		byDay:     byDay,
+		asOf:      current[0].Date,
+		covered:   make(map[*costbasis.Position]bool),
+		rowLookup: make(map[string]*costbasis.Position),
+	}
+
+	// A holding is covered when its open lots match the quantity held
+	for _, row := range current {
+		position := c.position(row.Platform, row.ISIN, row.ItemName)
+		if position != nil && position.Complete() && math.Abs(position.Quantity()-row.Quantity) <= quantityTolerance {
+			c.covered[position] = true
+		}
+	}
+	// Fully exited positions contribute their realized flows
+	for _, position := range book.Positions() {
+		if position.Complete() && position.Quantity() <= quantityTolerance && len(position.Disposals) > 0 {
+			c.covered[position] = true
+		}
+	}
+
+	result.Portfolio = c.measure(func(*costbasis.Position) bool { return true }, func(models.HoldingSnapshot) bool { return true })
+
+	platforms := make(map[string]bool)
+	for _, row := range current {
+		platforms[row.Platform] = true
+	}
+	for _, position := range book.Positions() {
+		if c.covered[position] {
+			platforms[position.Platform] = true
+		}
+	}
+	for _, platform := range sortedKeys(platforms) {
+		platform := platform
+		metrics := c.measure(
+			func(position *costbasis.Position) bool { return position.Platform == platform },
+			func(row models.HoldingSnapshot) bool { return row.Platform == platform },
+		)
+		result.Platforms = append(result.Platforms, models.PlatformReturns{Platform: platform, ReturnMetrics: metrics})
+	}
+
+	for _, row := range current {
+		row := row
+		position := c.position(row.Platform, row.ISIN, row.ItemName)
+		metrics := c.measure(
+			func(p *costbasis.Position) bool { return position != nil && p == position },
+			func(other models.HoldingSnapshot) bool { return sameHolding(row, other) },
+		)
+		result.Holdings = append(result.Holdings, models.HoldingReturns{
+			ItemName:      row.ItemName,
+			ISIN:          row.ISIN,
+			Platform:      row.Platform,
+			ReturnMetrics: metrics,
+		})
+	}
+	sort.SliceStable(result.Holdings, func(i, j int) bool {
+		if result.Holdings[i].Platform != result.Holdings[j].Platform {
+			return result.Holdings[i].Platform < result.Holdings[j].Platform
+		}
+		return result.Holdings[i].ItemName < result.Holdings[j].ItemName
+	})
+
+	return result
+}
+
+// calculator holds the shared state of a Compute call
+type calculator struct {
+	in        Input
+	book      *costbasis.Book
+	days      []string
+	byDay     map[string][]models.HoldingSnapshot
+	asOf      time.Time
+	covered   map[*costbasis.Position]bool
+	rowLookup map[string]*costbasis.Position
+}
+
+// position finds the cost basis position of a holding or trade
+func (c *calculator) position(platform, isin, symbol string) *costbasis.Position {
+	key := platform + "|" + isin + "|" + symbol
+	if position, ok := c.rowLookup[key]; ok {
+		return position
+	}
+	position, _ := c.book.Position(platform, isin, symbol)
+	c.rowLookup[key] = position
+	return position
+}
+
+// measure computes the metrics of the holdings selected by the position and row filters
+func (c *calculator) measure(inPosition func(*costbasis.Position) bool, inRow func(models.HoldingSnapshot) bool) models.ReturnMetrics {
+	var metrics models.ReturnMetrics
+
+	// Money-weighted return over covered holdings
+	var flows []CashFlow
+	for _, row := range c.byDay[c.days[len(c.days)-1]] {
+		if !inRow(row) {
+			continue
+		}
+		metrics.CurrentValue += row.CurrentValue
+		position := c.position(row.Platform, row.ISIN, row.ItemName)
+		if position != nil && c.covered[position] {
+			metrics.CoveredValue += row.CurrentValue
+		}
+	}
+	for _, trade := range c.in.Trades {
+		position := c.position(trade.Platform, trade.ISIN, trade.Symbol)
+		if position == nil || !c.covered[position] || !inPosition(position) {
+			continue
+		}
+		flows = append(flows, CashFlow{Date: trade.TradeDate, Amount: -tradeFlow(trade)})
+	}
+	for _, dividend := range c.in.Dividends {
+		position := c.position(dividend.Platform, dividend.ISIN, dividend.Symbol)
+		if position == nil || !c.covered[position] || !inPosition(position) {
+			continue
+		}
+		flows = append(flows, CashFlow{Date: dividend.PaidOn, Amount: dividend.Amount})
+	}
+	if len(flows) > 0 {
+		flows = append(flows, CashFlow{Date: c.asOf, Amount: metrics.CoveredValue})
+		if rate, err := XIRR(flows); err == nil {
+			metrics.XIRR = percent(rate)
+		}
+	}
+
+	// Time-weighted return over the snapshot series
+	valuations := c.valuations(inPosition, inRow)
+	if twr, err := TWR(valuations); err == nil {
+		from, to := valuations[0].Date, valuations[len(valuations)-1].Date
+		metrics.TWR = percent(twr)
+		metrics.TWRFrom, metrics.TWRTo = &from, &to
+		if days := to.Sub(from).Hours() / 24; days >= daysPerYear {
+			metrics.AnnualizedTWR = percent(Annualize(twr, days))
+		}
+	}
+
+	return metrics
+}
+
+// valuations builds the daily value series of the selected holdings with the flows between snapshots
+func (c *calculator) valuations(inPosition func(*costbasis.Position) bool, inRow func(models.HoldingSnapshot) bool) []Valuation {
+	flowsByDay := make(map[string]float64)
+	for _, trade := range c.in.Trades {
+		position := c.position(trade.Platform, trade.ISIN, trade.Symbol)
+		if position != nil && inPosition(position) {
+			flowsByDay[dayKey(trade.TradeDate.In(ist))] += tradeFlow(trade)
+		}
+	}
+	for _, dividend := range c.in.Dividends {
+		position := c.position(dividend.Platform, dividend.ISIN, dividend.Symbol)
+		if position != nil && inPosition(position) {
+			flowsByDay[dayKey(dividend.PaidOn.In(ist))] -= dividend.Amount
+		}
+	}
+	flowDays := sortedKeys(flowsByDay)
+
+	var valuations []Valuation
+	prev := ""
+	for _, day := range c.days {
+		valuation := Valuation{}
+		for _, row := range c.byDay[day] {
+			if inRow(row) {
+				valuation.Value += row.CurrentValue
+				valuation.Date = row.Date
+			}
+		}
+		if valuation.Date.IsZero() {
+			valuation.Date = c.byDay[day][0].Date
+		}
+
+		// Flows after the previous snapshot up to and including this day
+		for _, flowDay := range flowDays {
+			if flowDay > prev && flowDay <= day {
+				valuation.Flow += flowsByDay[flowDay]
+			}
+		}
+		prev = day
+
+		// The series starts once the holdings appear
+		if len(valuations) == 0 && valuation.Value == 0 {
+			continue
+		}
+		if len(valuations) == 0 {
+			valuation.Flow = 0
+		}
+		valuations = append(valuations, valuation)
+	}
+
+	return valuations
+}
+
+// tradeFlow returns the money a trade moved into the investment, net of charges
+func tradeFlow(trade models.Trade) float64 {
+	value := trade.Quantity * trade.Price
+	if trade.Side == models.TradeSideSell {
+		return -(value - trade.Charges)
+	}
+	return value + trade.Charges
+}
+
+// groupByDay groups snapshots by day, returning the days in order
+func groupByDay(snapshots []models.HoldingSnapshot) ([]string, map[string][]models.HoldingSnapshot) {
+	byDay := make(map[string][]models.HoldingSnapshot)
+	for _, snapshot := range snapshots {
+		day := dayKey(snapshot.Date)
+		byDay[day] = append(byDay[day], snapshot)
+	}
+	return sortedKeys(byDay), byDay
+}
+
+// sameHolding reports whether two snapshot rows are the same holding
+func sameHolding(a, b models.HoldingSnapshot) bool {
+	if a.Platform != b.Platform {
+		return false
+	}
+	if a.ISIN != "" || b.ISIN != "" {
+		return a.ISIN == b.ISIN
+	}
+	return a.ItemName == b.ItemName
+}
+
+// dayKey formats the calendar day of t
+func dayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// percent converts a fraction to a percentage pointer
+func percent(fraction float64) *float64 {
+	value := fraction * 100
+	return &value
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
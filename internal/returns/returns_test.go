@@ -0,0 +1,264 @@
+package returns
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+// npv discounts flows at the given annual rate, for checking XIRR results
+func npv(flows []CashFlow, rate float64) float64 {
+	total := 0.0
+	for _, flow := range flows {
+		years := flow.Date.Sub(flows[0].Date).Hours() / 24 / daysPerYear
+		total += flow.Amount / math.Pow(1+rate, years)
+	}
+	return total
+}
+
+func TestXIRR(t *testing.T) {
+	tests := []struct {
+		name    string
+		flows   []CashFlow
+		want    float64
+		wantErr error
+	}{
+		{
+			name: "single year",
+			flows: []CashFlow{
+				{Date: day(2021, 1, 1), Amount: -1000},
+				{Date: day(2022, 1, 1), Amount: 1100},
+			},
+			want: 0.10,
+		},
+		{
+			name: "spreadsheet reference example",
+			flows: []CashFlow{
+				{Date: day(2008, 1, 1), Amount: -10000},
+				{Date: day(2008, 3, 1), Amount: 2750},
+				{Date: day(2008, 10, 30), Amount: 4250},
+				{Date: day(2009, 2, 15), Amount: 3250},
+				{Date: day(2009, 4, 1), Amount: 2750},
+			},
+			want: 0.373362535,
+		},
+		{
+			name: "loss",
+			flows: []CashFlow{
+				{Date: day(2021, 1, 1), Amount: -1000},
+				{Date: day(2023, 1, 1), Amount: 810},
+			},
+			want: math.Pow(0.81, 365.0/730.0) - 1,
+		},
+		{
+			name: "unsorted flows",
+			flows: []CashFlow{
+				{Date: day(2022, 1, 1), Amount: 1100},
+				{Date: day(2021, 1, 1), Amount: -1000},
+			},
+			want: 0.10,
+		},
+		{
+			name: "no returns",
+			flows: []CashFlow{
+				{Date: day(2021, 1, 1), Amount: -1000},
+			},
+			wantErr: ErrNoSignChange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := XIRR(tt.flows)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.want, rate, 1e-6)
+		})
+	}
+}
+
+func TestTWR(t *testing.T) {
+	tests := []struct {
+		name       string
+		valuations []Valuation
+		want       float64
+		wantErr    error
+	}{
+		{
+			name: "no flows",
+			valuations: []Valuation{
+				{Date: day(2024, 1, 1), Value: 1000},
+				{Date: day(2024, 1, 2), Value: 1100},
+			},
+			want: 0.10,
+		},
+		{
+			name: "deposit does not count as return",
+			valuations: []Valuation{
+				{Date: day(2024, 1, 1), Value: 1000},
+				{Date: day(2024, 1, 2), Value: 1100},
+				// 2310 / (1100 + 1000) = 1.1
+				{Date: day(2024, 1, 3), Value: 2310, Flow: 1000},
+			},
+			want: 0.21,
+		},
+		{
+			name: "withdrawal does not count as loss",
+			valuations: []Valuation{
+				{Date: day(2024, 1, 1), Value: 1000},
+				// (550 + 550) / 1000 = 1.1
+				{Date: day(2024, 1, 2), Value: 550, Flow: -550},
+			},
+			want: 0.10,
+		},
+		{
+			name: "full exit at a gain",
+			valuations: []Valuation{
+				{Date: day(2024, 1, 1), Value: 100},
+				// Sold everything for 130: (0 + 130) / 100 = 1.3
+				{Date: day(2024, 1, 2), Value: 0, Flow: -130},
+			},
+			want: 0.30,
+		},
+		{
+			name: "full exit at a loss",
+			valuations: []Valuation{
+				{Date: day(2024, 1, 1), Value: 100},
+				// Sold everything for 90: (0 + 90) / 100 = 0.9
+				{Date: day(2024, 1, 2), Value: 0, Flow: -90},
+			},
+			want: -0.10,
+		},
+		{
+			name: "single valuation",
+			valuations: []Valuation{
+				{Date: day(2024, 1, 1), Value: 1000},
+			},
+			wantErr: ErrInsufficientData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			twr, err := TWR(tt.valuations)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.want, twr, 1e-9)
+		})
+	}
+
+	assert.InDelta(t, 0.10, Annualize(0.21, 730), 1e-9)
+}
+
+func snapshot(date time.Time, platform, isin, name string, quantity, value float64) models.HoldingSnapshot {
+	return models.HoldingSnapshot{
+		Date: date,
+		Holding: models.Holding{
+			ItemName:     name,
+			ISIN:         isin,
+			Quantity:     quantity,
+			CurrentValue: value,
+			Platform:     platform,
+		},
+	}
+}
+
+func testTrade(platform, isin, symbol string, side models.TradeSide, date time.Time, quantity, price float64) models.Trade {
+	return models.Trade{
+		TradeID:   symbol + date.Format("20060102") + string(side),
+		ISIN:      isin,
+		Symbol:    symbol,
+		Side:      side,
+		Quantity:  quantity,
+		Price:     price,
+		TradeDate: date,
+		Platform:  platform,
+	}
+}
+
+func TestCompute(t *testing.T) {
+	const reliance, tcs, infy = "INE002A01018", "INE467B01029", "INE009A01021"
+	zerodha, upstox := models.PlatformZerodha, models.PlatformUpstox
+	buy, sell := models.TradeSideBuy, models.TradeSideSell
+
+	input := Input{
+		Snapshots: []models.HoldingSnapshot{
+			snapshot(day(2024, 1, 1), zerodha, reliance, "RELIANCE", 10, 1000),
+			snapshot(day(2024, 1, 1), upstox, tcs, "TCS", 5, 500),
+			snapshot(day(2024, 7, 1), zerodha, reliance, "RELIANCE", 15, 1650),
+			snapshot(day(2024, 7, 1), upstox, tcs, "TCS", 5, 500),
+			snapshot(day(2025, 1, 1), zerodha, reliance, "RELIANCE", 15, 1800),
+			snapshot(day(2025, 1, 1), upstox, tcs, "TCS", 5, 500),
+		},
+		Trades: []models.Trade{
+			testTrade(zerodha, reliance, "RELIANCE", buy, day(2024, 1, 1), 10, 100),
+			testTrade(zerodha, reliance, "RELIANCE", buy, day(2024, 7, 1), 5, 110),
+			// Bought and sold between snapshots
+			testTrade(zerodha, infy, "INFY", buy, day(2024, 2, 1), 10, 100),
+			testTrade(zerodha, infy, "INFY", sell, day(2024, 3, 1), 10, 150),
+		},
+	}
+
+	result := Compute(input)
+	assert.Equal(t, day(2025, 1, 1), result.AsOf)
+
+	// TCS has no trade history, so only RELIANCE and the exited INFY position count towards XIRR
+	assert.Equal(t, 2300.0, result.Portfolio.CurrentValue)
+	assert.Equal(t, 1800.0, result.Portfolio.CoveredValue)
+	assert.NotNil(t, result.Portfolio.XIRR)
+	flows := []CashFlow{
+		{Date: day(2024, 1, 1), Amount: -1000},
+		{Date: day(2024, 2, 1), Amount: -1000},
+		{Date: day(2024, 3, 1), Amount: 1500},
+		{Date: day(2024, 7, 1), Amount: -550},
+		{Date: day(2025, 1, 1), Amount: 1800},
+	}
+	assert.InDelta(t, 0, npv(flows, *result.Portfolio.XIRR/100), 1e-6)
+
+	// Portfolio periods: 2150 / (1500 + 50) and 2300 / 2150
+	assert.NotNil(t, result.Portfolio.TWR)
+	assert.InDelta(t, (2150.0/1550.0*2300.0/2150.0-1)*100, *result.Portfolio.TWR, 1e-9)
+	assert.NotNil(t, result.Portfolio.AnnualizedTWR)
+
+	assert.Len(t, result.Platforms, 2)
+	upstoxReturns := result.Platforms[0]
+	assert.Equal(t, upstox, upstoxReturns.Platform)
+	assert.Nil(t, upstoxReturns.XIRR)
+	assert.InDelta(t, 0, *upstoxReturns.TWR, 1e-9)
+
+	zerodhaReturns := result.Platforms[1]
+	assert.Equal(t, 1800.0, zerodhaReturns.CoveredValue)
+	// 1650 / (1000 + 50) and 1800 / 1650
+	assert.InDelta(t, (1650.0/1050.0*1800.0/1650.0-1)*100, *zerodhaReturns.TWR, 1e-9)
+
+	assert.Len(t, result.Holdings, 2)
+	relianceReturns := result.Holdings[1]
+	assert.Equal(t, "RELIANCE", relianceReturns.ItemName)
+	assert.InDelta(t, 0, npv([]CashFlow{
+		{Date: day(2024, 1, 1), Amount: -1000},
+		{Date: day(2024, 7, 1), Amount: -550},
+		{Date: day(2025, 1, 1), Amount: 1800},
+	}, *relianceReturns.XIRR/100), 1e-6)
+	// 1650 / (1000 + 550) and 1800 / 1650
+	assert.InDelta(t, (1650.0/1550.0*1800.0/1650.0-1)*100, *relianceReturns.TWR, 1e-9)
+}
+
+func TestComputeWithoutSnapshots(t *testing.T) {
+	result := Compute(Input{})
+	assert.Nil(t, result.Portfolio.XIRR)
+	assert.Nil(t, result.Portfolio.TWR)
+	assert.Empty(t, result.Holdings)
+}
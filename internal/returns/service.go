@@ -0,0 +1,99 @@
+package returns
+
+import (
+	"context"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// TradeRepository defines the trade history the returns service reads
+type TradeRepository interface {
+	// GetTrades retrieves all trades for a user ordered by trade date
+	GetTrades(userID string) ([]models.Trade, error)
+}
+
+// CorporateActionRepository defines the splits and bonus issues the returns service reads
+type CorporateActionRepository interface {
+	// GetCorporateActions retrieves the actions for the given securities that took effect on or before the given date
+	GetCorporateActions(isins []string, asOf time.Time) ([]models.CorporateAction, error)
+}
+
+// SnapshotRepository defines the daily valuations the returns service reads
+type SnapshotRepository interface {
+	// GetHoldingSnapshots retrieves the holding snapshots of a user between two days inclusive, ordered by day
+	GetHoldingSnapshots(userID string, from, to time.Time) ([]models.HoldingSnapshot, error)
+}
+
+// DividendRepository defines the payouts the returns service reads
+type DividendRepository interface {
+	// GetDividends retrieves all dividends received by a user ordered by payment date
+	GetDividends(userID string) ([]models.Dividend, error)
+}
+
+// ServiceConfig holds configuration for the returns service
+type ServiceConfig struct {
+	TradeRepository           TradeRepository
+	CorporateActionRepository CorporateActionRepository
+	SnapshotRepository        SnapshotRepository
+	DividendRepository        DividendRepository
+}
+
+// Service computes portfolio returns from users' trade history and valuation snapshots
+type Service struct {
+	tradeRepository           TradeRepository
+	corporateActionRepository CorporateActionRepository
+	snapshotRepository        SnapshotRepository
+	dividendRepository        DividendRepository
+}
+
+// NewService creates a new returns service
+func NewService(config ServiceConfig) *Service {
+	return &Service{
+		tradeRepository:           config.TradeRepository,
+		corporateActionRepository: config.CorporateActionRepository,
+		snapshotRepository:        config.SnapshotRepository,
+		dividendRepository:        config.DividendRepository,
+	}
+}
+
+// PortfolioReturns computes the returns of a user's portfolio, platforms and holdings over all recorded history
+func (s *Service) PortfolioReturns(ctx context.Context, userID string) (*models.PortfolioReturns, error) {
+	now := time.Now()
+
+	snapshots, err := s.snapshotRepository.GetHoldingSnapshots(userID, time.Time{}, now)
+	if err != nil {
+		return nil, err
+	}
+
+	trades, err := s.tradeRepository.GetTrades(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	isins := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, trade := range trades {
+		if trade.ISIN != "" && !seen[trade.ISIN] {
+			seen[trade.ISIN] = true
+			isins = append(isins, trade.ISIN)
+		}
+	}
+
+	actions, err := s.corporateActionRepository.GetCorporateActions(isins, now)
+	if err != nil {
+		return nil, err
+	}
+
+	dividends, err := s.dividendRepository.GetDividends(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return Compute(Input{
+		Snapshots: snapshots,
+		Trades:    trades,
+		Dividends: dividends,
+		Actions:   actions,
+	}), nil
+}
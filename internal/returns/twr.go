@@ -0,0 +1,54 @@
+package returns
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Valuation is the value of an investment at the end of a day together with the
+// external money that moved in (positive) or out (negative) since the previous valuation
+type Valuation struct {
+	Date  time.Time
+	Value float64
+	Flow  float64
+}
+
+// TWR returns the cumulative time-weighted return of a valuation series as a fraction.
+// Inflows are treated as arriving at the start of a period, whose return is Value / (previous Value + Flow) - 1,
+// and outflows such as sales and dividends as leaving at its end, the return being (Value - Flow) / previous Value - 1,
+// so that exiting a holding keeps the gain or loss it was sold at. The periods are chain-linked so that the size
+// and timing of flows do not affect the result.
+func TWR(valuations []Valuation) (float64, error) {
+	if len(valuations) < 2 {
+		return 0, ErrInsufficientData
+	}
+
+	valuations = append([]Valuation(nil), valuations...)
+	sort.SliceStable(valuations, func(i, j int) bool {
+		return valuations[i].Date.Before(valuations[j].Date)
+	})
+
+	growth := 1.0
+	for i := 1; i < len(valuations); i++ {
+		base, value := valuations[i-1].Value+valuations[i].Flow, valuations[i].Value
+		if valuations[i].Flow < 0 {
+			base, value = valuations[i-1].Value, valuations[i].Value-valuations[i].Flow
+		}
+		if base <= 0 {
+			// Nothing was invested over the period, so it carries no return
+			continue
+		}
+		growth *= value / base
+	}
+
+	return growth - 1, nil
+}
+
+// Annualize converts a cumulative return over the given number of days into a yearly return
+func Annualize(cumulative float64, days float64) float64 {
+	if days <= 0 || cumulative <= -1 {
+		return cumulative
+	}
+	return math.Pow(1+cumulative, daysPerYear/days) - 1
+}
@@ -0,0 +1,108 @@
+// Package returns computes money-weighted (XIRR) and time-weighted returns from cash flows and valuations.
+package returns
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// Common errors
+var (
+	ErrNoSignChange     = errors.New("cash flows need both investments and returns")
+	ErrNotConverged     = errors.New("XIRR did not converge")
+	ErrInsufficientData = errors.New("at least two valuations are needed")
+)
+
+const (
+	// daysPerYear is the day count XIRR discounts over, matching spreadsheet XIRR
+	daysPerYear = 365.0
+
+	maxIterations = 100
+	tolerance     = 1e-9
+)
+
+// CashFlow is money moving between the investor and the investment.
+// Investments are negative and money returned, including the terminal value, is positive.
+type CashFlow struct {
+	Date   time.Time
+	Amount float64
+}
+
+// XIRR returns the annualized internal rate of return of irregularly dated cash flows as a fraction
+func XIRR(flows []CashFlow) (float64, error) {
+	var hasPositive, hasNegative bool
+	for _, flow := range flows {
+		hasPositive = hasPositive || flow.Amount > 0
+		hasNegative = hasNegative || flow.Amount < 0
+	}
+	if !hasPositive || !hasNegative {
+		return 0, ErrNoSignChange
+	}
+
+	flows = append([]CashFlow(nil), flows...)
+	sort.SliceStable(flows, func(i, j int) bool {
+		return flows[i].Date.Before(flows[j].Date)
+	})
+
+	start := flows[0].Date
+	years := make([]float64, len(flows))
+	for i, flow := range flows {
+		years[i] = flow.Date.Sub(start).Hours() / 24 / daysPerYear
+	}
+
+	npv := func(rate float64) float64 {
+		total := 0.0
+		for i, flow := range flows {
+			total += flow.Amount / math.Pow(1+rate, years[i])
+		}
+		return total
+	}
+	derivative := func(rate float64) float64 {
+		total := 0.0
+		for i, flow := range flows {
+			total -= years[i] * flow.Amount / math.Pow(1+rate, years[i]+1)
+		}
+		return total
+	}
+
+	// Newton's method converges quickly from a sensible guess
+	rate := 0.1
+	for i := 0; i < maxIterations; i++ {
+		value, slope := npv(rate), derivative(rate)
+		if slope == 0 {
+			break
+		}
+		next := rate - value/slope
+		if next <= -1 || math.IsNaN(next) || math.IsInf(next, 0) {
+			break
+		}
+		if math.Abs(next-rate) < tolerance {
+			return next, nil
+		}
+		rate = next
+	}
+
+	// Fall back to bisection, which always converges once the root is bracketed
+	low, high := -0.9999, 10.0
+	for npv(low)*npv(high) > 0 && high < 1e6 {
+		high *= 10
+	}
+	if npv(low)*npv(high) > 0 {
+		return 0, ErrNotConverged
+	}
+	for i := 0; i < 1000; i++ {
+		mid := (low + high) / 2
+		value := npv(mid)
+		if math.Abs(value) < tolerance || (high-low)/2 < tolerance {
+			return mid, nil
+		}
+		if npv(low)*value < 0 {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+	return 0, ErrNotConverged
+}
@@ -0,0 +1,180 @@
+package streaming
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Tick is a live price update of an instrument
+type Tick struct {
+	Token         uint32
+	LastPrice     float64
+	PreviousClose float64 // close of the previous trading day, zero when unknown
+	Timestamp     time.Time
+}
+
+// TickSource delivers live ticks for subscribed instrument tokens
+type TickSource interface {
+	// Subscribe starts delivering ticks for the tokens
+	Subscribe(tokens []uint32) error
+
+	// Unsubscribe stops delivering ticks for the tokens
+	Unsubscribe(tokens []uint32) error
+
+	// Run connects to the feed and calls onTick for every tick until ctx is cancelled
+	Run(ctx context.Context, onTick func(Tick))
+}
+
+// Hub shares one tick source between subscribers. A token stays subscribed at the source
+// for as long as at least one subscription holds it.
+type Hub struct {
+	source TickSource
+
+	mu            sync.Mutex
+	refs          map[uint32]int
+	last          map[uint32]Tick
+	subscriptions map[*Subscription]struct{}
+}
+
+// Subscription receives the ticks of a set of tokens. Ticks are coalesced per token,
+// so a slow reader sees the latest price of each token rather than every tick.
+type Subscription struct {
+	tokens map[uint32]bool
+	ready  chan struct{}
+
+	mu      sync.Mutex
+	pending map[uint32]Tick
+}
+
+// NewHub creates a hub over a tick source
+func NewHub(source TickSource) *Hub {
+	return &Hub{
+		source:        source,
+		refs:          make(map[uint32]int),
+		last:          make(map[uint32]Tick),
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+// Run delivers ticks from the source to subscribers until ctx is cancelled
+func (h *Hub) Run(ctx context.Context) {
+	h.source.Run(ctx, h.publish)
+}
+
+// Subscribe subscribes to the ticks of the tokens, subscribing the source to tokens no one else holds.
+// The last known tick of each token already held is delivered straight away.
+func (h *Hub) Subscribe(tokens []uint32) (*Subscription, error) {
+	subscription := &Subscription{
+		tokens:  make(map[uint32]bool, len(tokens)),
+		ready:   make(chan struct{}, 1),
+		pending: make(map[uint32]Tick),
+	}
+	for _, token := range tokens {
+		subscription.tokens[token] = true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var added []uint32
+	for token := range subscription.tokens {
+		if h.refs[token] == 0 {
+			added = append(added, token)
+		}
+	}
+	if len(added) > 0 {
+		if err := h.source.Subscribe(added); err != nil {
+			return nil, err
+		}
+	}
+
+	for token := range subscription.tokens {
+		h.refs[token]++
+		if tick, ok := h.last[token]; ok {
+			subscription.deliver(tick)
+		}
+	}
+	h.subscriptions[subscription] = struct{}{}
+	return subscription, nil
+}
+
+// Unsubscribe releases a subscription, unsubscribing the source from tokens no one else holds
+func (h *Hub) Unsubscribe(subscription *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscriptions[subscription]; !ok {
+		return
+	}
+	delete(h.subscriptions, subscription)
+
+	var released []uint32
+	for token := range subscription.tokens {
+		h.refs[token]--
+		if h.refs[token] <= 0 {
+			delete(h.refs, token)
+			delete(h.last, token)
+			released = append(released, token)
+		}
+	}
+	if len(released) > 0 {
+		if err := h.source.Unsubscribe(released); err != nil {
+			log.Printf("Error unsubscribing from %d instruments: %v", len(released), err)
+		}
+	}
+}
+
+// SubscribedTokens returns the number of distinct tokens held by subscriptions
+func (h *Hub) SubscribedTokens() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.refs)
+}
+
+// publish records a tick and hands it to the subscriptions holding its token
+func (h *Hub) publish(tick Tick) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.refs[tick.Token] == 0 {
+		return
+	}
+	h.last[tick.Token] = tick
+	for subscription := range h.subscriptions {
+		if subscription.tokens[tick.Token] {
+			subscription.deliver(tick)
+		}
+	}
+}
+
+// Ready is signalled when ticks are waiting to be drained
+func (s *Subscription) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// Drain returns the latest waiting tick of each token
+func (s *Subscription) Drain() []Tick {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ticks := make([]Tick, 0, len(s.pending))
+	for token, tick := range s.pending {
+		ticks = append(ticks, tick)
+		delete(s.pending, token)
+	}
+	return ticks
+}
+
+// deliver queues a tick, replacing any earlier tick of the same token
+func (s *Subscription) deliver(tick Tick) {
+	s.mu.Lock()
+	s.pending[tick.Token] = tick
+	s.mu.Unlock()
+
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
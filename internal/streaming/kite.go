@@ -0,0 +1,200 @@
+package streaming
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	kitemodels "github.com/zerodha/gokiteconnect/v4/models"
+	kiteticker "github.com/zerodha/gokiteconnect/v4/ticker"
+)
+
+// Ensure KiteSource implements TickSource
+var _ TickSource = (*KiteSource)(nil)
+
+// sessionRetryInterval is how long the ticker waits for a Kite Connect session before checking again
+const sessionRetryInterval = time.Minute
+
+// SessionProvider supplies the broker session the ticker connects with
+type SessionProvider interface {
+	// Session returns the API key and access token of a connected session of a broker
+	Session(clientType string) (apiKey, accessToken string, ok bool)
+}
+
+// StaticSession is a fixed Kite Connect session; its access token expires daily and must be replaced by hand
+type StaticSession struct {
+	APIKey      string
+	AccessToken string
+}
+
+// Session returns the fixed API key and access token
+func (s StaticSession) Session(clientType string) (string, string, bool) {
+	return s.APIKey, s.AccessToken, s.APIKey != "" && s.AccessToken != ""
+}
+
+// UserSessions supplies the broker sessions of individual users
+type UserSessions interface {
+	// UserSession returns the API key and access token of a user's connected session of a broker
+	UserSession(userID, clientType string) (apiKey, accessToken string, ok bool)
+}
+
+// AccountSession is the session of the one user designated as the ticker account, so no other user's credentials
+// are ever used for the shared ticker; it is renewed whenever that user connects the broker again
+type AccountSession struct {
+	Sessions UserSessions
+	UserID   string
+}
+
+// Session returns the API key and access token of the ticker account's session
+func (s AccountSession) Session(clientType string) (string, string, bool) {
+	return s.Sessions.UserSession(s.UserID, clientType)
+}
+
+// KiteSource streams ticks from the Kite Connect WebSocket ticker in quote mode,
+// which carries the previous close alongside the last price.
+// The session is read from the provider on every connect and reconnect, so a renewed token is picked up
+// without a restart; while no session is available the source waits for one.
+type KiteSource struct {
+	sessions SessionProvider
+
+	mu        sync.Mutex
+	ticker    *kiteticker.Ticker
+	tokens    map[uint32]bool
+	connected bool
+}
+
+// NewKiteSource creates a Kite ticker source connecting with the Zerodha session of a provider
+func NewKiteSource(sessions SessionProvider) *KiteSource {
+	return &KiteSource{
+		sessions: sessions,
+		tokens:   make(map[uint32]bool),
+	}
+}
+
+// Subscribe starts delivering ticks for the tokens; before the ticker connects they are subscribed on connect
+func (k *KiteSource) Subscribe(tokens []uint32) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for _, token := range tokens {
+		k.tokens[token] = true
+	}
+	if !k.connected {
+		return nil
+	}
+	return k.subscribe(tokens)
+}
+
+// Unsubscribe stops delivering ticks for the tokens
+func (k *KiteSource) Unsubscribe(tokens []uint32) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for _, token := range tokens {
+		delete(k.tokens, token)
+	}
+	if !k.connected {
+		return nil
+	}
+	return k.ticker.Unsubscribe(tokens)
+}
+
+// Run connects to the ticker, reconnecting when the connection drops, until ctx is cancelled
+func (k *KiteSource) Run(ctx context.Context, onTick func(Tick)) {
+	for ctx.Err() == nil {
+		apiKey, accessToken, ok := k.sessions.Session(models.PlatformZerodha)
+		if ok {
+			k.serve(ctx, apiKey, accessToken, onTick)
+			continue
+		}
+
+		log.Printf("No Kite Connect session for the ticker, retrying in %s", sessionRetryInterval)
+		select {
+		case <-ctx.Done():
+		case <-time.After(sessionRetryInterval):
+		}
+	}
+}
+
+// serve runs a ticker connected with one API key until ctx is cancelled, it gives up reconnecting,
+// or the session moves to another API key, which needs a new ticker
+func (k *KiteSource) serve(ctx context.Context, apiKey, accessToken string, onTick func(Tick)) {
+	ticker := kiteticker.New(apiKey, accessToken)
+	k.mu.Lock()
+	k.ticker = ticker
+	k.mu.Unlock()
+
+	ticker.OnTick(func(tick kitemodels.Tick) {
+		onTick(convertTick(tick))
+	})
+	ticker.OnConnect(func() {
+		k.mu.Lock()
+		defer k.mu.Unlock()
+
+		k.connected = true
+		tokens := make([]uint32, 0, len(k.tokens))
+		for token := range k.tokens {
+			tokens = append(tokens, token)
+		}
+		if err := k.subscribe(tokens); err != nil {
+			log.Printf("Error subscribing to %d instruments: %v", len(tokens), err)
+		}
+	})
+	ticker.OnReconnect(func(attempt int, delay time.Duration) {
+		k.setConnected(false)
+		log.Printf("Reconnecting to Kite ticker in %s (attempt %d)", delay, attempt)
+
+		// An expired token is the usual cause of a dropped connection, so reconnect with the current session
+		currentKey, currentToken, ok := k.sessions.Session(models.PlatformZerodha)
+		switch {
+		case !ok:
+		case currentKey != apiKey:
+			ticker.Stop()
+		default:
+			ticker.SetAccessToken(currentToken)
+		}
+	})
+	ticker.OnNoReconnect(func(attempt int) {
+		log.Printf("Giving up on Kite ticker after %d reconnect attempts", attempt)
+	})
+	ticker.OnError(func(err error) {
+		log.Printf("Kite ticker error: %v", err)
+	})
+
+	ticker.ServeWithContext(ctx)
+	k.setConnected(false)
+}
+
+// subscribe subscribes the ticker to tokens in quote mode; the caller holds k.mu
+func (k *KiteSource) subscribe(tokens []uint32) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	if err := k.ticker.Subscribe(tokens); err != nil {
+		return err
+	}
+	return k.ticker.SetMode(kiteticker.ModeQuote, tokens)
+}
+
+// setConnected records whether the ticker has a live connection
+func (k *KiteSource) setConnected(connected bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.connected = connected
+}
+
+// convertTick converts a Kite tick, falling back to the receive time when it carries no exchange timestamp
+func convertTick(tick kitemodels.Tick) Tick {
+	timestamp := tick.Timestamp.Time
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	return Tick{
+		Token:         tick.InstrumentToken,
+		LastPrice:     tick.LastPrice,
+		PreviousClose: tick.OHLC.Close,
+		Timestamp:     timestamp,
+	}
+}
@@ -0,0 +1,184 @@
+package streaming
+
+import (
+	"context"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// DefaultUpdateInterval is the minimum time between portfolio updates pushed to a stream
+const DefaultUpdateInterval = time.Second
+
+// PortfolioSource defines the interface for loading a user's stored portfolio
+type PortfolioSource interface {
+	GetPortfolio(ctx context.Context, userID string, holdingType models.HoldingType) (*models.Portfolio, error)
+}
+
+// InstrumentResolver defines the interface for finding the instrument of a holding's ISIN or symbol
+type InstrumentResolver interface {
+	Lookup(identifier string) (models.Instrument, bool)
+}
+
+// ServiceConfig holds configuration for the streaming service
+type ServiceConfig struct {
+	Source      TickSource
+	Portfolios  PortfolioSource
+	Instruments InstrumentResolver
+
+	// UpdateInterval is the minimum time between updates pushed to a stream (default: DefaultUpdateInterval)
+	UpdateInterval time.Duration
+}
+
+// Service streams portfolios revalued at live prices
+type Service struct {
+	hub            *Hub
+	portfolios     PortfolioSource
+	instruments    InstrumentResolver
+	updateInterval time.Duration
+}
+
+// NewService creates a new streaming service
+func NewService(config ServiceConfig) *Service {
+	if config.UpdateInterval <= 0 {
+		config.UpdateInterval = DefaultUpdateInterval
+	}
+
+	return &Service{
+		hub:            NewHub(config.Source),
+		portfolios:     config.Portfolios,
+		instruments:    config.Instruments,
+		updateInterval: config.UpdateInterval,
+	}
+}
+
+// Run delivers ticks from the tick source to streams until ctx is cancelled
+func (s *Service) Run(ctx context.Context) {
+	s.hub.Run(ctx)
+}
+
+// StreamPortfolio streams a user's portfolio, first as stored and then revalued whenever the price of a holding moves.
+// Holdings without an instrument token, such as mutual funds, keep their stored valuation.
+// The channel is closed once ctx is cancelled.
+func (s *Service) StreamPortfolio(ctx context.Context, userID string, holdingType models.HoldingType) (<-chan models.Portfolio, error) {
+	portfolio, err := s.portfolios.GetPortfolio(ctx, userID, holdingType)
+	if err != nil {
+		return nil, err
+	}
+
+	holdingsByToken := make(map[uint32][]int)
+	for i, holding := range portfolio.Holdings {
+		if token := s.token(holding); token != 0 {
+			holdingsByToken[token] = append(holdingsByToken[token], i)
+		}
+	}
+	tokens := make([]uint32, 0, len(holdingsByToken))
+	for token := range holdingsByToken {
+		tokens = append(tokens, token)
+	}
+
+	subscription, err := s.hub.Subscribe(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(chan models.Portfolio, 1)
+	updates <- copyPortfolio(portfolio)
+
+	go func() {
+		defer close(updates)
+		defer s.hub.Unsubscribe(subscription)
+
+		ticker := time.NewTicker(s.updateInterval)
+		defer ticker.Stop()
+
+		changed := false
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-subscription.Ready():
+				for _, tick := range subscription.Drain() {
+					for _, i := range holdingsByToken[tick.Token] {
+						applyTick(&portfolio.Holdings[i], tick)
+						changed = true
+					}
+				}
+			case <-ticker.C:
+				if !changed {
+					continue
+				}
+				calculateTotals(portfolio)
+				select {
+				case updates <- copyPortfolio(portfolio):
+					changed = false
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return updates, nil
+}
+
+// token returns the Kite instrument token of a holding, resolving it by ISIN and then by name
+func (s *Service) token(holding models.Holding) uint32 {
+	if s.instruments == nil || holding.Type == models.HoldingTypeMutualFund {
+		return 0
+	}
+	for _, identifier := range []string{holding.ISIN, holding.ItemName} {
+		if identifier == "" {
+			continue
+		}
+		if instrument, ok := s.instruments.Lookup(identifier); ok {
+			return instrument.KiteToken
+		}
+	}
+	return 0
+}
+
+// applyTick values a holding at the tick's last price, moving its P&L by the change in value.
+// The day change is computed from the previous close when the tick carries one.
+func applyTick(holding *models.Holding, tick Tick) {
+	if tick.LastPrice <= 0 {
+		return
+	}
+
+	value := holding.Quantity * tick.LastPrice
+	holding.TotalPnL += value - holding.CurrentValue
+	holding.LastTradedPrice = tick.LastPrice
+	holding.CurrentValue = value
+	holding.LastUpdated = tick.Timestamp
+
+	if tick.PreviousClose > 0 {
+		holding.DayChange = holding.Quantity * (tick.LastPrice - tick.PreviousClose)
+		holding.DayChangePercent = (tick.LastPrice - tick.PreviousClose) / tick.PreviousClose * 100
+	}
+}
+
+// calculateTotals recalculates the portfolio totals from its holdings
+func calculateTotals(portfolio *models.Portfolio) {
+	portfolio.TotalValue = 0
+	portfolio.TotalDayChange = 0
+	portfolio.TotalPnL = 0
+	for _, holding := range portfolio.Holdings {
+		portfolio.TotalValue += holding.CurrentValue
+		portfolio.TotalDayChange += holding.DayChange
+		portfolio.TotalPnL += holding.TotalPnL
+	}
+
+	portfolio.TotalDayChangePct = 0
+	if portfolio.TotalValue > 0 {
+		portfolio.TotalDayChangePct = (portfolio.TotalDayChange / portfolio.TotalValue) * 100
+	}
+	portfolio.LastUpdated = time.Now()
+}
+
+// copyPortfolio copies a portfolio so it can be sent while the original keeps being revalued
+func copyPortfolio(portfolio *models.Portfolio) models.Portfolio {
+	copied := *portfolio
+	copied.Holdings = make([]models.Holding, len(portfolio.Holdings))
+	copy(copied.Holdings, portfolio.Holdings)
+	return copied
+}
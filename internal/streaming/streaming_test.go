@@ -0,0 +1,257 @@
+package streaming
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSource is a tick source fed by the test, recording the tokens it is subscribed to
+type fakeSource struct {
+	ticks chan Tick
+
+	mu           sync.Mutex
+	subscribed   []uint32
+	unsubscribed []uint32
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{ticks: make(chan Tick)}
+}
+
+func (f *fakeSource) Subscribe(tokens []uint32) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subscribed = append(f.subscribed, tokens...)
+	return nil
+}
+
+func (f *fakeSource) Unsubscribe(tokens []uint32) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unsubscribed = append(f.unsubscribed, tokens...)
+	return nil
+}
+
+func (f *fakeSource) Run(ctx context.Context, onTick func(Tick)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case tick := <-f.ticks:
+			onTick(tick)
+		}
+	}
+}
+
+// calls returns the sorted tokens subscribed and unsubscribed so far
+func (f *fakeSource) calls() ([]uint32, []uint32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	subscribed := append([]uint32(nil), f.subscribed...)
+	unsubscribed := append([]uint32(nil), f.unsubscribed...)
+	sort.Slice(subscribed, func(i, j int) bool { return subscribed[i] < subscribed[j] })
+	sort.Slice(unsubscribed, func(i, j int) bool { return unsubscribed[i] < unsubscribed[j] })
+	return subscribed, unsubscribed
+}
+
+// fakePortfolios serves fixed portfolios by user
+type fakePortfolios map[string][]models.Holding
+
+func (f fakePortfolios) GetPortfolio(ctx context.Context, userID string, holdingType models.HoldingType) (*models.Portfolio, error) {
+	holdings := append([]models.Holding(nil), f[userID]...)
+	portfolio := &models.Portfolio{Holdings: holdings}
+	calculateTotals(portfolio)
+	return portfolio, nil
+}
+
+// fakeInstruments resolves ISINs and symbols to instruments
+type fakeInstruments map[string]models.Instrument
+
+func (f fakeInstruments) Lookup(identifier string) (models.Instrument, bool) {
+	instrument, ok := f[identifier]
+	return instrument, ok
+}
+
+func TestHubRefCounting(t *testing.T) {
+	source := newFakeSource()
+	hub := NewHub(source)
+
+	first, err := hub.Subscribe([]uint32{408065, 2953217})
+	assert.NoError(t, err)
+	second, err := hub.Subscribe([]uint32{408065, 779521})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, hub.SubscribedTokens())
+
+	// The shared token is only subscribed at the source once
+	subscribed, _ := source.calls()
+	assert.Equal(t, []uint32{408065, 779521, 2953217}, subscribed)
+
+	// Releasing the first subscription keeps the shared token
+	hub.Unsubscribe(first)
+	_, unsubscribed := source.calls()
+	assert.Equal(t, []uint32{2953217}, unsubscribed)
+
+	hub.Unsubscribe(second)
+	_, unsubscribed = source.calls()
+	assert.Equal(t, []uint32{408065, 779521, 2953217}, unsubscribed)
+	assert.Equal(t, 0, hub.SubscribedTokens())
+
+	// Releasing twice is a no-op
+	hub.Unsubscribe(second)
+	_, unsubscribed = source.calls()
+	assert.Len(t, unsubscribed, 3)
+}
+
+func TestHubCoalescesTicks(t *testing.T) {
+	hub := NewHub(newFakeSource())
+	subscription, err := hub.Subscribe([]uint32{408065})
+	assert.NoError(t, err)
+
+	hub.publish(Tick{Token: 408065, LastPrice: 1500})
+	hub.publish(Tick{Token: 408065, LastPrice: 1510})
+	// Ticks of tokens no one holds are dropped
+	hub.publish(Tick{Token: 779521, LastPrice: 800})
+
+	<-subscription.Ready()
+	assert.Equal(t, []Tick{{Token: 408065, LastPrice: 1510}}, subscription.Drain())
+	assert.Empty(t, subscription.Drain())
+
+	// A later subscriber starts from the last known price
+	late, err := hub.Subscribe([]uint32{408065})
+	assert.NoError(t, err)
+	<-late.Ready()
+	assert.Equal(t, []Tick{{Token: 408065, LastPrice: 1510}}, late.Drain())
+}
+
+func TestStreamPortfolio(t *testing.T) {
+	source := newFakeSource()
+	service := NewService(ServiceConfig{
+		Source: source,
+		Portfolios: fakePortfolios{
+			"user-1": {
+				{ItemName: "INFY", ISIN: "INE009A01021", Quantity: 10, LastTradedPrice: 1500, CurrentValue: 15000, TotalPnL: 1000, Type: models.HoldingTypeStock},
+				{ItemName: "HDFC Large Cap Fund", ISIN: "INF179K01YV8", Quantity: 100, CurrentValue: 10000, TotalPnL: 500, Type: models.HoldingTypeMutualFund},
+			},
+		},
+		Instruments: fakeInstruments{
+			"INE009A01021": {ISIN: "INE009A01021", NSESymbol: "INFY", KiteToken: 408065},
+		},
+		UpdateInterval: 10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go service.Run(ctx)
+
+	updates, err := service.StreamPortfolio(ctx, "user-1", "")
+	assert.NoError(t, err)
+
+	// The stored portfolio comes first
+	initial := <-updates
+	assert.Equal(t, 25000.0, initial.TotalValue)
+
+	// Only the listed holding is subscribed
+	subscribed, _ := source.calls()
+	assert.Equal(t, []uint32{408065}, subscribed)
+
+	timestamp := time.Date(2025, 10, 14, 10, 15, 0, 0, time.UTC)
+	source.ticks <- Tick{Token: 408065, LastPrice: 1520, PreviousClose: 1490, Timestamp: timestamp}
+
+	update := <-updates
+	infy := update.Holdings[0]
+	assert.Equal(t, 1520.0, infy.LastTradedPrice)
+	assert.Equal(t, 15200.0, infy.CurrentValue)
+	assert.InDelta(t, 300.0, infy.DayChange, 1e-9)
+	assert.InDelta(t, 2.0134, infy.DayChangePercent, 1e-4)
+	assert.Equal(t, 1200.0, infy.TotalPnL)
+	assert.Equal(t, timestamp, infy.LastUpdated)
+	// The mutual fund keeps its stored valuation
+	assert.Equal(t, 10000.0, update.Holdings[1].CurrentValue)
+	assert.Equal(t, 25200.0, update.TotalValue)
+	assert.InDelta(t, 300.0, update.TotalDayChange, 1e-9)
+	assert.Equal(t, 1700.0, update.TotalPnL)
+
+	// Cancelling the stream closes it and releases its tokens
+	cancel()
+	for range updates {
+	}
+	_, unsubscribed := source.calls()
+	assert.Equal(t, []uint32{408065}, unsubscribed)
+}
+
+// countingSessions reports no session, counting how often it is asked
+type countingSessions struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (s *countingSessions) Session(clientType string) (string, string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	return "", "", false
+}
+
+func TestKiteSourceWaitsForSession(t *testing.T) {
+	sessions := &countingSessions{}
+	source := NewKiteSource(sessions)
+
+	// Without a session nothing connects, and subscriptions are kept for when one does
+	assert.NoError(t, source.Subscribe([]uint32{408065}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		source.Run(ctx, func(Tick) {})
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		sessions.mu.Lock()
+		defer sessions.mu.Unlock()
+		return sessions.calls > 0
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
+
+func TestStaticSession(t *testing.T) {
+	apiKey, token, ok := StaticSession{APIKey: "key", AccessToken: "token"}.Session(models.PlatformZerodha)
+	assert.True(t, ok)
+	assert.Equal(t, "key", apiKey)
+	assert.Equal(t, "token", token)
+
+	_, _, ok = StaticSession{APIKey: "key"}.Session(models.PlatformZerodha)
+	assert.False(t, ok)
+}
+
+// fakeUserSessions holds the sessions of users by ID
+type fakeUserSessions map[string][2]string
+
+func (s fakeUserSessions) UserSession(userID, clientType string) (string, string, bool) {
+	session, ok := s[userID]
+	return session[0], session[1], ok
+}
+
+func TestAccountSession(t *testing.T) {
+	sessions := fakeUserSessions{"alice": {"alice-key", "alice-token"}}
+
+	// Only the ticker account's session is used
+	apiKey, token, ok := AccountSession{Sessions: sessions, UserID: "alice"}.Session(models.PlatformZerodha)
+	assert.True(t, ok)
+	assert.Equal(t, "alice-key", apiKey)
+	assert.Equal(t, "alice-token", token)
+
+	_, _, ok = AccountSession{Sessions: sessions, UserID: "bob"}.Session(models.PlatformZerodha)
+	assert.False(t, ok, "another user's session is never borrowed")
+}
@@ -0,0 +1,121 @@
+package tax
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Kora1128/FinSight/internal/marketdata"
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// ErrInvalidFairMarketValues is returned when an uploaded file holds no usable 31-Jan-2018 values
+var ErrInvalidFairMarketValues = errors.New("invalid fair market values")
+
+// Header columns of a CSV listing fair market values directly, as used for fund NAVs on 31-Jan-2018
+const (
+	fmvISINColumn  = "isin"
+	fmvValueColumn = "fair_market_value"
+)
+
+// ImportFairMarketValues stores the 31-Jan-2018 fair market values used for grandfathering from uploaded files.
+// Each file is either an NSE or BSE bhavcopy of 31 January 2018, as CSV or zip, whose listed securities are valued
+// at the highest price quoted that day across all the files as section 55(2)(ac) requires, or a CSV with
+// isin,fair_market_value columns for units valued otherwise, such as mutual funds at their NAV.
+func (s *Service) ImportFairMarketValues(files [][]byte) (*models.FairMarketValueImportResult, error) {
+	values := make(map[string]float64)
+	for i, data := range files {
+		var err error
+		if isFairMarketValueCSV(data) {
+			err = readFairMarketValueCSV(data, values)
+		} else {
+			err = readGrandfatheringBhavcopy(data, values)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("file %d: %w", i+1, err)
+		}
+	}
+
+	for isin, value := range values {
+		if err := s.fairMarketValueRepository.SaveFairMarketValue(isin, value); err != nil {
+			return nil, err
+		}
+	}
+	return &models.FairMarketValueImportResult{Files: len(files), Imported: len(values)}, nil
+}
+
+// readGrandfatheringBhavcopy keeps the highest price of each security in a bhavcopy of 31 January 2018
+func readGrandfatheringBhavcopy(data []byte, values map[string]float64) error {
+	bars, err := marketdata.ParseBhavcopyData(data)
+	if err != nil {
+		return err
+	}
+
+	cutoff := grandfatheringCutoff.AddDate(0, 0, -1)
+	found := false
+	for _, bar := range bars {
+		if bar.Date.Year() != cutoff.Year() || bar.Date.Month() != cutoff.Month() || bar.Date.Day() != cutoff.Day() {
+			continue
+		}
+		found = true
+
+		high := bar.High
+		if high <= 0 {
+			high = bar.Close
+		}
+		if high > values[bar.ISIN] {
+			values[bar.ISIN] = high
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: the bhavcopy has no prices for 31-Jan-2018", ErrInvalidFairMarketValues)
+	}
+	return nil
+}
+
+// isFairMarketValueCSV reports whether a file starts with the header of a fair market value CSV
+func isFairMarketValueCSV(data []byte) bool {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	header := strings.ToLower(strings.TrimPrefix(string(line), "\ufeff"))
+	return strings.Contains(header, fmvISINColumn) && strings.Contains(header, fmvValueColumn)
+}
+
+// readFairMarketValueCSV reads the values of a CSV with isin,fair_market_value columns
+func readFairMarketValueCSV(data []byte, values map[string]float64) error {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFairMarketValues, err)
+	}
+
+	isinIndex, valueIndex := -1, -1
+	for i, name := range records[0] {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) {
+		case fmvISINColumn:
+			isinIndex = i
+		case fmvValueColumn:
+			valueIndex = i
+		}
+	}
+	if isinIndex < 0 || valueIndex < 0 {
+		return fmt.Errorf("%w: expected %s and %s columns", ErrInvalidFairMarketValues, fmvISINColumn, fmvValueColumn)
+	}
+
+	for line, record := range records[1:] {
+		if len(record) <= isinIndex || len(record) <= valueIndex {
+			return fmt.Errorf("%w: line %d: missing columns", ErrInvalidFairMarketValues, line+2)
+		}
+		isin := strings.ToUpper(strings.TrimSpace(record[isinIndex]))
+		value, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(record[valueIndex]), ",", ""), 64)
+		if isin == "" || err != nil || value <= 0 {
+			return fmt.Errorf("%w: line %d: invalid row", ErrInvalidFairMarketValues, line+2)
+		}
+		values[isin] = value
+	}
+	return nil
+}
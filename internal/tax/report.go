@@ -0,0 +1,191 @@
+package tax
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Kora1128/FinSight/internal/costbasis"
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// BuildReport computes the capital gains realized in a financial year from the disposals in a cost basis book.
+// fairMarketValues maps ISINs to their 31-Jan-2018 value per unit for grandfathering equity bought before February 2018.
+func BuildReport(fy FinancialYear, book *costbasis.Book, fairMarketValues map[string]float64) *models.CapitalGainsReport {
+	report := &models.CapitalGainsReport{
+		FinancialYear: fy.Label,
+		From:          fy.From,
+		To:            fy.To.AddDate(0, 0, -1),
+		Entries:       []models.CapitalGainEntry{},
+	}
+
+	missingFMV := make(map[string]bool)
+	for _, position := range book.Positions() {
+		name := position.Symbol
+		if name == "" {
+			name = position.ISIN
+		}
+
+		// Without an ISIN the tax treatment is unknown, so the sales are reported rather than guessed at
+		if position.ISIN == "" {
+			if sales := countSales(position, fy); sales > 0 {
+				report.Warnings = append(report.Warnings, fmt.Sprintf(
+					"%s (%s): %d sales are excluded because the security's ISIN is unknown, so its tax treatment cannot be determined",
+					name, position.Platform, sales))
+			}
+			continue
+		}
+		class := ClassifyAsset(position.ISIN, name)
+
+		if !position.Complete() {
+			report.Warnings = append(report.Warnings, fmt.Sprintf(
+				"%s (%s): %.4g units were sold without matching purchases and are excluded; import older tradebooks to include them",
+				name, position.Platform, position.Unmatched))
+		}
+
+		for _, disposal := range position.Disposals {
+			if !fy.Contains(disposal.SoldAt) {
+				continue
+			}
+
+			entry := newEntry(position, name, class, disposal)
+			if entry.Section == Section112A && disposal.AcquiredAt.Before(grandfatheringCutoff) {
+				if fmv, ok := fairMarketValues[position.ISIN]; ok {
+					entry.FairMarketValue = fmv
+					entry.CostOfAcquisition = grandfatheredCost(entry.ActualCost, fmv*entry.Quantity, entry.SaleValue)
+					entry.Gain = entry.SaleValue - entry.CostOfAcquisition - entry.TransferExpenses
+				} else {
+					missingFMV[name] = true
+				}
+			}
+
+			report.Entries = append(report.Entries, entry)
+		}
+	}
+
+	for _, name := range sortedKeys(missingFMV) {
+		report.Warnings = append(report.Warnings, fmt.Sprintf(
+			"%s: no 31-Jan-2018 fair market value on record, so actual cost is used for units bought before February 2018", name))
+	}
+
+	sort.SliceStable(report.Entries, func(i, j int) bool {
+		return report.Entries[i].SoldAt.Before(report.Entries[j].SoldAt)
+	})
+
+	report.Summary = summarize(fy, report.Entries)
+	return report
+}
+
+// countSales returns the number of disposals of a position in a financial year
+func countSales(position *costbasis.Position, fy FinancialYear) int {
+	sales := 0
+	for _, disposal := range position.Disposals {
+		if fy.Contains(disposal.SoldAt) {
+			sales++
+		}
+	}
+	return sales
+}
+
+// newEntry converts a disposal into a report entry without grandfathering
+func newEntry(position *costbasis.Position, name string, class models.AssetClass, disposal costbasis.Disposal) models.CapitalGainEntry {
+	treatment := classifyGain(class, disposal.AcquiredAt, disposal.SoldAt)
+	cost := disposal.Cost()
+
+	return models.CapitalGainEntry{
+		ISIN:              position.ISIN,
+		Name:              name,
+		Platform:          position.Platform,
+		AssetClass:        class,
+		GainType:          treatment.gainType,
+		Section:           treatment.section,
+		AcquiredAt:        disposal.AcquiredAt,
+		SoldAt:            disposal.SoldAt,
+		Quantity:          disposal.Quantity,
+		SalePrice:         disposal.UnitSalePrice,
+		SaleValue:         disposal.SaleValue(),
+		ActualCost:        cost,
+		CostOfAcquisition: cost,
+		TransferExpenses:  disposal.SaleCharges,
+		Gain:              disposal.SaleValue() - cost - disposal.SaleCharges,
+		TaxRate:           treatment.rate,
+	}
+}
+
+// grandfatheredCost applies section 55(2)(ac): the higher of actual cost and the lower of fair market value and sale value
+func grandfatheredCost(actualCost, fairMarketValue, saleValue float64) float64 {
+	return math.Max(actualCost, math.Min(fairMarketValue, saleValue))
+}
+
+// summarize totals the entries and estimates tax on gains taxed at special rates.
+// Gains and losses are netted within each section and rate; the 112A exemption is used against the highest rate first.
+// Set-off of losses across sections and carried forward losses are not considered.
+func summarize(fy FinancialYear, entries []models.CapitalGainEntry) models.CapitalGainsSummary {
+	var summary models.CapitalGainsSummary
+
+	type bucket struct {
+		section string
+		rate    float64
+	}
+	nets := make(map[bucket]float64)
+
+	for _, entry := range entries {
+		switch {
+		case entry.AssetClass == models.AssetClassEquity && entry.GainType == models.GainTypeShortTerm:
+			summary.EquitySTCG += entry.Gain
+		case entry.AssetClass == models.AssetClassEquity:
+			summary.EquityLTCG += entry.Gain
+		case entry.AssetClass == models.AssetClassDebtFund:
+			summary.DebtFundGains += entry.Gain
+		case entry.GainType == models.GainTypeShortTerm:
+			summary.OtherSTCG += entry.Gain
+		default:
+			summary.OtherLTCG += entry.Gain
+		}
+
+		if entry.TaxRate == 0 {
+			summary.SlabRateGains += entry.Gain
+			continue
+		}
+		nets[bucket{entry.Section, entry.TaxRate}] += entry.Gain
+	}
+
+	if summary.EquityLTCG > 0 {
+		summary.LTCGExemption = math.Min(summary.EquityLTCG, fy.LTCGExemption())
+	}
+	summary.TaxableEquityLTCG = math.Max(0, summary.EquityLTCG-summary.LTCGExemption)
+
+	buckets := make([]bucket, 0, len(nets))
+	for b := range nets {
+		buckets = append(buckets, b)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].section != buckets[j].section {
+			return buckets[i].section < buckets[j].section
+		}
+		return buckets[i].rate > buckets[j].rate
+	})
+
+	exemption := summary.LTCGExemption
+	for _, b := range buckets {
+		taxable := math.Max(0, nets[b])
+		if b.section == Section112A {
+			used := math.Min(exemption, taxable)
+			taxable -= used
+			exemption -= used
+		}
+		summary.EstimatedTax += taxable * b.rate / 100
+	}
+
+	return summary
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
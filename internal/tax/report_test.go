@@ -0,0 +1,152 @@
+package tax
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/costbasis"
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testTrade(isin, symbol string, side models.TradeSide, at time.Time, quantity, price, charges float64) models.Trade {
+	return models.Trade{
+		TradeID:   symbol + at.Format("20060102") + string(side),
+		ISIN:      isin,
+		Symbol:    symbol,
+		Side:      side,
+		Quantity:  quantity,
+		Price:     price,
+		Charges:   charges,
+		TradeDate: at,
+		Platform:  models.PlatformZerodha,
+	}
+}
+
+func buildTestReport(t *testing.T) *models.CapitalGainsReport {
+	t.Helper()
+	buy, sell := models.TradeSideBuy, models.TradeSideSell
+
+	trades := []models.Trade{
+		// Grandfathered: cost is max(7000, min(9500, 14000)) = 9500; gain 14000 - 9500 - 20 = 4480
+		testTrade("INE002A01018", "RELIANCE", buy, date(2017, 6, 1), 10, 700, 0),
+		testTrade("INE002A01018", "RELIANCE", sell, date(2025, 5, 10), 10, 1400, 20),
+		// Long term: 100 * (4100 - 2500) = 160000
+		testTrade("INE467B01029", "TCS", buy, date(2024, 1, 15), 100, 2500, 0),
+		testTrade("INE467B01029", "TCS", sell, date(2025, 6, 1), 100, 4100, 0),
+		// Sold in the previous year, excluded
+		testTrade("INE009A01021", "INFY", buy, date(2024, 1, 1), 10, 1000, 0),
+		testTrade("INE009A01021", "INFY", sell, date(2024, 6, 1), 10, 1100, 0),
+		// Short term loss: 14000 - 15000 - 10 = -1010
+		testTrade("INE009A01021", "INFY", buy, date(2025, 4, 10), 10, 1500, 0),
+		testTrade("INE009A01021", "INFY", sell, date(2025, 8, 1), 10, 1400, 10),
+		// Specified fund: 2 * (5000 - 4500) = 1000 at slab rates
+		testTrade("INF179KB1HK0", "HDFC Liquid Fund - Direct Growth", buy, date(2023, 6, 1), 2, 4500, 0),
+		testTrade("INF179KB1HK0", "HDFC Liquid Fund - Direct Growth", sell, date(2025, 7, 1), 2, 5000, 0),
+		// Bought before 2018 without a recorded fair market value: 10 * (400 - 200) = 2000
+		testTrade("INE154A01025", "ITC", buy, date(2016, 3, 1), 10, 200, 0),
+		testTrade("INE154A01025", "ITC", sell, date(2025, 9, 1), 10, 400, 0),
+		// Sale without purchase history
+		testTrade("INE075A01022", "WIPRO", sell, date(2025, 10, 1), 5, 250, 0),
+	}
+
+	fy, err := ParseFinancialYear("2025-26")
+	assert.NoError(t, err)
+
+	return BuildReport(fy, costbasis.Build(trades, nil), map[string]float64{
+		"INE002A01018": 950,
+	})
+}
+
+func TestBuildReport(t *testing.T) {
+	report := buildTestReport(t)
+
+	assert.Equal(t, "2025-26", report.FinancialYear)
+	assert.Equal(t, date(2026, 3, 31), report.To)
+	assert.Len(t, report.Entries, 5)
+
+	reliance := report.Entries[0]
+	assert.Equal(t, "RELIANCE", reliance.Name)
+	assert.Equal(t, models.GainTypeLongTerm, reliance.GainType)
+	assert.Equal(t, Section112A, reliance.Section)
+	assert.Equal(t, 14000.0, reliance.SaleValue)
+	assert.Equal(t, 7000.0, reliance.ActualCost)
+	assert.Equal(t, 950.0, reliance.FairMarketValue)
+	assert.Equal(t, 9500.0, reliance.CostOfAcquisition)
+	assert.Equal(t, 20.0, reliance.TransferExpenses)
+	assert.Equal(t, 4480.0, reliance.Gain)
+	assert.Equal(t, 12.5, reliance.TaxRate)
+
+	// Entries are ordered by sale date
+	assert.Equal(t, "TCS", report.Entries[1].Name)
+	assert.Equal(t, models.AssetClassDebtFund, report.Entries[2].AssetClass)
+	assert.Equal(t, Section50AA, report.Entries[2].Section)
+	assert.Equal(t, Section111A, report.Entries[3].Section)
+	assert.Equal(t, -1010.0, report.Entries[3].Gain)
+	assert.Equal(t, 2000.0, report.Entries[4].Gain)
+
+	summary := report.Summary
+	assert.Equal(t, -1010.0, summary.EquitySTCG)
+	assert.Equal(t, 166480.0, summary.EquityLTCG)
+	assert.Equal(t, 125000.0, summary.LTCGExemption)
+	assert.Equal(t, 41480.0, summary.TaxableEquityLTCG)
+	assert.Equal(t, 1000.0, summary.DebtFundGains)
+	assert.Equal(t, 1000.0, summary.SlabRateGains)
+	assert.InDelta(t, 41480*0.125, summary.EstimatedTax, 1e-9)
+
+	assert.Len(t, report.Warnings, 2)
+	assert.Contains(t, report.Warnings[0], "WIPRO")
+	assert.Contains(t, report.Warnings[1], "ITC")
+}
+
+func TestExemptionAppliedToHigherRateFirst(t *testing.T) {
+	buy, sell := models.TradeSideBuy, models.TradeSideSell
+	trades := []models.Trade{
+		// 60000 at 10% before 23 July 2024 and 100000 at 12.5% after
+		testTrade("INE467B01029", "TCS", buy, date(2023, 1, 2), 100, 1000, 0),
+		testTrade("INE467B01029", "TCS", sell, date(2024, 5, 2), 50, 2200, 0),
+		testTrade("INE467B01029", "TCS", sell, date(2024, 9, 2), 50, 3000, 0),
+	}
+
+	fy, _ := ParseFinancialYear("2024-25")
+	report := BuildReport(fy, costbasis.Build(trades, nil), nil)
+
+	// 125000 exempts all of the 12.5% gains and 25000 of the 10% gains
+	assert.Equal(t, 160000.0, report.Summary.EquityLTCG)
+	assert.InDelta(t, 35000*0.10, report.Summary.EstimatedTax, 1e-9)
+}
+
+func TestWriteSchedule112A(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteSchedule112A(&buf, buildTestReport(t)))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+
+	// Header plus the three section 112A entries
+	assert.Len(t, records, 4)
+	assert.Len(t, records[0], 14)
+
+	assert.Equal(t, []string{
+		"On or before 31st January 2018",
+		"INE002A01018",
+		"RELIANCE",
+		"10",
+		"1400.00",
+		"14000.00",
+		"9500.00",
+		"7000.00",
+		"9500.00",
+		"950.00",
+		"9500.00",
+		"20.00",
+		"9520.00",
+		"4480.00",
+	}, records[1])
+
+	assert.Equal(t, "After 31st January 2018", records[2][0])
+	assert.Equal(t, "", records[2][9])
+	assert.Equal(t, "160000.00", records[2][13])
+}
@@ -0,0 +1,176 @@
+// Package tax computes Indian capital gains on realized sales reconstructed from trade history.
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// ErrInvalidFinancialYear is returned for financial years not in the 2025-26 form
+var ErrInvalidFinancialYear = errors.New("financial year must look like 2025-26")
+
+// ist is Indian Standard Time, in which financial years and holding periods are reckoned
+var ist = time.FixedZone("IST", 5*60*60+30*60)
+
+var (
+	// grandfatheringCutoff is the date before which equity acquisitions are grandfathered at their 31-Jan-2018 value
+	grandfatheringCutoff = time.Date(2018, 2, 1, 0, 0, 0, 0, ist)
+
+	// specifiedFundCutoff is the date from which debt fund purchases are always short term (section 50AA)
+	specifiedFundCutoff = time.Date(2023, 4, 1, 0, 0, 0, 0, ist)
+
+	// budget2024 is the date the Finance (No. 2) Act 2024 rates and holding periods apply from
+	budget2024 = time.Date(2024, 7, 23, 0, 0, 0, 0, ist)
+)
+
+// Tax sections gains are reported under
+const (
+	Section111A = "111A"
+	Section112A = "112A"
+	Section112  = "112"
+	Section50AA = "50AA"
+	SectionSlab = "slab"
+)
+
+// FinancialYear represents an Indian financial year running from 1 April to 31 March
+type FinancialYear struct {
+	Label string
+	From  time.Time
+	To    time.Time // exclusive
+}
+
+// Contains reports whether t falls in the financial year
+func (fy FinancialYear) Contains(t time.Time) bool {
+	return !t.Before(fy.From) && t.Before(fy.To)
+}
+
+// LTCGExemption returns the annual exemption on section 112A gains
+func (fy FinancialYear) LTCGExemption() float64 {
+	if fy.From.Year() >= 2024 {
+		return 125000
+	}
+	return 100000
+}
+
+// ParseFinancialYear parses a financial year label such as 2025-26
+func ParseFinancialYear(label string) (FinancialYear, error) {
+	parts := strings.Split(strings.TrimSpace(label), "-")
+	if len(parts) != 2 || len(parts[0]) != 4 || len(parts[1]) != 2 {
+		return FinancialYear{}, ErrInvalidFinancialYear
+	}
+
+	start, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return FinancialYear{}, ErrInvalidFinancialYear
+	}
+	end, err := strconv.Atoi(parts[1])
+	if err != nil || end != (start+1)%100 {
+		return FinancialYear{}, ErrInvalidFinancialYear
+	}
+
+	return financialYearStarting(start), nil
+}
+
+// CurrentFinancialYear returns the financial year containing t
+func CurrentFinancialYear(t time.Time) FinancialYear {
+	t = t.In(ist)
+	start := t.Year()
+	if t.Month() < time.April {
+		start--
+	}
+	return financialYearStarting(start)
+}
+
+// financialYearStarting returns the financial year beginning on 1 April of the given year
+func financialYearStarting(year int) FinancialYear {
+	return FinancialYear{
+		Label: fmt.Sprintf("%d-%02d", year, (year+1)%100),
+		From:  time.Date(year, time.April, 1, 0, 0, 0, 0, ist),
+		To:    time.Date(year+1, time.April, 1, 0, 0, 0, 0, ist),
+	}
+}
+
+// debtFundKeywords identify non-equity mutual fund schemes by name
+var debtFundKeywords = []string{
+	"liquid", "debt", "gilt", "bond", "overnight", "money market", "corporate", "credit risk",
+	"banking and psu", "banking & psu", "duration", "floater", "floating", "income", "treasury",
+	"fixed maturity", "fmp", "target maturity", "sdl", "savings",
+}
+
+// otherFundKeywords identify funds taxed like other capital assets
+var otherFundKeywords = []string{"gold", "silver"}
+
+// ClassifyAsset determines the tax treatment of a security from its ISIN and name.
+// Equity shares carry security type 01 in their ISIN; fund schemes are split by name since the ISIN does not say.
+func ClassifyAsset(isin, name string) models.AssetClass {
+	isin = strings.ToUpper(isin)
+	lowerName := strings.ToLower(name)
+
+	switch {
+	case len(isin) == 12 && strings.HasPrefix(isin, "INE") && isin[7:9] == "01":
+		return models.AssetClassEquity
+	case strings.HasPrefix(isin, "INF"):
+		for _, keyword := range otherFundKeywords {
+			if strings.Contains(lowerName, keyword) {
+				return models.AssetClassOther
+			}
+		}
+		for _, keyword := range debtFundKeywords {
+			if strings.Contains(lowerName, keyword) {
+				return models.AssetClassDebtFund
+			}
+		}
+		return models.AssetClassEquity
+	default:
+		return models.AssetClassOther
+	}
+}
+
+// treatment describes how a sale is taxed
+type treatment struct {
+	gainType models.GainType
+	section  string
+	rate     float64
+}
+
+// classifyGain applies the holding period and rate rules for an asset class
+func classifyGain(class models.AssetClass, acquiredAt, soldAt time.Time) treatment {
+	newRegime := !soldAt.Before(budget2024)
+
+	switch class {
+	case models.AssetClassEquity:
+		if soldAt.After(acquiredAt.AddDate(1, 0, 0)) {
+			if newRegime {
+				return treatment{models.GainTypeLongTerm, Section112A, 12.5}
+			}
+			return treatment{models.GainTypeLongTerm, Section112A, 10}
+		}
+		if newRegime {
+			return treatment{models.GainTypeShortTerm, Section111A, 20}
+		}
+		return treatment{models.GainTypeShortTerm, Section111A, 15}
+
+	case models.AssetClassDebtFund:
+		if !acquiredAt.Before(specifiedFundCutoff) {
+			return treatment{models.GainTypeShortTerm, Section50AA, 0}
+		}
+	}
+
+	// Other assets, and debt funds bought before April 2023
+	months := 36
+	if newRegime {
+		months = 24
+	}
+	if soldAt.After(acquiredAt.AddDate(0, months, 0)) {
+		if newRegime {
+			return treatment{models.GainTypeLongTerm, Section112, 12.5}
+		}
+		return treatment{models.GainTypeLongTerm, Section112, 20}
+	}
+	return treatment{models.GainTypeShortTerm, SectionSlab, 0}
+}
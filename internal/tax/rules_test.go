@@ -0,0 +1,148 @@
+package tax
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, ist)
+}
+
+func TestParseFinancialYear(t *testing.T) {
+	tests := []struct {
+		label    string
+		wantErr  bool
+		wantFrom time.Time
+		wantTo   time.Time
+	}{
+		{label: "2025-26", wantFrom: date(2025, 4, 1), wantTo: date(2026, 4, 1)},
+		{label: "1999-00", wantFrom: date(1999, 4, 1), wantTo: date(2000, 4, 1)},
+		{label: "2025-27", wantErr: true},
+		{label: "2025", wantErr: true},
+		{label: "25-26", wantErr: true},
+		{label: "abcd-ef", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			fy, err := ParseFinancialYear(tt.label)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidFinancialYear)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.label, fy.Label)
+			assert.Equal(t, tt.wantFrom, fy.From)
+			assert.Equal(t, tt.wantTo, fy.To)
+		})
+	}
+
+	assert.Equal(t, "2025-26", CurrentFinancialYear(date(2026, 3, 31)).Label)
+	assert.Equal(t, "2026-27", CurrentFinancialYear(date(2026, 4, 1)).Label)
+}
+
+func TestLTCGExemption(t *testing.T) {
+	fy, _ := ParseFinancialYear("2023-24")
+	assert.Equal(t, 100000.0, fy.LTCGExemption())
+	fy, _ = ParseFinancialYear("2024-25")
+	assert.Equal(t, 125000.0, fy.LTCGExemption())
+}
+
+func TestClassifyAsset(t *testing.T) {
+	tests := []struct {
+		isin string
+		name string
+		want models.AssetClass
+	}{
+		{"INE002A01018", "RELIANCE", models.AssetClassEquity},
+		{"INE020B08DA7", "REC LTD NCD", models.AssetClassOther},
+		{"INF179K01VY8", "HDFC Flexi Cap Fund - Direct Plan - Growth", models.AssetClassEquity},
+		{"INF179KB1HK0", "HDFC Liquid Fund - Direct Growth", models.AssetClassDebtFund},
+		{"INF209K01YY7", "ABSL Banking & PSU Debt Fund", models.AssetClassDebtFund},
+		{"INF204KB17I5", "Nippon India ETF Gold BeES", models.AssetClassOther},
+		{"IN0020230085", "SGBJUN31", models.AssetClassOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClassifyAsset(tt.isin, tt.name))
+		})
+	}
+}
+
+func TestClassifyGain(t *testing.T) {
+	tests := []struct {
+		name       string
+		class      models.AssetClass
+		acquiredAt time.Time
+		soldAt     time.Time
+		want       treatment
+	}{
+		{
+			name:       "equity held exactly twelve months is short term",
+			class:      models.AssetClassEquity,
+			acquiredAt: date(2023, 1, 10),
+			soldAt:     date(2024, 1, 10),
+			want:       treatment{models.GainTypeShortTerm, Section111A, 15},
+		},
+		{
+			name:       "equity held a day over twelve months is long term",
+			class:      models.AssetClassEquity,
+			acquiredAt: date(2023, 1, 10),
+			soldAt:     date(2024, 1, 11),
+			want:       treatment{models.GainTypeLongTerm, Section112A, 10},
+		},
+		{
+			name:       "equity sold from 23 July 2024 uses new rates",
+			class:      models.AssetClassEquity,
+			acquiredAt: date(2023, 1, 10),
+			soldAt:     date(2024, 7, 23),
+			want:       treatment{models.GainTypeLongTerm, Section112A, 12.5},
+		},
+		{
+			name:       "equity short term at new rate",
+			class:      models.AssetClassEquity,
+			acquiredAt: date(2024, 5, 10),
+			soldAt:     date(2024, 8, 1),
+			want:       treatment{models.GainTypeShortTerm, Section111A, 20},
+		},
+		{
+			name:       "debt fund bought from April 2023 is always short term",
+			class:      models.AssetClassDebtFund,
+			acquiredAt: date(2023, 4, 1),
+			soldAt:     date(2027, 4, 1),
+			want:       treatment{models.GainTypeShortTerm, Section50AA, 0},
+		},
+		{
+			name:       "older debt fund follows other asset rules",
+			class:      models.AssetClassDebtFund,
+			acquiredAt: date(2021, 1, 1),
+			soldAt:     date(2024, 3, 1),
+			want:       treatment{models.GainTypeLongTerm, Section112, 20},
+		},
+		{
+			name:       "other asset needed 36 months before July 2024",
+			class:      models.AssetClassOther,
+			acquiredAt: date(2022, 1, 1),
+			soldAt:     date(2024, 6, 1),
+			want:       treatment{models.GainTypeShortTerm, SectionSlab, 0},
+		},
+		{
+			name:       "other asset needs 24 months from July 2024",
+			class:      models.AssetClassOther,
+			acquiredAt: date(2022, 1, 1),
+			soldAt:     date(2024, 8, 1),
+			want:       treatment{models.GainTypeLongTerm, Section112, 12.5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyGain(tt.class, tt.acquiredAt, tt.soldAt))
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package tax
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// schedule112AHeader lists the columns of Schedule 112A in ITR-2 and ITR-3
+var schedule112AHeader = []string{
+	"Share/Unit acquired",
+	"ISIN Code",
+	"Name of the Share/Unit",
+	"No. of Shares/Units",
+	"Sale-price per Share/Unit",
+	"Full Value of Consideration",
+	"Cost of acquisition without indexation",
+	"Cost of acquisition",
+	"If the long term capital asset was acquired before 01.02.2018, Lower of 6 and 11",
+	"Fair Market Value per share/unit as on 31st January 2018",
+	"Total Fair Market Value of capital asset as per section 55(2)(ac)",
+	"Expenditure wholly and exclusively in connection with transfer",
+	"Total deductions",
+	"Balance",
+}
+
+// WriteSchedule112A writes the section 112A entries of a report as CSV in the Schedule 112A column layout
+func WriteSchedule112A(w io.Writer, report *models.CapitalGainsReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(schedule112AHeader); err != nil {
+		return err
+	}
+
+	for _, entry := range report.Entries {
+		if entry.Section != Section112A {
+			continue
+		}
+
+		acquired := "After 31st January 2018"
+		lowerOfSaleAndFMV, totalFMV := "", ""
+		if entry.AcquiredAt.Before(grandfatheringCutoff) {
+			acquired = "On or before 31st January 2018"
+			if entry.FairMarketValue > 0 {
+				fmv := entry.FairMarketValue * entry.Quantity
+				totalFMV = amount(fmv)
+				if fmv < entry.SaleValue {
+					lowerOfSaleAndFMV = amount(fmv)
+				} else {
+					lowerOfSaleAndFMV = amount(entry.SaleValue)
+				}
+			}
+		}
+
+		fmvPerUnit := ""
+		if entry.FairMarketValue > 0 {
+			fmvPerUnit = amount(entry.FairMarketValue)
+		}
+
+		record := []string{
+			acquired,
+			entry.ISIN,
+			entry.Name,
+			strconv.FormatFloat(entry.Quantity, 'f', -1, 64),
+			amount(entry.SalePrice),
+			amount(entry.SaleValue),
+			amount(entry.CostOfAcquisition),
+			amount(entry.ActualCost),
+			lowerOfSaleAndFMV,
+			fmvPerUnit,
+			totalFMV,
+			amount(entry.TransferExpenses),
+			amount(entry.CostOfAcquisition + entry.TransferExpenses),
+			amount(entry.Gain),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// amount formats a rupee amount with two decimals
+func amount(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
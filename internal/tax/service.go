@@ -0,0 +1,141 @@
+package tax
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/costbasis"
+	"github.com/Kora1128/FinSight/internal/models"
+)
+
+// TradeRepository defines the trade history the tax service reads
+type TradeRepository interface {
+	// GetTrades retrieves all trades for a user ordered by trade date
+	GetTrades(userID string) ([]models.Trade, error)
+}
+
+// CorporateActionRepository defines the splits and bonus issues the tax service reads
+type CorporateActionRepository interface {
+	// GetCorporateActions retrieves the actions for the given securities that took effect on or before the given date
+	GetCorporateActions(isins []string, asOf time.Time) ([]models.CorporateAction, error)
+}
+
+// FairMarketValueRepository defines the 31-Jan-2018 values used for grandfathering
+type FairMarketValueRepository interface {
+	// SaveFairMarketValue stores the 31-Jan-2018 value per unit of a security
+	SaveFairMarketValue(isin string, value float64) error
+
+	// GetFairMarketValues retrieves the 31-Jan-2018 value per unit of the given securities, keyed by ISIN
+	GetFairMarketValues(isins []string) (map[string]float64, error)
+}
+
+// HoldingRepository defines the holdings the tax service resolves trade ISINs from
+type HoldingRepository interface {
+	// GetLatestSnapshotHoldings retrieves the holdings of the most recent snapshot of a user
+	GetLatestSnapshotHoldings(userID string) ([]models.Holding, bool, error)
+}
+
+// InstrumentResolver defines the interface for resolving trade symbols to ISINs
+type InstrumentResolver interface {
+	// Lookup resolves an ISIN, exchange symbol, BSE code or company name to an instrument
+	Lookup(identifier string) (models.Instrument, bool)
+}
+
+// ServiceConfig holds configuration for the tax service
+type ServiceConfig struct {
+	TradeRepository           TradeRepository
+	CorporateActionRepository CorporateActionRepository
+	FairMarketValueRepository FairMarketValueRepository
+
+	// HoldingRepository and Instruments resolve the ISINs of trades recorded without one, such as those of
+	// ICICI Direct tradebooks (optional)
+	HoldingRepository HoldingRepository
+	Instruments       InstrumentResolver
+}
+
+// Service builds capital gains reports from users' trade history
+type Service struct {
+	tradeRepository           TradeRepository
+	corporateActionRepository CorporateActionRepository
+	fairMarketValueRepository FairMarketValueRepository
+	holdingRepository         HoldingRepository
+	instruments               InstrumentResolver
+}
+
+// NewService creates a new tax service
+func NewService(config ServiceConfig) *Service {
+	return &Service{
+		tradeRepository:           config.TradeRepository,
+		corporateActionRepository: config.CorporateActionRepository,
+		fairMarketValueRepository: config.FairMarketValueRepository,
+		holdingRepository:         config.HoldingRepository,
+		instruments:               config.Instruments,
+	}
+}
+
+// CapitalGains computes the capital gains a user realized in a financial year
+func (s *Service) CapitalGains(ctx context.Context, userID string, fy FinancialYear) (*models.CapitalGainsReport, error) {
+	trades, err := s.tradeRepository.GetTrades(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The asset class, and so the tax treatment, of a security is read from its ISIN
+	if err := s.resolveTradeISINs(userID, trades); err != nil {
+		return nil, err
+	}
+
+	isins := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, trade := range trades {
+		if trade.ISIN != "" && !seen[trade.ISIN] {
+			seen[trade.ISIN] = true
+			isins = append(isins, trade.ISIN)
+		}
+	}
+
+	actions, err := s.corporateActionRepository.GetCorporateActions(isins, fy.To)
+	if err != nil {
+		return nil, err
+	}
+
+	fairMarketValues, err := s.fairMarketValueRepository.GetFairMarketValues(isins)
+	if err != nil {
+		return nil, err
+	}
+
+	book := costbasis.Build(trades, actions)
+	return BuildReport(fy, book, fairMarketValues), nil
+}
+
+// resolveTradeISINs sets missing trade ISINs, first from the user's holdings at the same platform, which know
+// broker codes such as ICICI Direct's, then from the instrument master
+func (s *Service) resolveTradeISINs(userID string, trades []models.Trade) error {
+	isinBySymbol := make(map[string]string)
+	if s.holdingRepository != nil {
+		holdings, _, err := s.holdingRepository.GetLatestSnapshotHoldings(userID)
+		if err != nil {
+			return err
+		}
+		for _, holding := range holdings {
+			if holding.ISIN != "" {
+				isinBySymbol[holding.Platform+"|"+strings.ToUpper(holding.ItemName)] = holding.ISIN
+			}
+		}
+	}
+
+	for i := range trades {
+		if trades[i].ISIN != "" {
+			continue
+		}
+		if isin, ok := isinBySymbol[trades[i].Platform+"|"+strings.ToUpper(trades[i].Symbol)]; ok {
+			trades[i].ISIN = isin
+		} else if s.instruments != nil {
+			if instrument, ok := s.instruments.Lookup(trades[i].Symbol); ok {
+				trades[i].ISIN = instrument.ISIN
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package tax
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kora1128/FinSight/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTaxRepository serves fixed trades and holdings, no corporate actions and the fair market values saved
+type fakeTaxRepository struct {
+	trades   []models.Trade
+	holdings []models.Holding
+	values   map[string]float64
+}
+
+func (r *fakeTaxRepository) GetTrades(userID string) ([]models.Trade, error) {
+	return r.trades, nil
+}
+
+func (r *fakeTaxRepository) GetCorporateActions(isins []string, asOf time.Time) ([]models.CorporateAction, error) {
+	return nil, nil
+}
+
+func (r *fakeTaxRepository) SaveFairMarketValue(isin string, value float64) error {
+	if r.values == nil {
+		r.values = make(map[string]float64)
+	}
+	r.values[isin] = value
+	return nil
+}
+
+func (r *fakeTaxRepository) GetFairMarketValues(isins []string) (map[string]float64, error) {
+	return r.values, nil
+}
+
+func (r *fakeTaxRepository) GetLatestSnapshotHoldings(userID string) ([]models.Holding, bool, error) {
+	return r.holdings, len(r.holdings) > 0, nil
+}
+
+// fakeInstruments resolves symbols from a fixed map to ISINs
+type fakeInstruments map[string]string
+
+func (f fakeInstruments) Lookup(identifier string) (models.Instrument, bool) {
+	isin, ok := f[identifier]
+	return models.Instrument{ISIN: isin}, ok
+}
+
+func TestCapitalGainsResolvesISINs(t *testing.T) {
+	buy, sell := models.TradeSideBuy, models.TradeSideSell
+	trades := []models.Trade{
+		// ICICI Direct codes, resolved from the ICICI Direct holdings
+		testTrade("", "RELIND", buy, date(2025, 4, 10), 10, 1200, 0),
+		testTrade("", "RELIND", sell, date(2025, 8, 1), 5, 1400, 0),
+		// Resolved from the instrument master
+		testTrade("", "INFY", buy, date(2025, 4, 10), 10, 1500, 0),
+		testTrade("", "INFY", sell, date(2025, 8, 1), 10, 1600, 0),
+		// Known to neither
+		testTrade("", "HDFBAN", buy, date(2025, 4, 10), 10, 1500, 0),
+		testTrade("", "HDFBAN", sell, date(2025, 8, 1), 10, 1600, 0),
+	}
+	for i := 0; i < 2; i++ {
+		trades[i].Platform = models.PlatformICICIDirect
+	}
+	repo := &fakeTaxRepository{
+		trades:   trades,
+		holdings: []models.Holding{{ItemName: "RELIND", ISIN: "INE002A01018", Platform: models.PlatformICICIDirect}},
+	}
+	service := NewService(ServiceConfig{
+		TradeRepository:           repo,
+		CorporateActionRepository: repo,
+		FairMarketValueRepository: repo,
+		HoldingRepository:         repo,
+		Instruments:               fakeInstruments{"INFY": "INE009A01021"},
+	})
+
+	fy, err := ParseFinancialYear("2025-26")
+	assert.NoError(t, err)
+	report, err := service.CapitalGains(context.Background(), "user", fy)
+	assert.NoError(t, err)
+
+	if assert.Len(t, report.Entries, 2) {
+		for _, entry := range report.Entries {
+			assert.Equal(t, models.AssetClassEquity, entry.AssetClass)
+			assert.Equal(t, Section111A, entry.Section)
+		}
+	}
+	if assert.Len(t, report.Warnings, 1) {
+		assert.Contains(t, report.Warnings[0], "HDFBAN")
+	}
+}
+
+func TestImportFairMarketValues(t *testing.T) {
+	repo := &fakeTaxRepository{}
+	service := NewService(ServiceConfig{FairMarketValueRepository: repo})
+
+	nse := "SYMBOL,SERIES,OPEN,HIGH,LOW,CLOSE,LAST,PREVCLOSE,TOTTRDQTY,TOTTRDVAL,TIMESTAMP,TOTALTRADES,ISIN,\n" +
+		"RELIANCE,EQ,930.00,947.95,925.10,940.20,940.00,928.60,5123456,4811234567.50,31-JAN-2018,152340,INE002A01018,\n" +
+		"ITC,EQ,270.00,275.40,268.10,272.30,272.00,269.60,8123456,2211234567.50,31-JAN-2018,112340,INE154A01025,\n"
+	bse := "TRADING_DATE,SC_CODE,OPEN,HIGH,LOW,CLOSE,PREVCLOSE,NO_OF_SHRS,NET_TURNOV,ISIN_CODE\n" +
+		"31-Jan-18,500325,931.00,948.50,924.00,939.80,929.10,312345,293123456.00,INE002A01018\n"
+	funds := "isin,fair_market_value\nINF179K01BB8,512.345\n"
+
+	result, err := service.ImportFairMarketValues([][]byte{[]byte(nse), []byte(bse), []byte(funds)})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.FairMarketValueImportResult{Files: 3, Imported: 3}, result)
+	assert.Equal(t, map[string]float64{
+		// The highest price quoted on either exchange
+		"INE002A01018": 948.50,
+		"INE154A01025": 275.40,
+		"INF179K01BB8": 512.345,
+	}, repo.values)
+
+	// A bhavcopy of another day is refused
+	other := strings.ReplaceAll(nse, "31-JAN-2018", "01-FEB-2018")
+	_, err = service.ImportFairMarketValues([][]byte{[]byte(other)})
+	assert.ErrorIs(t, err, ErrInvalidFairMarketValues)
+
+	_, err = service.ImportFairMarketValues([][]byte{[]byte("isin,fair_market_value\nINE002A01018,n/a\n")})
+	assert.ErrorIs(t, err, ErrInvalidFairMarketValues)
+}